@@ -0,0 +1,114 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/ShoshinNikita/budget-manager/internal/app"
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+const commandsUsage = `Usage:
+  budget-manager                               run the server
+  budget-manager backup export [-o <file>]     export all data to a JSON file (stdout by default)
+  budget-manager backup restore <file>         replace all data with data from a JSON file
+  budget-manager migrate-db -from <type> -to <type>
+                                               copy all data from one db to another empty db,
+                                               types are 'postgres' and 'sqlite'
+
+All commands use the same environment variables as the server`
+
+var errUnknownCommand = errors.New("unknown command")
+
+// runCommand runs a command passed as command line arguments
+func runCommand(app *app.App, args []string) error {
+	switch {
+	case len(args) >= 2 && args[0] == "backup" && args[1] == "export":
+		return runBackupExport(app, args[2:])
+	case len(args) >= 2 && args[0] == "backup" && args[1] == "restore":
+		return runBackupRestore(app, args[2:])
+	case len(args) >= 1 && args[0] == "migrate-db":
+		return runMigrateDB(app, args[1:])
+	default:
+		return errUnknownCommand
+	}
+}
+
+func runBackupExport(app *app.App, args []string) (err error) {
+	flags := flag.NewFlagSet("backup export", flag.ContinueOnError)
+	output := flags.String("o", "", "output file")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return errors.Wrap(err, "couldn't create output file")
+		}
+		defer func() {
+			if closeErr := f.Close(); err == nil && closeErr != nil {
+				err = errors.Wrap(closeErr, "couldn't close output file")
+			}
+		}()
+		w = f
+	}
+
+	return app.ExportBackup(context.Background(), w)
+}
+
+func runBackupRestore(app *app.App, args []string) error {
+	if len(args) != 1 {
+		return errors.New("backup file must be passed")
+	}
+
+	f, err := os.Open(args[0])
+	if err != nil {
+		return errors.Wrap(err, "couldn't open backup file")
+	}
+	defer f.Close()
+
+	stats, err := app.RestoreBackup(context.Background(), f)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr,
+		"restored %d Spend Types, %d Spend Rules, %d months, %d Incomes, %d Monthly Payments, %d Spends, "+
+			"%d Saved Searches\n",
+		stats.SpendTypes, stats.SpendRules, stats.Months, stats.Incomes, stats.MonthlyPayments, stats.Spends,
+		stats.SavedSearches,
+	)
+	return nil
+}
+
+func runMigrateDB(app *app.App, args []string) error {
+	flags := flag.NewFlagSet("migrate-db", flag.ContinueOnError)
+	fromFlag := flags.String("from", "", "source db type")
+	toFlag := flags.String("to", "", "destination db type")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var from, to db.Type
+	_ = from.UnmarshalText([]byte(*fromFlag))
+	_ = to.UnmarshalText([]byte(*toFlag))
+	if from == db.Unknown || to == db.Unknown {
+		return errors.New("both source and destination db types must be passed")
+	}
+
+	stats, err := app.MigrateDB(context.Background(), from, to)
+	if err != nil {
+		return err
+	}
+
+	for _, s := range stats {
+		fmt.Fprintf(os.Stderr, "copied %d rows of table %q\n", s.Rows, s.Table)
+	}
+	return nil
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/ShoshinNikita/budget-manager/internal/app"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 )
 
 //nolint:gochecknoglobals
@@ -26,7 +27,7 @@ var (
 // @version v0.2
 // @description Easy-to-use, lightweight and self-hosted solution to track your finances - [GitHub](https://github.com/ShoshinNikita/budget-manager)
 //
-// @BasePath /api
+// @BasePath /
 //
 // @securityDefinitions.basic BasicAuth
 //
@@ -43,6 +44,17 @@ func main() {
 
 	app := app.NewApp(cfg, log, version, gitHash)
 
+	if len(os.Args) > 1 {
+		err := runCommand(app, os.Args[1:])
+		if errors.Is(err, errUnknownCommand) {
+			stdlog.Fatalf("%s\n\n%s\n", err, commandsUsage)
+		}
+		if err != nil {
+			stdlog.Fatalf("command failed: %s\n", err)
+		}
+		return
+	}
+
 	if err := app.PrepareComponents(); err != nil {
 		stdlog.Fatalf("couldn't prepare components: %s\n", err)
 	}
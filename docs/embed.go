@@ -0,0 +1,22 @@
+// Package docs provides Swagger 2.0 documentation of the API. It is generated by 'make generate-docs'
+package docs
+
+import (
+	_ "embed" //nolint:gci
+	"io/fs"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/embed"
+)
+
+// SwaggerFileName is a name of the file with the documentation
+const SwaggerFileName = "swagger.yaml"
+
+//go:embed swagger.yaml
+var docs embed.FS //nolint:gochecknoglobals
+
+func New(useEmbed bool) fs.ReadDirFS {
+	if useEmbed {
+		return docs
+	}
+	return embed.DirFS("docs")
+}
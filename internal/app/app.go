@@ -5,11 +5,14 @@ import (
 	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base"
 	"github.com/ShoshinNikita/budget-manager/internal/db/pg"
 	"github.com/ShoshinNikita/budget-manager/internal/db/sqlite"
+	"github.com/ShoshinNikita/budget-manager/internal/events"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/webhooks"
 )
 
 type App struct {
@@ -17,9 +20,11 @@ type App struct {
 	version string
 	gitHash string
 
-	db     Database
-	log    logger.Logger
-	server *web.Server
+	db       Database
+	log      logger.Logger
+	server   *web.Server
+	webhooks *webhooks.Sender
+	events   *events.Bus
 
 	shutdownSignal chan struct{}
 }
@@ -29,6 +34,7 @@ type Database interface {
 	Shutdown() error
 
 	web.Database
+	webhooks.DB
 }
 
 // NewApp returns a new instance of App
@@ -44,13 +50,19 @@ func NewApp(cfg Config, log logger.Logger, version, gitHash string) *App {
 	}
 }
 
-// PrepareComponents prepares logger, db and web server
+// PrepareComponents prepares logger, event bus, db, webhook sender and web server
 func (app *App) PrepareComponents() error {
+	app.log.Debug("prepare event bus")
+	app.events = events.NewBus()
+
 	app.log.Debug("prepare database")
 	if err := app.prepareDB(); err != nil {
 		return errors.Wrap(err, "couldn't prepare database")
 	}
 
+	app.log.Debug("prepare webhook sender")
+	app.webhooks = webhooks.NewSender(app.config.Webhooks, app.db, app.log)
+
 	app.log.Debug("prepare web server")
 	if err := app.prepareWebServer(); err != nil {
 		return errors.Wrap(err, "couldn't prepare web server")
@@ -59,22 +71,16 @@ func (app *App) PrepareComponents() error {
 	return nil
 }
 
-func (app *App) prepareDB() (err error) {
-	switch app.config.DB.Type {
-	case db.Postgres:
-		app.log.Debug("db type is PostgreSQL")
-		app.db, err = pg.NewDB(app.config.DB.Postgres, app.log)
-
-	case db.Sqlite3:
-		app.log.Debug("db type is SQLite")
-		app.db, err = sqlite.NewDB(app.config.DB.SQLite, app.log)
-
-	default:
-		err = errors.New("unsupported DB type")
-	}
+func (app *App) prepareDB() error {
+	database, err := app.openDB(app.config.DB.Type)
 	if err != nil {
-		return errors.Wrap(err, "couldn't create DB connection")
+		return err
 	}
+	// The event bus is prepared only by PrepareComponents, backup commands don't need it
+	if app.events != nil {
+		database.SetMonthChangePublisher(app.events)
+	}
+	app.db = database
 
 	// Init the current month
 	if err := app.initMonth(time.Now()); err != nil {
@@ -84,9 +90,37 @@ func (app *App) prepareDB() (err error) {
 	return nil
 }
 
-//nolint:unparam
+// openDB opens a connection to the db of the passed type and applies the migrations
+func (app *App) openDB(dbType db.Type) (*base.DB, error) {
+	switch dbType {
+	case db.Postgres:
+		app.log.Debug("db type is PostgreSQL")
+		pgDB, err := pg.NewDB(app.config.DB.Postgres, app.log)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't create DB connection")
+		}
+		return pgDB.DB, nil
+
+	case db.Sqlite3:
+		app.log.Debug("db type is SQLite")
+		sqliteDB, err := sqlite.NewDB(app.config.DB.SQLite, app.log)
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't create DB connection")
+		}
+		return sqliteDB.DB, nil
+
+	default:
+		return nil, errors.New("unsupported DB type")
+	}
+}
+
 func (app *App) prepareWebServer() error {
-	app.server = web.NewServer(app.config.Server, app.db, app.log, app.version, app.gitHash)
+	// Misconfigured proxy auth could let anyone in, so the app doesn't start
+	if err := app.config.Server.Auth.Proxy.Validate(); err != nil {
+		return errors.Wrap(err, "invalid proxy auth config")
+	}
+
+	app.server = web.NewServer(app.config.Server, app.db, app.events, app.log, app.version, app.gitHash)
 	return nil
 }
 
@@ -109,6 +143,7 @@ func (app *App) Run() error {
 	}
 	startBackroundJob("web server failed", app.server.ListenAndServer)
 	startBackroundJob("month init failed", app.startMonthInit)
+	startBackroundJob("webhook sender failed", app.startWebhookSender)
 
 	return <-errCh
 }
@@ -118,11 +153,19 @@ func (app *App) Shutdown() {
 	app.log.Info("shutdown app")
 	close(app.shutdownSignal)
 
+	// Close the event streams. Otherwise, the server waits for them until the shutdown timeout
+	app.log.Debug("close event bus")
+	app.events.Close()
+
 	app.log.Debug("shutdown web server")
 	if err := app.server.Shutdown(); err != nil {
 		app.log.WithError(err).Error("couldn't shutdown the server gracefully")
 	}
 
+	app.shutdownDB()
+}
+
+func (app *App) shutdownDB() {
 	app.log.Debug("shutdown the database")
 	if err := app.db.Shutdown(); err != nil {
 		app.log.WithError(err).Error("couldn't shutdown the db gracefully")
@@ -147,6 +190,25 @@ func (app *App) startMonthInit() error {
 	}
 }
 
+// startWebhookSender periodically sends pending deliveries of Webhooks. Errors are only logged
+// because the deliveries will be sent during the next iteration
+func (app *App) startWebhookSender() error {
+	ticker := time.NewTicker(app.config.Webhooks.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := app.webhooks.SendDueDeliveries(context.Background()); err != nil {
+				app.log.WithError(err).Error("couldn't send webhooks")
+			}
+
+		case <-app.shutdownSignal:
+			return nil
+		}
+	}
+}
+
 // calculateTimeToNextMonthInit returns time left to the start (00:00) of the next month
 func calculateTimeToNextMonthInit(now time.Time) time.Duration {
 	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location())
@@ -0,0 +1,58 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// ExportBackup writes a backup of all data to w. It must be called instead of PrepareComponents
+func (app *App) ExportBackup(ctx context.Context, w io.Writer) error {
+	if err := app.prepareDB(); err != nil {
+		return errors.Wrap(err, "couldn't prepare database")
+	}
+	defer app.shutdownDB()
+
+	backup, err := app.db.ExportBackup(ctx)
+	if err != nil {
+		return errors.Wrap(err, "couldn't export backup")
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(backup); err != nil {
+		return errors.Wrap(err, "couldn't encode backup")
+	}
+	return nil
+}
+
+// RestoreBackup replaces all data with a backup read from r. It must be called instead of PrepareComponents
+func (app *App) RestoreBackup(ctx context.Context, r io.Reader) (db.BackupStats, error) {
+	var backup db.Backup
+	if err := json.NewDecoder(r).Decode(&backup); err != nil {
+		return db.BackupStats{}, errors.Wrap(err, "couldn't decode backup")
+	}
+	if err := backup.Validate(); err != nil {
+		return db.BackupStats{}, errors.Wrap(err, "invalid backup")
+	}
+
+	if err := app.prepareDB(); err != nil {
+		return db.BackupStats{}, errors.Wrap(err, "couldn't prepare database")
+	}
+	defer app.shutdownDB()
+
+	stats, err := app.db.RestoreBackup(ctx, backup)
+	if err != nil {
+		return db.BackupStats{}, errors.Wrap(err, "couldn't restore backup")
+	}
+
+	// The backup may not contain the current month
+	if err := app.initMonth(time.Now()); err != nil {
+		return db.BackupStats{}, errors.Wrap(err, "couldn't init the current month")
+	}
+	return stats, nil
+}
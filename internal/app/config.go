@@ -1,18 +1,23 @@
 package app
 
 import (
+	"time"
+
 	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/db/pg"
 	"github.com/ShoshinNikita/budget-manager/internal/db/sqlite"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/env"
 	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
+	"github.com/ShoshinNikita/budget-manager/internal/webhooks"
 )
 
 type Config struct {
-	Logger logger.Config
-	DB     DBConfig
-	Server web.Config
+	Logger   logger.Config
+	DB       DBConfig
+	Server   web.Config
+	Webhooks webhooks.Config
 }
 
 type DBConfig struct {
@@ -45,10 +50,33 @@ func ParseConfig() (Config, error) {
 			Port:            8080,
 			UseEmbed:        true,
 			EnableProfiling: false,
+			TrustedProxies:  nil,
 			Auth: web.AuthConfig{
 				Disable:        false,
 				BasicAuthCreds: nil,
+				SessionTTL:     24 * time.Hour,
+				RememberMeTTL:  30 * 24 * time.Hour,
+				SecureCookie:   true,
+				Lockout: lockout.Config{
+					Threshold:   5,
+					Duration:    time.Minute,
+					MaxDuration: time.Hour,
+				},
+				Proxy: web.ProxyAuthConfig{
+					Header:         "",
+					TrustedProxies: nil,
+				},
 			},
+			CalendarReminder:  24 * time.Hour,
+			IdempotencyKeyTTL: 24 * time.Hour,
+		},
+		Webhooks: webhooks.Config{
+			PollInterval:    5 * time.Second,
+			Timeout:         10 * time.Second,
+			MaxAttempts:     8,
+			RetryDelay:      30 * time.Second,
+			MaxRetryDelay:   time.Hour,
+			RetentionPeriod: 30 * 24 * time.Hour,
 		},
 	}
 
@@ -70,8 +98,26 @@ func ParseConfig() (Config, error) {
 		{"SERVER_PORT", &cfg.Server.Port},
 		{"SERVER_USE_EMBED", &cfg.Server.UseEmbed},
 		{"SERVER_ENABLE_PROFILING", &cfg.Server.EnableProfiling},
+		{"SERVER_TRUSTED_PROXIES", &cfg.Server.TrustedProxies},
 		{"SERVER_AUTH_DISABLE", &cfg.Server.Auth.Disable},
 		{"SERVER_AUTH_BASIC_CREDS", &cfg.Server.Auth.BasicAuthCreds},
+		{"SERVER_AUTH_SESSION_TTL", &cfg.Server.Auth.SessionTTL},
+		{"SERVER_AUTH_REMEMBER_ME_TTL", &cfg.Server.Auth.RememberMeTTL},
+		{"SERVER_AUTH_SECURE_COOKIE", &cfg.Server.Auth.SecureCookie},
+		{"SERVER_AUTH_LOCKOUT_THRESHOLD", &cfg.Server.Auth.Lockout.Threshold},
+		{"SERVER_AUTH_LOCKOUT_DURATION", &cfg.Server.Auth.Lockout.Duration},
+		{"SERVER_AUTH_LOCKOUT_MAX_DURATION", &cfg.Server.Auth.Lockout.MaxDuration},
+		{"SERVER_AUTH_PROXY_HEADER", &cfg.Server.Auth.Proxy.Header},
+		{"SERVER_AUTH_PROXY_TRUSTED_PROXIES", &cfg.Server.Auth.Proxy.TrustedProxies},
+		{"SERVER_CALENDAR_REMINDER", &cfg.Server.CalendarReminder},
+		{"SERVER_IDEMPOTENCY_KEY_TTL", &cfg.Server.IdempotencyKeyTTL},
+		//
+		{"WEBHOOKS_POLL_INTERVAL", &cfg.Webhooks.PollInterval},
+		{"WEBHOOKS_TIMEOUT", &cfg.Webhooks.Timeout},
+		{"WEBHOOKS_MAX_ATTEMPTS", &cfg.Webhooks.MaxAttempts},
+		{"WEBHOOKS_RETRY_DELAY", &cfg.Webhooks.RetryDelay},
+		{"WEBHOOKS_MAX_RETRY_DELAY", &cfg.Webhooks.MaxRetryDelay},
+		{"WEBHOOKS_RETENTION_PERIOD", &cfg.Webhooks.RetentionPeriod},
 	} {
 		if err := env.Load(v.key, v.target); err != nil {
 			return Config{}, err
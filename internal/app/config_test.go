@@ -1,8 +1,10 @@
 package app
 
 import (
+	"net"
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
@@ -11,6 +13,8 @@ import (
 	"github.com/ShoshinNikita/budget-manager/internal/db/sqlite"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
+	"github.com/ShoshinNikita/budget-manager/internal/webhooks"
 )
 
 func TestParseConfig(t *testing.T) {
@@ -31,8 +35,25 @@ func TestParseConfig(t *testing.T) {
 		{"SERVER_PORT", "6666"},
 		{"SERVER_USE_EMBED", "false"},
 		{"SERVER_ENABLE_PROFILING", "true"},
+		{"SERVER_TRUSTED_PROXIES", "127.0.0.1, 10.0.0.0/8"},
 		{"SERVER_AUTH_DISABLE", "true"},
-		{"SERVER_AUTH_BASIC_CREDS", "user:qwerty,admin:admin"},
+		{"SERVER_AUTH_BASIC_CREDS", "user:qwerty,admin:admin:totp"},
+		{"SERVER_AUTH_SESSION_TTL", "12h"},
+		{"SERVER_AUTH_REMEMBER_ME_TTL", "168h"},
+		{"SERVER_AUTH_SECURE_COOKIE", "false"},
+		{"SERVER_AUTH_LOCKOUT_THRESHOLD", "10"},
+		{"SERVER_AUTH_LOCKOUT_DURATION", "30s"},
+		{"SERVER_AUTH_LOCKOUT_MAX_DURATION", "2h"},
+		{"SERVER_AUTH_PROXY_HEADER", "Remote-User"},
+		{"SERVER_AUTH_PROXY_TRUSTED_PROXIES", "172.16.0.0/12"},
+		{"SERVER_CALENDAR_REMINDER", "2h30m"},
+		{"SERVER_IDEMPOTENCY_KEY_TTL", "1h"},
+		{"WEBHOOKS_POLL_INTERVAL", "1s"},
+		{"WEBHOOKS_TIMEOUT", "3s"},
+		{"WEBHOOKS_MAX_ATTEMPTS", "3"},
+		{"WEBHOOKS_RETRY_DELAY", "1m"},
+		{"WEBHOOKS_MAX_RETRY_DELAY", "10m"},
+		{"WEBHOOKS_RETENTION_PERIOD", "168h"},
 	}
 	for _, env := range envs {
 		os.Setenv(env.key, env.value)
@@ -60,13 +81,41 @@ func TestParseConfig(t *testing.T) {
 			Port:            6666,
 			UseEmbed:        false,
 			EnableProfiling: true,
+			TrustedProxies: web.TrustedProxies{
+				{IP: net.IPv4(127, 0, 0, 1).To4(), Mask: net.CIDRMask(32, 32)},
+				{IP: net.IPv4(10, 0, 0, 0).To4(), Mask: net.CIDRMask(8, 32)},
+			},
 			Auth: web.AuthConfig{
 				Disable: true,
 				BasicAuthCreds: web.Credentials{
-					"user":  "qwerty",
-					"admin": "admin",
+					"user":  {PasswordHash: "qwerty"},
+					"admin": {PasswordHash: "admin", RequireTOTP: true},
+				},
+				SessionTTL:    12 * time.Hour,
+				RememberMeTTL: 7 * 24 * time.Hour,
+				SecureCookie:  false,
+				Lockout: lockout.Config{
+					Threshold:   10,
+					Duration:    30 * time.Second,
+					MaxDuration: 2 * time.Hour,
+				},
+				Proxy: web.ProxyAuthConfig{
+					Header: "Remote-User",
+					TrustedProxies: web.TrustedProxies{
+						{IP: net.IPv4(172, 16, 0, 0).To4(), Mask: net.CIDRMask(12, 32)},
+					},
 				},
 			},
+			CalendarReminder:  2*time.Hour + 30*time.Minute,
+			IdempotencyKeyTTL: time.Hour,
+		},
+		Webhooks: webhooks.Config{
+			PollInterval:    time.Second,
+			Timeout:         3 * time.Second,
+			MaxAttempts:     3,
+			RetryDelay:      time.Minute,
+			MaxRetryDelay:   10 * time.Minute,
+			RetentionPeriod: 7 * 24 * time.Hour,
 		},
 	}
 
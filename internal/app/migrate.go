@@ -0,0 +1,49 @@
+package app
+
+import (
+	"context"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// MigrateDB copies all data from one db to another and checks that the data matches. Connection
+// settings for both dbs are taken from the config. The destination db must be empty. It must be
+// called instead of PrepareComponents
+func (app *App) MigrateDB(ctx context.Context, from, to db.Type) ([]base.TableStats, error) {
+	if from == to {
+		return nil, errors.New("source and destination dbs must have different types")
+	}
+
+	src, err := app.openDB(from)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open source db")
+	}
+	defer app.closeDB(src)
+
+	dst, err := app.openDB(to)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open destination db")
+	}
+	defer app.closeDB(dst)
+
+	app.log.Info("copy data")
+	stats, err := src.CopyTo(ctx, dst)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't copy data")
+	}
+
+	app.log.Info("check copied data")
+	if err := src.Compare(ctx, dst); err != nil {
+		return nil, errors.Wrap(err, "copied data doesn't match")
+	}
+
+	return stats, nil
+}
+
+func (app *App) closeDB(conn *base.DB) {
+	if err := conn.Shutdown(); err != nil {
+		app.log.WithError(err).Error("couldn't shutdown the db gracefully")
+	}
+}
@@ -22,6 +22,10 @@ type EditIncomeArgs struct {
 	Title  *string
 	Notes  *string
 	Income *money.Money
+
+	// Version is an expected version of the record. The edit fails with ErrVersionConflict
+	// if the record has another version. The version isn't checked if it is nil
+	Version *int
 }
 
 // ----------------------------------------------------
@@ -34,6 +38,7 @@ type AddMonthlyPaymentArgs struct {
 	TypeID  uint
 	Notes   string
 	Cost    money.Money
+	DueDay  uint // optional
 }
 
 type EditMonthlyPaymentArgs struct {
@@ -43,6 +48,11 @@ type EditMonthlyPaymentArgs struct {
 	TypeID *uint
 	Notes  *string
 	Cost   *money.Money
+	DueDay *uint // use 0 to reset
+
+	// Version is an expected version of the record. The edit fails with ErrVersionConflict
+	// if the record has another version. The version isn't checked if it is nil
+	Version *int
 }
 
 // ----------------------------------------------------
@@ -63,10 +73,15 @@ type EditSpendArgs struct {
 	TypeID *uint
 	Notes  *string
 	Cost   *money.Money
+	DueDay *uint // use 0 to reset
+
+	// Version is an expected version of the record. The edit fails with ErrVersionConflict
+	// if the record has another version. The version isn't checked if it is nil
+	Version *int
 }
 
 // ----------------------------------------------------
-// Spend
+// Spend Type
 // ----------------------------------------------------
 
 type AddSpendTypeArgs struct {
@@ -78,6 +93,27 @@ type EditSpendTypeArgs struct {
 	ID       uint
 	Name     *string
 	ParentID *uint
+
+	// Version is an expected version of the record. The edit fails with ErrVersionConflict
+	// if the record has another version. The version isn't checked if it is nil
+	Version *int
+}
+
+// ----------------------------------------------------
+// Spend Rule
+// ----------------------------------------------------
+
+type AddSpendRuleArgs struct {
+	TitlePattern string
+	TypeID       uint
+	Notes        string // optional
+}
+
+type EditSpendRuleArgs struct {
+	ID           uint
+	TitlePattern *string
+	TypeID       *uint
+	Notes        *string
 }
 
 // ----------------------------------------------------
@@ -92,8 +128,139 @@ const (
 	OrderByDesc
 )
 
-// SearchSpendsArgs is used to search for spends. All fields are optional
+// SearchSpendsArgs is used to search for spends. All fields are optional. The args are stored
+// in Saved Searches, so json names and values of enums must not be changed
 type SearchSpendsArgs struct {
+	Title string `json:"title,omitempty"` // Must be in lovercase
+	Notes string `json:"notes,omitempty"` // Must be in lovercase
+
+	// Text is a full-text query. Spends match if their titles or notes contain all words of the query.
+	// Words are compared by their stems
+	Text string `json:"text,omitempty"`
+
+	// TitleExactly defines should we search exactly for the given title
+	TitleExactly bool `json:"title_exactly,omitempty"`
+	// NotesExactly defines should we search exactly for the given notes
+	NotesExactly bool `json:"notes_exactly,omitempty"`
+
+	After  time.Time `json:"after"`
+	Before time.Time `json:"before"`
+
+	MinCost money.Money `json:"min_cost,omitempty" swaggertype:"number"`
+	MaxCost money.Money `json:"max_cost,omitempty" swaggertype:"number"`
+
+	// TypeIDs is a list of Spend Type ids to search for. Use id '0' to search for Spends without type
+	TypeIDs []uint `json:"type_ids,omitempty"`
+
+	Sort  SearchSpendsColumn `json:"sort,omitempty"`
+	Order SearchOrder        `json:"order,omitempty"`
+
+	// Limit is a max number of returned Spends. Zero means no limit
+	Limit int `json:"limit,omitempty"`
+	// Offset is a number of Spends to skip. It is used only with Limit
+	Offset int `json:"offset,omitempty"`
+
+	// Expr is an additional filter. It is combined with other args with AND
+	Expr *SearchSpendsExpr `json:"expr,omitempty"`
+}
+
+// RangeTypeIDs calls fn for every Spend Type id in TypeIDs and Expr. fn can modify ids
+func (args *SearchSpendsArgs) RangeTypeIDs(fn func(id *uint)) {
+	for i := range args.TypeIDs {
+		fn(&args.TypeIDs[i])
+	}
+	if args.Expr != nil {
+		args.Expr.rangeTypeIDs(fn)
+	}
+}
+
+// SearchSpendsExpr is a boolean expression used to filter Spends. Only one field must be set
+type SearchSpendsExpr struct {
+	And  []SearchSpendsExpr `json:"and,omitempty"`
+	Or   []SearchSpendsExpr `json:"or,omitempty"`
+	Not  *SearchSpendsExpr  `json:"not,omitempty"`
+	Cond *SearchSpendsCond  `json:"cond,omitempty"`
+}
+
+func (expr *SearchSpendsExpr) rangeTypeIDs(fn func(id *uint)) {
+	for i := range expr.And {
+		expr.And[i].rangeTypeIDs(fn)
+	}
+	for i := range expr.Or {
+		expr.Or[i].rangeTypeIDs(fn)
+	}
+	if expr.Not != nil {
+		expr.Not.rangeTypeIDs(fn)
+	}
+	if expr.Cond != nil {
+		for i := range expr.Cond.TypeIDs {
+			fn(&expr.Cond.TypeIDs[i])
+		}
+	}
+}
+
+// SearchSpendsCond is a condition on a single field of Spends
+type SearchSpendsCond struct {
+	Field SearchSpendsField `json:"field"`
+	Op    SearchSpendsOp    `json:"op"`
+
+	// Text is used for Title and Notes. Must be in lowercase
+	Text string `json:"text,omitempty"`
+	// Cost is used for Cost
+	Cost money.Money `json:"cost,omitempty" swaggertype:"number"`
+	// Date is used for Date
+	Date time.Time `json:"date"`
+	// TypeIDs is used for Type. Spends without type match id '0'. Only OpEqual is supported
+	TypeIDs []uint `json:"type_ids,omitempty"`
+}
+
+// SearchSpendsField is used to specify a field of a condition
+type SearchSpendsField int
+
+const (
+	SearchSpendsByTitle SearchSpendsField = iota
+	SearchSpendsByNotes
+	SearchSpendsByCost
+	SearchSpendsByDate
+	SearchSpendsByType
+)
+
+// SearchSpendsOp is used to specify an operator of a condition. OpContains is supported only
+// by Title and Notes, OpLess, OpLessOrEqual, OpGreater and OpGreaterOrEqual - only by Cost and Date
+type SearchSpendsOp int
+
+const (
+	OpEqual SearchSpendsOp = iota
+	OpContains
+	OpLess
+	OpLessOrEqual
+	OpGreater
+	OpGreaterOrEqual
+)
+
+// SearchIncomesArgs is used to search for incomes. All fields are optional. Incomes are sorted by date
+type SearchIncomesArgs struct {
+	Title string // Must be in lovercase
+	Notes string // Must be in lovercase
+
+	// TitleExactly defines should we search exactly for the given title
+	TitleExactly bool
+	// NotesExactly defines should we search exactly for the given notes
+	NotesExactly bool
+
+	// After and Before are used to filter months. A month matches if its first day is within the range
+	After  time.Time
+	Before time.Time
+
+	MinIncome money.Money
+	MaxIncome money.Money
+
+	Order SearchOrder
+}
+
+// SearchMonthlyPaymentsArgs is used to search for monthly payments. All fields are optional.
+// Monthly Payments are sorted by date
+type SearchMonthlyPaymentsArgs struct {
 	Title string // Must be in lovercase
 	Notes string // Must be in lovercase
 
@@ -102,16 +269,16 @@ type SearchSpendsArgs struct {
 	// NotesExactly defines should we search exactly for the given notes
 	NotesExactly bool
 
+	// After and Before are used to filter months. A month matches if its first day is within the range
 	After  time.Time
 	Before time.Time
 
 	MinCost money.Money
 	MaxCost money.Money
 
-	// TypeIDs is a list of Spend Type ids to search for. Use id '0' to search for Spends without type
+	// TypeIDs is a list of Spend Type ids to search for. Use id '0' to search for Monthly Payments without type
 	TypeIDs []uint
 
-	Sort  SearchSpendsColumn
 	Order SearchOrder
 }
 
@@ -122,4 +289,138 @@ const (
 	SortSpendsByDate SearchSpendsColumn = iota
 	SortSpendsByTitle
 	SortSpendsByCost
+	// SortSpendsByRelevance sorts Spends by relevance to the full-text query, the most relevant Spends
+	// go first and Order is ignored. Spends are sorted by date if the query is empty
+	SortSpendsByRelevance
 )
+
+// ----------------------------------------------------
+// Saved Search
+// ----------------------------------------------------
+
+type AddSavedSearchArgs struct {
+	Name   string
+	Pinned bool
+	Args   SearchSpendsArgs
+}
+
+type EditSavedSearchArgs struct {
+	ID     uint
+	Name   *string
+	Pinned *bool
+	Args   *SearchSpendsArgs
+}
+
+// ----------------------------------------------------
+// Webhook
+// ----------------------------------------------------
+
+type AddWebhookArgs struct {
+	URL     string
+	Secret  string
+	Events  []WebhookEvent
+	Enabled bool
+}
+
+type EditWebhookArgs struct {
+	ID      uint
+	URL     *string
+	Secret  *string
+	Events  *[]WebhookEvent
+	Enabled *bool
+}
+
+type GetWebhookDeliveriesArgs struct {
+	// WebhookID is used to filter deliveries. All deliveries are returned if it is 0
+	WebhookID uint
+	// Status is used to filter deliveries. All deliveries are returned if it is empty
+	Status WebhookDeliveryStatus
+	Limit  int
+}
+
+// DueWebhookDelivery is a pending delivery with a Webhook it should be sent to
+type DueWebhookDelivery struct {
+	WebhookDelivery
+
+	URL    string
+	Secret string
+}
+
+type UpdateWebhookDeliveryArgs struct {
+	ID            uint
+	Status        WebhookDeliveryStatus
+	Attempts      int
+	ResponseCode  int
+	Error         string
+	NextAttemptAt time.Time
+}
+
+type AddIdempotencyKeyArgs struct {
+	Key         string
+	RequestHash string
+	// CreatedAt is also used to remove expired keys
+	CreatedAt time.Time
+	ExpiresAt time.Time
+}
+
+type SaveIdempotentResponseArgs struct {
+	Key          string
+	StatusCode   int
+	ContentType  string
+	ResponseBody string
+}
+
+// ----------------------------------------------------
+// API Token
+// ----------------------------------------------------
+
+type AddAPITokenArgs struct {
+	Username  string
+	Name      string
+	TokenHash string
+	ReadOnly  bool
+	Endpoints []string
+	CreatedAt time.Time
+}
+
+// ----------------------------------------------------
+// Session
+// ----------------------------------------------------
+
+type AddSessionArgs struct {
+	Username  string
+	TokenHash string
+	// CreatedAt is also used to remove expired sessions
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// TOTPPending sessions can be used only to pass two-factor authentication
+	TOTPPending bool
+}
+
+// ----------------------------------------------------
+// Auth Lockout
+// ----------------------------------------------------
+
+type AddAuthFailureArgs struct {
+	Kind     AuthLockoutKind
+	Value    string
+	FailedAt time.Time
+	// RemoveStaleBefore is used to remove lockouts without failed attempts and active locks after this time.
+	// Failed attempts of such lockouts are forgotten
+	RemoveStaleBefore time.Time
+	// LockedUntil returns the end of the lockout after the passed number of failed attempts. It returns nil
+	// if attempts must not be locked. The lock is saved in the same transaction as the failed attempt
+	LockedUntil func(failures int) *time.Time
+}
+
+// ----------------------------------------------------
+// TOTP
+// ----------------------------------------------------
+
+type EnableTOTPArgs struct {
+	Username string
+	// Step is a time step of the code used to confirm the secret
+	Step int64
+	// RecoveryCodeHashes are SHA-256 hashes of new recovery codes
+	RecoveryCodeHashes []string
+}
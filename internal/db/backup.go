@@ -0,0 +1,275 @@
+package db
+
+import (
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+// BackupVersion is the current version of the backup format. It must be increased after
+// every incompatible change
+const BackupVersion = 1
+
+// Backup is a db-agnostic representation of all data. Ids are used only to link
+// Spend Types with each other and with other entities. They are rebuilt during restore
+type Backup struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"created_at"`
+
+	SpendTypes []BackupSpendType `json:"spend_types"`
+	SpendRules []BackupSpendRule `json:"spend_rules"`
+	Months     []BackupMonth     `json:"months"`
+
+	SavedSearches []BackupSavedSearch `json:"saved_searches"`
+}
+
+type BackupSpendType struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	ParentID uint   `json:"parent_id,omitempty"`
+}
+
+type BackupSpendRule struct {
+	TitlePattern string `json:"title_pattern"`
+	TypeID       uint   `json:"type_id"`
+	Notes        string `json:"notes,omitempty"`
+}
+
+type BackupSavedSearch struct {
+	Name   string           `json:"name"`
+	Pinned bool             `json:"pinned,omitempty"`
+	Args   SearchSpendsArgs `json:"args"`
+}
+
+type BackupMonth struct {
+	Year  int        `json:"year"`
+	Month time.Month `json:"month"`
+
+	Incomes         []BackupIncome         `json:"incomes"`
+	MonthlyPayments []BackupMonthlyPayment `json:"monthly_payments"`
+	Days            []BackupDay            `json:"days"`
+}
+
+type BackupIncome struct {
+	Title  string      `json:"title"`
+	Notes  string      `json:"notes,omitempty"`
+	Income money.Money `json:"income"`
+}
+
+type BackupMonthlyPayment struct {
+	Title  string      `json:"title"`
+	TypeID uint        `json:"type_id,omitempty"`
+	Notes  string      `json:"notes,omitempty"`
+	Cost   money.Money `json:"cost"`
+	DueDay uint        `json:"due_day,omitempty"`
+}
+
+// BackupDay contains Spends of a single day. Days without Spends can be omitted
+type BackupDay struct {
+	Day    int           `json:"day"`
+	Spends []BackupSpend `json:"spends"`
+}
+
+type BackupSpend struct {
+	Title  string      `json:"title"`
+	TypeID uint        `json:"type_id,omitempty"`
+	Notes  string      `json:"notes,omitempty"`
+	Cost   money.Money `json:"cost"`
+}
+
+// BackupError is returned by Validate. It contains a json name of the invalid part of the backup
+type BackupError struct {
+	Field string
+	Err   error
+}
+
+func (e *BackupError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BackupError) Unwrap() error {
+	return e.Err
+}
+
+// Validate checks whether the backup is consistent and can be restored. It returns *BackupError
+func (b Backup) Validate() error {
+	if b.Version != BackupVersion {
+		return &BackupError{
+			Field: "version",
+			Err:   errors.Errorf("unsupported backup version %d, expected %d", b.Version, BackupVersion),
+		}
+	}
+
+	spendTypes, err := b.validateSpendTypes()
+	if err != nil {
+		return &BackupError{Field: "spend_types", Err: err}
+	}
+	checkTypeID := func(id uint) error {
+		if _, ok := spendTypes[id]; id != 0 && !ok {
+			return errors.Errorf("there's no Spend Type with id %d", id)
+		}
+		return nil
+	}
+	if err := b.validateSpendRules(checkTypeID); err != nil {
+		return &BackupError{Field: "spend_rules", Err: err}
+	}
+	if err := b.validateSavedSearches(checkTypeID); err != nil {
+		return &BackupError{Field: "saved_searches", Err: err}
+	}
+	if err := b.validateMonths(checkTypeID); err != nil {
+		return &BackupError{Field: "months", Err: err}
+	}
+	return nil
+}
+
+func (b Backup) validateSpendTypes() (map[uint]BackupSpendType, error) {
+	spendTypes := make(map[uint]BackupSpendType, len(b.SpendTypes))
+	for _, t := range b.SpendTypes {
+		if t.ID == 0 {
+			return nil, errors.New("id of Spend Type can't be zero")
+		}
+		if _, ok := spendTypes[t.ID]; ok {
+			return nil, errors.Errorf("duplicate Spend Type id %d", t.ID)
+		}
+		if t.Name == "" {
+			return nil, errors.Errorf("name of Spend Type with id %d is empty", t.ID)
+		}
+		spendTypes[t.ID] = t
+	}
+	for _, t := range b.SpendTypes {
+		if _, ok := spendTypes[t.ParentID]; t.ParentID != 0 && !ok {
+			return nil, errors.Errorf("invalid parent of Spend Type with id %d: there's no Spend Type with id %d",
+				t.ID, t.ParentID)
+		}
+
+		// Check for a cycle
+		parentID := t.ParentID
+		for depth := 0; parentID != 0; depth++ {
+			if parentID == t.ID || depth > len(spendTypes) {
+				return nil, errors.Errorf("parents of Spend Type with id %d have a cycle", t.ID)
+			}
+			parentID = spendTypes[parentID].ParentID
+		}
+	}
+
+	return spendTypes, nil
+}
+
+func (b Backup) validateSpendRules(checkTypeID func(id uint) error) error {
+	for i, r := range b.SpendRules {
+		if r.TitlePattern == "" {
+			return errors.Errorf("title pattern of Spend Rule #%d is empty", i+1)
+		}
+		if r.TypeID == 0 {
+			return errors.Errorf("type of Spend Rule #%d is not set", i+1)
+		}
+		if err := checkTypeID(r.TypeID); err != nil {
+			return errors.Wrapf(err, "invalid Spend Rule #%d", i+1)
+		}
+	}
+
+	return nil
+}
+
+func (b Backup) validateSavedSearches(checkTypeID func(id uint) error) error {
+	for i, s := range b.SavedSearches {
+		if s.Name == "" {
+			return errors.Errorf("name of Saved Search #%d is empty", i+1)
+		}
+		var err error
+		s.Args.RangeTypeIDs(func(id *uint) {
+			if err == nil {
+				err = checkTypeID(*id)
+			}
+		})
+		if err != nil {
+			return errors.Wrapf(err, "invalid Saved Search #%d", i+1)
+		}
+	}
+
+	return nil
+}
+
+//nolint:gocognit
+func (b Backup) validateMonths(checkTypeID func(id uint) error) error {
+	type monthKey struct {
+		year  int
+		month time.Month
+	}
+	months := make(map[monthKey]struct{}, len(b.Months))
+	for _, m := range b.Months {
+		if m.Year <= 0 || !(time.January <= m.Month && m.Month <= time.December) {
+			return errors.Errorf("invalid month %d-%d", m.Year, m.Month)
+		}
+		key := monthKey{m.Year, m.Month}
+		if _, ok := months[key]; ok {
+			return errors.Errorf("duplicate month %d-%02d", m.Year, m.Month)
+		}
+		months[key] = struct{}{}
+
+		wrapErr := func(err error) error {
+			return errors.Wrapf(err, "invalid month %d-%02d", m.Year, m.Month)
+		}
+
+		for _, in := range m.Incomes {
+			if in.Title == "" {
+				return wrapErr(errors.New("title of Income can't be empty"))
+			}
+			if in.Income <= 0 {
+				return wrapErr(errors.Errorf("income of Income %q must be greater than zero", in.Title))
+			}
+		}
+		for _, mp := range m.MonthlyPayments {
+			if mp.Title == "" {
+				return wrapErr(errors.New("title of Monthly Payment can't be empty"))
+			}
+			if mp.Cost <= 0 {
+				return wrapErr(errors.Errorf("cost of Monthly Payment %q must be greater than zero", mp.Title))
+			}
+			if mp.DueDay > 31 {
+				return wrapErr(errors.Errorf("due day of Monthly Payment %q is invalid", mp.Title))
+			}
+			if err := checkTypeID(mp.TypeID); err != nil {
+				return wrapErr(errors.Wrapf(err, "invalid Monthly Payment %q", mp.Title))
+			}
+		}
+
+		lastDay := time.Date(m.Year, m.Month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+		days := make(map[int]struct{}, len(m.Days))
+		for _, d := range m.Days {
+			if d.Day < 1 || d.Day > lastDay {
+				return wrapErr(errors.Errorf("invalid day %d", d.Day))
+			}
+			if _, ok := days[d.Day]; ok {
+				return wrapErr(errors.Errorf("duplicate day %d", d.Day))
+			}
+			days[d.Day] = struct{}{}
+
+			for _, s := range d.Spends {
+				if s.Title == "" {
+					return wrapErr(errors.New("title of Spend can't be empty"))
+				}
+				if s.Cost < 0 {
+					return wrapErr(errors.Errorf("cost of Spend %q can't be negative", s.Title))
+				}
+				if err := checkTypeID(s.TypeID); err != nil {
+					return wrapErr(errors.Wrapf(err, "invalid Spend %q", s.Title))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// BackupStats contains the number of restored entities
+type BackupStats struct {
+	SpendTypes      int `json:"spend_types"`
+	SpendRules      int `json:"spend_rules"`
+	Months          int `json:"months"`
+	Incomes         int `json:"incomes"`
+	MonthlyPayments int `json:"monthly_payments"`
+	Spends          int `json:"spends"`
+	SavedSearches   int `json:"saved_searches"`
+}
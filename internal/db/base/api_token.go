@@ -0,0 +1,160 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type APIToken struct {
+	ID         uint              `db:"id"`
+	Username   string            `db:"username"`
+	Name       string            `db:"name"`
+	TokenHash  string            `db:"token_hash"`
+	ReadOnly   bool              `db:"read_only"`
+	Endpoints  apiTokenEndpoints `db:"endpoints"`
+	CreatedAt  time.Time         `db:"created_at"`
+	LastUsedAt sql.NullTime      `db:"last_used_at"`
+}
+
+// ToCommon converts APIToken to common APIToken structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (t APIToken) ToCommon() common.APIToken {
+	res := common.APIToken{
+		ID:        t.ID,
+		Username:  t.Username,
+		Name:      t.Name,
+		TokenHash: t.TokenHash,
+		ReadOnly:  t.ReadOnly,
+		Endpoints: []string(t.Endpoints),
+		CreatedAt: t.CreatedAt.UTC(),
+	}
+	if t.LastUsedAt.Valid {
+		lastUsedAt := t.LastUsedAt.Time.UTC()
+		res.LastUsedAt = &lastUsedAt
+	}
+	return res
+}
+
+// apiTokenEndpoints is stored as JSON
+type apiTokenEndpoints []string
+
+var (
+	_ sql.Scanner   = (*apiTokenEndpoints)(nil)
+	_ driver.Valuer = (*apiTokenEndpoints)(nil)
+)
+
+func (v *apiTokenEndpoints) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return errors.Errorf("couldn't scan api token endpoints from %T", src)
+	}
+	return json.Unmarshal(data, (*[]string)(v))
+}
+
+func (v apiTokenEndpoints) Value() (driver.Value, error) {
+	if v == nil {
+		v = apiTokenEndpoints{}
+	}
+	data, err := json.Marshal([]string(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// GetAPITokens returns all API Tokens of the user
+func (db DB) GetAPITokens(ctx context.Context, username string) ([]common.APIToken, error) {
+	var tokens []APIToken
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Select(&tokens, `SELECT * FROM api_tokens WHERE username = ? ORDER BY id`, username)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select API Tokens")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.APIToken, 0, len(tokens))
+	for i := range tokens {
+		res = append(res, tokens[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetAPITokenByHash returns API Token with passed hash
+func (db DB) GetAPITokenByHash(ctx context.Context, tokenHash string) (common.APIToken, error) {
+	var token APIToken
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&token, `SELECT * FROM api_tokens WHERE token_hash = ?`, tokenHash)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.ErrAPITokenNotExist
+			}
+			return errors.Wrap(err, "couldn't select API Token")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.APIToken{}, err
+	}
+
+	return token.ToCommon(), nil
+}
+
+// AddAPIToken adds a new API Token
+func (db DB) AddAPIToken(ctx context.Context, args common.AddAPITokenArgs) (id uint, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		return tx.Get(
+			&id,
+			`INSERT INTO api_tokens(username, name, token_hash, read_only, endpoints, created_at)
+			 VALUES(?, ?, ?, ?, ?, ?) RETURNING id`,
+			args.Username, args.Name, args.TokenHash, args.ReadOnly, apiTokenEndpoints(args.Endpoints),
+			args.CreatedAt.UTC(),
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateAPITokenLastUsedAt sets the time of the last request with API Token
+func (db DB) UpdateAPITokenLastUsedAt(ctx context.Context, id uint, lastUsedAt time.Time) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, lastUsedAt.UTC(), id)
+		return err
+	})
+}
+
+// RemoveAPIToken removes API Token with passed id. Users can remove only their own tokens
+func (db DB) RemoveAPIToken(ctx context.Context, username string, id uint) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(`DELETE FROM api_tokens WHERE id = ? AND username = ?`, id, username)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove API Token")
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of removed rows")
+		}
+		if rows == 0 {
+			return common.ErrAPITokenNotExist
+		}
+		return nil
+	})
+}
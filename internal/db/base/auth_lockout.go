@@ -0,0 +1,177 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type AuthLockout struct {
+	ID           uint         `db:"id"`
+	Kind         string       `db:"kind"`
+	Value        string       `db:"value"`
+	Failures     int          `db:"failures"`
+	LastFailedAt time.Time    `db:"last_failed_at"`
+	LockedUntil  sql.NullTime `db:"locked_until"`
+}
+
+// ToCommon converts AuthLockout to common AuthLockout structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (l AuthLockout) ToCommon() common.AuthLockout {
+	res := common.AuthLockout{
+		ID:           l.ID,
+		Kind:         common.AuthLockoutKind(l.Kind),
+		Value:        l.Value,
+		Failures:     l.Failures,
+		LastFailedAt: l.LastFailedAt.UTC(),
+	}
+	if l.LockedUntil.Valid {
+		lockedUntil := l.LockedUntil.Time.UTC()
+		res.LockedUntil = &lockedUntil
+	}
+	return res
+}
+
+// GetAuthLockouts returns all Auth Lockouts, the latest failed attempts go first
+func (db DB) GetAuthLockouts(ctx context.Context) ([]common.AuthLockout, error) {
+	var lockouts []AuthLockout
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Select(&lockouts, `SELECT * FROM auth_lockouts ORDER BY last_failed_at DESC, id DESC`)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select Auth Lockouts")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.AuthLockout, 0, len(lockouts))
+	for i := range lockouts {
+		res = append(res, lockouts[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetAuthLockout returns Auth Lockout of an ip or a username
+func (db DB) GetAuthLockout(ctx context.Context, kind common.AuthLockoutKind,
+	value string) (common.AuthLockout, error) {
+
+	var lockout AuthLockout
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&lockout, `SELECT * FROM auth_lockouts WHERE kind = ? AND value = ?`, string(kind), value)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.ErrAuthLockoutNotExist
+			}
+			return errors.Wrap(err, "couldn't select Auth Lockout")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.AuthLockout{}, err
+	}
+
+	return lockout.ToCommon(), nil
+}
+
+// AddAuthFailure increments the number of failed attempts of an ip or a username and locks the attempts
+// if needed. It returns ErrAuthLockoutLocked with the current lockout if the attempts are already locked,
+// the failure is not added in this case. The increment and the lock are atomic, so concurrent attempts
+// can't get the same number or bypass the lock. Stale lockouts are removed before the update
+func (db DB) AddAuthFailure(ctx context.Context, args common.AddAuthFailureArgs) (common.AuthLockout, error) {
+	var (
+		lockout AuthLockout
+		locked  bool
+	)
+	err := db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`DELETE FROM auth_lockouts WHERE last_failed_at < ? AND (locked_until IS NULL OR locked_until < ?)`,
+			args.RemoveStaleBefore.UTC(), args.RemoveStaleBefore.UTC(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove stale Auth Lockouts")
+		}
+
+		// The row is not updated and nothing is returned if the attempts are locked
+		var failures int
+		err = tx.Get(
+			&failures,
+			`INSERT INTO auth_lockouts(kind, value, failures, last_failed_at) VALUES(?, ?, 1, ?)
+			 ON CONFLICT (kind, value) DO UPDATE SET
+				failures = auth_lockouts.failures + 1,
+				last_failed_at = excluded.last_failed_at
+			 WHERE auth_lockouts.locked_until IS NULL OR auth_lockouts.locked_until <= ?
+			 RETURNING failures`,
+			string(args.Kind), args.Value, args.FailedAt.UTC(), args.FailedAt.UTC(),
+		)
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			locked = true
+		case err != nil:
+			return errors.Wrap(err, "couldn't save Auth Lockout")
+		default:
+			if err := lockAuthLockout(tx, args, failures); err != nil {
+				return err
+			}
+		}
+
+		err = tx.Get(&lockout, `SELECT * FROM auth_lockouts WHERE kind = ? AND value = ?`, string(args.Kind), args.Value)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select Auth Lockout")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.AuthLockout{}, err
+	}
+	if locked {
+		return lockout.ToCommon(), common.ErrAuthLockoutLocked
+	}
+	return lockout.ToCommon(), nil
+}
+
+// lockAuthLockout locks auth attempts after the passed number of failures. The lock can only be extended
+func lockAuthLockout(tx *sqlx.Tx, args common.AddAuthFailureArgs, failures int) error {
+	if args.LockedUntil == nil {
+		return nil
+	}
+	until := args.LockedUntil(failures)
+	if until == nil {
+		return nil
+	}
+
+	_, err := tx.Exec(
+		`UPDATE auth_lockouts SET locked_until = ?
+		 WHERE kind = ? AND value = ? AND (locked_until IS NULL OR locked_until < ?)`,
+		until.UTC(), string(args.Kind), args.Value, until.UTC(),
+	)
+	if err != nil {
+		return errors.Wrap(err, "couldn't lock Auth Lockout")
+	}
+	return nil
+}
+
+// RemoveAuthLockout removes Auth Lockout with passed id
+func (db DB) RemoveAuthLockout(ctx context.Context, id uint) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkAuthLockout(tx, id) {
+			return common.ErrAuthLockoutNotExist
+		}
+		_, err := tx.Exec(`DELETE FROM auth_lockouts WHERE id = ?`, id)
+		return err
+	})
+}
+
+// ResetAuthLockout removes Auth Lockout of an ip or a username. It doesn't return an error if the lockout
+// doesn't exist
+func (db DB) ResetAuthLockout(ctx context.Context, kind common.AuthLockoutKind, value string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM auth_lockouts WHERE kind = ? AND value = ?`, string(kind), value)
+		return err
+	})
+}
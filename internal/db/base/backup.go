@@ -0,0 +1,288 @@
+package base
+
+import (
+	"context"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/types"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// ExportBackup returns a backup of all data
+//
+//nolint:funlen
+func (db DB) ExportBackup(ctx context.Context) (common.Backup, error) {
+	backup := common.Backup{
+		Version:   common.BackupVersion,
+		CreatedAt: time.Now().UTC(),
+	}
+
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		var spendTypes []SpendType
+		if err := tx.Select(&spendTypes, `SELECT * FROM spend_types ORDER BY id`); err != nil {
+			return errors.Wrap(err, "couldn't select Spend Types")
+		}
+		backup.SpendTypes = make([]common.BackupSpendType, 0, len(spendTypes))
+		for _, t := range spendTypes {
+			backup.SpendTypes = append(backup.SpendTypes, common.BackupSpendType{
+				ID:       uint(t.ID),
+				Name:     string(t.Name),
+				ParentID: uint(t.ParentID),
+			})
+		}
+
+		spendRules, err := selectSpendRules(tx)
+		if err != nil {
+			return err
+		}
+		backup.SpendRules = make([]common.BackupSpendRule, 0, len(spendRules))
+		for _, r := range spendRules {
+			backup.SpendRules = append(backup.SpendRules, common.BackupSpendRule{
+				TitlePattern: r.TitlePattern,
+				TypeID:       r.TypeID,
+				Notes:        string(r.Notes),
+			})
+		}
+
+		savedSearches, err := selectSavedSearches(tx, "1 = 1")
+		if err != nil {
+			return err
+		}
+		backup.SavedSearches = make([]common.BackupSavedSearch, 0, len(savedSearches))
+		for _, s := range savedSearches {
+			backup.SavedSearches = append(backup.SavedSearches, common.BackupSavedSearch{
+				Name:   s.Name,
+				Pinned: s.Pinned,
+				Args:   common.SearchSpendsArgs(s.Args),
+			})
+		}
+
+		var monthIDs []uint
+		if err := tx.Select(&monthIDs, `SELECT id FROM months ORDER BY year, month`); err != nil {
+			return errors.Wrap(err, "couldn't select month ids")
+		}
+		backup.Months = make([]common.BackupMonth, 0, len(monthIDs))
+		for _, id := range monthIDs {
+			m, err := getFullMonth(tx, "id = ?", id)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't get month with id %d", id)
+			}
+			backup.Months = append(backup.Months, monthToBackup(m))
+		}
+		return nil
+	})
+	if err != nil {
+		return common.Backup{}, err
+	}
+
+	return backup, nil
+}
+
+func monthToBackup(m Month) common.BackupMonth {
+	res := common.BackupMonth{
+		Year:            m.Year,
+		Month:           m.Month,
+		Incomes:         make([]common.BackupIncome, 0, len(m.Incomes)),
+		MonthlyPayments: make([]common.BackupMonthlyPayment, 0, len(m.MonthlyPayments)),
+		Days:            make([]common.BackupDay, 0),
+	}
+	for _, in := range m.Incomes {
+		res.Incomes = append(res.Incomes, common.BackupIncome{
+			Title:  in.Title,
+			Notes:  string(in.Notes),
+			Income: in.Income,
+		})
+	}
+	for _, mp := range m.MonthlyPayments {
+		res.MonthlyPayments = append(res.MonthlyPayments, common.BackupMonthlyPayment{
+			Title:  mp.Title,
+			TypeID: uint(mp.TypeID),
+			Notes:  string(mp.Notes),
+			Cost:   mp.Cost,
+			DueDay: uint(mp.DueDay),
+		})
+	}
+	for _, d := range m.Days {
+		if len(d.Spends) == 0 {
+			continue
+		}
+
+		day := common.BackupDay{
+			Day:    d.Day,
+			Spends: make([]common.BackupSpend, 0, len(d.Spends)),
+		}
+		for _, s := range d.Spends {
+			day.Spends = append(day.Spends, common.BackupSpend{
+				Title:  s.Title,
+				TypeID: uint(s.TypeID),
+				Notes:  string(s.Notes),
+				Cost:   s.Cost,
+			})
+		}
+		res.Days = append(res.Days, day)
+	}
+	return res
+}
+
+// RestoreBackup replaces all data with data from the backup. Ids are rebuilt and all months are recomputed
+//
+//nolint:funlen,gocognit
+func (db DB) RestoreBackup(ctx context.Context, backup common.Backup) (stats common.BackupStats, err error) {
+	if err := backup.Validate(); err != nil {
+		return common.BackupStats{}, errors.Wrap(err, "invalid backup")
+	}
+
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		stats = common.BackupStats{}
+
+		if err := clearBackupTables(tx); err != nil {
+			return err
+		}
+
+		// Spend Types. Insert them without parents first because parents can be defined after children
+		spendTypeIDs := make(map[uint]uint, len(backup.SpendTypes)) // backup id -> new id
+		for _, t := range backup.SpendTypes {
+			var id uint
+			if err := tx.Get(&id, `INSERT INTO spend_types(name) VALUES(?) RETURNING id`, t.Name); err != nil {
+				return errors.Wrapf(err, "couldn't insert Spend Type %q", t.Name)
+			}
+			spendTypeIDs[t.ID] = id
+			stats.SpendTypes++
+		}
+		for _, t := range backup.SpendTypes {
+			if t.ParentID == 0 {
+				continue
+			}
+			_, err := tx.Exec(
+				`UPDATE spend_types SET parent_id = ? WHERE id = ?`, spendTypeIDs[t.ParentID], spendTypeIDs[t.ID],
+			)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't set parent of Spend Type %q", t.Name)
+			}
+		}
+		getTypeID := func(id uint) types.Uint {
+			return types.Uint(spendTypeIDs[id])
+		}
+
+		for _, r := range backup.SpendRules {
+			_, err := tx.Exec(
+				`INSERT INTO spend_rules(title_pattern, type_id, notes) VALUES(?, ?, ?)`,
+				r.TitlePattern, getTypeID(r.TypeID), r.Notes,
+			)
+			if err != nil {
+				return errors.Wrap(err, "couldn't insert Spend Rule")
+			}
+			stats.SpendRules++
+		}
+
+		for _, m := range backup.Months {
+			monthID, err := insertMonth(tx, m.Year, m.Month)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't insert month %d-%02d", m.Year, m.Month)
+			}
+			var dayIDs []uint
+			err = tx.Select(&dayIDs, `SELECT id FROM days WHERE month_id = ? ORDER BY day`, monthID)
+			if err != nil {
+				return errors.Wrap(err, "couldn't select day ids")
+			}
+			stats.Months++
+
+			for _, in := range m.Incomes {
+				_, err := tx.Exec(
+					`INSERT INTO incomes(month_id, title, notes, income) VALUES(?, ?, ?, ?)`,
+					monthID, in.Title, in.Notes, in.Income,
+				)
+				if err != nil {
+					return errors.Wrapf(err, "couldn't insert Income %q", in.Title)
+				}
+				stats.Incomes++
+			}
+			for _, mp := range m.MonthlyPayments {
+				_, err := tx.Exec(
+					`INSERT INTO monthly_payments(month_id, title, type_id, notes, cost, due_day) VALUES(?, ?, ?, ?, ?, ?)`,
+					monthID, mp.Title, getTypeID(mp.TypeID), mp.Notes, mp.Cost, types.Uint(mp.DueDay),
+				)
+				if err != nil {
+					return errors.Wrapf(err, "couldn't insert Monthly Payment %q", mp.Title)
+				}
+				stats.MonthlyPayments++
+			}
+			for _, d := range m.Days {
+				for _, s := range d.Spends {
+					_, err := tx.Exec(
+						`INSERT INTO spends(day_id, title, type_id, notes, cost) VALUES(?, ?, ?, ?, ?)`,
+						dayIDs[d.Day-1], s.Title, getTypeID(s.TypeID), s.Notes, s.Cost,
+					)
+					if err != nil {
+						return errors.Wrapf(err, "couldn't insert Spend %q", s.Title)
+					}
+					stats.Spends++
+				}
+			}
+
+			if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+				return err
+			}
+		}
+
+		for _, s := range backup.SavedSearches {
+			// Spends without type are searched with id '0', so it must be kept
+			s.Args.RangeTypeIDs(func(id *uint) { *id = uint(getTypeID(*id)) })
+
+			_, err := tx.Exec(
+				`INSERT INTO saved_searches(name, pinned, args) VALUES(?, ?, ?)`,
+				s.Name, s.Pinned, savedSearchArgs(s.Args),
+			)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't insert Saved Search %q", s.Name)
+			}
+			stats.SavedSearches++
+		}
+		return nil
+	})
+	if err != nil {
+		return common.BackupStats{}, err
+	}
+
+	return stats, nil
+}
+
+// backupTables contains tables with data that is included in backups, in the dependency order. Only these
+// tables are cleared on restore: other tables (API Tokens, sessions, 2FA, webhooks and etc.) are not
+// affected. It must be updated when a backup gets new data
+//
+//nolint:gochecknoglobals
+var backupTables = []dataTable{
+	{name: "spend_types", selfRefColumn: "parent_id"},
+	{name: "spend_rules"},
+	{name: "months"},
+	{name: "days"},
+	{name: "incomes"},
+	{name: "monthly_payments"},
+	{name: "spends"},
+	{name: "saved_searches"},
+}
+
+// clearBackupTables removes all data that is included in backups. Tables are cleared in the reverse
+// dependency order
+func clearBackupTables(tx *sqlx.Tx) error {
+	for _, table := range backupTables {
+		if table.selfRefColumn == "" {
+			continue
+		}
+		// Reset references first to remove rows without violating the foreign key constraint
+		if _, err := tx.Exec(`UPDATE ` + table.name + ` SET ` + table.selfRefColumn + ` = NULL`); err != nil {
+			return errors.Wrapf(err, "couldn't reset column %q of table %q", table.selfRefColumn, table.name)
+		}
+	}
+
+	for i := len(backupTables) - 1; i >= 0; i-- {
+		table := backupTables[i].name
+		if _, err := tx.Exec(`DELETE FROM ` + table); err != nil {
+			return errors.Wrapf(err, "couldn't clear table %q", table)
+		}
+	}
+	return nil
+}
@@ -17,14 +17,17 @@ const (
 )
 
 type DB struct {
-	db *sqlx.DB
+	db             *sqlx.DB
+	fullTextSearch fullTextSearch
+	publisher      MonthChangePublisher
 }
 
-// NewDB creates a new connection to the db and applies the migrations
-func NewDB(driverName, dataSourceName string, placeholder sqlx.Placeholder,
+// NewDB creates a new connection to the db and applies the migrations. Read-only transactions use
+// a separate connection pool if readDataSourceName is not empty
+func NewDB(driverName, dataSourceName, readDataSourceName string, placeholder sqlx.Placeholder,
 	migrations []*migrator.Migration, log logger.Logger) (*DB, error) {
 
-	conn, err := sqlx.Open(driverName, dataSourceName, placeholder, log)
+	conn, err := sqlx.Open(driverName, dataSourceName, readDataSourceName, placeholder, log)
 	if err != nil {
 		return nil, errors.Wrap(err, "couldn't open a connection to db")
 	}
@@ -38,7 +41,15 @@ func NewDB(driverName, dataSourceName string, placeholder sqlx.Placeholder,
 		return nil, err
 	}
 
-	return &DB{conn}, nil
+	fullTextSearch, err := detectFullTextSearch(ctx, conn, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DB{
+		db:             conn,
+		fullTextSearch: fullTextSearch,
+	}, nil
 }
 
 const (
@@ -0,0 +1,175 @@
+package base
+
+import (
+	"context"
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type dataTable struct {
+	name string
+	// selfRefColumn is a column that references the same table. Its values are set after
+	// all rows are copied, so rows can be inserted in any order
+	selfRefColumn string
+}
+
+// dataTables contains all tables with data in the dependency order. It must be updated
+// after every migration that adds a new table
+//
+//nolint:gochecknoglobals
+var dataTables = []dataTable{
+	{name: "spend_types", selfRefColumn: "parent_id"},
+	{name: "spend_rules"},
+	{name: "months"},
+	{name: "days"},
+	{name: "incomes"},
+	{name: "monthly_payments"},
+	{name: "spends"},
+	{name: "saved_searches"},
+	{name: "webhooks"},
+	{name: "webhook_deliveries"},
+	{name: "idempotency_keys"},
+	{name: "api_tokens"},
+	{name: "sessions"},
+	{name: "auth_lockouts"},
+	{name: "totp"},
+	{name: "recovery_codes"},
+}
+
+// TableStats contains the number of rows in a table
+type TableStats struct {
+	Table string
+	Rows  int
+}
+
+// CopyTo copies all data to another db with ids. The destination db must be empty.
+// Both dbs must have the same schema (all migrations must be applied)
+//
+//nolint:funlen,gocognit
+func (db DB) CopyTo(ctx context.Context, dst *DB) (stats []TableStats, err error) {
+	err = db.db.RunInReadOnlyTransaction(ctx, func(srcTx *sqlx.Tx) error {
+		return dst.db.RunInTransaction(ctx, func(dstTx *sqlx.Tx) error {
+			stats = make([]TableStats, 0, len(dataTables))
+
+			for _, table := range dataTables {
+				var count int
+				if err := dstTx.Get(&count, `SELECT COUNT(*) FROM `+table.name); err != nil {
+					return errors.Wrapf(err, "couldn't count rows in table %q of the destination db", table.name)
+				}
+				if count != 0 {
+					return errors.Errorf("destination db is not empty: table %q has %d rows", table.name, count)
+				}
+			}
+
+			for _, table := range dataTables {
+				columns, rows, err := srcTx.SelectRows(`SELECT * FROM ` + table.name + ` ORDER BY id`)
+				if err != nil {
+					return errors.Wrapf(err, "couldn't select rows from table %q", table.name)
+				}
+
+				idIndex, selfRefIndex := -1, -1
+				for i, c := range columns {
+					switch {
+					case c == "id":
+						idIndex = i
+					case table.selfRefColumn != "" && c == table.selfRefColumn:
+						selfRefIndex = i
+					}
+				}
+
+				query := `INSERT INTO ` + table.name + `(` + strings.Join(columns, ", ") + `) ` +
+					`VALUES(?` + strings.Repeat(", ?", len(columns)-1) + `)`
+				for _, row := range rows {
+					values := row
+					if selfRefIndex != -1 {
+						values = make([]interface{}, len(row))
+						copy(values, row)
+						values[selfRefIndex] = nil
+					}
+					if _, err := dstTx.Exec(query, values...); err != nil {
+						return errors.Wrapf(err, "couldn't insert row into table %q", table.name)
+					}
+				}
+
+				if selfRefIndex != -1 {
+					query := `UPDATE ` + table.name + ` SET ` + table.selfRefColumn + ` = ? WHERE id = ?`
+					for _, row := range rows {
+						if row[selfRefIndex] == nil {
+							continue
+						}
+						if _, err := dstTx.Exec(query, row[selfRefIndex], row[idIndex]); err != nil {
+							return errors.Wrapf(err, "couldn't update column %q of table %q", table.selfRefColumn, table.name)
+						}
+					}
+				}
+
+				stats = append(stats, TableStats{Table: table.name, Rows: len(rows)})
+			}
+
+			if dst.db.DriverName() == "postgres" {
+				// PostgreSQL doesn't update sequences when ids are passed explicitly
+				for _, table := range dataTables {
+					_, err := dstTx.Exec(
+						`SELECT setval(pg_get_serial_sequence('` + table.name + `', 'id'), ` +
+							`COALESCE(MAX(id), 1), MAX(id) IS NOT NULL) FROM ` + table.name,
+					)
+					if err != nil {
+						return errors.Wrapf(err, "couldn't update id sequence of table %q", table.name)
+					}
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+// Compare checks that both dbs have the same number of rows in all tables and the same month totals
+func (db DB) Compare(ctx context.Context, other *DB) error {
+	type dbData struct {
+		counts []int
+		months []MonthOverview
+	}
+	load := func(db *sqlx.DB) (data dbData, err error) {
+		err = db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+			for _, table := range dataTables {
+				var count int
+				if err := tx.Get(&count, `SELECT COUNT(*) FROM `+table.name); err != nil {
+					return errors.Wrapf(err, "couldn't count rows in table %q", table.name)
+				}
+				data.counts = append(data.counts, count)
+			}
+			return tx.Select(
+				&data.months,
+				`SELECT id, year, month, daily_budget, total_income, total_spend, result FROM months ORDER BY id`,
+			)
+		})
+		return data, err
+	}
+
+	a, err := load(db.db)
+	if err != nil {
+		return err
+	}
+	b, err := load(other.db)
+	if err != nil {
+		return err
+	}
+
+	for i, table := range dataTables {
+		if a.counts[i] != b.counts[i] {
+			return errors.Errorf("table %q has different number of rows: %d and %d", table.name, a.counts[i], b.counts[i])
+		}
+	}
+	for i := range a.months {
+		if a.months[i] != b.months[i] {
+			return errors.Errorf("month %d-%02d has different totals", a.months[i].Year, a.months[i].Month)
+		}
+	}
+	return nil
+}
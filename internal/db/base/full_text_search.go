@@ -0,0 +1,122 @@
+package base
+
+import (
+	"context"
+	"strings"
+	"unicode"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// fullTextSearch defines how the full-text search over titles and notes of Spends is performed
+type fullTextSearch int
+
+const (
+	// fullTextSearchLike is a fallback that checks every word with LIKE. Results are not ranked
+	fullTextSearchLike fullTextSearch = iota
+	// fullTextSearchPostgres uses tsvector with GIN index
+	fullTextSearchPostgres
+	// fullTextSearchSQLite uses FTS5 virtual table 'spends_fts'
+	fullTextSearchSQLite
+)
+
+// fullTextSearchDocument is a document used by PostgreSQL. It must be the same as the expression
+// of the GIN index. Configuration 'english' compares English words by their stems and ignores stop-words,
+// configuration 'simple' matches words in other languages (for example, Cyrillic ones) and stop-words
+const fullTextSearchDocument = `(to_tsvector('english', title || ' ' || COALESCE(notes, '')) || ` +
+	`to_tsvector('simple', title || ' ' || COALESCE(notes, '')))`
+
+// detectFullTextSearch checks which full-text search the db supports
+func detectFullTextSearch(ctx context.Context, db *sqlx.DB, log logger.Logger) (fullTextSearch, error) {
+	switch db.DriverName() {
+	case "postgres":
+		return fullTextSearchPostgres, nil
+	case "sqlite3":
+		// Check below
+	default:
+		return fullTextSearchLike, nil
+	}
+
+	var (
+		fts5Enabled bool
+		tableExists bool
+	)
+	err := db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&fts5Enabled, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`)
+		if err != nil {
+			return errors.Wrap(err, "couldn't check whether FTS5 is enabled")
+		}
+		err = tx.Get(&tableExists, `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'spends_fts'`)
+		if err != nil {
+			return errors.Wrap(err, "couldn't check whether table 'spends_fts' exists")
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case fts5Enabled && tableExists:
+		return fullTextSearchSQLite, nil
+	case tableExists:
+		// The triggers of 'spends_fts' would break all changes of Spends
+		return 0, errors.New("the db has a full-text index, but SQLite is built without FTS5, " +
+			"use build tag 'sqlite_fts5'")
+	case fts5Enabled:
+		log.Warn("the db was created without FTS5, full-text search is performed with LIKE. " +
+			"Copy the db to a new file to create a full-text index")
+	default:
+		log.Warn("SQLite is built without FTS5, full-text search is performed with LIKE")
+	}
+	return fullTextSearchLike, nil
+}
+
+// buildFullTextSearchJoin returns a join of a subquery 'fts' with Spends that match the full-text query.
+// The subquery has columns 'id' and 'rank', the more rank is, the more relevant a Spend is.
+// It returns an empty string if the text has no words
+func (db DB) buildFullTextSearchJoin(text string) (join string, args []interface{}) {
+	words := splitFullTextQuery(text)
+	if len(words) == 0 {
+		return "", nil
+	}
+
+	var subquery string
+	switch db.fullTextSearch {
+	case fullTextSearchPostgres:
+		subquery = `SELECT id, ts_rank(` + fullTextSearchDocument + `, query) AS rank
+		              FROM spends, (plainto_tsquery('english', ?) || plainto_tsquery('simple', ?)) AS query
+		             WHERE ` + fullTextSearchDocument + ` @@ query`
+		query := strings.Join(words, " ")
+		args = []interface{}{query, query}
+
+	case fullTextSearchSQLite:
+		// Quoted words are matched as strings, so FTS5 operators in the query are ignored
+		for i := range words {
+			words[i] = `"` + words[i] + `"`
+		}
+		// bm25 returns lower values for more relevant rows
+		subquery = `SELECT rowid AS id, -bm25(spends_fts) AS rank FROM spends_fts WHERE spends_fts MATCH ?`
+		args = []interface{}{strings.Join(words, " ")}
+
+	default:
+		wheres := make([]string, 0, len(words))
+		for _, word := range words {
+			wheres = append(wheres, `LOWER(title || ' ' || COALESCE(notes, '')) LIKE ?`)
+			args = append(args, "%"+word+"%")
+		}
+		subquery = `SELECT id, 0 AS rank FROM spends WHERE ` + strings.Join(wheres, " AND ")
+	}
+
+	return `INNER JOIN (` + subquery + `) AS fts ON fts.id = spend.id`, args
+}
+
+// splitFullTextQuery returns lowercased words of the query. All characters except letters and digits
+// are treated as separators
+func splitFullTextQuery(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
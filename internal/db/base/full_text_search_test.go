@@ -0,0 +1,104 @@
+package base
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+func TestBuildFullTextSearchJoin(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc           string
+		fullTextSearch fullTextSearch
+		text           string
+		wantJoin       string
+		wantArgs       []interface{}
+	}{
+		{
+			desc:           "like",
+			fullTextSearch: fullTextSearchLike,
+			text:           "Coffee, beans",
+			wantJoin: `
+				INNER JOIN (SELECT id, 0 AS rank FROM spends
+				             WHERE LOWER(title || ' ' || COALESCE(notes, '')) LIKE ?
+				               AND LOWER(title || ' ' || COALESCE(notes, '')) LIKE ?) AS fts
+				ON fts.id = spend.id`,
+			wantArgs: []interface{}{"%coffee%", "%beans%"},
+		},
+		{
+			desc:           "postgres",
+			fullTextSearch: fullTextSearchPostgres,
+			text:           "Coffee, beans",
+			wantJoin: `
+				INNER JOIN (SELECT id, ts_rank((to_tsvector('english', title || ' ' || COALESCE(notes, '')) || to_tsvector('simple', title || ' ' || COALESCE(notes, ''))), query) AS rank
+				              FROM spends, (plainto_tsquery('english', ?) || plainto_tsquery('simple', ?)) AS query
+				             WHERE (to_tsvector('english', title || ' ' || COALESCE(notes, '')) || to_tsvector('simple', title || ' ' || COALESCE(notes, ''))) @@ query) AS fts
+				ON fts.id = spend.id`,
+			wantArgs: []interface{}{"coffee beans", "coffee beans"},
+		},
+		{
+			desc:           "sqlite",
+			fullTextSearch: fullTextSearchSQLite,
+			text:           `Coffee OR "beans*"`,
+			wantJoin: `
+				INNER JOIN (SELECT rowid AS id, -bm25(spends_fts) AS rank FROM spends_fts WHERE spends_fts MATCH ?) AS fts
+				ON fts.id = spend.id`,
+			wantArgs: []interface{}{`"coffee" "or" "beans"`},
+		},
+		{
+			desc:           "no words",
+			fullTextSearch: fullTextSearchSQLite,
+			text:           " - ",
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			join, args := DB{fullTextSearch: tt.fullTextSearch}.buildFullTextSearchJoin(tt.text)
+			if tt.wantJoin == "" {
+				require.Empty(t, join)
+			} else {
+				require.Equal(t, formatQuery(tt.wantJoin), formatQuery(join))
+			}
+			require.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBuildSearchSpendsQuery_FullText(t *testing.T) {
+	t.Parallel()
+
+	db := DB{fullTextSearch: fullTextSearchSQLite}
+
+	query, args := db.buildSearchSpendsQuery(common.SearchSpendsArgs{
+		Text:  "coffee",
+		Title: "beans",
+		Sort:  common.SortSpendsByRelevance,
+		Order: common.OrderByAsc,
+		Limit: 10,
+	})
+	require.Contains(t, query, "ON fts.id = spend.id WHERE LOWER(spend.title) LIKE ?")
+	require.True(t, strings.HasSuffix(
+		query, "ORDER BY fts.rank DESC, month.year DESC, month.month DESC, day.day DESC, spend.id LIMIT ?",
+	))
+	require.Equal(t, []interface{}{`"coffee"`, "%beans%", 10}, args)
+
+	// Spends are sorted by date without the text
+	query, _ = db.buildSearchSpendsQuery(common.SearchSpendsArgs{Sort: common.SortSpendsByRelevance})
+	require.NotContains(t, query, "fts")
+	require.True(t, strings.HasSuffix(query, "ORDER BY month.year, month.month, day.day, spend.id"))
+}
+
+func TestSplitFullTextQuery(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"coffee", "beans", "2kg", "кофе"}, splitFullTextQuery(` Coffee-BEANS "2kg" (Кофе)*`))
+	require.Empty(t, splitFullTextQuery(` "*" `))
+}
@@ -0,0 +1,103 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type IdempotencyKey struct {
+	ID           uint      `db:"id"`
+	Key          string    `db:"key"`
+	RequestHash  string    `db:"request_hash"`
+	StatusCode   int       `db:"status_code"`
+	ContentType  string    `db:"content_type"`
+	ResponseBody string    `db:"response_body"`
+	CreatedAt    time.Time `db:"created_at"`
+	ExpiresAt    time.Time `db:"expires_at"`
+}
+
+// ToCommon converts IdempotencyKey to common IdempotencyKey structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (k IdempotencyKey) ToCommon() common.IdempotencyKey {
+	return common.IdempotencyKey{
+		Key:          k.Key,
+		RequestHash:  k.RequestHash,
+		StatusCode:   k.StatusCode,
+		ContentType:  k.ContentType,
+		ResponseBody: k.ResponseBody,
+		CreatedAt:    k.CreatedAt.UTC(),
+		ExpiresAt:    k.ExpiresAt.UTC(),
+	}
+}
+
+// GetIdempotencyKey returns Idempotency Key that hasn't expired by the passed time
+func (db DB) GetIdempotencyKey(ctx context.Context, key string, now time.Time) (common.IdempotencyKey, error) {
+	var res IdempotencyKey
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&res, `SELECT * FROM idempotency_keys WHERE key = ? AND expires_at > ?`, key, now.UTC())
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.ErrIdempotencyKeyNotExist
+			}
+			return errors.Wrap(err, "couldn't select Idempotency Key")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.IdempotencyKey{}, err
+	}
+
+	return res.ToCommon(), nil
+}
+
+// AddIdempotencyKey adds a new Idempotency Key without a response. It returns ErrIdempotencyKeyExists
+// if the key is already used. Expired keys are removed before the insertion
+func (db DB) AddIdempotencyKey(ctx context.Context, args common.AddIdempotencyKeyArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM idempotency_keys WHERE expires_at <= ?`, args.CreatedAt.UTC())
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove expired Idempotency Keys")
+		}
+
+		res, err := tx.Exec(
+			`INSERT INTO idempotency_keys(key, request_hash, created_at, expires_at) VALUES(?, ?, ?, ?)
+			 ON CONFLICT (key) DO NOTHING`,
+			args.Key, args.RequestHash, args.CreatedAt.UTC(), args.ExpiresAt.UTC(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't insert Idempotency Key")
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of inserted rows")
+		}
+		if rows == 0 {
+			return common.ErrIdempotencyKeyExists
+		}
+		return nil
+	})
+}
+
+// SaveIdempotentResponse saves the response of the request with Idempotency Key
+func (db DB) SaveIdempotentResponse(ctx context.Context, args common.SaveIdempotentResponseArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE idempotency_keys SET status_code = ?, content_type = ?, response_body = ? WHERE key = ?`,
+			args.StatusCode, args.ContentType, args.ResponseBody, args.Key,
+		)
+		return err
+	})
+}
+
+// RemoveIdempotencyKey removes Idempotency Key, so the request can be retried with the same key
+func (db DB) RemoveIdempotencyKey(ctx context.Context, key string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM idempotency_keys WHERE key = ?`, key)
+		return err
+	})
+}
@@ -17,6 +17,7 @@ type Income struct {
 	Title   string       `db:"title"`
 	Notes   types.String `db:"notes"`
 	Income  money.Money  `db:"income"`
+	Version int          `db:"version"`
 }
 
 // ToCommon converts Income to common Income structure from
@@ -29,6 +30,8 @@ func (in Income) ToCommon(year int, month time.Month) common.Income {
 		Title:  in.Title,
 		Notes:  string(in.Notes),
 		Income: in.Income,
+		//
+		Version: in.Version,
 	}
 }
 
@@ -47,7 +50,10 @@ func (db DB) AddIncome(ctx context.Context, args common.AddIncomeArgs) (id uint,
 		if err != nil {
 			return err
 		}
-		return db.recomputeAndUpdateMonth(tx, args.MonthID)
+		if err := db.recomputeAndUpdateMonth(tx, args.MonthID); err != nil {
+			return err
+		}
+		return db.notifyIncomeChanged(tx, common.WebhookEventIncomeCreated, id, args.MonthID)
 	})
 	if err != nil {
 		return 0, err
@@ -56,9 +62,9 @@ func (db DB) AddIncome(ctx context.Context, args common.AddIncomeArgs) (id uint,
 	return id, nil
 }
 
-// EditIncome edits income with passed id, nil args are ignored
-func (db DB) EditIncome(ctx context.Context, args common.EditIncomeArgs) error {
-	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+// EditIncome edits income with passed id, nil args are ignored. It returns a new version of the income
+func (db DB) EditIncome(ctx context.Context, args common.EditIncomeArgs) (version int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
 		if !checkIncome(tx, args.ID) {
 			return common.ErrIncomeNotExist
 		}
@@ -78,16 +84,27 @@ func (db DB) EditIncome(ctx context.Context, args common.EditIncomeArgs) error {
 		if args.Income != nil {
 			query.Set("income", *args.Income)
 		}
-		if _, err := tx.ExecQuery(query); err != nil {
+		query.IncrementVersion(args.Version)
+		if err := execVersionedUpdate(tx, query); err != nil {
+			return err
+		}
+		version, err = selectVersion(tx, "incomes", args.ID)
+		if err != nil {
 			return err
 		}
 
 		if args.Income != nil {
 			// Recompute month only when income has been changed
-			return db.recomputeAndUpdateMonth(tx, monthID)
+			if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+				return err
+			}
 		}
-		return nil
+		return db.notifyIncomeChanged(tx, common.WebhookEventIncomeUpdated, args.ID, monthID)
 	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
 // RemoveIncome removes income with passed id
@@ -101,16 +118,97 @@ func (db DB) RemoveIncome(ctx context.Context, id uint) error {
 		if err != nil {
 			return err
 		}
+		// Select Income before removal to send it to Webhooks
+		incomes, err := selectIncomes(tx, "incomes.id = ?", id)
+		if err != nil {
+			return err
+		}
 
 		_, err = tx.Exec(`DELETE FROM incomes WHERE id = ?`, id)
 		if err != nil {
 			return err
 		}
 
-		return db.recomputeAndUpdateMonth(tx, monthID)
+		if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+			return err
+		}
+		return db.notifyRecordChanged(tx, common.WebhookEventIncomeDeleted, incomes[0], monthID)
 	})
 }
 
+// GetIncome returns Income with passed id
+func (db DB) GetIncome(ctx context.Context, id uint) (common.Income, error) {
+	var incomes []common.Income
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkIncome(tx, id) {
+			return common.ErrIncomeNotExist
+		}
+
+		incomes, err = selectIncomes(tx, "incomes.id = ?", id)
+		return err
+	})
+	if err != nil {
+		return common.Income{}, err
+	}
+
+	return incomes[0], nil
+}
+
+// GetIncomes returns all Incomes of Month with passed id
+func (db DB) GetIncomes(ctx context.Context, monthID uint) (incomes []common.Income, err error) {
+	err = db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkMonth(tx, monthID) {
+			return common.ErrMonthNotExist
+		}
+
+		incomes, err = selectIncomes(tx, "incomes.month_id = ?", monthID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return incomes, nil
+}
+
+func selectIncomes(tx *sqlx.Tx, whereCond string, args ...interface{}) ([]common.Income, error) {
+	var incomes []struct {
+		Income
+
+		Year  int        `db:"year"`
+		Month time.Month `db:"month"`
+	}
+	err := tx.Select(
+		&incomes, `
+		SELECT
+			incomes.*,
+			months.year AS year,
+			months.month AS month
+		FROM incomes
+		INNER JOIN months ON months.id = incomes.month_id
+		WHERE `+whereCond+`
+		ORDER BY incomes.id`, args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Incomes")
+	}
+
+	res := make([]common.Income, 0, len(incomes))
+	for _, in := range incomes {
+		res = append(res, in.Income.ToCommon(in.Year, in.Month))
+	}
+	return res, nil
+}
+
+// notifyIncomeChanged selects Income with passed id and notifies about the event
+func (db DB) notifyIncomeChanged(tx *sqlx.Tx, event common.WebhookEvent, id, monthID uint) error {
+	incomes, err := selectIncomes(tx, "incomes.id = ?", id)
+	if err != nil {
+		return err
+	}
+	return db.notifyRecordChanged(tx, event, incomes[0], monthID)
+}
+
 func (DB) selectIncomeMonthID(tx *sqlx.Tx, id uint) (monthID uint, err error) {
 	err = tx.Get(&monthID, `SELECT month_id FROM incomes WHERE id = ?`, id)
 	if err != nil {
@@ -19,20 +19,41 @@ const (
 )
 
 type DB struct {
-	db          *sqlx.DB
+	db *sqlx.DB
+	// readDB is used for read-only transactions. It is the same as db if a separate data source
+	// name is not passed
+	readDB      *sqlx.DB
 	placeholder Placeholder
 	log         logger.Logger
 }
 
-func Open(driverName, dataSourceName string, placeholder Placeholder, log logger.Logger) (*DB, error) {
+// Open opens a new db. Read-only transactions use a separate connection pool opened with readDataSourceName
+// if it is not empty. It can be used to set different options for such transactions (for example, SQLite
+// locking mode)
+func Open(driverName, dataSourceName, readDataSourceName string, placeholder Placeholder,
+	log logger.Logger) (*DB, error) {
+
 	db, err := sqlx.Open(driverName, dataSourceName)
 	if err != nil {
 		return nil, err
 	}
-	return &DB{db, placeholder, log}, nil
+	readDB := db
+	if readDataSourceName != "" {
+		readDB, err = sqlx.Open(driverName, readDataSourceName)
+		if err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	return &DB{db, readDB, placeholder, log}, nil
 }
 
 func (db DB) Close() error {
+	if db.readDB != db.db {
+		if err := db.readDB.Close(); err != nil {
+			return err
+		}
+	}
 	return db.db.Close()
 }
 
@@ -41,18 +62,36 @@ func (db DB) GetInternalDB() *sql.DB {
 	return db.db.DB
 }
 
+// DriverName returns the driver name used to open the connection
+func (db DB) DriverName() string {
+	return db.db.DriverName()
+}
+
 func (db DB) Ping(ctx context.Context) error {
 	return db.db.PingContext(ctx)
 }
 
-func (db DB) RunInTransaction(ctx context.Context, fn func(*Tx) error) (err error) {
+// RunInTransaction runs fn in a transaction that can modify data
+func (db DB) RunInTransaction(ctx context.Context, fn func(*Tx) error) error {
+	return db.runInTransaction(ctx, db.db, &sql.TxOptions{}, fn)
+}
+
+// RunInReadOnlyTransaction runs fn in a transaction that can only read data. Such transactions
+// don't block each other
+func (db DB) RunInReadOnlyTransaction(ctx context.Context, fn func(*Tx) error) error {
+	return db.runInTransaction(ctx, db.readDB, &sql.TxOptions{ReadOnly: true}, fn)
+}
+
+func (db DB) runInTransaction(ctx context.Context, conn *sqlx.DB, opts *sql.TxOptions,
+	fn func(*Tx) error) (err error) {
+
 	rollback := func(tx *sqlx.Tx) {
 		if err := tx.Rollback(); err != nil {
 			db.log.WithError(err).Error("couldn't rollback tx")
 		}
 	}
 
-	tx, err := db.db.BeginTxx(ctx, &sql.TxOptions{})
+	tx, err := conn.BeginTxx(ctx, opts)
 	if err != nil {
 		return errors.Wrap(err, "couldn't begin tx")
 	}
@@ -63,7 +102,8 @@ func (db DB) RunInTransaction(ctx context.Context, fn func(*Tx) error) (err erro
 		}
 	}()
 
-	if err := fn(&Tx{tx: tx, placeholder: db.placeholder}); err != nil {
+	wrappedTx := &Tx{tx: tx, placeholder: db.placeholder, ctx: ctx}
+	if err := fn(wrappedTx); err != nil {
 		rollback(tx)
 		return err
 	}
@@ -71,12 +111,29 @@ func (db DB) RunInTransaction(ctx context.Context, fn func(*Tx) error) (err erro
 	if err := tx.Commit(); err != nil {
 		return errors.Wrap(err, "couldn't commit tx")
 	}
+	for _, f := range wrappedTx.afterCommit {
+		f()
+	}
 	return nil
 }
 
 type Tx struct {
 	tx          *sqlx.Tx
 	placeholder Placeholder
+
+	ctx         context.Context
+	afterCommit []func()
+}
+
+// Context returns the context the transaction was started with
+func (tx Tx) Context() context.Context {
+	return tx.ctx
+}
+
+// AfterCommit registers a function that is called after the transaction is committed.
+// Functions are not called if the transaction is rolled back
+func (tx *Tx) AfterCommit(f func()) {
+	tx.afterCommit = append(tx.afterCommit, f)
 }
 
 func (tx Tx) Get(dest interface{}, query string, args ...interface{}) error {
@@ -91,6 +148,37 @@ func (tx Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
 	return tx.ExecQuery(newRawQuery(query, args...))
 }
 
+// SelectRows returns column names and values of all selected rows. It can be used to
+// process tables with an unknown structure
+func (tx Tx) SelectRows(query string, args ...interface{}) (columns []string, values [][]interface{}, err error) {
+	sql, args, err := tx.prepareQuery(newRawQuery(query, args...))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err := tx.tx.Queryx(sql, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer rows.Close()
+
+	columns, err = rows.Columns()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "couldn't get columns")
+	}
+	for rows.Next() {
+		row, err := rows.SliceScan()
+		if err != nil {
+			return nil, nil, errors.Wrap(err, "couldn't scan row")
+		}
+		values = append(values, row)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+	return columns, values, nil
+}
+
 type Sqlizer interface {
 	ToSQL() (query string, args []interface{}, err error)
 }
@@ -74,7 +74,7 @@ func (m Month) ToCommon() common.Month {
 
 func (db DB) GetMonthByDate(ctx context.Context, year int, month time.Month) (common.Month, error) {
 	var m Month
-	err := db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
 		m, err = getFullMonth(tx, "year = ? AND month = ?", year, month)
 		return err
 	})
@@ -89,9 +89,28 @@ func (db DB) GetMonthByDate(ctx context.Context, year int, month time.Month) (co
 }
 
 // GetMonths returns month overviews for passed years
+// GetMonthOverview returns an overview of the Month with passed id
+func (db DB) GetMonthOverview(ctx context.Context, id uint) (common.MonthOverview, error) {
+	var m MonthOverview
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		return tx.Get(
+			&m,
+			`SELECT id, year, month, daily_budget, total_income, total_spend, result FROM months WHERE id = ?`,
+			id,
+		)
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			err = common.ErrMonthNotExist
+		}
+		return common.MonthOverview{}, err
+	}
+	return m.ToCommon(), nil
+}
+
 func (db DB) GetMonths(ctx context.Context, years ...int) ([]common.MonthOverview, error) {
 	var m []MonthOverview
-	err := db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
 		return tx.SelectQuery(&m, sqlx.In(`SELECT * FROM months WHERE year IN (?) ORDER BY id ASC`, years))
 	})
 	if err != nil {
@@ -108,6 +127,65 @@ func (db DB) GetMonths(ctx context.Context, years ...int) ([]common.MonthOvervie
 	return res, nil
 }
 
+// GetMonthsInRange returns month overviews. Only months which first day is within the passed
+// date range are returned. Zero time means no limit
+func (db DB) GetMonthsInRange(ctx context.Context, after, before time.Time) ([]common.MonthOverview, error) {
+	whereCond, sqlArgs := getQueryToFilterMonths(after, before)
+
+	var m []MonthOverview
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		return tx.Select(
+			&m,
+			`SELECT id, year, month, daily_budget, total_income, total_spend, result FROM months
+			 WHERE `+whereCond+` ORDER BY year, month`,
+			sqlArgs...,
+		)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.MonthOverview, 0, len(m))
+	for i := range m {
+		res = append(res, m[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetFullMonths returns months with all their data. Only months which first day is within the passed
+// date range are returned. Zero time means no limit
+func (db DB) GetFullMonths(ctx context.Context, after, before time.Time) ([]common.Month, error) {
+	whereCond, sqlArgs := getQueryToFilterMonths(after, before)
+
+	var months []Month
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		var ids []uint
+		err := tx.Select(&ids, `SELECT id FROM months WHERE `+whereCond+` ORDER BY year, month`, sqlArgs...)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select month ids")
+		}
+
+		months = make([]Month, 0, len(ids))
+		for _, id := range ids {
+			m, err := getFullMonth(tx, "id = ?", id)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't get month with id %d", id)
+			}
+			months = append(months, m)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.Month, 0, len(months))
+	for i := range months {
+		res = append(res, months[i].ToCommon())
+	}
+	return res, nil
+}
+
 // InitMonth inits a month and days for the passed date
 func (db *DB) InitMonth(ctx context.Context, year int, month time.Month) error {
 	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
@@ -122,27 +200,33 @@ func (db *DB) InitMonth(ctx context.Context, year int, month time.Month) error {
 		}
 
 		// We have to init the current month
-
-		var monthID uint
-		err = tx.Get(&monthID, `INSERT INTO months(year, month) VALUES(?, ?) RETURNING id`, year, month)
-		if err != nil {
+		if _, err := insertMonth(tx, year, month); err != nil {
 			return errors.Wrap(err, "couldn't init the current month")
 		}
+		return nil
+	})
+}
 
-		daysNumber := daysInMonth(year, month)
+// insertMonth inserts a month and all its days
+func insertMonth(tx *sqlx.Tx, year int, month time.Month) (monthID uint, err error) {
+	err = tx.Get(&monthID, `INSERT INTO months(year, month) VALUES(?, ?) RETURNING id`, year, month)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't insert month")
+	}
 
-		query := `INSERT INTO days(month_id, day) VALUES ` + strings.Repeat("(?, ?), ", daysNumber)
-		query = query[:len(query)-2]
+	daysNumber := daysInMonth(year, month)
 
-		sqlArgs := make([]interface{}, 0, daysNumber*2)
-		for i := 0; i < daysNumber; i++ {
-			sqlArgs = append(sqlArgs, monthID, i+1)
-		}
-		if _, err = tx.Exec(query, sqlArgs...); err != nil {
-			return errors.Wrap(err, "couldn't insert days for the current month")
-		}
-		return nil
-	})
+	query := `INSERT INTO days(month_id, day) VALUES ` + strings.Repeat("(?, ?), ", daysNumber)
+	query = query[:len(query)-2]
+
+	sqlArgs := make([]interface{}, 0, daysNumber*2)
+	for i := 0; i < daysNumber; i++ {
+		sqlArgs = append(sqlArgs, monthID, i+1)
+	}
+	if _, err = tx.Exec(query, sqlArgs...); err != nil {
+		return 0, errors.Wrap(err, "couldn't insert days")
+	}
+	return monthID, nil
 }
 
 func (db DB) recomputeAndUpdateMonth(tx *sqlx.Tx, monthID uint) (err error) {
@@ -295,3 +379,22 @@ func getFullMonth(tx *sqlx.Tx, whereCond string, args ...interface{}) (m Month,
 
 	return m, nil
 }
+
+// getQueryToFilterMonths returns a where condition to select months which first day is within
+// the passed date range. Months are compared by their index: year * 12 + (month - 1)
+func getQueryToFilterMonths(after, before time.Time) (where string, args []interface{}) {
+	whereConds := []string{"1 = 1"}
+	if !after.IsZero() {
+		first := time.Date(after.Year(), after.Month(), 1, 0, 0, 0, 0, after.Location())
+		if first.Before(after) {
+			first = first.AddDate(0, 1, 0)
+		}
+		whereConds = append(whereConds, "year * 12 + (month - 1) >= ?")
+		args = append(args, first.Year()*12+int(first.Month()-1))
+	}
+	if !before.IsZero() {
+		whereConds = append(whereConds, "year * 12 + (month - 1) <= ?")
+		args = append(args, before.Year()*12+int(before.Month()-1))
+	}
+	return strings.Join(whereConds, " AND "), args
+}
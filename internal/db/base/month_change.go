@@ -0,0 +1,68 @@
+package base
+
+import (
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+)
+
+// MonthChangePublisher is notified after Incomes, Monthly Payments or Spends of a month are changed
+type MonthChangePublisher interface {
+	// HasSubscribers is used to skip preparing of changes nobody is interested in
+	HasSubscribers(monthID uint) bool
+	Publish(change common.MonthChange)
+}
+
+// SetMonthChangePublisher sets a publisher of month changes. It must be called before the db is used
+func (db *DB) SetMonthChangePublisher(publisher MonthChangePublisher) {
+	db.publisher = publisher
+}
+
+// notifyRecordChanged adds Webhook deliveries of the event and publishes the month change after the commit.
+// It must be called after the month is recomputed
+func (db DB) notifyRecordChanged(tx *sqlx.Tx, event common.WebhookEvent, record interface{}, monthID uint) error {
+	if err := addWebhookDeliveries(tx, event, record, monthID); err != nil {
+		return err
+	}
+	return db.publishMonthChange(tx, event, monthID)
+}
+
+func (db DB) publishMonthChange(tx *sqlx.Tx, event common.WebhookEvent, monthID uint) error {
+	if db.publisher == nil || !db.publisher.HasSubscribers(monthID) {
+		return nil
+	}
+
+	m, err := getFullMonth(tx, "id = ?", monthID)
+	if err != nil {
+		return err
+	}
+
+	change := common.MonthChange{
+		Event:     event,
+		RequestID: reqid.FromContext(tx.Context()).ToString(),
+		Month:     m.MonthOverview.ToCommon(),
+		Days:      make([]common.DayChange, 0, len(m.Days)),
+	}
+	for _, mp := range m.MonthlyPayments {
+		change.MonthlyPaymentsCost = change.MonthlyPaymentsCost.Add(mp.Cost)
+	}
+	for _, day := range m.Days {
+		var cost money.Money
+		for _, spend := range day.Spends {
+			cost = cost.Add(spend.Cost)
+		}
+		change.Days = append(change.Days, common.DayChange{
+			ID:          day.ID,
+			Day:         day.Day,
+			Saldo:       day.Saldo,
+			SpendsCount: len(day.Spends),
+			SpendsCost:  cost,
+		})
+	}
+
+	tx.AfterCommit(func() {
+		db.publisher.Publish(change)
+	})
+	return nil
+}
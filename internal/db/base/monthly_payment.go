@@ -18,6 +18,8 @@ type MonthlyPayment struct {
 	TypeID  types.Uint   `db:"type_id"`
 	Notes   types.String `db:"notes"`
 	Cost    money.Money  `db:"cost"`
+	DueDay  types.Uint   `db:"due_day"`
+	Version int          `db:"version"`
 
 	Type *SpendType `db:"type"`
 }
@@ -33,6 +35,9 @@ func (mp MonthlyPayment) ToCommon(year int, month time.Month) common.MonthlyPaym
 		Type:  mp.Type.ToCommon(),
 		Notes: string(mp.Notes),
 		Cost:  mp.Cost,
+		//
+		DueDay:  uint(mp.DueDay),
+		Version: mp.Version,
 	}
 }
 
@@ -48,13 +53,17 @@ func (db DB) AddMonthlyPayment(ctx context.Context, args common.AddMonthlyPaymen
 
 		err = tx.Get(
 			&id,
-			`INSERT INTO monthly_payments(month_id, title, notes, type_id, cost) VALUES(?, ?, ?, ?, ?) RETURNING id`,
-			args.MonthID, args.Title, args.Notes, types.Uint(args.TypeID), args.Cost,
+			`INSERT INTO monthly_payments(month_id, title, notes, type_id, cost, due_day)
+			 VALUES(?, ?, ?, ?, ?, ?) RETURNING id`,
+			args.MonthID, args.Title, args.Notes, types.Uint(args.TypeID), args.Cost, types.Uint(args.DueDay),
 		)
 		if err != nil {
 			return err
 		}
-		return db.recomputeAndUpdateMonth(tx, args.MonthID)
+		if err := db.recomputeAndUpdateMonth(tx, args.MonthID); err != nil {
+			return err
+		}
+		return db.notifyMonthlyPaymentChanged(tx, common.WebhookEventMonthlyPaymentCreated, id, args.MonthID)
 	})
 	if err != nil {
 		return 0, err
@@ -63,9 +72,9 @@ func (db DB) AddMonthlyPayment(ctx context.Context, args common.AddMonthlyPaymen
 	return id, nil
 }
 
-// EditMonthlyPayment modifies existing Monthly Payment
-func (db DB) EditMonthlyPayment(ctx context.Context, args common.EditMonthlyPaymentArgs) error {
-	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+// EditMonthlyPayment modifies existing Monthly Payment. It returns a new version of the Monthly Payment
+func (db DB) EditMonthlyPayment(ctx context.Context, args common.EditMonthlyPaymentArgs) (version int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
 		if !checkMonthlyPayment(tx, args.ID) {
 			return common.ErrMonthlyPaymentNotExist
 		}
@@ -95,16 +104,34 @@ func (db DB) EditMonthlyPayment(ctx context.Context, args common.EditMonthlyPaym
 		if args.Cost != nil {
 			query.Set("cost", *args.Cost)
 		}
-		if _, err := tx.ExecQuery(query); err != nil {
+		if args.DueDay != nil {
+			if *args.DueDay == 0 {
+				query.Set("due_day", nil)
+			} else {
+				query.Set("due_day", *args.DueDay)
+			}
+		}
+		query.IncrementVersion(args.Version)
+		if err := execVersionedUpdate(tx, query); err != nil {
+			return err
+		}
+		version, err = selectVersion(tx, "monthly_payments", args.ID)
+		if err != nil {
 			return err
 		}
 
 		if args.Cost != nil {
 			// Recompute month only when cost has been changed
-			return db.recomputeAndUpdateMonth(tx, monthID)
+			if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+				return err
+			}
 		}
-		return nil
+		return db.notifyMonthlyPaymentChanged(tx, common.WebhookEventMonthlyPaymentUpdated, args.ID, monthID)
 	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
 // RemoveMonthlyPayment removes Monthly Payment with passed id
@@ -118,14 +145,99 @@ func (db DB) RemoveMonthlyPayment(ctx context.Context, id uint) error {
 		if err != nil {
 			return err
 		}
+		// Select Monthly Payment before removal to send it to Webhooks
+		mps, err := selectMonthlyPayments(tx, "monthly_payments.id = ?", id)
+		if err != nil {
+			return err
+		}
 
 		_, err = tx.Exec(`DELETE FROM monthly_payments WHERE id = ?`, id)
 		if err != nil {
 			return err
 		}
 
-		return db.recomputeAndUpdateMonth(tx, monthID)
+		if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+			return err
+		}
+		return db.notifyRecordChanged(tx, common.WebhookEventMonthlyPaymentDeleted, mps[0], monthID)
+	})
+}
+
+// GetMonthlyPayment returns Monthly Payment with passed id
+func (db DB) GetMonthlyPayment(ctx context.Context, id uint) (common.MonthlyPayment, error) {
+	var mps []common.MonthlyPayment
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkMonthlyPayment(tx, id) {
+			return common.ErrMonthlyPaymentNotExist
+		}
+
+		mps, err = selectMonthlyPayments(tx, "monthly_payments.id = ?", id)
+		return err
 	})
+	if err != nil {
+		return common.MonthlyPayment{}, err
+	}
+
+	return mps[0], nil
+}
+
+// GetMonthlyPayments returns all Monthly Payments of Month with passed id
+func (db DB) GetMonthlyPayments(ctx context.Context, monthID uint) (mps []common.MonthlyPayment, err error) {
+	err = db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkMonth(tx, monthID) {
+			return common.ErrMonthNotExist
+		}
+
+		mps, err = selectMonthlyPayments(tx, "monthly_payments.month_id = ?", monthID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return mps, nil
+}
+
+func selectMonthlyPayments(tx *sqlx.Tx, whereCond string, args ...interface{}) ([]common.MonthlyPayment, error) {
+	var mps []struct {
+		MonthlyPayment
+
+		Year  int        `db:"year"`
+		Month time.Month `db:"month"`
+	}
+	err := tx.Select(
+		&mps, `
+		SELECT
+			monthly_payments.*,
+			spend_types.id AS "type.id",
+			spend_types.name AS "type.name",
+			spend_types.parent_id AS "type.parent_id",
+			months.year AS year,
+			months.month AS month
+		FROM monthly_payments
+		INNER JOIN months ON months.id = monthly_payments.month_id
+		LEFT JOIN spend_types ON spend_types.id = monthly_payments.type_id
+		WHERE `+whereCond+`
+		ORDER BY monthly_payments.id`, args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Monthly Payments")
+	}
+
+	res := make([]common.MonthlyPayment, 0, len(mps))
+	for _, mp := range mps {
+		res = append(res, mp.MonthlyPayment.ToCommon(mp.Year, mp.Month))
+	}
+	return res, nil
+}
+
+// notifyMonthlyPaymentChanged selects Monthly Payment with passed id and notifies about the event
+func (db DB) notifyMonthlyPaymentChanged(tx *sqlx.Tx, event common.WebhookEvent, id, monthID uint) error {
+	mps, err := selectMonthlyPayments(tx, "monthly_payments.id = ?", id)
+	if err != nil {
+		return err
+	}
+	return db.notifyRecordChanged(tx, event, mps[0], monthID)
 }
 
 func (DB) selectMonthlyPaymentMonthID(tx *sqlx.Tx, id uint) (monthID uint, err error) {
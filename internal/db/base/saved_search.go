@@ -0,0 +1,213 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type SavedSearch struct {
+	ID     uint            `db:"id"`
+	Name   string          `db:"name"`
+	Pinned bool            `db:"pinned"`
+	Args   savedSearchArgs `db:"args"`
+}
+
+// ToCommon converts SavedSearch to common SavedSearch structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (s SavedSearch) ToCommon() common.SavedSearch {
+	return common.SavedSearch{
+		ID:     s.ID,
+		Name:   s.Name,
+		Pinned: s.Pinned,
+		Args:   common.SearchSpendsArgs(s.Args),
+	}
+}
+
+// savedSearchArgs is stored as JSON
+type savedSearchArgs common.SearchSpendsArgs
+
+var (
+	_ sql.Scanner   = (*savedSearchArgs)(nil)
+	_ driver.Valuer = (*savedSearchArgs)(nil)
+)
+
+func (v *savedSearchArgs) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return errors.Errorf("couldn't scan search args from %T", src)
+	}
+	return json.Unmarshal(data, (*common.SearchSpendsArgs)(v))
+}
+
+func (v savedSearchArgs) Value() (driver.Value, error) {
+	data, err := json.Marshal(common.SearchSpendsArgs(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+// GetSavedSearches returns all Saved Searches
+func (db DB) GetSavedSearches(ctx context.Context) ([]common.SavedSearch, error) {
+	var searches []SavedSearch
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		searches, err = selectSavedSearches(tx, "1 = 1")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.SavedSearch, 0, len(searches))
+	for i := range searches {
+		res = append(res, searches[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetSavedSearch returns Saved Search with passed id
+func (db DB) GetSavedSearch(ctx context.Context, id uint) (common.SavedSearch, error) {
+	var search SavedSearch
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSavedSearch(tx, id) {
+			return common.ErrSavedSearchNotExist
+		}
+		return tx.Get(&search, `SELECT * FROM saved_searches WHERE id = ?`, id)
+	})
+	if err != nil {
+		return common.SavedSearch{}, err
+	}
+
+	return search.ToCommon(), nil
+}
+
+// AddSavedSearch adds a new Saved Search
+func (db DB) AddSavedSearch(ctx context.Context, args common.AddSavedSearchArgs) (id uint, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		return tx.Get(
+			&id,
+			`INSERT INTO saved_searches(name, pinned, args) VALUES(?, ?, ?) RETURNING id`,
+			args.Name, args.Pinned, savedSearchArgs(args.Args),
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// EditSavedSearch modifies existing Saved Search
+func (db DB) EditSavedSearch(ctx context.Context, args common.EditSavedSearchArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSavedSearch(tx, args.ID) {
+			return common.ErrSavedSearchNotExist
+		}
+
+		query := newUpdateQueryBuilder("saved_searches", args.ID)
+		if args.Name != nil {
+			query.Set("name", *args.Name)
+		}
+		if args.Pinned != nil {
+			query.Set("pinned", *args.Pinned)
+		}
+		if args.Args != nil {
+			query.Set("args", savedSearchArgs(*args.Args))
+		}
+		_, err := tx.ExecQuery(query)
+		return err
+	})
+}
+
+// RemoveSavedSearch removes Saved Search with passed id
+func (db DB) RemoveSavedSearch(ctx context.Context, id uint) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSavedSearch(tx, id) {
+			return common.ErrSavedSearchNotExist
+		}
+
+		_, err := tx.Exec(`DELETE FROM saved_searches WHERE id = ?`, id)
+		return err
+	})
+}
+
+// GetSavedSearchReports returns totals of pinned Saved Searches for the passed month and the previous one.
+// Dates of Saved Searches are narrowed to the months
+func (db DB) GetSavedSearchReports(ctx context.Context,
+	year int, month time.Month) ([]common.SavedSearchReport, error) {
+
+	var searches []SavedSearch
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		searches, err = selectSavedSearches(tx, "pinned")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	prevYear, prevMonth := year, month-1
+	if prevMonth == 0 {
+		prevYear, prevMonth = year-1, time.December
+	}
+
+	reports := make([]common.SavedSearchReport, 0, len(searches))
+	for _, s := range searches {
+		search := s.ToCommon()
+
+		total, err := db.GetSearchSpendsTotal(ctx, narrowSearchSpendsArgsToMonth(search.Args, year, month))
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't get total of Saved Search with id %d", search.ID)
+		}
+		prevTotal, err := db.GetSearchSpendsTotal(ctx, narrowSearchSpendsArgsToMonth(search.Args, prevYear, prevMonth))
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't get previous total of Saved Search with id %d", search.ID)
+		}
+
+		reports = append(reports, common.SavedSearchReport{
+			SavedSearch: search,
+			Year:        year,
+			Month:       month,
+			Total:       total,
+			PrevTotal:   prevTotal,
+			Change:      total.Cost.Sub(prevTotal.Cost),
+		})
+	}
+	return reports, nil
+}
+
+func selectSavedSearches(tx *sqlx.Tx, where string) (searches []SavedSearch, err error) {
+	err = tx.Select(&searches, `SELECT * FROM saved_searches WHERE `+where+` ORDER BY id ASC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Saved Searches")
+	}
+	return searches, nil
+}
+
+// narrowSearchSpendsArgsToMonth returns args with After and Before within the month. Limit and Offset are reset
+func narrowSearchSpendsArgsToMonth(args common.SearchSpendsArgs, year int, month time.Month) common.SearchSpendsArgs {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	last := time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC)
+
+	if args.After.Before(first) {
+		args.After = first
+	}
+	if args.Before.IsZero() || args.Before.After(last) {
+		args.Before = last
+	}
+	args.Limit = 0
+	args.Offset = 0
+
+	return args
+}
@@ -0,0 +1,56 @@
+package base
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+func TestNarrowSearchSpendsArgsToMonth(t *testing.T) {
+	t.Parallel()
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		desc string
+		args common.SearchSpendsArgs
+		want common.SearchSpendsArgs
+	}{
+		{
+			desc: "no dates",
+			args: common.SearchSpendsArgs{Title: "coffee", Limit: 10, Offset: 5},
+			want: common.SearchSpendsArgs{
+				Title: "coffee", After: date(2024, time.February, 1), Before: date(2024, time.February, 29),
+			},
+		},
+		{
+			desc: "dates within the month",
+			args: common.SearchSpendsArgs{After: date(2024, time.February, 10), Before: date(2024, time.February, 20)},
+			want: common.SearchSpendsArgs{After: date(2024, time.February, 10), Before: date(2024, time.February, 20)},
+		},
+		{
+			desc: "dates outside the month",
+			args: common.SearchSpendsArgs{After: date(2023, time.January, 1), Before: date(2025, time.January, 1)},
+			want: common.SearchSpendsArgs{After: date(2024, time.February, 1), Before: date(2024, time.February, 29)},
+		},
+		{
+			desc: "no intersection",
+			args: common.SearchSpendsArgs{Before: date(2023, time.January, 1)},
+			want: common.SearchSpendsArgs{After: date(2024, time.February, 1), Before: date(2023, time.January, 1)},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			got := narrowSearchSpendsArgsToMonth(tt.args, 2024, time.February)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -8,6 +8,7 @@ import (
 	common "github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
 	"github.com/ShoshinNikita/budget-manager/internal/db/base/types"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 )
 
@@ -23,7 +24,7 @@ func (db DB) SearchSpends(ctx context.Context, args common.SearchSpendsArgs) ([]
 
 		Type SpendType `db:"type"`
 	}
-	err := db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
 		query, sqlArgs := db.buildSearchSpendsQuery(args)
 		return tx.Select(&spends, query, sqlArgs...)
 	})
@@ -48,18 +49,104 @@ func (db DB) SearchSpends(ctx context.Context, args common.SearchSpendsArgs) ([]
 	return res, nil
 }
 
-// buildSearchSpendsQuery builds a query to search for spends
-//nolint:funlen
-func (DB) buildSearchSpendsQuery(args common.SearchSpendsArgs) (string, []interface{}) {
+// GetSearchSpendsTotal returns a number and a total cost of all Spends that match the search args.
+// Sort, Order, Limit and Offset are ignored
+func (db DB) GetSearchSpendsTotal(ctx context.Context, args common.SearchSpendsArgs) (common.SearchSpendsTotal, error) {
+	var total struct {
+		Count int         `db:"count"`
+		Cost  money.Money `db:"cost"`
+	}
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		query, sqlArgs := db.buildSearchSpendsTotalQuery(args)
+		return tx.Get(&total, query, sqlArgs...)
+	})
+	if err != nil {
+		return common.SearchSpendsTotal{}, err
+	}
+
+	return common.SearchSpendsTotal{
+		Count: total.Count,
+		Cost:  total.Cost,
+	}, nil
+}
+
+// GetSearchSpendsStats returns statistics of all Spends that match the search args. Sort, Order,
+// Limit and Offset are ignored
+func (db DB) GetSearchSpendsStats(ctx context.Context, args common.SearchSpendsArgs) (common.SearchSpendsStats, error) {
 	var (
-		wheres    []string
-		whereArgs []interface{}
+		spentByType []struct {
+			TypeID types.Uint  `db:"type_id"`
+			Spent  money.Money `db:"spent"`
+		}
+		spentByDay []struct {
+			Year  int         `db:"year"`
+			Month time.Month  `db:"month"`
+			Day   int         `db:"day"`
+			Spent money.Money `db:"spent"`
+		}
+		costs []struct {
+			Cost  money.Money `db:"cost"`
+			Count int         `db:"count"`
+		}
 	)
-	addWhere := func(where string, args ...interface{}) {
-		wheres = append(wheres, where)
-		whereArgs = append(whereArgs, args...)
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		fromQuery, queryArgs := db.buildSearchSpendsFromQuery(args)
+
+		// SUM returns NUMERIC in PostgreSQL, so we have to cast it
+		err := tx.Select(
+			&spentByType,
+			`SELECT spend.type_id AS type_id, CAST(SUM(spend.cost) AS BIGINT) AS spent`+fromQuery+
+				` GROUP BY spend.type_id`,
+			queryArgs...,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select costs by Spend Type")
+		}
+
+		err = tx.Select(
+			&spentByDay,
+			`SELECT month.year AS year, month.month AS month, day.day AS day,
+			        CAST(SUM(spend.cost) AS BIGINT) AS spent`+fromQuery+
+				` GROUP BY month.year, month.month, day.day`,
+			queryArgs...,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select costs by day")
+		}
+
+		err = tx.Select(
+			&costs,
+			`SELECT spend.cost AS cost, COUNT(*) AS count`+fromQuery+` GROUP BY spend.cost ORDER BY spend.cost`,
+			queryArgs...,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select numbers of Spends by cost")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.SearchSpendsStats{}, err
 	}
 
+	stats := common.SearchSpendsStats{
+		SpentByType: make(map[uint]money.Money, len(spentByType)),
+		SpentByDay:  make(map[time.Time]money.Money, len(spentByDay)),
+		Costs:       make([]common.CostCount, 0, len(costs)),
+	}
+	for _, t := range spentByType {
+		stats.SpentByType[uint(t.TypeID)] = t.Spent
+	}
+	for _, d := range spentByDay {
+		stats.SpentByDay[time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, time.UTC)] = d.Spent
+	}
+	for _, c := range costs {
+		stats.Costs = append(stats.Costs, common.CostCount{Cost: c.Cost, Count: c.Count})
+	}
+	return stats, nil
+}
+
+// buildSearchSpendsQuery builds a query to search for spends
+func (db DB) buildSearchSpendsQuery(args common.SearchSpendsArgs) (string, []interface{}) {
 	query := "SELECT "
 
 	query += strings.Join([]string{
@@ -75,7 +162,73 @@ func (DB) buildSearchSpendsQuery(args common.SearchSpendsArgs) (string, []interf
 		`spend_type.parent_id AS "type.parent_id"`,
 	}, ", ")
 
-	query += " FROM spends AS spend "
+	fromQuery, queryArgs := db.buildSearchSpendsFromQuery(args)
+	query += fromQuery
+
+	sort := args.Sort
+	if sort == common.SortSpendsByRelevance && len(splitFullTextQuery(args.Text)) == 0 {
+		sort = common.SortSpendsByDate
+	}
+
+	var orders []string
+	switch sort {
+	case common.SortSpendsByDate:
+		orders = []string{"month.year", "month.month", "day.day"}
+	case common.SortSpendsByTitle:
+		orders = []string{"spend.title"}
+	case common.SortSpendsByCost:
+		orders = []string{"spend.cost"}
+	}
+	if args.Order == common.OrderByDesc {
+		for i := range orders {
+			orders[i] += " DESC"
+		}
+	}
+	if sort == common.SortSpendsByRelevance {
+		// Spends with the same rank are sorted by date
+		orders = []string{"fts.rank DESC", "month.year DESC", "month.month DESC", "day.day DESC"}
+	}
+	orders = append(orders, "spend.id")
+
+	query += " ORDER BY " + strings.Join(orders, ", ")
+
+	if args.Limit > 0 {
+		query += " LIMIT ?"
+		queryArgs = append(queryArgs, args.Limit)
+
+		if args.Offset > 0 {
+			query += " OFFSET ?"
+			queryArgs = append(queryArgs, args.Offset)
+		}
+	}
+
+	return query, queryArgs
+}
+
+// buildSearchSpendsTotalQuery builds a query to count spends and sum their costs. Sort, Order,
+// Limit and Offset are ignored
+func (db DB) buildSearchSpendsTotalQuery(args common.SearchSpendsArgs) (string, []interface{}) {
+	// SUM returns NUMERIC in PostgreSQL, so we have to cast it
+	query := `SELECT COUNT(*) AS count, CAST(COALESCE(SUM(spend.cost), 0) AS BIGINT) AS cost`
+
+	fromQuery, queryArgs := db.buildSearchSpendsFromQuery(args)
+	query += fromQuery
+
+	return query, queryArgs
+}
+
+// buildSearchSpendsFromQuery builds FROM and WHERE clauses to filter spends
+func (db DB) buildSearchSpendsFromQuery(args common.SearchSpendsArgs) (string, []interface{}) {
+	var (
+		wheres    []string
+		whereArgs []interface{}
+	)
+	addWhere := func(where string, args ...interface{}) {
+		wheres = append(wheres, where)
+		whereArgs = append(whereArgs, args...)
+	}
+
+	query := " FROM spends AS spend "
 
 	query += strings.Join([]string{
 		`INNER JOIN days AS day ON day.id = spend.day_id`,
@@ -83,112 +236,329 @@ func (DB) buildSearchSpendsQuery(args common.SearchSpendsArgs) (string, []interf
 		`LEFT JOIN spend_types AS spend_type ON spend_type.id = spend.type_id`,
 	}, " ")
 
-	if args.Title != "" {
-		title := "%" + args.Title + "%"
-		if args.TitleExactly {
-			title = args.Title
-		}
-		addWhere("LOWER(spend.title) LIKE ?", title)
+	// The join is placed before WHERE clause, so its args go first
+	var queryArgs []interface{}
+	if join, joinArgs := db.buildFullTextSearchJoin(args.Text); join != "" {
+		query += " " + join
+		queryArgs = joinArgs
 	}
 
+	if args.Title != "" {
+		addWhere(getQueryToFilterByText("spend.title", args.Title, args.TitleExactly))
+	}
 	if args.Notes != "" {
-		notes := "%" + args.Notes + "%"
-		if args.NotesExactly {
-			notes = args.Notes
-		}
-		addWhere("LOWER(spend.notes) LIKE ?", notes)
+		addWhere(getQueryToFilterByText("spend.notes", args.Notes, args.NotesExactly))
 	}
-
 	if q, args := getQueryToFilterByTime(args.After, args.Before); q != "" {
 		addWhere(q, args...)
 	}
+	if q, args := getQueryToFilterByCost("spend.cost", args.MinCost, args.MaxCost); q != "" {
+		addWhere(q, args...)
+	}
+	if q, args := getQueryToFilterByTypeIDs("spend.type_id", args.TypeIDs); q != "" {
+		addWhere(q, args...)
+	}
+	if args.Expr != nil {
+		q, args := buildSearchSpendsExprQuery(*args.Expr)
+		addWhere(q, args...)
+	}
+
+	if len(wheres) != 0 {
+		query += " WHERE " + strings.Join(wheres, " AND ")
+	}
+
+	return query, append(queryArgs, whereArgs...)
+}
+
+// spendDateExpr is a db-agnostic expression to compare dates of Spends
+const spendDateExpr = "month.year*10000 + month.month*100 + day.day"
+
+// convertDateForSpendDateExpr converts the date to a value that can be compared with spendDateExpr
+func convertDateForSpendDateExpr(t time.Time) int {
+	return t.Year()*10000 + int(t.Month())*100 + t.Day()
+}
+
+func getQueryToFilterByTime(after, before time.Time) (where string, args []interface{}) {
+	where = spendDateExpr
 
 	switch {
-	case args.MinCost != 0 && args.MaxCost != 0:
-		addWhere("spend.cost BETWEEN ? AND ?", int(args.MinCost), int(args.MaxCost))
-	case args.MinCost != 0:
-		addWhere("spend.cost >= ?", int(args.MinCost))
-	case args.MaxCost != 0:
-		addWhere("spend.cost <= ?", int(args.MaxCost))
+	case !after.IsZero() && !before.IsZero():
+		where += " BETWEEN ? AND ?"
+		args = []interface{}{convertDateForSpendDateExpr(after), convertDateForSpendDateExpr(before)}
+
+	case !after.IsZero():
+		where += " >= ?"
+		args = []interface{}{convertDateForSpendDateExpr(after)}
+
+	case !before.IsZero():
+		where += " <= ?"
+		args = []interface{}{convertDateForSpendDateExpr(before)}
+
+	default:
+		return "", nil
 	}
+	return where, args
+}
 
-	if len(args.TypeIDs) != 0 {
+// buildSearchSpendsExprQuery converts the boolean expression to a condition for WHERE clause
+func buildSearchSpendsExprQuery(expr common.SearchSpendsExpr) (where string, args []interface{}) {
+	join := func(exprs []common.SearchSpendsExpr, sep string) (string, []interface{}) {
 		var (
-			orWheres    []string
-			typeIDsArgs []interface{}
+			wheres []string
+			args   []interface{}
 		)
-
-		typeIDs := args.TypeIDs
-		for i, id := range typeIDs {
-			if id == 0 {
-				// Search for spends without type
-				orWheres = append(orWheres, "spend.type_id IS NULL")
-				typeIDs = append(typeIDs[:i], typeIDs[i+1:]...)
-				break
-			}
+		for _, e := range exprs {
+			where, whereArgs := buildSearchSpendsExprQuery(e)
+			wheres = append(wheres, where)
+			args = append(args, whereArgs...)
 		}
+		return "(" + strings.Join(wheres, sep) + ")", args
+	}
 
-		if len(typeIDs) != 0 {
-			inPlaceholders := strings.Repeat("?,", len(typeIDs))
-			inPlaceholders = inPlaceholders[:len(inPlaceholders)-1]
+	switch {
+	case len(expr.And) != 0:
+		return join(expr.And, " AND ")
+	case len(expr.Or) != 0:
+		return join(expr.Or, " OR ")
+	case expr.Not != nil:
+		where, args := buildSearchSpendsExprQuery(*expr.Not)
+		return "NOT (" + where + ")", args
+	case expr.Cond != nil:
+		return buildSearchSpendsCondQuery(*expr.Cond)
+	default:
+		// An empty expression matches all Spends
+		return "1 = 1", nil
+	}
+}
 
-			orWheres = append(orWheres, "spend.type_id IN ("+inPlaceholders+")")
-			for _, id := range typeIDs {
-				typeIDsArgs = append(typeIDsArgs, int(id))
-			}
+func buildSearchSpendsCondQuery(cond common.SearchSpendsCond) (where string, args []interface{}) {
+	var arg interface{}
+	switch cond.Field {
+	case common.SearchSpendsByTitle:
+		where, arg = "LOWER(spend.title)", cond.Text
+	case common.SearchSpendsByNotes:
+		// Notes can be NULL. Without COALESCE negative conditions would skip such Spends
+		where, arg = "LOWER(COALESCE(spend.notes, ''))", cond.Text
+	case common.SearchSpendsByCost:
+		where, arg = "spend.cost", int(cond.Cost)
+	case common.SearchSpendsByDate:
+		where, arg = spendDateExpr, convertDateForSpendDateExpr(cond.Date)
+	case common.SearchSpendsByType:
+		if len(cond.TypeIDs) == 0 {
+			return "1 = 0", nil
 		}
+		for _, id := range cond.TypeIDs {
+			args = append(args, int(id))
+		}
+		inPlaceholders := strings.Repeat("?,", len(args))
+		inPlaceholders = inPlaceholders[:len(inPlaceholders)-1]
 
-		addWhere("("+strings.Join(orWheres, " OR ")+")", typeIDsArgs...)
+		// Spends without type match id '0'
+		return "COALESCE(spend.type_id, 0) IN (" + inPlaceholders + ")", args
 	}
 
-	var orders []string
-	switch args.Sort {
-	case common.SortSpendsByDate:
-		orders = []string{"month.year", "month.month", "day.day"}
-	case common.SortSpendsByTitle:
-		orders = []string{"spend.title"}
-	case common.SortSpendsByCost:
-		orders = []string{"spend.cost"}
+	switch cond.Op {
+	case common.OpContains:
+		where += " LIKE ?"
+		arg = "%" + cond.Text + "%"
+	case common.OpLess:
+		where += " < ?"
+	case common.OpLessOrEqual:
+		where += " <= ?"
+	case common.OpGreater:
+		where += " > ?"
+	case common.OpGreaterOrEqual:
+		where += " >= ?"
+	default:
+		where += " = ?"
 	}
-	if args.Order == common.OrderByDesc {
-		for i := range orders {
-			orders[i] += " DESC"
-		}
+	return where, []interface{}{arg}
+}
+
+func (db DB) SearchIncomes(ctx context.Context, args common.SearchIncomesArgs) ([]common.Income, error) {
+	var incomes []struct {
+		Income
+
+		Year  int        `db:"year"`
+		Month time.Month `db:"month"`
+	}
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		query, sqlArgs := db.buildSearchIncomesQuery(args)
+		return tx.Select(&incomes, query, sqlArgs...)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.Income, 0, len(incomes))
+	for _, in := range incomes {
+		res = append(res, in.Income.ToCommon(in.Year, in.Month))
+	}
+	return res, nil
+}
+
+// buildSearchIncomesQuery builds a query to search for incomes
+func (DB) buildSearchIncomesQuery(args common.SearchIncomesArgs) (string, []interface{}) {
+	var (
+		wheres    []string
+		whereArgs []interface{}
+	)
+	addWhere := func(where string, args ...interface{}) {
+		wheres = append(wheres, where)
+		whereArgs = append(whereArgs, args...)
+	}
+
+	query := `SELECT incomes.*, months.year AS year, months.month AS month
+	            FROM incomes INNER JOIN months ON months.id = incomes.month_id`
+
+	if args.Title != "" {
+		addWhere(getQueryToFilterByText("incomes.title", args.Title, args.TitleExactly))
+	}
+	if args.Notes != "" {
+		addWhere(getQueryToFilterByText("incomes.notes", args.Notes, args.NotesExactly))
+	}
+	if !args.After.IsZero() || !args.Before.IsZero() {
+		q, args := getQueryToFilterMonths(args.After, args.Before)
+		addWhere(q, args...)
+	}
+	if q, args := getQueryToFilterByCost("incomes.income", args.MinIncome, args.MaxIncome); q != "" {
+		addWhere(q, args...)
 	}
-	orders = append(orders, "spend.id")
 
-	// Build the final query
 	if len(wheres) != 0 {
 		query += " WHERE " + strings.Join(wheres, " AND ")
 	}
-	query += " ORDER BY " + strings.Join(orders, ", ")
+	query += " ORDER BY " + getOrderByDate(args.Order, "incomes.id")
 
 	return query, whereArgs
 }
 
-func getQueryToFilterByTime(after, before time.Time) (where string, args []interface{}) {
-	convertTime := func(t time.Time) int {
-		return t.Year()*10000 + int(t.Month())*100 + t.Day()
+func (db DB) SearchMonthlyPayments(ctx context.Context,
+	args common.SearchMonthlyPaymentsArgs) ([]common.MonthlyPayment, error) {
+
+	var mps []struct {
+		MonthlyPayment
+
+		Year  int        `db:"year"`
+		Month time.Month `db:"month"`
+	}
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		query, sqlArgs := db.buildSearchMonthlyPaymentsQuery(args)
+		return tx.Select(&mps, query, sqlArgs...)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// It is a db-agnostic solution to compare dates
-	where = "month.year*10000 + month.month*100 + day.day"
+	res := make([]common.MonthlyPayment, 0, len(mps))
+	for _, mp := range mps {
+		res = append(res, mp.MonthlyPayment.ToCommon(mp.Year, mp.Month))
+	}
+	return res, nil
+}
 
-	switch {
-	case !after.IsZero() && !before.IsZero():
-		where += " BETWEEN ? AND ?"
-		args = []interface{}{convertTime(after), convertTime(before)}
+// buildSearchMonthlyPaymentsQuery builds a query to search for monthly payments
+func (DB) buildSearchMonthlyPaymentsQuery(args common.SearchMonthlyPaymentsArgs) (string, []interface{}) {
+	var (
+		wheres    []string
+		whereArgs []interface{}
+	)
+	addWhere := func(where string, args ...interface{}) {
+		wheres = append(wheres, where)
+		whereArgs = append(whereArgs, args...)
+	}
 
-	case !after.IsZero():
-		where += " >= ?"
-		args = []interface{}{convertTime(after)}
+	query := `SELECT monthly_payments.*,
+	                 spend_types.id AS "type.id", spend_types.name AS "type.name",
+	                 spend_types.parent_id AS "type.parent_id",
+	                 months.year AS year, months.month AS month
+	            FROM monthly_payments
+	                 INNER JOIN months ON months.id = monthly_payments.month_id
+	                 LEFT JOIN spend_types ON spend_types.id = monthly_payments.type_id`
 
-	case !before.IsZero():
-		where += " <= ?"
-		args = []interface{}{convertTime(before)}
+	if args.Title != "" {
+		addWhere(getQueryToFilterByText("monthly_payments.title", args.Title, args.TitleExactly))
+	}
+	if args.Notes != "" {
+		addWhere(getQueryToFilterByText("monthly_payments.notes", args.Notes, args.NotesExactly))
+	}
+	if !args.After.IsZero() || !args.Before.IsZero() {
+		q, args := getQueryToFilterMonths(args.After, args.Before)
+		addWhere(q, args...)
+	}
+	if q, args := getQueryToFilterByCost("monthly_payments.cost", args.MinCost, args.MaxCost); q != "" {
+		addWhere(q, args...)
+	}
+	if q, args := getQueryToFilterByTypeIDs("monthly_payments.type_id", args.TypeIDs); q != "" {
+		addWhere(q, args...)
+	}
+
+	if len(wheres) != 0 {
+		query += " WHERE " + strings.Join(wheres, " AND ")
+	}
+	query += " ORDER BY " + getOrderByDate(args.Order, "monthly_payments.id")
+
+	return query, whereArgs
+}
+
+// getOrderByDate returns ORDER BY expression to sort records of months by date. The id column
+// is used to keep the order of records within a month
+func getOrderByDate(order common.SearchOrder, idColumn string) string {
+	if order == common.OrderByDesc {
+		return "months.year DESC, months.month DESC, " + idColumn + " DESC"
+	}
+	return "months.year, months.month, " + idColumn
+}
 
+// getQueryToFilterByText returns a condition to search for the lowercased text in the column
+func getQueryToFilterByText(column, text string, exactly bool) (where string, arg interface{}) {
+	if !exactly {
+		text = "%" + text + "%"
+	}
+	return "LOWER(" + column + ") LIKE ?", text
+}
+
+func getQueryToFilterByCost(column string, min, max money.Money) (where string, args []interface{}) {
+	switch {
+	case min != 0 && max != 0:
+		return column + " BETWEEN ? AND ?", []interface{}{int(min), int(max)}
+	case min != 0:
+		return column + " >= ?", []interface{}{int(min)}
+	case max != 0:
+		return column + " <= ?", []interface{}{int(max)}
 	default:
 		return "", nil
 	}
-	return where, args
+}
+
+// getQueryToFilterByTypeIDs returns a condition to search for records with the passed Spend Types.
+// Id '0' is used to search for records without type
+func getQueryToFilterByTypeIDs(column string, typeIDs []uint) (where string, args []interface{}) {
+	if len(typeIDs) == 0 {
+		return "", nil
+	}
+
+	var (
+		orWheres    []string
+		withoutType bool
+	)
+	for _, id := range typeIDs {
+		if id == 0 {
+			withoutType = true
+			continue
+		}
+		args = append(args, int(id))
+	}
+
+	if withoutType {
+		orWheres = append(orWheres, column+" IS NULL")
+	}
+	if len(args) != 0 {
+		inPlaceholders := strings.Repeat("?,", len(args))
+		inPlaceholders = inPlaceholders[:len(inPlaceholders)-1]
+
+		orWheres = append(orWheres, column+" IN ("+inPlaceholders+")")
+	}
+
+	return "(" + strings.Join(orWheres, " OR ") + ")", args
 }
@@ -239,6 +239,65 @@ func TestBuildSearchSpendsQuery(t *testing.T) {
 			},
 			wantQuery: buildWhereQuery("", `ORDER BY spend.cost, spend.id`),
 		},
+		{
+			desc: "limit",
+			args: common.SearchSpendsArgs{
+				Limit: 10,
+			},
+			wantQuery: buildWhereQuery("", defaultOrderByQuery+` LIMIT ?`),
+			wantArgs:  []interface{}{10},
+		},
+		{
+			desc: "limit and offset",
+			args: common.SearchSpendsArgs{
+				Title:  "rent",
+				Limit:  10,
+				Offset: 20,
+			},
+			wantQuery: buildWhereQuery(`WHERE LOWER(spend.title) LIKE ?`, defaultOrderByQuery+` LIMIT ? OFFSET ?`),
+			wantArgs:  []interface{}{"%rent%", 10, 20},
+		},
+		{
+			desc: "offset without limit",
+			args: common.SearchSpendsArgs{
+				Offset: 20,
+			},
+			wantQuery: buildWhereQuery("", defaultOrderByQuery),
+		},
+		{
+			desc: "expression",
+			args: common.SearchSpendsArgs{
+				Title: "coffee",
+				Expr: &common.SearchSpendsExpr{
+					Or: []common.SearchSpendsExpr{
+						{
+							And: []common.SearchSpendsExpr{
+								{Cond: &common.SearchSpendsCond{
+									Field: common.SearchSpendsByCost, Op: common.OpGreater, Cost: money.FromInt(5),
+								}},
+								{Not: &common.SearchSpendsExpr{Cond: &common.SearchSpendsCond{
+									Field: common.SearchSpendsByNotes, Op: common.OpContains, Text: "decaf",
+								}}},
+							},
+						},
+						{Cond: &common.SearchSpendsCond{
+							Field: common.SearchSpendsByType, TypeIDs: []uint{0, 2},
+						}},
+						{Cond: &common.SearchSpendsCond{
+							Field: common.SearchSpendsByDate, Op: common.OpLess,
+							Date: time.Date(2020, time.March, 5, 0, 0, 0, 0, time.UTC),
+						}},
+					},
+				},
+			},
+			wantQuery: buildWhereQuery(`
+				WHERE LOWER(spend.title) LIKE ?
+				  AND ((spend.cost > ? AND NOT (LOWER(COALESCE(spend.notes, '')) LIKE ?))
+				       OR COALESCE(spend.type_id, 0) IN (?,?)
+				       OR month.year*10000 + month.month*100 + day.day < ?)
+			`, defaultOrderByQuery),
+			wantArgs: []interface{}{"%coffee%", 500, "%decaf%", 0, 2, 20200305},
+		},
 		{
 			desc:      "sql injection",
 			wantQuery: buildWhereQuery(`WHERE LOWER(spend.title) LIKE ?`, defaultOrderByQuery),
@@ -259,6 +318,108 @@ func TestBuildSearchSpendsQuery(t *testing.T) {
 	}
 }
 
+func TestBuildSearchSpendsTotalQuery(t *testing.T) {
+	t.Parallel()
+
+	query, args := (&DB{}).buildSearchSpendsTotalQuery(common.SearchSpendsArgs{
+		Title:  "rent",
+		Sort:   common.SortSpendsByCost,
+		Limit:  10,
+		Offset: 20,
+	})
+
+	wantQuery := formatQuery(`
+		SELECT COUNT(*) AS count, CAST(COALESCE(SUM(spend.cost), 0) AS BIGINT) AS cost
+
+		  FROM spends AS spend
+		       INNER JOIN days AS day
+		       ON day.id = spend.day_id
+
+		       INNER JOIN months AS month
+		       ON month.id = day.month_id
+
+		       LEFT JOIN spend_types AS spend_type
+		       ON spend_type.id = spend.type_id
+
+		 WHERE LOWER(spend.title) LIKE ?`,
+	)
+	require.Equal(t, wantQuery, query)
+	require.Equal(t, []interface{}{"%rent%"}, args)
+}
+
+func TestBuildSearchIncomesQuery(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		desc      string
+		args      common.SearchIncomesArgs
+		wantQuery string
+		wantArgs  []interface{}
+	}{
+		{
+			desc: "no args",
+			wantQuery: `
+				SELECT incomes.*, months.year AS year, months.month AS month
+				  FROM incomes INNER JOIN months ON months.id = incomes.month_id
+				 ORDER BY months.year, months.month, incomes.id`,
+		},
+		{
+			desc: "all args",
+			args: common.SearchIncomesArgs{
+				Title:        "salary",
+				Notes:        "bonus",
+				NotesExactly: true,
+				After:        time.Date(2020, time.January, 2, 0, 0, 0, 0, time.UTC),
+				Before:       time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC),
+				MinIncome:    money.FromInt(100),
+				Order:        common.OrderByDesc,
+			},
+			wantQuery: `
+				SELECT incomes.*, months.year AS year, months.month AS month
+				  FROM incomes INNER JOIN months ON months.id = incomes.month_id
+				 WHERE LOWER(incomes.title) LIKE ?
+				       AND LOWER(incomes.notes) LIKE ?
+				       AND 1 = 1 AND year * 12 + (month - 1) >= ? AND year * 12 + (month - 1) <= ?
+				       AND incomes.income >= ?
+				 ORDER BY months.year DESC, months.month DESC, incomes.id DESC`,
+			wantArgs: []interface{}{"%salary%", "bonus", 2020*12 + 1, 2020*12 + 11, 10000},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			query, args := (&DB{}).buildSearchIncomesQuery(tt.args)
+			require.Equal(t, formatQuery(tt.wantQuery), formatQuery(query))
+			require.Equal(t, tt.wantArgs, args)
+		})
+	}
+}
+
+func TestBuildSearchMonthlyPaymentsQuery(t *testing.T) {
+	t.Parallel()
+
+	query, args := (&DB{}).buildSearchMonthlyPaymentsQuery(common.SearchMonthlyPaymentsArgs{
+		Title:   "internet",
+		MaxCost: money.FromInt(50),
+		TypeIDs: []uint{0, 3},
+	})
+
+	wantQuery := `
+		SELECT monthly_payments.*,
+		       spend_types.id AS "type.id", spend_types.name AS "type.name",
+		       spend_types.parent_id AS "type.parent_id",
+		       months.year AS year, months.month AS month
+		  FROM monthly_payments
+		       INNER JOIN months ON months.id = monthly_payments.month_id
+		       LEFT JOIN spend_types ON spend_types.id = monthly_payments.type_id
+		 WHERE LOWER(monthly_payments.title) LIKE ?
+		       AND monthly_payments.cost <= ?
+		       AND (monthly_payments.type_id IS NULL OR monthly_payments.type_id IN (?))
+		 ORDER BY months.year, months.month, monthly_payments.id`
+	require.Equal(t, formatQuery(wantQuery), formatQuery(query))
+	require.Equal(t, []interface{}{"%internet%", 5000, 3}, args)
+}
+
 func formatQuery(query string) string {
 	queryBuilder := strings.Builder{}
 
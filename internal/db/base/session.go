@@ -0,0 +1,85 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type Session struct {
+	ID          uint      `db:"id"`
+	Username    string    `db:"username"`
+	TokenHash   string    `db:"token_hash"`
+	CreatedAt   time.Time `db:"created_at"`
+	ExpiresAt   time.Time `db:"expires_at"`
+	TOTPPending bool      `db:"totp_pending"`
+}
+
+// ToCommon converts Session to common Session structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (s Session) ToCommon() common.Session {
+	return common.Session{
+		ID:          s.ID,
+		Username:    s.Username,
+		TokenHash:   s.TokenHash,
+		CreatedAt:   s.CreatedAt.UTC(),
+		ExpiresAt:   s.ExpiresAt.UTC(),
+		TOTPPending: s.TOTPPending,
+	}
+}
+
+// GetSession returns Session with passed token hash that hasn't expired by the passed time
+func (db DB) GetSession(ctx context.Context, tokenHash string, now time.Time) (common.Session, error) {
+	var session Session
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(
+			&session, `SELECT * FROM sessions WHERE token_hash = ? AND expires_at > ?`, tokenHash, now.UTC(),
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.ErrSessionNotExist
+			}
+			return errors.Wrap(err, "couldn't select Session")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.Session{}, err
+	}
+
+	return session.ToCommon(), nil
+}
+
+// AddSession adds a new Session. Expired sessions are removed before the insertion
+func (db DB) AddSession(ctx context.Context, args common.AddSessionArgs) (id uint, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM sessions WHERE expires_at <= ?`, args.CreatedAt.UTC())
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove expired Sessions")
+		}
+
+		return tx.Get(
+			&id,
+			`INSERT INTO sessions(username, token_hash, created_at, expires_at, totp_pending)
+			 VALUES(?, ?, ?, ?, ?) RETURNING id`,
+			args.Username, args.TokenHash, args.CreatedAt.UTC(), args.ExpiresAt.UTC(), args.TOTPPending,
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// RemoveSession removes Session with passed token hash. It doesn't return an error if the session doesn't exist
+func (db DB) RemoveSession(ctx context.Context, tokenHash string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(`DELETE FROM sessions WHERE token_hash = ?`, tokenHash)
+		return err
+	})
+}
@@ -20,6 +20,8 @@ type Spend struct {
 	Notes  types.String `db:"notes"`
 	Cost   money.Money  `db:"cost"`
 
+	Version int `db:"version"`
+
 	Type *SpendType `db:"type"`
 }
 
@@ -35,6 +37,8 @@ func (s Spend) ToCommon(year int, month time.Month, day int) common.Spend {
 		Type:  s.Type.ToCommon(),
 		Notes: string(s.Notes),
 		Cost:  s.Cost,
+		//
+		Version: s.Version,
 	}
 }
 
@@ -48,6 +52,18 @@ func (db DB) AddSpend(ctx context.Context, args common.AddSpendArgs) (id uint, e
 			return common.ErrSpendTypeNotExist
 		}
 
+		if args.TypeID == 0 {
+			// Try to categorize Spend with user-defined rules
+			rules, err := selectSpendRules(tx)
+			if err != nil {
+				return err
+			}
+			if rule, ok := newSpendRuleMatcher(rules).Match(args.Title); ok {
+				args.TypeID = rule.TypeID
+				args.Notes = appendSpendRuleNotes(args.Notes, string(rule.Notes))
+			}
+		}
+
 		err = tx.Get(
 			&id,
 			`INSERT INTO spends(day_id, title, notes, type_id, cost) VALUES(?, ?, ?, ?, ?) RETURNING id`,
@@ -61,7 +77,10 @@ func (db DB) AddSpend(ctx context.Context, args common.AddSpendArgs) (id uint, e
 		if err != nil {
 			return err
 		}
-		return db.recomputeAndUpdateMonth(tx, monthID)
+		if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+			return err
+		}
+		return db.notifySpendChanged(tx, common.WebhookEventSpendCreated, id, monthID)
 	})
 	if err != nil {
 		return 0, err
@@ -70,9 +89,9 @@ func (db DB) AddSpend(ctx context.Context, args common.AddSpendArgs) (id uint, e
 	return id, nil
 }
 
-// EditSpend edits existeng Spend
-func (db DB) EditSpend(ctx context.Context, args common.EditSpendArgs) error {
-	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+// EditSpend edits existeng Spend. It returns a new version of the Spend
+func (db DB) EditSpend(ctx context.Context, args common.EditSpendArgs) (version int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
 		if !checkSpend(tx, args.ID) {
 			return common.ErrSpendNotExist
 		}
@@ -102,20 +121,31 @@ func (db DB) EditSpend(ctx context.Context, args common.EditSpendArgs) error {
 		if args.Cost != nil {
 			query.Set("cost", *args.Cost)
 		}
-		if _, err := tx.ExecQuery(query); err != nil {
+		query.IncrementVersion(args.Version)
+		if err := execVersionedUpdate(tx, query); err != nil {
+			return err
+		}
+		version, err = selectVersion(tx, "spends", args.ID)
+		if err != nil {
 			return err
 		}
 
+		monthID, err := db.selectMonthIDByDayID(tx, dayID)
+		if err != nil {
+			return err
+		}
 		if args.Cost != nil {
 			// Recompute month only when cost has been changed
-			monthID, err := db.selectMonthIDByDayID(tx, dayID)
-			if err != nil {
+			if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
 				return err
 			}
-			return db.recomputeAndUpdateMonth(tx, monthID)
 		}
-		return nil
+		return db.notifySpendChanged(tx, common.WebhookEventSpendUpdated, args.ID, monthID)
 	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
 // RemoveSpend removes Spend with passed id
@@ -129,6 +159,11 @@ func (db DB) RemoveSpend(ctx context.Context, id uint) error {
 		if err != nil {
 			return err
 		}
+		// Select Spend before removal to send it to Webhooks
+		spends, err := selectSpends(tx, "spends.id = ?", id)
+		if err != nil {
+			return err
+		}
 
 		_, err = tx.Exec(`DELETE FROM spends WHERE id = ?`, id)
 		if err != nil {
@@ -139,8 +174,91 @@ func (db DB) RemoveSpend(ctx context.Context, id uint) error {
 		if err != nil {
 			return err
 		}
-		return db.recomputeAndUpdateMonth(tx, monthID)
+		if err := db.recomputeAndUpdateMonth(tx, monthID); err != nil {
+			return err
+		}
+		return db.notifyRecordChanged(tx, common.WebhookEventSpendDeleted, spends[0], monthID)
+	})
+}
+
+// GetSpend returns Spend with passed id
+func (db DB) GetSpend(ctx context.Context, id uint) (common.Spend, error) {
+	var spends []common.Spend
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkSpend(tx, id) {
+			return common.ErrSpendNotExist
+		}
+
+		spends, err = selectSpends(tx, "spends.id = ?", id)
+		return err
+	})
+	if err != nil {
+		return common.Spend{}, err
+	}
+
+	return spends[0], nil
+}
+
+// GetSpends returns all Spends of Month with passed id sorted by date
+func (db DB) GetSpends(ctx context.Context, monthID uint) (spends []common.Spend, err error) {
+	err = db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		if !checkMonth(tx, monthID) {
+			return common.ErrMonthNotExist
+		}
+
+		spends, err = selectSpends(tx, "days.month_id = ?", monthID)
+		return err
 	})
+	if err != nil {
+		return nil, err
+	}
+
+	return spends, nil
+}
+
+func selectSpends(tx *sqlx.Tx, whereCond string, args ...interface{}) ([]common.Spend, error) {
+	var spends []struct {
+		Spend
+
+		Year  int        `db:"year"`
+		Month time.Month `db:"month"`
+		Day   int        `db:"day"`
+	}
+	err := tx.Select(
+		&spends, `
+		SELECT
+			spends.*,
+			spend_types.id AS "type.id",
+			spend_types.name AS "type.name",
+			spend_types.parent_id AS "type.parent_id",
+			months.year AS year,
+			months.month AS month,
+			days.day AS day
+		FROM spends
+		INNER JOIN days ON days.id = spends.day_id
+		INNER JOIN months ON months.id = days.month_id
+		LEFT JOIN spend_types ON spend_types.id = spends.type_id
+		WHERE `+whereCond+`
+		ORDER BY days.day, spends.id`, args...,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Spends")
+	}
+
+	res := make([]common.Spend, 0, len(spends))
+	for _, s := range spends {
+		res = append(res, s.Spend.ToCommon(s.Year, s.Month, s.Day))
+	}
+	return res, nil
+}
+
+// notifySpendChanged selects Spend with passed id and notifies about the event
+func (db DB) notifySpendChanged(tx *sqlx.Tx, event common.WebhookEvent, id, monthID uint) error {
+	spends, err := selectSpends(tx, "spends.id = ?", id)
+	if err != nil {
+		return err
+	}
+	return db.notifyRecordChanged(tx, event, spends[0], monthID)
 }
 
 func (DB) selectSpendDayID(tx *sqlx.Tx, id uint) (dayID uint, err error) {
@@ -0,0 +1,206 @@
+package base
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/types"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type SpendRule struct {
+	ID           uint         `db:"id"`
+	TitlePattern string       `db:"title_pattern"`
+	TypeID       uint         `db:"type_id"`
+	Notes        types.String `db:"notes"`
+}
+
+// ToCommon converts SpendRule to common SpendRule structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (r SpendRule) ToCommon() common.SpendRule {
+	return common.SpendRule{
+		ID:           r.ID,
+		TitlePattern: r.TitlePattern,
+		TypeID:       r.TypeID,
+		Notes:        string(r.Notes),
+	}
+}
+
+// GetSpendRules returns all Spend Rules
+func (db DB) GetSpendRules(ctx context.Context) ([]common.SpendRule, error) {
+	var rules []SpendRule
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		rules, err = selectSpendRules(tx)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.SpendRule, 0, len(rules))
+	for i := range rules {
+		res = append(res, rules[i].ToCommon())
+	}
+	return res, nil
+}
+
+// AddSpendRule adds a new Spend Rule
+func (db DB) AddSpendRule(ctx context.Context, args common.AddSpendRuleArgs) (id uint, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSpendType(tx, args.TypeID) {
+			return common.ErrSpendTypeNotExist
+		}
+
+		return tx.Get(
+			&id,
+			`INSERT INTO spend_rules(title_pattern, type_id, notes) VALUES(?, ?, ?) RETURNING id`,
+			args.TitlePattern, args.TypeID, args.Notes,
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// EditSpendRule modifies existing Spend Rule
+func (db DB) EditSpendRule(ctx context.Context, args common.EditSpendRuleArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSpendRule(tx, args.ID) {
+			return common.ErrSpendRuleNotExist
+		}
+		if args.TypeID != nil && !checkSpendType(tx, *args.TypeID) {
+			return common.ErrSpendTypeNotExist
+		}
+
+		query := newUpdateQueryBuilder("spend_rules", args.ID)
+		if args.TitlePattern != nil {
+			query.Set("title_pattern", *args.TitlePattern)
+		}
+		if args.TypeID != nil {
+			query.Set("type_id", *args.TypeID)
+		}
+		if args.Notes != nil {
+			query.Set("notes", *args.Notes)
+		}
+		_, err := tx.ExecQuery(query)
+		return err
+	})
+}
+
+// RemoveSpendRule removes Spend Rule with passed id
+func (db DB) RemoveSpendRule(ctx context.Context, id uint) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSpendRule(tx, id) {
+			return common.ErrSpendRuleNotExist
+		}
+
+		_, err := tx.Exec(`DELETE FROM spend_rules WHERE id = ?`, id)
+		return err
+	})
+}
+
+// ApplySpendRules applies Spend Rules to all Spends without type. It returns the number of categorized Spends
+func (db DB) ApplySpendRules(ctx context.Context) (count int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		var spends []Spend
+		err := tx.Select(&spends, `SELECT id, title, notes FROM spends WHERE type_id IS NULL ORDER BY id`)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select Spends without type")
+		}
+		if len(spends) == 0 {
+			return nil
+		}
+
+		rules, err := selectSpendRules(tx)
+		if err != nil {
+			return err
+		}
+		matcher := newSpendRuleMatcher(rules)
+
+		for _, spend := range spends {
+			rule, ok := matcher.Match(spend.Title)
+			if !ok {
+				continue
+			}
+
+			// Spends can be changed concurrently, so only Spends that are still without type are updated
+			res, err := tx.Exec(
+				`UPDATE spends SET type_id = ?, notes = ?, version = version + 1 WHERE id = ? AND type_id IS NULL`,
+				rule.TypeID, appendSpendRuleNotes(string(spend.Notes), string(rule.Notes)), spend.ID,
+			)
+			if err != nil {
+				return errors.Wrapf(err, "couldn't update Spend with id %d", spend.ID)
+			}
+			rows, err := res.RowsAffected()
+			if err != nil {
+				return errors.Wrap(err, "couldn't get number of updated rows")
+			}
+			count += int(rows)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func selectSpendRules(tx *sqlx.Tx) (rules []SpendRule, err error) {
+	err = tx.Select(&rules, `SELECT * FROM spend_rules ORDER BY id ASC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Spend Rules")
+	}
+	return rules, nil
+}
+
+// spendRuleMatcher finds the first Spend Rule that matches a Spend title
+type spendRuleMatcher struct {
+	rules    []SpendRule
+	patterns []*regexp.Regexp
+}
+
+func newSpendRuleMatcher(rules []SpendRule) *spendRuleMatcher {
+	m := &spendRuleMatcher{
+		rules:    make([]SpendRule, 0, len(rules)),
+		patterns: make([]*regexp.Regexp, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		pattern, err := regexp.Compile(rule.TitlePattern)
+		if err != nil {
+			// Patterns are checked before saving, so just skip invalid ones
+			continue
+		}
+		m.rules = append(m.rules, rule)
+		m.patterns = append(m.patterns, pattern)
+	}
+	return m
+}
+
+func (m *spendRuleMatcher) Match(title string) (SpendRule, bool) {
+	for i, pattern := range m.patterns {
+		if pattern.MatchString(title) {
+			return m.rules[i], true
+		}
+	}
+	return SpendRule{}, false
+}
+
+// appendSpendRuleNotes appends notes of a Spend Rule to notes of a Spend. Notes are not duplicated
+func appendSpendRuleNotes(notes, ruleNotes string) string {
+	switch {
+	case ruleNotes == "":
+		return notes
+	case notes == "":
+		return ruleNotes
+	case strings.Contains(notes, ruleNotes):
+		return notes
+	default:
+		return notes + "; " + ruleNotes
+	}
+}
@@ -0,0 +1,61 @@
+package base
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpendRuleMatcher(t *testing.T) {
+	t.Parallel()
+
+	rules := []SpendRule{
+		{ID: 1, TitlePattern: `(?i)lidl|aldi`, TypeID: 1, Notes: "groceries"},
+		{ID: 2, TitlePattern: `[invalid`, TypeID: 2},
+		{ID: 3, TitlePattern: `^coffee`, TypeID: 3},
+		{ID: 4, TitlePattern: `(?i)coffee`, TypeID: 4},
+	}
+	matcher := newSpendRuleMatcher(rules)
+
+	tests := []struct {
+		title      string
+		wantRuleID uint
+	}{
+		{title: "LIDL", wantRuleID: 1},
+		{title: "weekly shopping in Aldi", wantRuleID: 1},
+		{title: "coffee", wantRuleID: 3},
+		{title: "Morning coffee", wantRuleID: 4},
+		{title: "[invalid", wantRuleID: 0},
+		{title: "pizza", wantRuleID: 0},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.title, func(t *testing.T) {
+			rule, ok := matcher.Match(tt.title)
+			require.Equal(t, tt.wantRuleID != 0, ok)
+			require.Equal(t, tt.wantRuleID, rule.ID)
+		})
+	}
+}
+
+func TestAppendSpendRuleNotes(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		notes     string
+		ruleNotes string
+		want      string
+	}{
+		{notes: "", ruleNotes: "", want: ""},
+		{notes: "fresh", ruleNotes: "", want: "fresh"},
+		{notes: "", ruleNotes: "groceries", want: "groceries"},
+		{notes: "fresh", ruleNotes: "groceries", want: "fresh; groceries"},
+		{notes: "fresh; groceries", ruleNotes: "groceries", want: "fresh; groceries"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run("", func(t *testing.T) {
+			require.Equal(t, tt.want, appendSpendRuleNotes(tt.notes, tt.ruleNotes))
+		})
+	}
+}
@@ -14,6 +14,7 @@ type SpendType struct {
 	ID       types.Uint   `db:"id"`
 	Name     types.String `db:"name"`
 	ParentID types.Uint   `db:"parent_id"`
+	Version  int          `db:"version"`
 }
 
 // ToCommon converts SpendType to common SpendType structure from
@@ -33,13 +34,14 @@ func (s *SpendType) ToCommon() *common.SpendType {
 		ID:       uint(s.ID),
 		Name:     string(s.Name),
 		ParentID: uint(s.ParentID),
+		Version:  s.Version,
 	}
 }
 
 // GetSpendTypes returns all Spend Types
 func (db DB) GetSpendTypes(ctx context.Context) ([]common.SpendType, error) {
 	var spendTypes []SpendType
-	err := db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
 		return tx.Select(&spendTypes, `SELECT * from spend_types ORDER BY id ASC`)
 	})
 	if err != nil {
@@ -53,6 +55,21 @@ func (db DB) GetSpendTypes(ctx context.Context) ([]common.SpendType, error) {
 	return res, nil
 }
 
+// GetSpendType returns Spend Type with passed id
+func (db DB) GetSpendType(ctx context.Context, id uint) (common.SpendType, error) {
+	var spendType SpendType
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkSpendType(tx, id) {
+			return common.ErrSpendTypeNotExist
+		}
+		return tx.Get(&spendType, `SELECT * from spend_types WHERE id = ?`, id)
+	})
+	if err != nil {
+		return common.SpendType{}, err
+	}
+	return *spendType.ToCommon(), nil
+}
+
 // AddSpendType adds new Spend Type
 func (db DB) AddSpendType(ctx context.Context, args common.AddSpendTypeArgs) (id uint, err error) {
 	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
@@ -69,9 +86,9 @@ func (db DB) AddSpendType(ctx context.Context, args common.AddSpendTypeArgs) (id
 	return id, nil
 }
 
-// EditSpendType modifies existing Spend Type
-func (db DB) EditSpendType(ctx context.Context, args common.EditSpendTypeArgs) error {
-	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+// EditSpendType modifies existing Spend Type. It returns a new version of the Spend Type
+func (db DB) EditSpendType(ctx context.Context, args common.EditSpendTypeArgs) (version int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
 		if !checkSpendType(tx, args.ID) {
 			return common.ErrSpendTypeNotExist
 		}
@@ -87,9 +104,17 @@ func (db DB) EditSpendType(ctx context.Context, args common.EditSpendTypeArgs) e
 				query.Set("parent_id", *args.ParentID)
 			}
 		}
-		_, err := tx.ExecQuery(query)
+		query.IncrementVersion(args.Version)
+		if err := execVersionedUpdate(tx, query); err != nil {
+			return err
+		}
+		version, err = selectVersion(tx, "spend_types", args.ID)
 		return err
 	})
+	if err != nil {
+		return 0, err
+	}
+	return version, nil
 }
 
 // RemoveSpendType removes Spend Type with passed id
@@ -99,8 +124,8 @@ func (db DB) RemoveSpendType(ctx context.Context, id uint) error {
 			return common.ErrSpendTypeNotExist
 		}
 
-		// Don't remove Spend Type if it is used by Monthly Payment or Spend
-		for _, table := range []string{"monthly_payments", "spends"} {
+		// Don't remove Spend Type if it is used by Monthly Payment, Spend or Spend Rule
+		for _, table := range []string{"monthly_payments", "spends", "spend_rules"} {
 			var c int
 			err := tx.Get(&c, fmt.Sprintf("SELECT COUNT(*) FROM %s WHERE type_id = ?", table), id)
 			if err != nil {
@@ -111,6 +136,24 @@ func (db DB) RemoveSpendType(ctx context.Context, id uint) error {
 			}
 		}
 
+		// Don't remove Spend Type if it is used by Saved Search. Otherwise, the search would be changed
+		// and a backup with it would be invalid
+		var searches []savedSearchArgs
+		if err := tx.Select(&searches, `SELECT args FROM saved_searches`); err != nil {
+			return errors.Wrap(err, "couldn't select Saved Searches")
+		}
+		for _, args := range searches {
+			var used bool
+			(*common.SearchSpendsArgs)(&args).RangeTypeIDs(func(typeID *uint) {
+				if *typeID == id {
+					used = true
+				}
+			})
+			if used {
+				return common.ErrSpendTypeIsUsed
+			}
+		}
+
 		// Remove Spend Type
 		_, err := tx.Exec(`DELETE FROM spend_types WHERE id = ?`, id)
 		if err != nil {
@@ -0,0 +1,174 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type TOTP struct {
+	ID           uint      `db:"id"`
+	Username     string    `db:"username"`
+	Secret       string    `db:"secret"`
+	Enabled      bool      `db:"enabled"`
+	LastUsedStep int64     `db:"last_used_step"`
+	CreatedAt    time.Time `db:"created_at"`
+	//
+	RecoveryCodes int `db:"recovery_codes"`
+}
+
+// ToCommon converts TOTP to common TOTP structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (t TOTP) ToCommon() common.TOTP {
+	return common.TOTP{
+		ID:            t.ID,
+		Username:      t.Username,
+		Secret:        t.Secret,
+		Enabled:       t.Enabled,
+		LastUsedStep:  t.LastUsedStep,
+		CreatedAt:     t.CreatedAt.UTC(),
+		RecoveryCodes: t.RecoveryCodes,
+	}
+}
+
+// GetTOTP returns TOTP of the user with a number of unused recovery codes
+func (db DB) GetTOTP(ctx context.Context, username string) (common.TOTP, error) {
+	var totp TOTP
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Get(&totp, `
+			SELECT totp.*, (SELECT COUNT(*) FROM recovery_codes WHERE username = totp.username) AS recovery_codes
+			FROM totp WHERE username = ?`,
+			username,
+		)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return common.ErrTOTPNotExist
+			}
+			return errors.Wrap(err, "couldn't select TOTP")
+		}
+		return nil
+	})
+	if err != nil {
+		return common.TOTP{}, err
+	}
+
+	return totp.ToCommon(), nil
+}
+
+// SetTOTPSecret saves a new secret of the user. TOTP stays disabled until it is confirmed with 'EnableTOTP'
+func (db DB) SetTOTPSecret(ctx context.Context, username, secret string, createdAt time.Time) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO totp(username, secret, enabled, last_used_step, created_at) VALUES(?, ?, false, 0, ?)
+			 ON CONFLICT (username) DO UPDATE SET
+				secret = excluded.secret,
+				enabled = excluded.enabled,
+				last_used_step = excluded.last_used_step,
+				created_at = excluded.created_at`,
+			username, secret, createdAt.UTC(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't save TOTP secret")
+		}
+		return nil
+	})
+}
+
+// EnableTOTP enables TOTP of the user and replaces recovery codes
+func (db DB) EnableTOTP(ctx context.Context, args common.EnableTOTPArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(
+			`UPDATE totp SET enabled = true, last_used_step = ? WHERE username = ?`, args.Step, args.Username,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't enable TOTP")
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of updated rows")
+		}
+		if rows == 0 {
+			return common.ErrTOTPNotExist
+		}
+
+		return replaceRecoveryCodes(tx, args.Username, args.RecoveryCodeHashes)
+	})
+}
+
+// UseTOTPStep marks the time step of the code as used. It returns 'ErrTOTPCodeAlreadyUsed' if the code
+// of this or a later step has already been used
+func (db DB) UseTOTPStep(ctx context.Context, username string, step int64) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(
+			`UPDATE totp SET last_used_step = ? WHERE username = ? AND last_used_step < ?`, step, username, step,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't update last used step")
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of updated rows")
+		}
+		if rows == 0 {
+			return common.ErrTOTPCodeAlreadyUsed
+		}
+		return nil
+	})
+}
+
+// UseRecoveryCode removes the recovery code of the user. It returns 'ErrRecoveryCodeNotExist' if
+// the code is invalid or has already been used
+func (db DB) UseRecoveryCode(ctx context.Context, username, codeHash string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(`DELETE FROM recovery_codes WHERE username = ? AND code_hash = ?`, username, codeHash)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove Recovery Code")
+		}
+		rows, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of removed rows")
+		}
+		if rows == 0 {
+			return common.ErrRecoveryCodeNotExist
+		}
+		return nil
+	})
+}
+
+// ReplaceRecoveryCodes replaces all recovery codes of the user
+func (db DB) ReplaceRecoveryCodes(ctx context.Context, username string, codeHashes []string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		return replaceRecoveryCodes(tx, username, codeHashes)
+	})
+}
+
+func replaceRecoveryCodes(tx *sqlx.Tx, username string, codeHashes []string) error {
+	if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE username = ?`, username); err != nil {
+		return errors.Wrap(err, "couldn't remove old Recovery Codes")
+	}
+	for _, hash := range codeHashes {
+		_, err := tx.Exec(`INSERT INTO recovery_codes(username, code_hash) VALUES(?, ?)`, username, hash)
+		if err != nil {
+			return errors.Wrap(err, "couldn't add Recovery Code")
+		}
+	}
+	return nil
+}
+
+// RemoveTOTP disables TOTP of the user and removes recovery codes. It doesn't return an error if TOTP
+// isn't set up
+func (db DB) RemoveTOTP(ctx context.Context, username string) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM totp WHERE username = ?`, username); err != nil {
+			return errors.Wrap(err, "couldn't remove TOTP")
+		}
+		if _, err := tx.Exec(`DELETE FROM recovery_codes WHERE username = ?`, username); err != nil {
+			return errors.Wrap(err, "couldn't remove Recovery Codes")
+		}
+		return nil
+	})
+}
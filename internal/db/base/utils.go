@@ -1,12 +1,13 @@
 package base
 
 import (
-	"errors"
 	"fmt"
 	"strings"
 	"time"
 
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 )
 
 // --------------------------------------------------
@@ -43,6 +44,26 @@ func checkSpendType(tx *sqlx.Tx, id uint) bool {
 	return checkModel(tx, "spend_types", id)
 }
 
+// checkSpendRule checks if a Spend Rule with passed id exists
+func checkSpendRule(tx *sqlx.Tx, id uint) bool {
+	return checkModel(tx, "spend_rules", id)
+}
+
+// checkSavedSearch checks if a Saved Search with passed id exists
+func checkSavedSearch(tx *sqlx.Tx, id uint) bool {
+	return checkModel(tx, "saved_searches", id)
+}
+
+// checkWebhook checks if a Webhook with passed id exists
+func checkWebhook(tx *sqlx.Tx, id uint) bool {
+	return checkModel(tx, "webhooks", id)
+}
+
+// checkAuthLockout checks if an Auth Lockout with passed id exists
+func checkAuthLockout(tx *sqlx.Tx, id uint) bool {
+	return checkModel(tx, "auth_lockouts", id)
+}
+
 // checkModel checks if a model with passed id exists
 func checkModel(tx *sqlx.Tx, table string, id uint) bool {
 	var c int
@@ -75,6 +96,9 @@ type updateQueryBuilder struct {
 	setArgs []interface{}
 
 	whereID uint
+
+	incrementVersion bool
+	expectedVersion  *int
 }
 
 func newUpdateQueryBuilder(table string, whereID uint) *updateQueryBuilder {
@@ -89,12 +113,54 @@ func (b *updateQueryBuilder) Set(column string, v interface{}) {
 	b.setArgs = append(b.setArgs, v)
 }
 
+// IncrementVersion increments the version of the record. If the expected version is not nil,
+// the record is updated only if it has this version
+func (b *updateQueryBuilder) IncrementVersion(expected *int) {
+	b.incrementVersion = true
+	b.expectedVersion = expected
+}
+
 func (b *updateQueryBuilder) ToSQL() (string, []interface{}, error) {
 	if len(b.sets) == 0 {
 		return "", nil, errors.New("list of SETs is empty")
 	}
 
-	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = ?`, b.table, strings.Join(b.sets, ", "))
+	sets := b.sets
+	if b.incrementVersion {
+		sets = append(sets[:len(sets):len(sets)], "version = version + 1")
+	}
+
+	query := fmt.Sprintf(`UPDATE %s SET %s WHERE id = ?`, b.table, strings.Join(sets, ", "))
 	args := append(b.setArgs, b.whereID) //nolint:gocritic
+	if b.expectedVersion != nil {
+		query += " AND version = ?"
+		args = append(args, *b.expectedVersion)
+	}
 	return query, args, nil
 }
+
+// selectVersion returns the current version of the record
+func selectVersion(tx *sqlx.Tx, table string, id uint) (version int, err error) {
+	err = tx.Get(&version, `SELECT version FROM `+table+` WHERE id = ?`, id)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't select version")
+	}
+	return version, nil
+}
+
+// execVersionedUpdate executes the update query and returns ErrVersionConflict if the record
+// was not updated because of a version mismatch. The record must exist
+func execVersionedUpdate(tx *sqlx.Tx, query *updateQueryBuilder) error {
+	res, err := tx.ExecQuery(query)
+	if err != nil {
+		return err
+	}
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return errors.Wrap(err, "couldn't get number of updated rows")
+	}
+	if rows == 0 {
+		return common.ErrVersionConflict
+	}
+	return nil
+}
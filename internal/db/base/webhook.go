@@ -0,0 +1,379 @@
+package base
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	common "github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base/internal/sqlx"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type Webhook struct {
+	ID      uint          `db:"id"`
+	URL     string        `db:"url"`
+	Secret  string        `db:"secret"`
+	Events  webhookEvents `db:"events"`
+	Enabled bool          `db:"enabled"`
+}
+
+// ToCommon converts Webhook to common Webhook structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (w Webhook) ToCommon() common.Webhook {
+	return common.Webhook{
+		ID:      w.ID,
+		URL:     w.URL,
+		Secret:  w.Secret,
+		Events:  []common.WebhookEvent(w.Events),
+		Enabled: w.Enabled,
+	}
+}
+
+// Has checks whether the Webhook is subscribed to the event
+func (w Webhook) Has(event common.WebhookEvent) bool {
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// webhookEvents is stored as JSON
+type webhookEvents []common.WebhookEvent
+
+var (
+	_ sql.Scanner   = (*webhookEvents)(nil)
+	_ driver.Valuer = (*webhookEvents)(nil)
+)
+
+func (v *webhookEvents) Scan(src interface{}) error {
+	var data []byte
+	switch src := src.(type) {
+	case string:
+		data = []byte(src)
+	case []byte:
+		data = src
+	default:
+		return errors.Errorf("couldn't scan webhook events from %T", src)
+	}
+	return json.Unmarshal(data, (*[]common.WebhookEvent)(v))
+}
+
+func (v webhookEvents) Value() (driver.Value, error) {
+	if v == nil {
+		v = webhookEvents{}
+	}
+	data, err := json.Marshal([]common.WebhookEvent(v))
+	if err != nil {
+		return nil, err
+	}
+	return string(data), nil
+}
+
+type WebhookDelivery struct {
+	ID            uint      `db:"id"`
+	WebhookID     uint      `db:"webhook_id"`
+	Event         string    `db:"event"`
+	Payload       string    `db:"payload"`
+	Status        string    `db:"status"`
+	Attempts      int       `db:"attempts"`
+	ResponseCode  int       `db:"response_code"`
+	Error         string    `db:"error"`
+	CreatedAt     time.Time `db:"created_at"`
+	NextAttemptAt time.Time `db:"next_attempt_at"`
+}
+
+// ToCommon converts WebhookDelivery to common WebhookDelivery structure from
+// "github.com/ShoshinNikita/budget-manager/internal/db" package
+func (d WebhookDelivery) ToCommon() common.WebhookDelivery {
+	return common.WebhookDelivery{
+		ID:            d.ID,
+		WebhookID:     d.WebhookID,
+		Event:         common.WebhookEvent(d.Event),
+		Payload:       d.Payload,
+		Status:        common.WebhookDeliveryStatus(d.Status),
+		Attempts:      d.Attempts,
+		ResponseCode:  d.ResponseCode,
+		Error:         d.Error,
+		CreatedAt:     d.CreatedAt.UTC(),
+		NextAttemptAt: d.NextAttemptAt.UTC(),
+	}
+}
+
+// GetWebhooks returns all Webhooks
+func (db DB) GetWebhooks(ctx context.Context) ([]common.Webhook, error) {
+	var webhooks []Webhook
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) (err error) {
+		webhooks, err = selectWebhooks(tx, "1 = 1")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.Webhook, 0, len(webhooks))
+	for i := range webhooks {
+		res = append(res, webhooks[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetWebhook returns Webhook with passed id
+func (db DB) GetWebhook(ctx context.Context, id uint) (common.Webhook, error) {
+	var webhook Webhook
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkWebhook(tx, id) {
+			return common.ErrWebhookNotExist
+		}
+		return tx.Get(&webhook, `SELECT * FROM webhooks WHERE id = ?`, id)
+	})
+	if err != nil {
+		return common.Webhook{}, err
+	}
+
+	return webhook.ToCommon(), nil
+}
+
+// AddWebhook adds a new Webhook
+func (db DB) AddWebhook(ctx context.Context, args common.AddWebhookArgs) (id uint, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		return tx.Get(
+			&id,
+			`INSERT INTO webhooks(url, secret, events, enabled) VALUES(?, ?, ?, ?) RETURNING id`,
+			args.URL, args.Secret, webhookEvents(args.Events), args.Enabled,
+		)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// EditWebhook modifies existing Webhook
+func (db DB) EditWebhook(ctx context.Context, args common.EditWebhookArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkWebhook(tx, args.ID) {
+			return common.ErrWebhookNotExist
+		}
+
+		query := newUpdateQueryBuilder("webhooks", args.ID)
+		if args.URL != nil {
+			query.Set("url", *args.URL)
+		}
+		if args.Secret != nil {
+			query.Set("secret", *args.Secret)
+		}
+		if args.Events != nil {
+			query.Set("events", webhookEvents(*args.Events))
+		}
+		if args.Enabled != nil {
+			query.Set("enabled", *args.Enabled)
+		}
+		_, err := tx.ExecQuery(query)
+		return err
+	})
+}
+
+// RemoveWebhook removes Webhook with passed id and all its deliveries
+func (db DB) RemoveWebhook(ctx context.Context, id uint) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		if !checkWebhook(tx, id) {
+			return common.ErrWebhookNotExist
+		}
+
+		// SQLite doesn't enforce foreign keys by default, so deliveries are removed explicitly
+		_, err := tx.Exec(`DELETE FROM webhook_deliveries WHERE webhook_id = ?`, id)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove Webhook deliveries")
+		}
+		_, err = tx.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+		return err
+	})
+}
+
+// GetWebhookDeliveries returns the latest Webhook deliveries, the newest go first
+func (db DB) GetWebhookDeliveries(ctx context.Context,
+	args common.GetWebhookDeliveriesArgs) ([]common.WebhookDelivery, error) {
+
+	var deliveries []WebhookDelivery
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		if args.WebhookID != 0 && !checkWebhook(tx, args.WebhookID) {
+			return common.ErrWebhookNotExist
+		}
+
+		var (
+			where     = "1 = 1"
+			whereArgs []interface{}
+		)
+		if args.WebhookID != 0 {
+			where += " AND webhook_id = ?"
+			whereArgs = append(whereArgs, args.WebhookID)
+		}
+		if args.Status != "" {
+			where += " AND status = ?"
+			whereArgs = append(whereArgs, string(args.Status))
+		}
+		query := `SELECT * FROM webhook_deliveries WHERE ` + where + ` ORDER BY id DESC`
+		if args.Limit > 0 {
+			query += ` LIMIT ?`
+			whereArgs = append(whereArgs, args.Limit)
+		}
+
+		err := tx.Select(&deliveries, query, whereArgs...)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select Webhook deliveries")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.WebhookDelivery, 0, len(deliveries))
+	for i := range deliveries {
+		res = append(res, deliveries[i].ToCommon())
+	}
+	return res, nil
+}
+
+// GetDueWebhookDeliveries returns pending deliveries of enabled Webhooks which next attempt is not
+// after the passed time. The oldest deliveries go first
+func (db DB) GetDueWebhookDeliveries(ctx context.Context,
+	now time.Time, limit int) ([]common.DueWebhookDelivery, error) {
+
+	var deliveries []struct {
+		WebhookDelivery
+
+		URL    string `db:"url"`
+		Secret string `db:"secret"`
+	}
+	err := db.db.RunInReadOnlyTransaction(ctx, func(tx *sqlx.Tx) error {
+		err := tx.Select(
+			&deliveries, `
+			SELECT
+				webhook_deliveries.*,
+				webhooks.url AS url,
+				webhooks.secret AS secret
+			FROM webhook_deliveries
+			INNER JOIN webhooks ON webhooks.id = webhook_deliveries.webhook_id
+			WHERE webhooks.enabled AND webhook_deliveries.status = ? AND webhook_deliveries.next_attempt_at <= ?
+			ORDER BY webhook_deliveries.id
+			LIMIT ?`,
+			string(common.WebhookDeliveryPending), now.UTC(), limit,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't select due Webhook deliveries")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]common.DueWebhookDelivery, 0, len(deliveries))
+	for _, d := range deliveries {
+		res = append(res, common.DueWebhookDelivery{
+			WebhookDelivery: d.WebhookDelivery.ToCommon(),
+			URL:             d.URL,
+			Secret:          d.Secret,
+		})
+	}
+	return res, nil
+}
+
+// UpdateWebhookDelivery saves a result of a delivery attempt
+func (db DB) UpdateWebhookDelivery(ctx context.Context, args common.UpdateWebhookDeliveryArgs) error {
+	return db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(
+			`UPDATE webhook_deliveries
+			    SET status = ?, attempts = ?, response_code = ?, error = ?, next_attempt_at = ?
+			  WHERE id = ?`,
+			string(args.Status), args.Attempts, args.ResponseCode, args.Error, args.NextAttemptAt.UTC(), args.ID,
+		)
+		return err
+	})
+}
+
+// RemoveOldWebhookDeliveries removes succeeded and failed deliveries created before the passed time.
+// Pending deliveries are kept until they are sent or all attempts fail
+func (db DB) RemoveOldWebhookDeliveries(ctx context.Context, before time.Time) (removed int, err error) {
+	err = db.db.RunInTransaction(ctx, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(
+			`DELETE FROM webhook_deliveries WHERE status != ? AND created_at < ?`,
+			string(common.WebhookDeliveryPending), before.UTC(),
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't remove old Webhook deliveries")
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return errors.Wrap(err, "couldn't get number of removed Webhook deliveries")
+		}
+		removed = int(n)
+		return nil
+	})
+	return removed, err
+}
+
+func selectWebhooks(tx *sqlx.Tx, where string) (webhooks []Webhook, err error) {
+	err = tx.Select(&webhooks, `SELECT * FROM webhooks WHERE `+where+` ORDER BY id ASC`)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't select Webhooks")
+	}
+	return webhooks, nil
+}
+
+// addWebhookDeliveries adds deliveries of the event to all enabled Webhooks subscribed to it. It must
+// be called after the month is recomputed, so the payload contains the actual month totals
+func addWebhookDeliveries(tx *sqlx.Tx, event common.WebhookEvent, record interface{}, monthID uint) error {
+	webhooks, err := selectWebhooks(tx, "enabled")
+	if err != nil {
+		return err
+	}
+	var webhookIDs []uint
+	for _, w := range webhooks {
+		if w.Has(event) {
+			webhookIDs = append(webhookIDs, w.ID)
+		}
+	}
+	if len(webhookIDs) == 0 {
+		return nil
+	}
+
+	var month MonthOverview
+	err = tx.Get(
+		&month,
+		`SELECT id, year, month, daily_budget, total_income, total_spend, result FROM months WHERE id = ?`, monthID,
+	)
+	if err != nil {
+		return errors.Wrap(err, "couldn't select month for Webhook payload")
+	}
+
+	now := time.Now().UTC()
+	payload, err := json.Marshal(common.WebhookPayload{
+		Event:     event,
+		CreatedAt: now,
+		Data:      record,
+		Month:     month.ToCommon(),
+	})
+	if err != nil {
+		return errors.Wrap(err, "couldn't marshal Webhook payload")
+	}
+
+	for _, id := range webhookIDs {
+		_, err := tx.Exec(
+			`INSERT INTO webhook_deliveries(webhook_id, event, payload, status, created_at, next_attempt_at)
+			 VALUES(?, ?, ?, ?, ?, ?)`,
+			id, string(event), string(payload), string(common.WebhookDeliveryPending), now, now,
+		)
+		if err != nil {
+			return errors.Wrap(err, "couldn't insert Webhook delivery")
+		}
+	}
+	return nil
+}
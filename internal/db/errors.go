@@ -12,5 +12,18 @@ var (
 	ErrMonthlyPaymentNotExist = errors.New("such Monthly Payment doesn't exist")
 	ErrSpendNotExist          = errors.New("such Spend doesn't exist")
 	ErrSpendTypeNotExist      = errors.New("such Spend Type doesn't exist")
-	ErrSpendTypeIsUsed        = errors.New("Spend Type is used by Monthly Payment or Spend")
+	ErrSpendTypeIsUsed        = errors.New("Spend Type is used by Monthly Payment, Spend, Spend Rule or Saved Search")
+	ErrSpendRuleNotExist      = errors.New("such Spend Rule doesn't exist")
+	ErrSavedSearchNotExist    = errors.New("such Saved Search doesn't exist")
+	ErrWebhookNotExist        = errors.New("such Webhook doesn't exist")
+	ErrVersionConflict        = errors.New("record was changed by another request, version doesn't match")
+	ErrIdempotencyKeyNotExist = errors.New("such Idempotency Key doesn't exist")
+	ErrIdempotencyKeyExists   = errors.New("Idempotency Key already exists")
+	ErrAPITokenNotExist       = errors.New("such API Token doesn't exist")
+	ErrSessionNotExist        = errors.New("such Session doesn't exist")
+	ErrAuthLockoutNotExist    = errors.New("such Auth Lockout doesn't exist")
+	ErrAuthLockoutLocked      = errors.New("auth attempts are locked")
+	ErrTOTPNotExist           = errors.New("TOTP isn't set up")
+	ErrTOTPCodeAlreadyUsed    = errors.New("TOTP code has already been used")
+	ErrRecoveryCodeNotExist   = errors.New("such Recovery Code doesn't exist")
 )
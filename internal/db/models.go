@@ -54,6 +54,8 @@ type Income struct {
 	Title  string      `json:"title"`
 	Notes  string      `json:"notes,omitempty"`
 	Income money.Money `json:"income" swaggertype:"number"`
+	// Version is incremented on every edit. It is used to detect concurrent edits
+	Version int `json:"version"`
 }
 
 // MonthlyPayment contains information about monthly payments (rent, Patreon and etc.)
@@ -67,6 +69,10 @@ type MonthlyPayment struct {
 	Type  *SpendType  `json:"type,omitempty"`
 	Notes string      `json:"notes,omitempty"`
 	Cost  money.Money `json:"cost" swaggertype:"number"`
+	// DueDay is a day of month when the payment is due. If the month is shorter, the last day is used
+	DueDay uint `json:"due_day,omitempty"`
+	// Version is incremented on every edit. It is used to detect concurrent edits
+	Version int `json:"version"`
 }
 
 // Spend contains information about spends
@@ -81,6 +87,31 @@ type Spend struct {
 	Type  *SpendType  `json:"type,omitempty"`
 	Notes string      `json:"notes,omitempty"`
 	Cost  money.Money `json:"cost" swaggertype:"number"`
+	// Version is incremented on every edit. It is used to detect concurrent edits
+	Version int `json:"version"`
+}
+
+// SearchSpendsTotal contains aggregated info about all Spends that match search args
+type SearchSpendsTotal struct {
+	Count int         `json:"count"`
+	Cost  money.Money `json:"cost" swaggertype:"number"`
+}
+
+// SearchSpendsStats contains statistics of all Spends that match search args. The statistics are aggregated
+// by the db, so Spends don't have to be loaded
+type SearchSpendsStats struct {
+	// SpentByType contains costs of Spends by ids of their Spend Types. Id 0 is used for Spends without type
+	SpentByType map[uint]money.Money
+	// SpentByDay contains costs of Spends by their dates in UTC
+	SpentByDay map[time.Time]money.Money
+	// Costs contains numbers of Spends with every cost. It is sorted by cost
+	Costs []CostCount
+}
+
+// CostCount contains a number of Spends with the cost
+type CostCount struct {
+	Cost  money.Money
+	Count int
 }
 
 // SpendType contains information about spend type
@@ -88,4 +119,227 @@ type SpendType struct {
 	ID       uint   `json:"id"`
 	Name     string `json:"name"`
 	ParentID uint   `json:"parent_id"`
+	// Version is incremented on every edit. It is used to detect concurrent edits.
+	// It is omitted for Spend Types of Monthly Payments and Spends
+	Version int `json:"version,omitempty"`
+}
+
+// SpendRule contains information about a rule used to categorize Spends automatically
+type SpendRule struct {
+	ID uint `json:"id"`
+
+	// TitlePattern is a regular expression that is matched against Spend titles
+	TitlePattern string `json:"title_pattern"`
+	// TypeID is an id of Spend Type that is assigned to matched Spends
+	TypeID uint `json:"type_id"`
+	// Notes are appended to notes of matched Spends
+	Notes string `json:"notes,omitempty"`
+}
+
+// SavedSearch is a named Spend search
+type SavedSearch struct {
+	ID   uint   `json:"id"`
+	Name string `json:"name"`
+	// Pinned Saved Searches are shown on the months page
+	Pinned bool             `json:"pinned"`
+	Args   SearchSpendsArgs `json:"args"`
+}
+
+// SavedSearchReport contains totals of a pinned Saved Search for a month and for the previous one
+type SavedSearchReport struct {
+	SavedSearch SavedSearch `json:"saved_search"`
+
+	Year  int        `json:"year"`
+	Month time.Month `json:"month" swaggertype:"integer"`
+
+	Total     SearchSpendsTotal `json:"total"`
+	PrevTotal SearchSpendsTotal `json:"prev_total"`
+	// Change is a difference between costs of Total and PrevTotal
+	Change money.Money `json:"change" swaggertype:"number"`
+}
+
+// WebhookEvent is an event that triggers Webhooks
+type WebhookEvent string
+
+const (
+	WebhookEventIncomeCreated         WebhookEvent = "income.created"
+	WebhookEventIncomeUpdated         WebhookEvent = "income.updated"
+	WebhookEventIncomeDeleted         WebhookEvent = "income.deleted"
+	WebhookEventMonthlyPaymentCreated WebhookEvent = "monthly_payment.created"
+	WebhookEventMonthlyPaymentUpdated WebhookEvent = "monthly_payment.updated"
+	WebhookEventMonthlyPaymentDeleted WebhookEvent = "monthly_payment.deleted"
+	WebhookEventSpendCreated          WebhookEvent = "spend.created"
+	WebhookEventSpendUpdated          WebhookEvent = "spend.updated"
+	WebhookEventSpendDeleted          WebhookEvent = "spend.deleted"
+)
+
+// WebhookEvents returns all supported Webhook events
+func WebhookEvents() []WebhookEvent {
+	return []WebhookEvent{
+		WebhookEventIncomeCreated,
+		WebhookEventIncomeUpdated,
+		WebhookEventIncomeDeleted,
+		WebhookEventMonthlyPaymentCreated,
+		WebhookEventMonthlyPaymentUpdated,
+		WebhookEventMonthlyPaymentDeleted,
+		WebhookEventSpendCreated,
+		WebhookEventSpendUpdated,
+		WebhookEventSpendDeleted,
+	}
+}
+
+// Webhook is an outgoing webhook that is called on changes of Incomes, Monthly Payments and Spends
+type Webhook struct {
+	ID  uint   `json:"id"`
+	URL string `json:"url"`
+	// Secret is used to sign payloads. It is never returned
+	Secret  string         `json:"-"`
+	Events  []WebhookEvent `json:"events" swaggertype:"array,string"`
+	Enabled bool           `json:"enabled"`
+}
+
+// WebhookPayload is a body of a Webhook request
+type WebhookPayload struct {
+	Event     WebhookEvent `json:"event" swaggertype:"string"`
+	CreatedAt time.Time    `json:"created_at"`
+	// Data is a changed Income, Monthly Payment or Spend. For deletion events it is a removed record
+	Data interface{} `json:"data"`
+	// Month contains totals of the month of the record after the change
+	Month MonthOverview `json:"month"`
+}
+
+// WebhookDeliveryStatus is a status of Webhook delivery
+type WebhookDeliveryStatus string
+
+const (
+	// WebhookDeliveryPending means the delivery will be sent or retried
+	WebhookDeliveryPending WebhookDeliveryStatus = "pending"
+	// WebhookDeliverySucceeded means the receiver has responded with 2xx status code
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	// WebhookDeliveryFailed means all attempts have failed
+	WebhookDeliveryFailed WebhookDeliveryStatus = "failed"
+)
+
+// WebhookDelivery is a single event sent to a Webhook
+type WebhookDelivery struct {
+	ID        uint                  `json:"id"`
+	WebhookID uint                  `json:"webhook_id"`
+	Event     WebhookEvent          `json:"event" swaggertype:"string"`
+	Payload   string                `json:"payload"`
+	Status    WebhookDeliveryStatus `json:"status" swaggertype:"string"`
+	// Attempts is a number of sent requests
+	Attempts int `json:"attempts"`
+	// ResponseCode is a status code of the last response. It is 0 if no response was received
+	ResponseCode int `json:"response_code,omitempty"`
+	// Error describes why the last attempt has failed
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	// NextAttemptAt is a time of the next attempt of a pending delivery
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// MonthChange is published after Incomes, Monthly Payments or Spends of a month are changed
+type MonthChange struct {
+	// Event is the same event that is sent to Webhooks
+	Event WebhookEvent `json:"event" swaggertype:"string"`
+	// RequestID is an id of the request that has caused the change
+	RequestID string `json:"request_id,omitempty"`
+
+	Month MonthOverview `json:"month"`
+	// MonthlyPaymentsCost is a total cost of all Monthly Payments of the month
+	MonthlyPaymentsCost money.Money `json:"monthly_payments_cost" swaggertype:"number"`
+	Days                []DayChange `json:"days"`
+}
+
+// DayChange contains the recomputed Saldo of a day and a summary of its Spends
+type DayChange struct {
+	ID    uint        `json:"id"`
+	Day   int         `json:"day"`
+	Saldo money.Money `json:"saldo" swaggertype:"number"`
+	// SpendsCount is a number of Spends of the day
+	SpendsCount int `json:"spends_count"`
+	// SpendsCost is a total cost of Spends of the day
+	SpendsCost money.Money `json:"spends_cost" swaggertype:"number"`
+}
+
+// IdempotencyKey is a key passed in 'Idempotency-Key' header with the saved response of the request
+type IdempotencyKey struct {
+	Key string
+	// RequestHash is a hash of the request. It is used to detect reuse of the key for another request
+	RequestHash string
+	// StatusCode is 0 while the request is being processed
+	StatusCode   int
+	ContentType  string
+	ResponseBody string
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+}
+
+// APIToken is a named token that can be used instead of a password to access the API
+type APIToken struct {
+	ID uint `json:"id"`
+	// Username is a name of the user who has created the token
+	Username string `json:"-"`
+	Name     string `json:"name"`
+	// TokenHash is a SHA-256 hash of the token. The token itself is not stored
+	TokenHash string `json:"-"`
+	// ReadOnly defines whether the token can be used only for GET and HEAD requests
+	ReadOnly bool `json:"read_only"`
+	// Endpoints is a list of API paths the token can be used for. Nested paths are allowed too.
+	// Empty list means all API endpoints
+	Endpoints []string  `json:"endpoints"`
+	CreatedAt time.Time `json:"created_at"`
+	// LastUsedAt is a time of the last request with the token. It is null if the token hasn't been used
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// Session is a login session of the user. The session token is passed in a cookie
+type Session struct {
+	ID       uint
+	Username string
+	// TokenHash is a SHA-256 hash of the session token. The token itself is not stored
+	TokenHash string
+	CreatedAt time.Time
+	ExpiresAt time.Time
+	// TOTPPending defines whether the user has entered the password, but hasn't passed two-factor authentication
+	TOTPPending bool
+}
+
+// AuthLockoutKind defines what is locked after failed auth attempts
+type AuthLockoutKind string
+
+const (
+	AuthLockoutIP       AuthLockoutKind = "ip"
+	AuthLockoutUsername AuthLockoutKind = "username"
+)
+
+// AuthLockout contains failed auth attempts from an ip or with a username
+type AuthLockout struct {
+	ID    uint            `json:"id"`
+	Kind  AuthLockoutKind `json:"kind" swaggertype:"string" enums:"ip,username"`
+	Value string          `json:"value"`
+	// Failures is a number of failed attempts in a row
+	Failures     int       `json:"failures"`
+	LastFailedAt time.Time `json:"last_failed_at"`
+	// LockedUntil is null if auth attempts have never been locked
+	LockedUntil *time.Time `json:"locked_until"`
+}
+
+// IsLocked checks whether auth attempts are locked at the passed time
+func (l AuthLockout) IsLocked(now time.Time) bool {
+	return l.LockedUntil != nil && l.LockedUntil.After(now)
+}
+
+// TOTP contains a secret of Time-Based One-Time Passwords used for two-factor authentication
+type TOTP struct {
+	ID       uint
+	Username string
+	Secret   string
+	// Enabled is false until the user confirms the secret with a valid code
+	Enabled bool
+	// LastUsedStep is a time step of the last used code. Codes can't be reused
+	LastUsedStep int64
+	CreatedAt    time.Time
+	// RecoveryCodes is a number of unused recovery codes
+	RecoveryCodes int
 }
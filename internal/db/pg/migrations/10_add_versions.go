@@ -0,0 +1,13 @@
+package migrations
+
+import "database/sql"
+
+func addVersionsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		ALTER TABLE incomes ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+		ALTER TABLE monthly_payments ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+		ALTER TABLE spends ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;
+		ALTER TABLE spend_types ADD COLUMN IF NOT EXISTS version bigint NOT NULL DEFAULT 1;`,
+	)
+	return err
+}
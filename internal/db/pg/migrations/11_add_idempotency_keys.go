@@ -0,0 +1,22 @@
+package migrations
+
+import "database/sql"
+
+func addIdempotencyKeysMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			id bigserial PRIMARY KEY,
+
+			key           text        NOT NULL UNIQUE,
+			request_hash  text        NOT NULL,
+			status_code   integer     NOT NULL DEFAULT 0,
+			content_type  text        NOT NULL DEFAULT '',
+			response_body text        NOT NULL DEFAULT '',
+			created_at    timestamptz NOT NULL,
+			expires_at    timestamptz NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);`,
+	)
+	return err
+}
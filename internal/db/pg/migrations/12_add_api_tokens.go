@@ -0,0 +1,22 @@
+package migrations
+
+import "database/sql"
+
+func addAPITokensMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS api_tokens (
+			id bigserial PRIMARY KEY,
+
+			username     text        NOT NULL,
+			name         text        NOT NULL,
+			token_hash   text        NOT NULL UNIQUE,
+			read_only    boolean     NOT NULL DEFAULT false,
+			endpoints    text        NOT NULL,
+			created_at   timestamptz NOT NULL,
+			last_used_at timestamptz
+		);
+
+		CREATE INDEX IF NOT EXISTS api_tokens_username_idx ON api_tokens (username);`,
+	)
+	return err
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+func addSessionsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id bigserial PRIMARY KEY,
+
+			username   text        NOT NULL,
+			token_hash text        NOT NULL UNIQUE,
+			created_at timestamptz NOT NULL,
+			expires_at timestamptz NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);`,
+	)
+	return err
+}
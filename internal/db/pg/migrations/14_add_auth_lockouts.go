@@ -0,0 +1,20 @@
+package migrations
+
+import "database/sql"
+
+func addAuthLockoutsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_lockouts (
+			id bigserial PRIMARY KEY,
+
+			kind           text        NOT NULL,
+			value          text        NOT NULL,
+			failures       integer     NOT NULL,
+			last_failed_at timestamptz NOT NULL,
+			locked_until   timestamptz,
+
+			UNIQUE (kind, value)
+		);`,
+	)
+	return err
+}
@@ -0,0 +1,29 @@
+package migrations
+
+import "database/sql"
+
+func addTOTPMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS totp (
+			id bigserial PRIMARY KEY,
+
+			username       text        NOT NULL UNIQUE,
+			secret         text        NOT NULL,
+			enabled        boolean     NOT NULL DEFAULT false,
+			last_used_step bigint      NOT NULL DEFAULT 0,
+			created_at     timestamptz NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS recovery_codes (
+			id bigserial PRIMARY KEY,
+
+			username  text NOT NULL,
+			code_hash text NOT NULL UNIQUE
+		);
+
+		CREATE INDEX IF NOT EXISTS recovery_codes_username_idx ON recovery_codes (username);
+
+		ALTER TABLE sessions ADD COLUMN totp_pending boolean NOT NULL DEFAULT false;`,
+	)
+	return err
+}
@@ -0,0 +1,16 @@
+package migrations
+
+import "database/sql"
+
+func addSpendRulesMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS spend_rules (
+			id bigserial PRIMARY KEY,
+
+			title_pattern text   NOT NULL,
+			type_id       bigint NOT NULL REFERENCES spend_types(id),
+			notes         text
+		);`,
+	)
+	return err
+}
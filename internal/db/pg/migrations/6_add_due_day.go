@@ -0,0 +1,8 @@
+package migrations
+
+import "database/sql"
+
+func addDueDayToMonthlyPaymentsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE monthly_payments ADD COLUMN IF NOT EXISTS due_day bigint;`)
+	return err
+}
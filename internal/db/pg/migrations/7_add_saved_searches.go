@@ -0,0 +1,16 @@
+package migrations
+
+import "database/sql"
+
+func addSavedSearchesMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_searches (
+			id bigserial PRIMARY KEY,
+
+			name   text    NOT NULL,
+			pinned boolean NOT NULL DEFAULT false,
+			args   text    NOT NULL
+		);`,
+	)
+	return err
+}
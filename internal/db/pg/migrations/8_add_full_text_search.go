@@ -0,0 +1,17 @@
+package migrations
+
+import "database/sql"
+
+// addFullTextSearchMigration creates GIN index for the full-text search over titles and notes of Spends.
+// The expression must be the same as the one used in queries. It combines configurations 'english'
+// (stems of English words) and 'simple' (words in other languages)
+func addFullTextSearchMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE INDEX IF NOT EXISTS spends_full_text_search_idx
+		    ON spends USING GIN ((
+		        to_tsvector('english', title || ' ' || COALESCE(notes, '')) ||
+		        to_tsvector('simple', title || ' ' || COALESCE(notes, ''))
+		    ));`,
+	)
+	return err
+}
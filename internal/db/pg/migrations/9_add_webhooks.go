@@ -0,0 +1,33 @@
+package migrations
+
+import "database/sql"
+
+func addWebhooksMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id bigserial PRIMARY KEY,
+
+			url     text    NOT NULL,
+			secret  text    NOT NULL,
+			events  text    NOT NULL,
+			enabled boolean NOT NULL DEFAULT true
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id bigserial PRIMARY KEY,
+
+			webhook_id      bigint      NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+			event           text        NOT NULL,
+			payload         text        NOT NULL,
+			status          text        NOT NULL,
+			attempts        integer     NOT NULL DEFAULT 0,
+			response_code   integer     NOT NULL DEFAULT 0,
+			error           text        NOT NULL DEFAULT '',
+			created_at      timestamptz NOT NULL,
+			next_attempt_at timestamptz NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_status_idx ON webhook_deliveries (status, next_attempt_at);`,
+	)
+	return err
+}
@@ -28,7 +28,7 @@ type DB struct {
 }
 
 func NewDB(config Config, log logger.Logger) (*DB, error) {
-	db, err := base.NewDB("postgres", config.toURL(), base.Dollar, migrations.GetMigrations(), log)
+	db, err := base.NewDB("postgres", config.toURL(), "", base.Dollar, migrations.GetMigrations(), log)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,18 @@
+package migrations
+
+import "database/sql"
+
+func addSessionsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS sessions (
+			id         INTEGER  PRIMARY KEY,
+			username   TEXT     NOT NULL,
+			token_hash TEXT     NOT NULL UNIQUE,
+			created_at DATETIME NOT NULL,
+			expires_at DATETIME NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS sessions_expires_at_idx ON sessions (expires_at);`,
+	)
+	return err
+}
@@ -0,0 +1,19 @@
+package migrations
+
+import "database/sql"
+
+func addAuthLockoutsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS auth_lockouts (
+			id             INTEGER  PRIMARY KEY,
+			kind           TEXT     NOT NULL,
+			value          TEXT     NOT NULL,
+			failures       INTEGER  NOT NULL,
+			last_failed_at DATETIME NOT NULL,
+			locked_until   DATETIME,
+
+			UNIQUE (kind, value)
+		);`,
+	)
+	return err
+}
@@ -0,0 +1,27 @@
+package migrations
+
+import "database/sql"
+
+func addTOTPMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS totp (
+			id             INTEGER  PRIMARY KEY,
+			username       TEXT     NOT NULL UNIQUE,
+			secret         TEXT     NOT NULL,
+			enabled        BOOLEAN  NOT NULL DEFAULT false,
+			last_used_step INTEGER  NOT NULL DEFAULT 0,
+			created_at     DATETIME NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS recovery_codes (
+			id        INTEGER PRIMARY KEY,
+			username  TEXT    NOT NULL,
+			code_hash TEXT    NOT NULL UNIQUE
+		);
+
+		CREATE INDEX IF NOT EXISTS recovery_codes_username_idx ON recovery_codes (username);
+
+		ALTER TABLE sessions ADD COLUMN totp_pending BOOLEAN NOT NULL DEFAULT false;`,
+	)
+	return err
+}
@@ -0,0 +1,17 @@
+package migrations
+
+import "database/sql"
+
+func addSpendRulesMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS spend_rules (
+			id            INTEGER PRIMARY KEY,
+			title_pattern TEXT NOT NULL,
+			type_id       INTEGER NOT NULL,
+			notes         TEXT,
+
+			FOREIGN KEY (type_id) REFERENCES spend_types(id)
+		);`,
+	)
+	return err
+}
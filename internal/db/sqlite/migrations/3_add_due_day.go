@@ -0,0 +1,8 @@
+package migrations
+
+import "database/sql"
+
+func addDueDayToMonthlyPaymentsMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`ALTER TABLE monthly_payments ADD COLUMN due_day INTEGER;`)
+	return err
+}
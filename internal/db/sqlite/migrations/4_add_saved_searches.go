@@ -0,0 +1,15 @@
+package migrations
+
+import "database/sql"
+
+func addSavedSearchesMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS saved_searches (
+			id     INTEGER PRIMARY KEY,
+			name   TEXT    NOT NULL,
+			pinned BOOLEAN NOT NULL DEFAULT false,
+			args   TEXT    NOT NULL
+		);`,
+	)
+	return err
+}
@@ -0,0 +1,40 @@
+package migrations
+
+import "database/sql"
+
+// addFullTextSearchMigration creates FTS5 table for the full-text search over titles and notes of Spends.
+// The table is kept in sync with triggers. FTS5 is available only if SQLite is built with tag 'sqlite_fts5'.
+// Otherwise, the migration is skipped and the search falls back to LIKE
+func addFullTextSearchMigration(tx *sql.Tx) error {
+	var fts5Enabled bool
+	if err := tx.QueryRow(`SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5Enabled); err != nil {
+		return err
+	}
+	if !fts5Enabled {
+		return nil
+	}
+
+	_, err := tx.Exec(`
+		CREATE VIRTUAL TABLE IF NOT EXISTS spends_fts USING fts5(
+			title, notes,
+			content='spends', content_rowid='id',
+			tokenize='porter unicode61 remove_diacritics 2'
+		);
+
+		CREATE TRIGGER IF NOT EXISTS spends_fts_insert AFTER INSERT ON spends BEGIN
+			INSERT INTO spends_fts(rowid, title, notes) VALUES (new.id, new.title, new.notes);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS spends_fts_delete AFTER DELETE ON spends BEGIN
+			INSERT INTO spends_fts(spends_fts, rowid, title, notes) VALUES ('delete', old.id, old.title, old.notes);
+		END;
+
+		CREATE TRIGGER IF NOT EXISTS spends_fts_update AFTER UPDATE OF title, notes ON spends BEGIN
+			INSERT INTO spends_fts(spends_fts, rowid, title, notes) VALUES ('delete', old.id, old.title, old.notes);
+			INSERT INTO spends_fts(rowid, title, notes) VALUES (new.id, new.title, new.notes);
+		END;
+
+		INSERT INTO spends_fts(spends_fts) VALUES ('rebuild');`,
+	)
+	return err
+}
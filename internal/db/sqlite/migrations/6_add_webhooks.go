@@ -0,0 +1,33 @@
+package migrations
+
+import "database/sql"
+
+func addWebhooksMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS webhooks (
+			id      INTEGER PRIMARY KEY,
+			url     TEXT    NOT NULL,
+			secret  TEXT    NOT NULL,
+			events  TEXT    NOT NULL,
+			enabled BOOLEAN NOT NULL DEFAULT true
+		);
+
+		CREATE TABLE IF NOT EXISTS webhook_deliveries (
+			id              INTEGER  PRIMARY KEY,
+			webhook_id      INTEGER  NOT NULL,
+			event           TEXT     NOT NULL,
+			payload         TEXT     NOT NULL,
+			status          TEXT     NOT NULL,
+			attempts        INTEGER  NOT NULL DEFAULT 0,
+			response_code   INTEGER  NOT NULL DEFAULT 0,
+			error           TEXT     NOT NULL DEFAULT '',
+			created_at      DATETIME NOT NULL,
+			next_attempt_at DATETIME NOT NULL,
+
+			FOREIGN KEY (webhook_id) REFERENCES webhooks(id) ON DELETE CASCADE
+		);
+
+		CREATE INDEX IF NOT EXISTS webhook_deliveries_status_idx ON webhook_deliveries (status, next_attempt_at);`,
+	)
+	return err
+}
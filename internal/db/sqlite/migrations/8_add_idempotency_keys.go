@@ -0,0 +1,21 @@
+package migrations
+
+import "database/sql"
+
+func addIdempotencyKeysMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			id            INTEGER  PRIMARY KEY,
+			key           TEXT     NOT NULL UNIQUE,
+			request_hash  TEXT     NOT NULL,
+			status_code   INTEGER  NOT NULL DEFAULT 0,
+			content_type  TEXT     NOT NULL DEFAULT '',
+			response_body TEXT     NOT NULL DEFAULT '',
+			created_at    DATETIME NOT NULL,
+			expires_at    DATETIME NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at);`,
+	)
+	return err
+}
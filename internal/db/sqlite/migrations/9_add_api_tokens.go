@@ -0,0 +1,21 @@
+package migrations
+
+import "database/sql"
+
+func addAPITokensMigration(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS api_tokens (
+			id           INTEGER  PRIMARY KEY,
+			username     TEXT     NOT NULL,
+			name         TEXT     NOT NULL,
+			token_hash   TEXT     NOT NULL UNIQUE,
+			read_only    BOOLEAN  NOT NULL DEFAULT false,
+			endpoints    TEXT     NOT NULL,
+			created_at   DATETIME NOT NULL,
+			last_used_at DATETIME
+		);
+
+		CREATE INDEX IF NOT EXISTS api_tokens_username_idx ON api_tokens (username);`,
+	)
+	return err
+}
@@ -8,5 +8,49 @@ func GetMigrations() []*migrator.Migration {
 			Name: "init",
 			Func: initMigration,
 		},
+		{
+			Name: "add spend rules",
+			Func: addSpendRulesMigration,
+		},
+		{
+			Name: "add due day to monthly payments",
+			Func: addDueDayToMonthlyPaymentsMigration,
+		},
+		{
+			Name: "add saved searches",
+			Func: addSavedSearchesMigration,
+		},
+		{
+			Name: "add full-text search",
+			Func: addFullTextSearchMigration,
+		},
+		{
+			Name: "add webhooks",
+			Func: addWebhooksMigration,
+		},
+		{
+			Name: "add versions of records",
+			Func: addVersionsMigration,
+		},
+		{
+			Name: "add idempotency keys",
+			Func: addIdempotencyKeysMigration,
+		},
+		{
+			Name: "add api tokens",
+			Func: addAPITokensMigration,
+		},
+		{
+			Name: "add sessions",
+			Func: addSessionsMigration,
+		},
+		{
+			Name: "add auth lockouts",
+			Func: addAuthLockoutsMigration,
+		},
+		{
+			Name: "add totp",
+			Func: addTOTPMigration,
+		},
 	}
 }
@@ -2,11 +2,14 @@ package sqlite
 
 //nolint:gci
 import (
+	"strings"
+
 	_ "github.com/mattn/go-sqlite3" // register SQLite driver
 
 	"github.com/ShoshinNikita/budget-manager/internal/db/base"
 	"github.com/ShoshinNikita/budget-manager/internal/db/sqlite/migrations"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 )
 
 type DB struct {
@@ -18,9 +21,29 @@ type Config struct {
 }
 
 func NewDB(config Config, log logger.Logger) (*DB, error) {
-	db, err := base.NewDB("sqlite3", config.Path, base.Question, migrations.GetMigrations(), log)
+	if config.Path == "" {
+		return nil, errors.New("path of SQLite db can't be empty")
+	}
+
+	// Writing transactions must acquire the write lock at the beginning. Otherwise, SQLite returns
+	// "database is locked" error without waiting when a transaction tries to write while another one
+	// (for example, the webhook sender) is holding the lock. Read-only transactions use a separate pool
+	// with deferred transactions, so they are not blocked by each other and by writing transactions
+	dataSourceName := addParams(config.Path, "_txlock=immediate")
+	readDataSourceName := addParams(config.Path, "_txlock=deferred&_query_only=true")
+
+	db, err := base.NewDB(
+		"sqlite3", dataSourceName, readDataSourceName, base.Question, migrations.GetMigrations(), log,
+	)
 	if err != nil {
 		return nil, err
 	}
 	return &DB{db}, nil
 }
+
+func addParams(path string, params string) string {
+	if strings.Contains(path, "?") {
+		return path + "&" + params
+	}
+	return path + "?" + params
+}
@@ -0,0 +1,198 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+)
+
+func newTestDB(t *testing.T) (db *DB, path string) {
+	path = filepath.Join(t.TempDir(), "db.sqlite")
+
+	db, err := NewDB(Config{Path: path}, logger.New(logger.Config{Level: "fatal"}))
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		require.NoError(t, db.Shutdown())
+	})
+	return db, path
+}
+
+// TestConcurrentWrites checks that concurrent transactions that read and then write don't fail
+// with "database is locked" error
+func TestConcurrentWrites(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	sqliteDB, _ := newTestDB(t)
+	ctx := context.Background()
+
+	id, err := sqliteDB.AddSpendType(ctx, db.AddSpendTypeArgs{Name: "type"})
+	require.NoError(err)
+
+	const edits = 50
+
+	errs := make(chan error, edits)
+	var wg sync.WaitGroup
+	for i := 0; i < edits; i++ {
+		i := i
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			// The edit checks whether the Spend Type exists and then updates it
+			name := fmt.Sprintf("type %d", i)
+			_, err := sqliteDB.EditSpendType(ctx, db.EditSpendTypeArgs{ID: id, Name: &name})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(err)
+	}
+
+	spendType, err := sqliteDB.GetSpendType(ctx, id)
+	require.NoError(err)
+	require.Equal(edits+1, spendType.Version)
+}
+
+// TestReadDuringWrite checks that reads are not blocked by a transaction that holds the write lock
+func TestReadDuringWrite(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	sqliteDB, path := newTestDB(t)
+	ctx := context.Background()
+
+	id, err := sqliteDB.AddSpendType(ctx, db.AddSpendTypeArgs{Name: "type"})
+	require.NoError(err)
+
+	// Acquire the write lock with another connection
+	conn, err := sql.Open("sqlite3", path+"?_txlock=immediate")
+	require.NoError(err)
+	defer conn.Close()
+
+	tx, err := conn.BeginTx(ctx, nil)
+	require.NoError(err)
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`UPDATE spend_types SET name = 'new name' WHERE id = ?`, id)
+	require.NoError(err)
+
+	// The uncommitted changes are not visible
+	spendType, err := sqliteDB.GetSpendType(ctx, id)
+	require.NoError(err)
+	require.Equal("type", spendType.Name)
+}
+
+func TestRemoveOldWebhookDeliveries(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	sqliteDB, path := newTestDB(t)
+	ctx := context.Background()
+
+	webhookID, err := sqliteDB.AddWebhook(ctx, db.AddWebhookArgs{
+		URL:     "http://localhost",
+		Events:  []db.WebhookEvent{db.WebhookEventSpendCreated},
+		Enabled: true,
+	})
+	require.NoError(err)
+
+	conn, err := sql.Open("sqlite3", path)
+	require.NoError(err)
+	defer conn.Close()
+
+	now := time.Now().UTC()
+	old := now.Add(-48 * time.Hour)
+	for _, d := range []struct {
+		status    db.WebhookDeliveryStatus
+		createdAt time.Time
+	}{
+		{status: db.WebhookDeliverySucceeded, createdAt: old},
+		{status: db.WebhookDeliveryFailed, createdAt: old},
+		{status: db.WebhookDeliveryPending, createdAt: old},
+		{status: db.WebhookDeliverySucceeded, createdAt: now},
+		{status: db.WebhookDeliveryFailed, createdAt: now},
+	} {
+		_, err := conn.Exec(
+			`INSERT INTO webhook_deliveries(webhook_id, event, payload, status, created_at, next_attempt_at)
+			 VALUES(?, ?, '{}', ?, ?, ?)`,
+			webhookID, string(db.WebhookEventSpendCreated), string(d.status), d.createdAt, d.createdAt,
+		)
+		require.NoError(err)
+	}
+
+	removed, err := sqliteDB.RemoveOldWebhookDeliveries(ctx, now.Add(-24*time.Hour))
+	require.NoError(err)
+	require.Equal(2, removed)
+
+	deliveries, err := sqliteDB.GetWebhookDeliveries(ctx, db.GetWebhookDeliveriesArgs{})
+	require.NoError(err)
+	// Only the old pending delivery and the new ones are left
+	require.Len(deliveries, 3)
+	require.Equal(uint(5), deliveries[0].ID)
+	require.Equal(uint(4), deliveries[1].ID)
+	require.Equal(uint(3), deliveries[2].ID)
+}
+
+func TestAddAuthFailure(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	sqliteDB, _ := newTestDB(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	until := now.Add(time.Hour)
+	args := db.AddAuthFailureArgs{
+		Kind:              db.AuthLockoutIP,
+		Value:             "127.0.0.1",
+		FailedAt:          now,
+		RemoveStaleBefore: now.Add(-time.Hour),
+		LockedUntil: func(failures int) *time.Time {
+			if failures < 2 {
+				return nil
+			}
+			return &until
+		},
+	}
+
+	lockout, err := sqliteDB.AddAuthFailure(ctx, args)
+	require.NoError(err)
+	require.Equal(1, lockout.Failures)
+	require.Nil(lockout.LockedUntil)
+
+	// The lock is saved together with the failure
+	lockout, err = sqliteDB.AddAuthFailure(ctx, args)
+	require.NoError(err)
+	require.Equal(2, lockout.Failures)
+	require.True(lockout.IsLocked(now))
+
+	// Failures are not added during the lockout
+	lockout, err = sqliteDB.AddAuthFailure(ctx, args)
+	require.ErrorIs(err, db.ErrAuthLockoutLocked)
+	require.Equal(2, lockout.Failures)
+	require.True(lockout.IsLocked(now))
+
+	// Failures are added again after the lockout
+	args.FailedAt = until
+	lockout, err = sqliteDB.AddAuthFailure(ctx, args)
+	require.NoError(err)
+	require.Equal(3, lockout.Failures)
+}
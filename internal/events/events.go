@@ -0,0 +1,99 @@
+// Package events delivers month changes to subscribers in the same process
+package events
+
+import (
+	"sync"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+// subscriberBufferSize is a number of changes that can wait for a slow subscriber.
+// Next changes are dropped for this subscriber
+const subscriberBufferSize = 16
+
+// Bus is an in-process publisher of month changes
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[uint]map[chan db.MonthChange]struct{}
+	closed      bool
+}
+
+// NewBus returns a new Bus
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[uint]map[chan db.MonthChange]struct{}),
+	}
+}
+
+// Subscribe returns a channel with changes of the month with passed id and a function to unsubscribe.
+// The channel is closed after the unsubscription or after the Bus is closed
+func (b *Bus) Subscribe(monthID uint) (changes <-chan db.MonthChange, unsubscribe func()) {
+	ch := make(chan db.MonthChange, subscriberBufferSize)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[monthID] == nil {
+		b.subscribers[monthID] = make(map[chan db.MonthChange]struct{})
+	}
+	b.subscribers[monthID][ch] = struct{}{}
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			b.mu.Lock()
+			defer b.mu.Unlock()
+
+			if _, ok := b.subscribers[monthID][ch]; !ok {
+				// The Bus is already closed
+				return
+			}
+			delete(b.subscribers[monthID], ch)
+			if len(b.subscribers[monthID]) == 0 {
+				delete(b.subscribers, monthID)
+			}
+			close(ch)
+		})
+	}
+}
+
+// HasSubscribers reports whether anybody is subscribed to changes of the month with passed id
+func (b *Bus) HasSubscribers(monthID uint) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subscribers[monthID]) > 0
+}
+
+// Publish sends the change to all subscribers of the month. It doesn't block: the change
+// is dropped for subscribers that don't keep up
+func (b *Bus) Publish(change db.MonthChange) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for ch := range b.subscribers[change.Month.ID] {
+		select {
+		case ch <- change:
+		default:
+		}
+	}
+}
+
+// Close closes channels of all subscribers. Next subscriptions get closed channels
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for monthID, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(b.subscribers, monthID)
+	}
+}
@@ -0,0 +1,84 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+func newChange(monthID uint, event db.WebhookEvent) db.MonthChange {
+	return db.MonthChange{Event: event, Month: db.MonthOverview{ID: monthID}}
+}
+
+func TestBus(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	bus := NewBus()
+	require.False(bus.HasSubscribers(1))
+
+	first, unsubscribeFirst := bus.Subscribe(1)
+	second, unsubscribeSecond := bus.Subscribe(1)
+	other, unsubscribeOther := bus.Subscribe(2)
+	defer unsubscribeOther()
+
+	require.True(bus.HasSubscribers(1))
+	require.True(bus.HasSubscribers(2))
+	require.False(bus.HasSubscribers(3))
+
+	bus.Publish(newChange(1, db.WebhookEventSpendCreated))
+
+	require.Equal(newChange(1, db.WebhookEventSpendCreated), <-first)
+	require.Equal(newChange(1, db.WebhookEventSpendCreated), <-second)
+	require.Empty(other)
+
+	unsubscribeFirst()
+	unsubscribeFirst() // must not panic
+	_, ok := <-first
+	require.False(ok)
+	require.True(bus.HasSubscribers(1))
+
+	unsubscribeSecond()
+	require.False(bus.HasSubscribers(1))
+
+	// Publishing without subscribers must not block
+	bus.Publish(newChange(1, db.WebhookEventSpendDeleted))
+}
+
+func TestBus_SlowSubscriber(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	bus := NewBus()
+	changes, unsubscribe := bus.Subscribe(1)
+	defer unsubscribe()
+
+	for i := 0; i < subscriberBufferSize*2; i++ {
+		bus.Publish(newChange(1, db.WebhookEventIncomeCreated))
+	}
+	require.Len(changes, subscriberBufferSize)
+}
+
+func TestBus_Close(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	bus := NewBus()
+	changes, unsubscribe := bus.Subscribe(1)
+
+	bus.Close()
+	_, ok := <-changes
+	require.False(ok)
+	require.False(bus.HasSubscribers(1))
+
+	unsubscribe() // must not panic
+
+	changes, _ = bus.Subscribe(1)
+	_, ok = <-changes
+	require.False(ok)
+}
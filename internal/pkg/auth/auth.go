@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// User is an authenticated user
+type User struct {
+	Username string
+	// APITokenID is an id of the API Token used to authenticate the request. It is 0 for other auth methods
+	APITokenID uint
+	// SessionID is an id of the session used to authenticate the request. It is 0 for other auth methods
+	SessionID uint
+	// TOTPPending defines whether the user has entered the password, but hasn't passed two-factor
+	// authentication yet. Such users can access only the verification page
+	TOTPPending bool
+}
+
+type userContextKey struct{}
+
+// FromContext extracts the authenticated user from context. It returns false if auth is disabled
+func FromContext(ctx context.Context) (User, bool) {
+	user, ok := ctx.Value(userContextKey{}).(User)
+	return user, ok
+}
+
+// ToContext returns a context based on passed one with injected user
+func ToContext(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, userContextKey{}, user)
+}
+
+type Credentials interface {
+	Get(username string) (secret string, ok bool)
+	// RequiresTOTP checks whether two-factor authentication is mandatory for the user
+	RequiresTOTP(username string) bool
+}
+
+// dummyPasswordHash is a hash of a random password. It is compared with passwords of unknown users,
+// so the response time doesn't reveal whether the user exists. It has the same cost as hashes generated
+// by 'htpasswd -nB'
+const dummyPasswordHash = "$2a$05$mIkIJPLzv9PV2jHATE//Nu2hC3f0WUlZzLID5lv4CcKqLDyCv8aCu"
+
+// CheckPassword checks the password of the user. Passwords in credentials must be hashed using BCrypt
+func CheckPassword(creds Credentials, username, password string) bool {
+	hashedPassword, ok := creds.Get(username)
+	if !ok {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return false
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
+}
+
+const (
+	apiTokenPrefix = "bm_"
+	tokenLength    = 32
+)
+
+// NewAPIToken generates a new random API Token. The prefix helps to find leaked tokens
+func NewAPIToken() (string, error) {
+	token, err := newToken()
+	if err != nil {
+		return "", err
+	}
+	return apiTokenPrefix + token, nil
+}
+
+// SessionCookieName is a name of the cookie with the session token
+const SessionCookieName = "session"
+
+// NewSessionToken generates a new random session token
+func NewSessionToken() (string, error) {
+	return newToken()
+}
+
+func newToken() (string, error) {
+	data := make([]byte, tokenLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// HashToken returns a hash of API Token or session token that is stored in the db. Tokens are random
+// and long enough, so there is no need for a slow hash function like BCrypt
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
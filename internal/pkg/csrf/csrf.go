@@ -0,0 +1,64 @@
+// Package csrf provides tokens that protect state-changing requests of browsers from Cross-Site Request Forgery
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+)
+
+const (
+	// CookieName is a name of the cookie with a random token. It is used by clients without a login session
+	CookieName = "csrf"
+	// HeaderName is a name of the header that must contain the token. It is used by JavaScript
+	HeaderName = "X-CSRF-Token"
+	// FormFieldName is a name of the form field that must contain the token. It is used by HTML forms
+	FormFieldName = "csrf_token"
+
+	tokenLength = 32
+)
+
+// NewToken generates a new random token
+func NewToken() (string, error) {
+	data := make([]byte, tokenLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// SessionToken returns the token of the login session. It is derived from the session token, so it doesn't
+// have to be stored and changes with every login
+func SessionToken(sessionToken string) string {
+	hash := sha256.Sum256([]byte("csrf:" + sessionToken))
+	return hex.EncodeToString(hash[:])
+}
+
+// IsValidToken checks whether the passed string can be a token
+func IsValidToken(token string) bool {
+	if len(token) != tokenLength*2 {
+		return false
+	}
+	_, err := hex.DecodeString(token)
+	return err == nil
+}
+
+// Equal compares tokens in constant time
+func Equal(a, b string) bool {
+	return a != "" && subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+type tokenContextKey struct{}
+
+// FromContext extracts the token of the request from context. It returns an empty string if there is no token
+func FromContext(ctx context.Context) string {
+	token, _ := ctx.Value(tokenContextKey{}).(string)
+	return token
+}
+
+// ToContext returns a context based on passed one with injected token
+func ToContext(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, tokenContextKey{}, token)
+}
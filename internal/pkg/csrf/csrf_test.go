@@ -0,0 +1,34 @@
+package csrf
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokens(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	token, err := NewToken()
+	require.NoError(err)
+	require.True(IsValidToken(token))
+	require.True(Equal(token, token))
+
+	sessionToken := SessionToken("session token")
+	require.True(IsValidToken(sessionToken))
+	require.Equal(sessionToken, SessionToken("session token"))
+	require.NotEqual(sessionToken, SessionToken("another session token"))
+	require.False(Equal(token, sessionToken))
+
+	for _, token := range []string{"", "abc", token[1:] + "x", token + "0"} {
+		require.False(IsValidToken(token), token)
+	}
+	require.False(Equal("", ""))
+
+	ctx := ToContext(context.Background(), token)
+	require.Equal(token, FromContext(ctx))
+	require.Empty(FromContext(context.Background()))
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"reflect"
 	"strconv"
+	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 )
@@ -18,6 +19,14 @@ func Load(key string, target interface{}) error {
 	if u, ok := target.(encoding.TextUnmarshaler); ok {
 		return u.UnmarshalText([]byte(envValue))
 	}
+	if d, ok := target.(*time.Duration); ok {
+		v, err := time.ParseDuration(envValue)
+		if err != nil {
+			return errors.Wrapf(err, "value for %q is invalid", key)
+		}
+		*d = v
+		return nil
+	}
 
 	value := reflect.ValueOf(target)
 	if value.Kind() != reflect.Ptr {
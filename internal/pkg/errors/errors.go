@@ -34,3 +34,7 @@ func Wrapf(err error, format string, args ...interface{}) error {
 func Is(err, target error) bool {
 	return errors.Is(err, target)
 }
+
+func As(err error, target interface{}) bool {
+	return errors.As(err, target)
+}
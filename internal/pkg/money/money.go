@@ -79,6 +79,11 @@ func (m Money) Sub(sub Money) Money {
 	return m - sub
 }
 
+// Mul multiplies Money by n
+func (m Money) Mul(n int64) Money {
+	return Money(int64(m) * n)
+}
+
 // Div divides Money by n (if n <= 0, it panics)
 func (m Money) Div(n int64) Money {
 	if n <= 0 {
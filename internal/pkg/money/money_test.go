@@ -166,6 +166,28 @@ func TestSub(t *testing.T) {
 	}
 }
 
+func TestMultiply(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	tests := []struct {
+		origin Money
+		n      int64
+		res    Money
+	}{
+		{origin: FromInt(15), n: 0, res: Money(0)},
+		{origin: FromInt(15), n: 3, res: Money(4500)},
+		{origin: FromFloat(15.07), n: 2, res: Money(3014)},
+		{origin: FromInt(-15), n: 2, res: Money(-3000)},
+	}
+
+	for _, tt := range tests {
+		res := tt.origin.Mul(tt.n)
+		require.Equal(tt.res, res)
+	}
+}
+
 func TestDivide(t *testing.T) {
 	t.Parallel()
 
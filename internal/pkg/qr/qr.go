@@ -0,0 +1,571 @@
+// Package qr provides a minimal QR Code encoder. It supports only byte mode and medium error
+// correction level (versions 1-10), which is enough for otpauth:// URIs
+package qr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+var errDataTooLong = errors.New("data is too long")
+
+// Code is an encoded QR Code
+type Code struct {
+	// Size is a number of modules per side
+	Size int
+	// Modules contains rows of modules, true means a dark module
+	Modules [][]bool
+}
+
+// Encode encodes data using the smallest suitable version and the mask with the lowest penalty
+func Encode(data []byte) (Code, error) {
+	version, err := chooseVersion(len(data))
+	if err != nil {
+		return Code{}, err
+	}
+	codewords := addErrorCorrection(version, encodeData(version, data))
+
+	var (
+		best        Code
+		bestPenalty = -1
+	)
+	for mask := 0; mask < 8; mask++ {
+		code := newMatrix(version).build(codewords, mask)
+		if penalty := code.penalty(); bestPenalty == -1 || penalty < bestPenalty {
+			best, bestPenalty = code, penalty
+		}
+	}
+	return best, nil
+}
+
+// quietZone is a number of light modules around the code required by the specification
+const quietZone = 4
+
+// SVG returns an SVG image of the code. moduleSize is a size of a single module in pixels
+func (c Code) SVG(moduleSize int) string {
+	size := (c.Size + 2*quietZone) * moduleSize
+
+	b := &strings.Builder{}
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%[1]d" height="%[1]d" viewBox="0 0 %[2]d %[2]d"`,
+		size, c.Size+2*quietZone)
+	b.WriteString(` shape-rendering="crispEdges">`)
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	for y, row := range c.Modules {
+		for x, dark := range row {
+			if dark {
+				fmt.Fprintf(b, "M%d,%dh1v1h-1z", x+quietZone, y+quietZone)
+			}
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String()
+}
+
+// ----------------------------------------------------
+// Data encoding
+// ----------------------------------------------------
+
+const maxVersion = 10
+
+type blockInfo struct {
+	// ecPerBlock is a number of error correction codewords per block
+	ecPerBlock int
+	// blocks contains numbers of data codewords of every block
+	blocks []int
+}
+
+// mediumLevelBlocks describes blocks of versions 1-10 with medium error correction level
+var mediumLevelBlocks = [maxVersion + 1]blockInfo{ //nolint:gochecknoglobals
+	1:  {10, []int{16}},
+	2:  {16, []int{28}},
+	3:  {26, []int{44}},
+	4:  {18, []int{32, 32}},
+	5:  {24, []int{43, 43}},
+	6:  {16, []int{27, 27, 27, 27}},
+	7:  {18, []int{31, 31, 31, 31}},
+	8:  {22, []int{38, 38, 39, 39}},
+	9:  {22, []int{36, 36, 36, 37, 37}},
+	10: {26, []int{43, 43, 43, 43, 44}},
+}
+
+func dataCapacity(version int) (n int) {
+	for _, b := range mediumLevelBlocks[version].blocks {
+		n += b
+	}
+	return n
+}
+
+// countBits returns a length of the character count indicator in byte mode
+func countBits(version int) int {
+	if version < 10 {
+		return 8
+	}
+	return 16
+}
+
+func chooseVersion(dataLen int) (int, error) {
+	for version := 1; version <= maxVersion; version++ {
+		headerBits := 4 + countBits(version)
+		if (headerBits+dataLen*8+7)/8 <= dataCapacity(version) {
+			return version, nil
+		}
+	}
+	return 0, errDataTooLong
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*b = append(*b, value>>i&1 == 1)
+	}
+}
+
+// encodeData returns data codewords: mode indicator, character count, data, terminator and padding
+func encodeData(version int, data []byte) []byte {
+	const byteMode = 0b0100
+
+	capacity := dataCapacity(version)
+
+	bits := make(bitBuffer, 0, capacity*8)
+	bits.append(byteMode, 4)
+	bits.append(len(data), countBits(version))
+	for _, c := range data {
+		bits.append(int(c), 8)
+	}
+	// Terminator is up to 4 zero bits
+	for i := 0; i < 4 && len(bits) < capacity*8; i++ {
+		bits = append(bits, false)
+	}
+	for len(bits)%8 != 0 {
+		bits = append(bits, false)
+	}
+
+	res := make([]byte, 0, capacity)
+	for i := 0; i < len(bits); i += 8 {
+		var c byte
+		for _, bit := range bits[i : i+8] {
+			c <<= 1
+			if bit {
+				c |= 1
+			}
+		}
+		res = append(res, c)
+	}
+	// Pad bytes alternate
+	for i := 0; len(res) < capacity; i++ {
+		res = append(res, []byte{0xEC, 0x11}[i%2])
+	}
+	return res
+}
+
+// addErrorCorrection splits data into blocks, computes error correction codewords and interleaves
+// the blocks
+func addErrorCorrection(version int, data []byte) []byte {
+	info := mediumLevelBlocks[version]
+	generator := rsGenerator(info.ecPerBlock)
+
+	var (
+		dataBlocks = make([][]byte, 0, len(info.blocks))
+		ecBlocks   = make([][]byte, 0, len(info.blocks))
+		maxLen     int
+	)
+	for _, n := range info.blocks {
+		block := data[:n]
+		data = data[n:]
+
+		dataBlocks = append(dataBlocks, block)
+		ecBlocks = append(ecBlocks, rsRemainder(block, generator))
+		if n > maxLen {
+			maxLen = n
+		}
+	}
+
+	res := make([]byte, 0, len(data)+len(info.blocks)*info.ecPerBlock)
+	for i := 0; i < maxLen; i++ {
+		for _, block := range dataBlocks {
+			if i < len(block) {
+				res = append(res, block[i])
+			}
+		}
+	}
+	for i := 0; i < info.ecPerBlock; i++ {
+		for _, block := range ecBlocks {
+			res = append(res, block[i])
+		}
+	}
+	return res
+}
+
+// ----------------------------------------------------
+// Reed-Solomon
+// ----------------------------------------------------
+
+// gfMul multiplies two elements of GF(2^8) with the primitive polynomial x^8 + x^4 + x^3 + x^2 + 1
+func gfMul(x, y byte) byte {
+	var res byte
+	for i := 7; i >= 0; i-- {
+		// Multiply res by x (alpha) and reduce
+		carry := res >> 7
+		res <<= 1
+		res ^= carry * 0x1D
+		res ^= (y >> i & 1) * x
+	}
+	return res
+}
+
+// rsGenerator returns coefficients of the generator polynomial (x - a^0)(x - a^1)...(x - a^(degree-1))
+// without the leading term, from the highest degree to the lowest
+func rsGenerator(degree int) []byte {
+	res := make([]byte, degree)
+	res[degree-1] = 1
+
+	var root byte = 1
+	for i := 0; i < degree; i++ {
+		// Multiply the polynomial by (x - root)
+		for j := 0; j < degree; j++ {
+			res[j] = gfMul(res[j], root)
+			if j+1 < degree {
+				res[j] ^= res[j+1]
+			}
+		}
+		root = gfMul(root, 2)
+	}
+	return res
+}
+
+func rsRemainder(data, generator []byte) []byte {
+	res := make([]byte, len(generator))
+	for _, c := range data {
+		factor := c ^ res[0]
+		copy(res, res[1:])
+		res[len(res)-1] = 0
+		for i, coef := range generator {
+			res[i] ^= gfMul(coef, factor)
+		}
+	}
+	return res
+}
+
+// ----------------------------------------------------
+// Matrix
+// ----------------------------------------------------
+
+type matrix struct {
+	version  int
+	size     int
+	modules  [][]bool
+	function [][]bool
+}
+
+func newMatrix(version int) *matrix {
+	size := version*4 + 17
+	m := &matrix{
+		version:  version,
+		size:     size,
+		modules:  make([][]bool, size),
+		function: make([][]bool, size),
+	}
+	for i := 0; i < size; i++ {
+		m.modules[i] = make([]bool, size)
+		m.function[i] = make([]bool, size)
+	}
+	return m
+}
+
+func (m *matrix) setFunction(x, y int, dark bool) {
+	m.modules[y][x] = dark
+	m.function[y][x] = true
+}
+
+func (m *matrix) build(codewords []byte, mask int) Code {
+	m.drawFunctionPatterns()
+	m.drawCodewords(codewords)
+	m.applyMask(mask)
+	m.drawFormatBits(mask)
+
+	return Code{Size: m.size, Modules: m.modules}
+}
+
+func (m *matrix) drawFunctionPatterns() {
+	// Timing patterns
+	for i := 0; i < m.size; i++ {
+		m.setFunction(6, i, i%2 == 0)
+		m.setFunction(i, 6, i%2 == 0)
+	}
+
+	// Finder patterns with separators
+	for _, pos := range [][2]int{{3, 3}, {m.size - 4, 3}, {3, m.size - 4}} {
+		for dy := -4; dy <= 4; dy++ {
+			for dx := -4; dx <= 4; dx++ {
+				x, y := pos[0]+dx, pos[1]+dy
+				if x < 0 || x >= m.size || y < 0 || y >= m.size {
+					continue
+				}
+				dist := maxInt(absInt(dx), absInt(dy))
+				m.setFunction(x, y, dist != 2 && dist != 4)
+			}
+		}
+	}
+
+	// Alignment patterns
+	positions := alignmentPositions(m.version)
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the corners with finder patterns
+			last := len(positions) - 1
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			for dy := -2; dy <= 2; dy++ {
+				for dx := -2; dx <= 2; dx++ {
+					m.setFunction(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+				}
+			}
+		}
+	}
+
+	// Reserve format areas, they are drawn after masking
+	m.drawFormatBits(0)
+
+	if m.version >= 7 {
+		m.drawVersionBits()
+	}
+}
+
+// alignmentPositions returns coordinates of alignment pattern centers
+func alignmentPositions(version int) []int {
+	if version == 1 {
+		return nil
+	}
+	// The positions are evenly spaced between the first and the last one, the step is even
+	size := version*4 + 17
+	count := version/7 + 2
+	step := (size - 13) / (count - 1)
+	if step%2 != 0 {
+		step++
+	}
+
+	res := make([]int, count)
+	res[0] = 6
+	for i := count - 1; i > 0; i-- {
+		res[i] = size - 7 - (count-1-i)*step
+	}
+	return res
+}
+
+// formatBits returns 15 bits of format information: error correction level, mask and BCH code
+func formatBits(mask int) int {
+	// Medium error correction level is encoded as 00
+	const mediumLevel = 0b00
+
+	data := mediumLevel<<3 | mask
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = rem<<1 ^ (rem>>9)*0x537
+	}
+	return (data<<10 | rem) ^ 0x5412
+}
+
+// versionBits returns 18 bits of version information: version and BCH code
+func versionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = rem<<1 ^ (rem>>11)*0x1F25
+	}
+	return version<<12 | rem
+}
+
+func (m *matrix) drawFormatBits(mask int) {
+	bits := formatBits(mask)
+	bit := func(i int) bool {
+		return bits>>i&1 == 1
+	}
+
+	// First copy around the top left finder
+	for i := 0; i <= 5; i++ {
+		m.setFunction(8, i, bit(i))
+	}
+	m.setFunction(8, 7, bit(6))
+	m.setFunction(8, 8, bit(7))
+	m.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		m.setFunction(14-i, 8, bit(i))
+	}
+
+	// Second copy near the top right and bottom left finders
+	for i := 0; i < 8; i++ {
+		m.setFunction(m.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		m.setFunction(8, m.size-15+i, bit(i))
+	}
+	// Dark module
+	m.setFunction(8, m.size-8, true)
+}
+
+func (m *matrix) drawVersionBits() {
+	bits := versionBits(m.version)
+	for i := 0; i < 18; i++ {
+		dark := bits>>i&1 == 1
+		a, b := m.size-11+i%3, i/3
+		m.setFunction(a, b, dark)
+		m.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places codewords in the zigzag order, starting from the bottom right corner
+func (m *matrix) drawCodewords(codewords []byte) {
+	i := 0
+	for right := m.size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			// Skip the vertical timing pattern
+			right = 5
+		}
+		upward := (right+1)&2 == 0
+		for vert := 0; vert < m.size; vert++ {
+			y := vert
+			if upward {
+				y = m.size - 1 - vert
+			}
+			for j := 0; j < 2; j++ {
+				x := right - j
+				if m.function[y][x] || i >= len(codewords)*8 {
+					continue
+				}
+				m.modules[y][x] = codewords[i/8]>>(7-i%8)&1 == 1
+				i++
+			}
+		}
+	}
+}
+
+func (m *matrix) applyMask(mask int) {
+	for y := 0; y < m.size; y++ {
+		for x := 0; x < m.size; x++ {
+			if !m.function[y][x] && isMasked(mask, x, y) {
+				m.modules[y][x] = !m.modules[y][x]
+			}
+		}
+	}
+}
+
+func isMasked(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// ----------------------------------------------------
+// Penalty
+// ----------------------------------------------------
+
+// penalty computes the penalty score of the code. The mask with the lowest score should be used
+func (c Code) penalty() (res int) {
+	get := func(x, y int, transposed bool) bool {
+		if transposed {
+			return c.Modules[x][y]
+		}
+		return c.Modules[y][x]
+	}
+
+	for _, transposed := range []bool{false, true} {
+		for y := 0; y < c.Size; y++ {
+			// Rule 1: runs of 5 or more modules of the same color
+			run := 1
+			for x := 1; x <= c.Size; x++ {
+				if x < c.Size && get(x, y, transposed) == get(x-1, y, transposed) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					res += 3 + run - 5
+				}
+				run = 1
+			}
+
+			// Rule 3: finder-like patterns 1011101 with 4 light modules on either side
+			for x := 0; x+7 <= c.Size; x++ {
+				if !isFinderLike(c.Size, x, func(i int) bool { return get(i, y, transposed) }) {
+					continue
+				}
+				res += 40
+			}
+		}
+	}
+
+	var dark int
+	for y := 0; y < c.Size; y++ {
+		for x := 0; x < c.Size; x++ {
+			if c.Modules[y][x] {
+				dark++
+			}
+			// Rule 2: 2x2 blocks of the same color
+			if x+1 < c.Size && y+1 < c.Size {
+				color := c.Modules[y][x]
+				if color == c.Modules[y][x+1] && color == c.Modules[y+1][x] && color == c.Modules[y+1][x+1] {
+					res += 3
+				}
+			}
+		}
+	}
+
+	// Rule 4: proportion of dark modules
+	total := c.Size * c.Size
+	deviation := absInt(dark*20-total*10) / total
+	res += deviation * 10
+
+	return res
+}
+
+// isFinderLike checks whether there is a 1011101 pattern at 'start' with 4 light modules before
+// or after it. Modules outside the code are light
+func isFinderLike(size, start int, get func(i int) bool) bool {
+	pattern := [7]bool{true, false, true, true, true, false, true}
+	for i, dark := range pattern {
+		if get(start+i) != dark {
+			return false
+		}
+	}
+
+	isLight := func(from, to int) bool {
+		for i := from; i < to; i++ {
+			if i >= 0 && i < size && get(i) {
+				return false
+			}
+		}
+		return true
+	}
+	return isLight(start-4, start) || isLight(start+7, start+11)
+}
+
+func absInt(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
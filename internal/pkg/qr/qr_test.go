@@ -0,0 +1,88 @@
+package qr
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestErrorCorrection(t *testing.T) {
+	t.Parallel()
+
+	// "HELLO WORLD" encoded in alphanumeric mode, version 1-M
+	data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+	want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+
+	require.Equal(t, want, rsRemainder(data, rsGenerator(10)))
+}
+
+func TestFormatAndVersionBits(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	for mask, want := range []int{
+		0b101010000010010,
+		0b101000100100101,
+		0b101111001111100,
+		0b101101101001011,
+		0b100010111111001,
+		0b100000011001110,
+		0b100111110010111,
+		0b100101010100000,
+	} {
+		require.Equal(want, formatBits(mask), "mask: %d", mask)
+	}
+
+	require.Equal(0b000111110010010100, versionBits(7))
+	require.Equal(0b001010010011010011, versionBits(10))
+}
+
+func TestEncode(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	for _, tt := range []struct {
+		dataLen     int
+		wantVersion int
+	}{
+		{dataLen: 1, wantVersion: 1},
+		{dataLen: 14, wantVersion: 1},
+		{dataLen: 15, wantVersion: 2},
+		{dataLen: 106, wantVersion: 6},
+		{dataLen: 122, wantVersion: 7},
+		{dataLen: 213, wantVersion: 10},
+	} {
+		code, err := Encode([]byte(strings.Repeat("a", tt.dataLen)))
+		require.NoError(err)
+		require.Equal(tt.wantVersion*4+17, code.Size, "data length: %d", tt.dataLen)
+		require.Len(code.Modules, code.Size)
+
+		// Finder patterns
+		for _, pos := range [][2]int{{0, 0}, {code.Size - 7, 0}, {0, code.Size - 7}} {
+			for i := 0; i < 7; i++ {
+				require.True(code.Modules[pos[1]][pos[0]+i])
+				require.True(code.Modules[pos[1]+i][pos[0]])
+				require.True(code.Modules[pos[1]+3][pos[0]+3])
+			}
+		}
+	}
+
+	_, err := Encode([]byte(strings.Repeat("a", 214)))
+	require.ErrorIs(err, errDataTooLong)
+}
+
+func TestSVG(t *testing.T) {
+	t.Parallel()
+
+	code, err := Encode([]byte("otpauth://totp/Budget%20Manager:user?secret=JBSWY3DPEHPK3PXP&issuer=Budget%20Manager"))
+	require.NoError(t, err)
+
+	svg := code.SVG(4)
+	require.True(t, strings.HasPrefix(svg, `<svg xmlns="http://www.w3.org/2000/svg" width="180" height="180"`))
+	// The top left module of the finder pattern is shifted by the quiet zone
+	require.Contains(t, svg, `d="M4,4h1v1h-1z`)
+	require.True(t, strings.HasSuffix(svg, `"/></svg>`))
+}
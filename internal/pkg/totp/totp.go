@@ -0,0 +1,133 @@
+// Package totp implements Time-Based One-Time Passwords (RFC 6238) with the parameters supported
+// by all authenticator apps: HMAC-SHA1, 6 digits and 30 seconds period
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	digits = 6
+	period = 30
+	// skew is a number of periods before and after the current one. Their codes are accepted too
+	// because of clock drift
+	skew = 1
+
+	secretLength = 20
+
+	// recoveryCodeLength is a number of random bytes in a recovery code. Codes are formatted
+	// as 'xxxxx-xxxxx' to be easier to write down
+	recoveryCodeLength = 5
+)
+
+//nolint:gochecknoglobals
+var base32Encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// NewSecret generates a new random secret encoded in base32
+func NewSecret() (string, error) {
+	data := make([]byte, secretLength)
+	if _, err := rand.Read(data); err != nil {
+		return "", err
+	}
+	return base32Encoding.EncodeToString(data), nil
+}
+
+// Step returns the time step of the passed time
+func Step(t time.Time) int64 {
+	return t.Unix() / period
+}
+
+// Code returns the code for the passed time step
+func Code(secret string, step int64) (string, error) {
+	key, err := base32Encoding.DecodeString(strings.TrimRight(strings.ToUpper(secret), "="))
+	if err != nil {
+		return "", err
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	// Dynamic truncation
+	offset := sum[len(sum)-1] & 0x0F
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7FFFFFFF
+	return fmt.Sprintf("%06d", value%1_000_000), nil
+}
+
+// Validate checks the code and returns its time step. Codes of the adjacent steps are accepted too.
+// Callers should reject steps that are not greater than the step of the last used code to prevent replays
+func Validate(secret, code string, now time.Time) (step int64, ok bool) {
+	code = strings.ReplaceAll(code, " ", "")
+	if len(code) != digits {
+		return 0, false
+	}
+
+	current := Step(now)
+	for step := current - skew; step <= current+skew; step++ {
+		want, err := Code(secret, step)
+		if err != nil {
+			return 0, false
+		}
+		if subtle.ConstantTimeCompare([]byte(want), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// URI returns 'otpauth://' URI for authenticator apps. It is usually encoded in a QR Code
+func URI(issuer, account, secret string) string {
+	params := url.Values{
+		"secret":    {secret},
+		"issuer":    {issuer},
+		"algorithm": {"SHA1"},
+		"digits":    {strconv.Itoa(digits)},
+		"period":    {strconv.Itoa(period)},
+	}
+	u := url.URL{
+		Scheme:   "otpauth",
+		Host:     "totp",
+		Path:     "/" + issuer + ":" + account,
+		RawQuery: params.Encode(),
+	}
+	return u.String()
+}
+
+// NewRecoveryCodes generates n random recovery codes. Every code can be used once instead of a TOTP code
+func NewRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	data := make([]byte, recoveryCodeLength)
+	for i := 0; i < n; i++ {
+		if _, err := rand.Read(data); err != nil {
+			return nil, err
+		}
+		code := hex.EncodeToString(data)
+		codes = append(codes, code[:len(code)/2]+"-"+code[len(code)/2:])
+	}
+	return codes, nil
+}
+
+// IsRecoveryCode checks whether the passed string looks like a recovery code and not like a TOTP code
+func IsRecoveryCode(code string) bool {
+	return len(NormalizeRecoveryCode(code)) == recoveryCodeLength*2+1
+}
+
+// NormalizeRecoveryCode removes spaces and converts the code to lower case. It should be called
+// before hashing the code
+func NormalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(code, " ", ""))
+}
@@ -0,0 +1,111 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCode(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	// Test vectors from RFC 6238, Appendix B (SHA1). The codes are truncated to 6 digits
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	for _, tt := range []struct {
+		unix int64
+		want string
+	}{
+		{unix: 59, want: "287082"},
+		{unix: 1111111109, want: "081804"},
+		{unix: 1111111111, want: "050471"},
+		{unix: 1234567890, want: "005924"},
+		{unix: 2000000000, want: "279037"},
+		{unix: 20000000000, want: "353130"},
+	} {
+		code, err := Code(secret, Step(time.Unix(tt.unix, 0)))
+		require.NoError(err)
+		require.Equal(tt.want, code, "time: %d", tt.unix)
+	}
+
+	_, err := Code("not base32!", 1)
+	require.Error(err)
+}
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	secret, err := NewSecret()
+	require.NoError(err)
+	require.Len(secret, 32)
+
+	now := time.Unix(1600000000, 0)
+	step := Step(now)
+	for _, tt := range []struct {
+		offset int64
+		valid  bool
+	}{
+		{offset: -2, valid: false},
+		{offset: -1, valid: true},
+		{offset: 0, valid: true},
+		{offset: 1, valid: true},
+		{offset: 2, valid: false},
+	} {
+		code, err := Code(secret, step+tt.offset)
+		require.NoError(err)
+
+		gotStep, ok := Validate(secret, code, now)
+		require.Equal(tt.valid, ok, "offset: %d", tt.offset)
+		if ok {
+			require.Equal(step+tt.offset, gotStep)
+		}
+	}
+
+	// Spaces are ignored
+	code, err := Code(secret, step)
+	require.NoError(err)
+	_, ok := Validate(secret, code[:3]+" "+code[3:], now)
+	require.True(ok)
+
+	for _, code := range []string{"", "12345", "1234567", "abcdef"} {
+		_, ok := Validate(secret, code, now)
+		require.False(ok)
+	}
+}
+
+func TestURI(t *testing.T) {
+	t.Parallel()
+
+	got := URI("Budget Manager", "user", "JBSWY3DPEHPK3PXP")
+	require.Equal(t,
+		"otpauth://totp/Budget%20Manager:user?algorithm=SHA1&digits=6&issuer=Budget+Manager&period=30&secret=JBSWY3DPEHPK3PXP",
+		got,
+	)
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	codes, err := NewRecoveryCodes(10)
+	require.NoError(err)
+	require.Len(codes, 10)
+
+	unique := make(map[string]bool)
+	for _, code := range codes {
+		require.Regexp(`^[0-9a-f]{5}-[0-9a-f]{5}$`, code)
+		require.True(IsRecoveryCode(code))
+		require.Equal(code, NormalizeRecoveryCode(" "+strings.ToUpper(code)+" "))
+		unique[code] = true
+	}
+	require.Len(unique, len(codes))
+
+	require.False(IsRecoveryCode("123456"))
+}
@@ -1,32 +1,74 @@
 package api
 
-import "github.com/ShoshinNikita/budget-manager/internal/logger"
+import (
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/docs"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+)
 
 type Handlers struct {
 	MonthsHandlers
+	MonthEventsHandlers
 	IncomesHandlers
 	MonthlyPaymentsHandlers
 	SpendsHandlers
 	SpendTypesHandlers
+	SpendRulesHandlers
+	SavedSearchesHandlers
+	WebhooksHandlers
+	APITokensHandlers
+	AuthLockoutsHandlers
 	SearchHandlers
+	BackupHandlers
+	ExportHandlers
+	CalendarHandlers
+	StatisticsHandlers
+	DocsHandlers
 }
 
 type DB interface {
 	MonthsDB
+	MonthEventsDB
 	IncomesDB
 	MonthlyPaymentsDB
 	SpendsDB
 	SpendTypesDB
+	SpendRulesDB
+	SavedSearchesDB
+	WebhooksDB
+	APITokensDB
+	AuthLockoutsDB
 	SearchDB
+	BackupDB
+	ExportDB
+	CalendarDB
+	StatisticsDB
 }
 
-func NewHandlers(db DB, log logger.Logger) *Handlers {
+// NewHandlers creates API handlers. events is used to stream month changes. calendarReminder defines
+// when a reminder is triggered before a due date of a Monthly Payment in the calendar feed. useEmbed
+// defines whether the embedded API documentation should be used
+func NewHandlers(db DB, events MonthEvents, log logger.Logger, calendarReminder time.Duration,
+	useEmbed bool) *Handlers {
+
 	return &Handlers{
 		MonthsHandlers:          MonthsHandlers{db: db, log: log},
+		MonthEventsHandlers:     MonthEventsHandlers{db: db, events: events, log: log},
 		IncomesHandlers:         IncomesHandlers{db: db, log: log},
 		MonthlyPaymentsHandlers: MonthlyPaymentsHandlers{db: db, log: log},
 		SpendsHandlers:          SpendsHandlers{db: db, log: log},
 		SpendTypesHandlers:      SpendTypesHandlers{db: db, log: log},
+		SpendRulesHandlers:      SpendRulesHandlers{db: db, log: log},
+		SavedSearchesHandlers:   SavedSearchesHandlers{db: db, log: log},
+		WebhooksHandlers:        WebhooksHandlers{db: db, log: log},
+		APITokensHandlers:       APITokensHandlers{db: db, log: log},
+		AuthLockoutsHandlers:    AuthLockoutsHandlers{db: db, log: log},
 		SearchHandlers:          SearchHandlers{db: db, log: log},
+		BackupHandlers:          BackupHandlers{db: db, log: log},
+		ExportHandlers:          ExportHandlers{db: db, log: log},
+		CalendarHandlers:        CalendarHandlers{db: db, log: log, reminder: calendarReminder},
+		StatisticsHandlers:      StatisticsHandlers{db: db, log: log},
+		DocsHandlers:            DocsHandlers{docs: docs.New(useEmbed), log: log},
 	}
 }
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type APITokensHandlers struct {
+	db  APITokensDB
+	log logger.Logger
+}
+
+type APITokensDB interface {
+	GetAPITokens(ctx context.Context, username string) ([]db.APIToken, error)
+	AddAPIToken(ctx context.Context, args db.AddAPITokenArgs) (id uint, err error)
+	RemoveAPIToken(ctx context.Context, username string, id uint) error
+}
+
+// @Summary Get API Tokens
+// @Description Returns API Tokens of the current user
+// @Tags API Tokens
+// @Router /api/tokens [get]
+// @Produce json
+// @Success 200 {object} models.GetAPITokensResp
+// @Failure 403 {object} models.ErrorResp "Auth is disabled or the request is authorized with API Token"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h APITokensHandlers) GetAPITokens(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	user, ok := getAPITokensOwner(ctx, w, log)
+	if !ok {
+		return
+	}
+	tokens, err := h.db.GetAPITokens(ctx, user.Username)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get API Tokens", err)
+		return
+	}
+
+	resp := &models.GetAPITokensResp{
+		APITokens: tokens,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Create API Token
+// @Description API Token can be passed in 'Authorization: Bearer <token>' header instead of a password.
+// @Description The token is returned only once
+// @Tags API Tokens
+// @Router /api/tokens [post]
+// @Accept json
+// @Param body body models.AddAPITokenReq true "New API Token"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 201 {object} models.AddAPITokenResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 403 {object} models.ErrorResp "Auth is disabled or the request is authorized with API Token"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h APITokensHandlers) AddAPIToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.AddAPITokenReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	user, ok := getAPITokensOwner(ctx, w, log)
+	if !ok {
+		return
+	}
+	token, err := auth.NewAPIToken()
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't generate API Token", err)
+		return
+	}
+	args := db.AddAPITokenArgs{
+		Username:  user.Username,
+		Name:      req.Name,
+		TokenHash: auth.HashToken(token),
+		ReadOnly:  req.ReadOnly,
+		Endpoints: req.Endpoints,
+		CreatedAt: time.Now(),
+	}
+	id, err := h.db.AddAPIToken(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't add API Token", err)
+		return
+	}
+	log = log.WithField("id", id)
+	log.Debug("API Token was successfully added")
+
+	resp := &models.AddAPITokenResp{
+		ID:    id,
+		Token: token,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp), utils.EncodeStatusCode(http.StatusCreated),
+		utils.EncodeNoStore())
+}
+
+// @Summary Revoke API Token
+// @Tags API Tokens
+// @Router /api/tokens [delete]
+// @Accept json
+// @Param body body models.RemoveAPITokenReq true "API Token id"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 403 {object} models.ErrorResp "Auth is disabled or the request is authorized with API Token"
+// @Failure 404 {object} models.ErrorResp "API Token doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h APITokensHandlers) RemoveAPIToken(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RemoveAPITokenReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	user, ok := getAPITokensOwner(ctx, w, log)
+	if !ok {
+		return
+	}
+	err := h.db.RemoveAPIToken(ctx, user.Username, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAPITokenNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't remove API Token", err)
+		}
+		return
+	}
+	log.Debug("API Token was successfully revoked")
+
+	utils.Encode(ctx, w, log)
+}
+
+// getAPITokensOwner returns the user whose API Tokens are managed. API Tokens can't be managed
+// with API Tokens, so a leaked token can't be used to issue new ones
+func getAPITokensOwner(ctx context.Context, w http.ResponseWriter, log logger.Logger) (auth.User, bool) {
+	user, ok := auth.FromContext(ctx)
+	switch {
+	case !ok:
+		err := errors.New("API Tokens can't be used when auth is disabled")
+		utils.EncodeError(ctx, w, log, err, http.StatusForbidden)
+		return auth.User{}, false
+	case user.APITokenID != 0:
+		err := errors.New("API Tokens can't be managed with API Token")
+		utils.EncodeError(ctx, w, log, err, http.StatusForbidden)
+		return auth.User{}, false
+	}
+	return user, true
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type AuthLockoutsHandlers struct {
+	db  AuthLockoutsDB
+	log logger.Logger
+}
+
+type AuthLockoutsDB interface {
+	GetAuthLockouts(ctx context.Context) ([]db.AuthLockout, error)
+	RemoveAuthLockout(ctx context.Context, id uint) error
+}
+
+// @Summary Get Auth Lockouts
+// @Description Returns ips and usernames with failed auth attempts. Auth attempts are locked until 'locked_until'
+// @Tags Auth Lockouts
+// @Router /api/auth/lockouts [get]
+// @Produce json
+// @Success 200 {object} models.GetAuthLockoutsResp
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h AuthLockoutsHandlers) GetAuthLockouts(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	lockouts, err := h.db.GetAuthLockouts(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Auth Lockouts", err)
+		return
+	}
+
+	resp := &models.GetAuthLockoutsResp{
+		AuthLockouts: lockouts,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Clear Auth Lockout
+// @Description Unlocks an ip or a username and resets its failed auth attempts
+// @Tags Auth Lockouts
+// @Router /api/auth/lockouts [delete]
+// @Accept json
+// @Param body body models.RemoveAuthLockoutReq true "Auth Lockout id"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Auth Lockout doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h AuthLockoutsHandlers) RemoveAuthLockout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RemoveAuthLockoutReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	err := h.db.RemoveAuthLockout(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrAuthLockoutNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't remove Auth Lockout", err)
+		}
+		return
+	}
+	log.Debug("Auth Lockout was successfully removed")
+
+	utils.Encode(ctx, w, log)
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type BackupHandlers struct {
+	db  BackupDB
+	log logger.Logger
+}
+
+type BackupDB interface {
+	ExportBackup(ctx context.Context) (db.Backup, error)
+	RestoreBackup(ctx context.Context, backup db.Backup) (db.BackupStats, error)
+	InitMonth(ctx context.Context, year int, month time.Month) error
+}
+
+// @Summary Export Backup
+// @Description Export all data in the db-agnostic JSON format. The response can be passed to /api/backup/restore
+// @Tags Backup
+// @Router /api/backup [get]
+// @Produce json
+// @Success 200 {object} db.Backup
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h BackupHandlers) ExportBackup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	backup, err := h.db.ExportBackup(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't export backup", err)
+		return
+	}
+
+	filename := fmt.Sprintf("budget-manager-%s.json", backup.CreatedAt.Format("2006-01-02"))
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := json.NewEncoder(w).Encode(backup); err != nil {
+		utils.LogInternalError(log, "couldn't encode backup", err)
+		return
+	}
+	log.Debug("backup was successfully exported")
+}
+
+// @Summary Restore Backup
+// @Description Replace all data with data from the backup. Ids are rebuilt and all months are recomputed
+// @Tags Backup
+// @Router /api/backup/restore [post]
+// @Accept json
+// @Param body body models.RestoreBackupReq true "Backup"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 200 {object} models.RestoreBackupResp
+// @Failure 400 {object} models.ErrorResp "Invalid backup"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h BackupHandlers) RestoreBackup(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RestoreBackupReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	// Don't log the whole backup
+	log = log.WithFields(logger.Fields{"version": req.Version, "created_at": req.CreatedAt})
+
+	// Process
+	stats, err := h.db.RestoreBackup(ctx, req.Backup)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't restore backup", err)
+		return
+	}
+
+	// The backup may not contain the current month
+	now := time.Now()
+	if err := h.db.InitMonth(ctx, now.Year(), now.Month()); err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't init the current month", err)
+		return
+	}
+	log.WithField("stats", stats).Info("backup was successfully restored")
+
+	resp := &models.RestoreBackupResp{
+		Stats: stats,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/ical"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type CalendarHandlers struct {
+	db  CalendarDB
+	log logger.Logger
+
+	// reminder defines when an alarm is triggered before a due date. Zero value disables alarms
+	reminder time.Duration
+}
+
+type CalendarDB interface {
+	GetMonthsInRange(ctx context.Context, after, before time.Time) ([]db.MonthOverview, error)
+	GetFullMonths(ctx context.Context, after, before time.Time) ([]db.Month, error)
+}
+
+// @Summary Monthly Payments Calendar
+// @Description iCalendar feed with due dates of Monthly Payments for the current and following months.
+// @Description Only Monthly Payments with a due day are included. Months that haven't been created yet
+// @Description (including the current one) get Monthly Payments of the latest previous month
+// @Tags Monthly Payments
+// @Router /api/monthly-payments/calendar.ics [get]
+// @Param params query models.GetMonthlyPaymentsCalendarReq true "Calendar args"
+// @Produce plain
+// @Success 200 {string} string "iCalendar feed"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h CalendarHandlers) GetMonthlyPaymentsCalendar(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetMonthlyPaymentsCalendarReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	last := first.AddDate(0, req.Months-1, 0)
+
+	months, err := h.getCalendarMonths(ctx, first, last)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get months", err)
+		return
+	}
+
+	cal := ical.Calendar{
+		Name:   "Monthly Payments",
+		Events: newMonthlyPaymentEvents(first, req.Months, months, h.reminder),
+	}
+
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	w.Header().Set("Content-Disposition", `inline; filename="monthly-payments.ics"`)
+	if err := ical.Write(w, cal, now); err != nil {
+		utils.LogInternalError(log, "couldn't write calendar", err)
+		return
+	}
+	log.Debug("calendar was successfully written")
+}
+
+// getCalendarMonths returns months within the passed range. The current month is created at the start
+// of the month, so it can be missing for some time. The latest previous month is returned first in this
+// case, so its Monthly Payments can be used
+func (h CalendarHandlers) getCalendarMonths(ctx context.Context, first, last time.Time) ([]db.Month, error) {
+	months, err := h.db.GetFullMonths(ctx, first, last)
+	if err != nil {
+		return nil, err
+	}
+	if len(months) != 0 && months[0].Year == first.Year() && months[0].Month == first.Month() {
+		return months, nil
+	}
+
+	previous, err := h.db.GetMonthsInRange(ctx, time.Time{}, first.AddDate(0, -1, 0))
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get previous months")
+	}
+	if len(previous) == 0 {
+		return months, nil
+	}
+	latest := previous[len(previous)-1]
+	date := time.Date(latest.Year, latest.Month, 1, 0, 0, 0, 0, time.UTC)
+
+	latestMonths, err := h.db.GetFullMonths(ctx, date, date)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't get the latest previous month")
+	}
+	return append(latestMonths, months...), nil
+}
+
+// newMonthlyPaymentEvents returns events for Monthly Payments with due days. Months without
+// data in the db (usually future ones) get Monthly Payments of the latest previous month.
+// Months must be sorted, months before the first one are used only for their Monthly Payments
+func newMonthlyPaymentEvents(first time.Time, count int, months []db.Month, reminder time.Duration) []ical.Event {
+	var payments []db.MonthlyPayment
+
+	byDate := make(map[time.Time]db.Month, len(months))
+	for _, m := range months {
+		date := time.Date(m.Year, m.Month, 1, 0, 0, 0, 0, time.UTC)
+		if date.Before(first) {
+			payments = m.MonthlyPayments
+			continue
+		}
+		byDate[date] = m
+	}
+
+	var events []ical.Event
+	for i := 0; i < count; i++ {
+		date := first.AddDate(0, i, 0)
+		if m, ok := byDate[date]; ok {
+			payments = m.MonthlyPayments
+		}
+
+		lastDay := date.AddDate(0, 1, -1).Day()
+		for _, mp := range payments {
+			if mp.DueDay == 0 {
+				continue
+			}
+			day := int(mp.DueDay)
+			if day > lastDay {
+				day = lastDay
+			}
+
+			var (
+				description = []string{"Cost: " + mp.Cost.String()}
+				categories  []string
+			)
+			if mp.Type != nil {
+				description = append(description, "Type: "+mp.Type.Name)
+				categories = append(categories, mp.Type.Name)
+			}
+			if mp.Notes != "" {
+				description = append(description, "Notes: "+mp.Notes)
+			}
+
+			events = append(events, ical.Event{
+				UID:         fmt.Sprintf("monthly-payment-%d-%s@budget-manager", mp.ID, date.Format("200601")),
+				Date:        date.AddDate(0, 0, day-1),
+				Summary:     fmt.Sprintf("%s: %s", mp.Title, mp.Cost.String()),
+				Description: strings.Join(description, "\n"),
+				Categories:  categories,
+				Reminder:    reminder,
+			})
+		}
+	}
+	return events
+}
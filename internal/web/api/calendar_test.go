@@ -0,0 +1,91 @@
+package api
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+type calendarMockDB struct {
+	months []db.Month
+}
+
+func (m calendarMockDB) GetMonthsInRange(_ context.Context, after, before time.Time) ([]db.MonthOverview, error) {
+	var res []db.MonthOverview
+	for _, month := range m.filter(after, before) {
+		res = append(res, month.MonthOverview)
+	}
+	return res, nil
+}
+
+func (m calendarMockDB) GetFullMonths(_ context.Context, after, before time.Time) ([]db.Month, error) {
+	return m.filter(after, before), nil
+}
+
+func (m calendarMockDB) filter(after, before time.Time) (res []db.Month) {
+	for _, month := range m.months {
+		date := time.Date(month.Year, month.Month, 1, 0, 0, 0, 0, time.UTC)
+		if (after.IsZero() || !date.Before(after)) && (before.IsZero() || !date.After(before)) {
+			res = append(res, month)
+		}
+	}
+	return res
+}
+
+func TestGetCalendarMonths(t *testing.T) {
+	t.Parallel()
+
+	newMonth := func(id uint, year int, month time.Month, dueDay uint) db.Month {
+		return db.Month{
+			MonthOverview: db.MonthOverview{ID: id, Year: year, Month: month},
+			MonthlyPayments: []db.MonthlyPayment{
+				{ID: id, Title: "rent", Cost: money.FromInt(1000), DueDay: dueDay},
+			},
+		}
+	}
+	first := time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, tt := range []struct {
+		desc      string
+		months    []db.Month
+		wantDates []string
+	}{
+		{
+			desc:      "current month exists",
+			months:    []db.Month{newMonth(1, 2026, time.February, 5), newMonth(2, 2026, time.March, 10)},
+			wantDates: []string{"2026-03-10", "2026-04-10", "2026-05-10"},
+		},
+		{
+			// The current month hasn't been initialized yet
+			desc:      "current month doesn't exist",
+			months:    []db.Month{newMonth(1, 2026, time.January, 15), newMonth(2, 2026, time.February, 5)},
+			wantDates: []string{"2026-03-05", "2026-04-05", "2026-05-05"},
+		},
+		{
+			desc:      "current month doesn't exist, next month exists",
+			months:    []db.Month{newMonth(1, 2026, time.February, 5), newMonth(2, 2026, time.April, 20)},
+			wantDates: []string{"2026-03-05", "2026-04-20", "2026-05-20"},
+		},
+		{
+			desc:      "no months",
+			months:    nil,
+			wantDates: nil,
+		},
+	} {
+		h := CalendarHandlers{db: calendarMockDB{months: tt.months}}
+
+		months, err := h.getCalendarMonths(context.Background(), first, first.AddDate(0, 2, 0))
+		require.NoError(t, err, tt.desc)
+
+		var dates []string
+		for _, event := range newMonthlyPaymentEvents(first, 3, months, 0) {
+			dates = append(dates, event.Date.Format("2006-01-02"))
+		}
+		require.Equal(t, tt.wantDates, dates, tt.desc)
+	}
+}
@@ -0,0 +1,87 @@
+package api
+
+import (
+	"encoding/json"
+	"io/fs"
+	"net/http"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/ShoshinNikita/budget-manager/docs"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type DocsHandlers struct {
+	docs fs.FS
+	log  logger.Logger
+}
+
+// @Summary Get API documentation in YAML
+// @Description Swagger 2.0 documentation of the API. The interactive explorer is available at '/docs/api'
+// @Tags Docs
+// @Router /api/docs/swagger.yaml [get]
+// @Produce plain
+// @Success 200 {string} string "Swagger 2.0 documentation"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h DocsHandlers) GetSwaggerYAML(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	data, err := fs.ReadFile(h.docs, docs.SwaggerFileName)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't read API documentation", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/yaml; charset=utf-8")
+	if _, err := w.Write(data); err != nil {
+		utils.LogInternalError(log, "couldn't write API documentation", err)
+	}
+}
+
+// @Summary Get API documentation in JSON
+// @Description Swagger 2.0 documentation of the API. The interactive explorer is available at '/docs/api'
+// @Tags Docs
+// @Router /api/docs/swagger.json [get]
+// @Produce json
+// @Success 200 {object} object "Swagger 2.0 documentation"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h DocsHandlers) GetSwaggerJSON(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	data, err := fs.ReadFile(h.docs, docs.SwaggerFileName)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't read API documentation", err)
+		return
+	}
+	data, err = convertYAMLToJSON(data)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't convert API documentation to JSON", err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	if _, err := w.Write(data); err != nil {
+		utils.LogInternalError(log, "couldn't write API documentation", err)
+	}
+}
+
+func convertYAMLToJSON(data []byte) ([]byte, error) {
+	// yaml.v3 decodes mappings with string keys into map[string]interface{}, so the result
+	// can be encoded to JSON. Swagger documents don't have mappings with other keys
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, errors.Wrap(err, "couldn't decode YAML")
+	}
+	res, err := json.Marshal(doc)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't encode JSON")
+	}
+	return res, nil
+}
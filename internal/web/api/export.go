@@ -0,0 +1,99 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/ledger"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type ExportHandlers struct {
+	db  ExportDB
+	log logger.Logger
+}
+
+type ExportDB interface {
+	GetFullMonths(ctx context.Context, after, before time.Time) ([]db.Month, error)
+	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
+	SearchSpends(ctx context.Context, args db.SearchSpendsArgs) ([]db.Spend, error)
+}
+
+// @Summary Export to Ledger or Beancount
+// @Description Export Incomes, Monthly Payments and Spends as transactions in plain-text accounting formats.
+// @Description Spend Types are converted to accounts with parents (for example, 'Expenses:Food:Groceries'),
+// @Description words with prefix '#' in notes are converted to tags.
+// @Description Sort, Order, Limit and Offset are ignored: all found Spends are exported
+// @Tags Export
+// @Router /api/export/ledger [get]
+// @Param params query models.ExportLedgerReq true "Export args"
+// @Produce plain
+// @Success 200 {string} string "Transactions"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h ExportHandlers) ExportLedger(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.ExportLedgerReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args, err := newSearchSpendsArgs(ctx, h.db, &req.SearchSpendsReq)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+	// Export all found Spends: Incomes and Monthly Payments are not paginated
+	args.Sort = db.SortSpendsByDate
+	args.Order = db.OrderByAsc
+	args.Limit = 0
+	args.Offset = 0
+
+	spends, err := h.db.SearchSpends(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't search for Spends", err)
+		return
+	}
+	months, err := h.db.GetFullMonths(ctx, req.After, req.Before)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get months", err)
+		return
+	}
+	spendTypes, err := h.db.GetSpendTypes(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Spend Types", err)
+		return
+	}
+
+	opts := ledger.Options{
+		Format:   ledger.Ledger,
+		Currency: req.Currency,
+		Account:  req.Account,
+	}
+	ext := "ledger"
+	if req.Format == "beancount" {
+		opts.Format = ledger.Beancount
+		ext = "beancount"
+	}
+	filename := fmt.Sprintf("budget-manager-%s.%s", time.Now().Format("2006-01-02"), ext)
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	if err := ledger.Write(w, opts, months, spends, spendTypes); err != nil {
+		utils.LogInternalError(log, "couldn't write transactions", err)
+		return
+	}
+	log.Debug("data was successfully exported")
+}
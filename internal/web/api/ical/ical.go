@@ -0,0 +1,167 @@
+// Package ical provides a minimal writer of iCalendar files (RFC 5545) with all-day events
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	dateFormat     = "20060102"
+	dateTimeFormat = "20060102T150405Z"
+
+	// maxLineLength is a max length of a line in octets without line break
+	maxLineLength = 75
+)
+
+type Calendar struct {
+	Name   string
+	Events []Event
+}
+
+// Event is an all-day event
+type Event struct {
+	// UID must be globally unique and must not change between feed updates
+	UID         string
+	Date        time.Time
+	Summary     string
+	Description string
+	Categories  []string
+	// Reminder defines when an alarm is triggered before the start of the event. Zero value disables the alarm
+	Reminder time.Duration
+}
+
+// Write writes the calendar. now is used as a timestamp of all events
+func Write(w io.Writer, cal Calendar, now time.Time) error {
+	buf := bufio.NewWriter(w)
+	lw := lineWriter{w: buf}
+
+	lw.WriteLine("BEGIN:VCALENDAR")
+	lw.WriteLine("VERSION:2.0")
+	lw.WriteLine("PRODID:-//Budget Manager//Monthly Payments//EN")
+	lw.WriteLine("CALSCALE:GREGORIAN")
+	lw.WriteLine("METHOD:PUBLISH")
+	if cal.Name != "" {
+		lw.WriteLine("X-WR-CALNAME:" + escapeText(cal.Name))
+	}
+
+	for _, e := range cal.Events {
+		lw.WriteLine("BEGIN:VEVENT")
+		lw.WriteLine("UID:" + e.UID)
+		lw.WriteLine("DTSTAMP:" + now.UTC().Format(dateTimeFormat))
+		lw.WriteLine("DTSTART;VALUE=DATE:" + e.Date.Format(dateFormat))
+		lw.WriteLine("DTEND;VALUE=DATE:" + e.Date.AddDate(0, 0, 1).Format(dateFormat))
+		lw.WriteLine("SUMMARY:" + escapeText(e.Summary))
+		if e.Description != "" {
+			lw.WriteLine("DESCRIPTION:" + escapeText(e.Description))
+		}
+		if len(e.Categories) != 0 {
+			categories := make([]string, 0, len(e.Categories))
+			for _, c := range e.Categories {
+				categories = append(categories, escapeText(c))
+			}
+			lw.WriteLine("CATEGORIES:" + strings.Join(categories, ","))
+		}
+		lw.WriteLine("TRANSP:TRANSPARENT")
+		if e.Reminder > 0 {
+			lw.WriteLine("BEGIN:VALARM")
+			lw.WriteLine("ACTION:DISPLAY")
+			lw.WriteLine("DESCRIPTION:" + escapeText(e.Summary))
+			lw.WriteLine("TRIGGER:" + formatNegativeDuration(e.Reminder))
+			lw.WriteLine("END:VALARM")
+		}
+		lw.WriteLine("END:VEVENT")
+	}
+
+	lw.WriteLine("END:VCALENDAR")
+
+	if lw.err != nil {
+		return lw.err
+	}
+	return buf.Flush()
+}
+
+// lineWriter writes lines with CRLF and folds long lines
+type lineWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (lw *lineWriter) WriteLine(line string) {
+	if lw.err != nil {
+		return
+	}
+
+	// Fold the line: every continuation line starts with a space. Don't split multi-byte characters
+	for first := true; ; first = false {
+		limit := maxLineLength
+		if !first {
+			limit--
+		}
+		if len(line) <= limit {
+			break
+		}
+
+		i := limit
+		for i > 0 && !utf8.RuneStart(line[i]) {
+			i--
+		}
+
+		lw.write(line[:i] + "\r\n ")
+		line = line[i:]
+	}
+	lw.write(line + "\r\n")
+}
+
+func (lw *lineWriter) write(s string) {
+	if lw.err != nil {
+		return
+	}
+	_, lw.err = io.WriteString(lw.w, s)
+}
+
+//nolint:gochecknoglobals
+var textEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`;`, `\;`,
+	`,`, `\,`,
+	"\r\n", `\n`,
+	"\n", `\n`,
+)
+
+func escapeText(s string) string {
+	return textEscaper.Replace(s)
+}
+
+// formatNegativeDuration formats a duration according to RFC 5545 (3.3.6): 36h30m -> -P1DT12H30M
+func formatNegativeDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+
+	days := d / (24 * time.Hour)
+	d -= days * 24 * time.Hour
+	hours := d / time.Hour
+	d -= hours * time.Hour
+	minutes := d / time.Minute
+
+	res := "-P"
+	if days != 0 {
+		res += fmt.Sprintf("%dD", days)
+	}
+	if hours != 0 || minutes != 0 {
+		res += "T"
+		if hours != 0 {
+			res += fmt.Sprintf("%dH", hours)
+		}
+		if minutes != 0 {
+			res += fmt.Sprintf("%dM", minutes)
+		}
+	}
+	if res == "-P" {
+		res += "T0M"
+	}
+	return res
+}
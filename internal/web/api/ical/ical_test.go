@@ -0,0 +1,120 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	cal := Calendar{
+		Name: "Monthly Payments",
+		Events: []Event{
+			{
+				UID:         "1@test",
+				Date:        time.Date(2020, time.July, 31, 0, 0, 0, 0, time.UTC),
+				Summary:     "Rent: 1500.00",
+				Description: "Type: house, utilities\nNotes: a;b\\c",
+				Categories:  []string{"house, utilities"},
+				Reminder:    36*time.Hour + 30*time.Minute,
+			},
+			{
+				UID:     "2@test",
+				Date:    time.Date(2020, time.December, 31, 0, 0, 0, 0, time.UTC),
+				Summary: "Internet: 10.00",
+			},
+		},
+	}
+	now := time.Date(2020, time.July, 1, 15, 4, 5, 0, time.FixedZone("", 3*60*60))
+
+	want := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//Budget Manager//Monthly Payments//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:Monthly Payments",
+		"BEGIN:VEVENT",
+		"UID:1@test",
+		"DTSTAMP:20200701T120405Z",
+		"DTSTART;VALUE=DATE:20200731",
+		"DTEND;VALUE=DATE:20200801",
+		"SUMMARY:Rent: 1500.00",
+		`DESCRIPTION:Type: house\, utilities\nNotes: a\;b\\c`,
+		`CATEGORIES:house\, utilities`,
+		"TRANSP:TRANSPARENT",
+		"BEGIN:VALARM",
+		"ACTION:DISPLAY",
+		"DESCRIPTION:Rent: 1500.00",
+		"TRIGGER:-P1DT12H30M",
+		"END:VALARM",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"UID:2@test",
+		"DTSTAMP:20200701T120405Z",
+		"DTSTART;VALUE=DATE:20201231",
+		"DTEND;VALUE=DATE:20210101",
+		"SUMMARY:Internet: 10.00",
+		"TRANSP:TRANSPARENT",
+		"END:VEVENT",
+		"END:VCALENDAR",
+		"",
+	}
+
+	buf := &bytes.Buffer{}
+	err := Write(buf, cal, now)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join(want, "\r\n"), buf.String())
+}
+
+func TestLineFolding(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	line := "SUMMARY:" + strings.Repeat("ab", 40) + strings.Repeat("й", 60)
+
+	buf := &bytes.Buffer{}
+	lw := lineWriter{w: buf}
+	lw.WriteLine(line)
+	require.NoError(lw.err)
+
+	res := buf.String()
+	require.True(strings.HasSuffix(res, "\r\n"))
+
+	lines := strings.Split(strings.TrimSuffix(res, "\r\n"), "\r\n")
+	require.Greater(len(lines), 1)
+
+	unfolded := lines[0]
+	for i, l := range lines {
+		require.LessOrEqual(len(l), maxLineLength, "line %d is too long", i)
+		require.True(strings.ToValidUTF8(l, "") == l, "line %d is invalid UTF-8", i)
+		if i != 0 {
+			require.True(strings.HasPrefix(l, " "), "line %d must start with a space", i)
+			unfolded += l[1:]
+		}
+	}
+	require.Equal(line, unfolded)
+}
+
+func TestFormatNegativeDuration(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		d    time.Duration
+		want string
+	}{
+		{d: 24 * time.Hour, want: "-P1D"},
+		{d: 2 * time.Hour, want: "-PT2H"},
+		{d: 15 * time.Minute, want: "-PT15M"},
+		{d: 49*time.Hour + 5*time.Minute, want: "-P2DT1H5M"},
+		{d: 10 * time.Second, want: "-PT0M"},
+	} {
+		require.Equal(t, tt.want, formatNegativeDuration(tt.d))
+	}
+}
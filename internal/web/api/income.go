@@ -19,21 +19,106 @@ type IncomesHandlers struct {
 }
 
 type IncomesDB interface {
+	GetIncome(ctx context.Context, id uint) (db.Income, error)
+	GetIncomes(ctx context.Context, monthID uint) ([]db.Income, error)
 	AddIncome(ctx context.Context, args db.AddIncomeArgs) (id uint, err error)
-	EditIncome(ctx context.Context, args db.EditIncomeArgs) error
+	EditIncome(ctx context.Context, args db.EditIncomeArgs) (version int, err error)
 	RemoveIncome(ctx context.Context, id uint) error
 }
 
+// @Summary Get Income
+// @Tags Incomes
+// @Router /api/incomes/{id} [get]
+// @Param id path int true "Income id"
+// @Produce json
+// @Success 200 {object} models.GetIncomeResp
+// @Header 200 {string} ETag "Version of the Income"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Income doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h IncomesHandlers) GetIncome(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetIncomeReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	income, err := h.db.GetIncome(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrIncomeNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Income", err)
+		}
+		return
+	}
+
+	utils.SetETag(w, income.Version)
+	resp := &models.GetIncomeResp{
+		Income: income,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get Incomes of Month
+// @Tags Incomes
+// @Router /api/incomes [get]
+// @Param params query models.GetIncomesReq true "Month id"
+// @Produce json
+// @Success 200 {object} models.GetIncomesResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h IncomesHandlers) GetIncomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetIncomesReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	incomes, err := h.db.GetIncomes(ctx, req.MonthID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrMonthNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Incomes", err)
+		}
+		return
+	}
+
+	resp := &models.GetIncomesResp{
+		Incomes: incomes,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
 // @Summary Create Income
 // @Tags Incomes
 // @Router /api/incomes [post]
 // @Accept json
 // @Param body body models.AddIncomeReq true "New Income"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
 // @Produce json
 // @Success 201 {object} models.AddIncomeResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Month doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h IncomesHandlers) AddIncome(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -77,11 +162,14 @@ func (h IncomesHandlers) AddIncome(w http.ResponseWriter, r *http.Request) {
 // @Router /api/incomes [put]
 // @Accept json
 // @Param body body models.EditIncomeReq true "Updated Income"
+// @Param If-Match header string false "Expected version of the Income"
 // @Produce json
-// @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Income doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Success 200 {object} models.EditIncomeResp
+// @Header 200 {string} ETag "New version of the Income"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Income doesn't exist"
+// @Failure 409 {object} models.GetIncomeResp "Income was changed by another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h IncomesHandlers) EditIncome(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -94,21 +182,30 @@ func (h IncomesHandlers) EditIncome(w http.ResponseWriter, r *http.Request) {
 	}
 	log = log.WithRequest(req)
 
+	version, err := utils.ExpectedVersion(r, req.Version)
+	if err != nil {
+		utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		return
+	}
+
 	// Process
 	args := db.EditIncomeArgs{
-		ID:    req.ID,
-		Title: req.Title,
-		Notes: req.Notes,
+		ID:      req.ID,
+		Title:   req.Title,
+		Notes:   req.Notes,
+		Version: version,
 	}
 	if req.Income != nil {
 		income := money.FromFloat(*req.Income)
 		args.Income = &income
 	}
-	err := h.db.EditIncome(ctx, args)
+	newVersion, err := h.db.EditIncome(ctx, args)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrIncomeNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		case errors.Is(err, db.ErrVersionConflict):
+			h.encodeVersionConflict(ctx, w, log, err, req.ID)
 		default:
 			utils.EncodeInternalError(ctx, w, log, "couldn't edit Income", err)
 		}
@@ -116,7 +213,28 @@ func (h IncomesHandlers) EditIncome(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug("Income was successfully edited")
 
-	utils.Encode(ctx, w, log)
+	utils.SetETag(w, newVersion)
+	resp := &models.EditIncomeResp{
+		Version: newVersion,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// encodeVersionConflict encodes the version conflict error with the current Income
+func (h IncomesHandlers) encodeVersionConflict(ctx context.Context, w http.ResponseWriter, log logger.Logger,
+	err error, id uint) {
+
+	income, getErr := h.db.GetIncome(ctx, id)
+	if getErr != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get the current Income", getErr)
+		return
+	}
+
+	utils.SetETag(w, income.Version)
+	resp := &models.GetIncomeResp{
+		Income: income,
+	}
+	utils.EncodeError(ctx, w, log, err, http.StatusConflict, utils.EncodeResponse(resp))
 }
 
 // @Summary Remove Income
@@ -126,9 +244,9 @@ func (h IncomesHandlers) EditIncome(w http.ResponseWriter, r *http.Request) {
 // @Param body body models.RemoveIncomeReq true "Income id"
 // @Produce json
 // @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Income doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Income doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h IncomesHandlers) RemoveIncome(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
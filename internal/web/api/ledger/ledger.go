@@ -0,0 +1,282 @@
+// Package ledger exports incomes, monthly payments and spends in plain-text accounting formats
+package ledger
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+type Format int
+
+const (
+	// Ledger is a format of Ledger (https://www.ledger-cli.org). It is also supported by hledger
+	Ledger Format = iota
+	// Beancount is a format of Beancount (https://beancount.github.io)
+	Beancount
+)
+
+const (
+	incomeAccount        = "Income"
+	expensesAccount      = "Expenses"
+	uncategorizedAccount = "Uncategorized"
+	unknownAccount       = "Unknown"
+)
+
+type Options struct {
+	Format Format
+	// Currency is added to all amounts. It is required for Beancount
+	Currency string
+	// Account is an account that receives incomes and pays for Monthly Payments and Spends
+	Account string
+}
+
+// transaction is a format-agnostic transaction. The amount is transferred from Options.Account
+// to the account (it is negative for incomes)
+type transaction struct {
+	date    time.Time
+	title   string
+	notes   string
+	tags    []string
+	account string
+	amount  money.Money
+}
+
+// Write writes incomes and monthly payments of the passed months and the passed spends as transactions.
+// Incomes and Monthly Payments are dated by the first day of a month
+func Write(w io.Writer, opts Options, months []db.Month, spends []db.Spend, spendTypes []db.SpendType) error {
+	if opts.Account == "" {
+		return errors.New("account can't be empty")
+	}
+	if opts.Format == Beancount && opts.Currency == "" {
+		return errors.New("currency is required for Beancount")
+	}
+
+	accounts := newAccountBuilder(spendTypes)
+
+	var txs []transaction
+	for _, m := range months {
+		date := time.Date(m.Year, m.Month, 1, 0, 0, 0, 0, time.UTC)
+
+		for _, in := range m.Incomes {
+			txs = append(txs, transaction{
+				date:    date,
+				title:   in.Title,
+				notes:   in.Notes,
+				tags:    extractTags(in.Notes),
+				account: accounts.Income(in.Title),
+				amount:  -in.Income,
+			})
+		}
+		for _, mp := range m.MonthlyPayments {
+			txs = append(txs, transaction{
+				date:    date,
+				title:   mp.Title,
+				notes:   mp.Notes,
+				tags:    extractTags(mp.Notes),
+				account: accounts.Expenses(mp.Type),
+				amount:  mp.Cost,
+			})
+		}
+	}
+	for _, s := range spends {
+		txs = append(txs, transaction{
+			date:    time.Date(s.Year, s.Month, s.Day, 0, 0, 0, 0, time.UTC),
+			title:   s.Title,
+			notes:   s.Notes,
+			tags:    extractTags(s.Notes),
+			account: accounts.Expenses(s.Type),
+			amount:  s.Cost,
+		})
+	}
+	sort.SliceStable(txs, func(i, j int) bool {
+		return txs[i].date.Before(txs[j].date)
+	})
+
+	buf := bufio.NewWriter(w)
+	switch opts.Format {
+	case Ledger:
+		writeLedger(buf, opts, txs)
+	case Beancount:
+		writeBeancount(buf, opts, txs)
+	default:
+		return errors.Errorf("unknown format: %d", opts.Format)
+	}
+	return buf.Flush()
+}
+
+// writeLedger writes transactions in the following format:
+//
+//	2020-07-01 * salary
+//	    ; notes #tag
+//	    ; tag:
+//	    Income:Salary    -2500.00 USD
+//	    Assets:Cash
+//
+// Tags are written as "tag:" because it is supported by both Ledger (as metadata) and hledger
+func writeLedger(w io.Writer, opts Options, txs []transaction) {
+	for i, tx := range txs {
+		if i != 0 {
+			fmt.Fprintln(w)
+		}
+
+		fmt.Fprintf(w, "%s * %s\n", tx.date.Format("2006-01-02"), singleLine(tx.title))
+		if tx.notes != "" {
+			fmt.Fprintf(w, "    ; %s\n", singleLine(tx.notes))
+		}
+		for _, tag := range tx.tags {
+			fmt.Fprintf(w, "    ; %s:\n", tag)
+		}
+		fmt.Fprintf(w, "    %s    %s\n", tx.account, formatAmount(tx.amount, opts.Currency))
+		fmt.Fprintf(w, "    %s\n", opts.Account)
+	}
+}
+
+// writeBeancount writes transactions in the following format:
+//
+//	2020-07-01 open Assets:Cash
+//	2020-07-01 open Income:Salary
+//
+//	2020-07-01 * "salary" #tag
+//	  notes: "notes #tag"
+//	  Income:Salary  -2500.00 USD
+//	  Assets:Cash
+//
+func writeBeancount(w io.Writer, opts Options, txs []transaction) {
+	if len(txs) == 0 {
+		return
+	}
+
+	// All accounts must be opened before the first usage
+	openDate := txs[0].date.Format("2006-01-02")
+	accounts := []string{opts.Account}
+	seen := map[string]bool{opts.Account: true}
+	for _, tx := range txs {
+		if !seen[tx.account] {
+			seen[tx.account] = true
+			accounts = append(accounts, tx.account)
+		}
+	}
+	sort.Strings(accounts[1:])
+	for _, account := range accounts {
+		fmt.Fprintf(w, "%s open %s\n", openDate, account)
+	}
+
+	for _, tx := range txs {
+		fmt.Fprintln(w)
+
+		fmt.Fprintf(w, "%s * %s", tx.date.Format("2006-01-02"), quote(tx.title))
+		for _, tag := range tx.tags {
+			fmt.Fprintf(w, " #%s", tag)
+		}
+		fmt.Fprintln(w)
+		if tx.notes != "" {
+			fmt.Fprintf(w, "  notes: %s\n", quote(tx.notes))
+		}
+		fmt.Fprintf(w, "  %s  %s\n", tx.account, formatAmount(tx.amount, opts.Currency))
+		fmt.Fprintf(w, "  %s\n", opts.Account)
+	}
+}
+
+func formatAmount(amount money.Money, currency string) string {
+	if currency == "" {
+		return amount.String()
+	}
+	return amount.String() + " " + currency
+}
+
+func singleLine(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+func quote(s string) string {
+	s = strings.ReplaceAll(singleLine(s), `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+//nolint:gochecknoglobals
+var tagRegexp = regexp.MustCompile(`(?:^|\s)#([\w/-]+)`)
+
+// extractTags returns unique tags from notes. Tags are words with prefix '#'
+func extractTags(notes string) (tags []string) {
+	seen := make(map[string]bool)
+	for _, match := range tagRegexp.FindAllStringSubmatch(notes, -1) {
+		tag := match[1]
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+	return tags
+}
+
+// accountBuilder builds account names. Names of Spend Types are converted to account names
+// with all parents: 'food' -> 'groceries' is converted to 'Expenses:Food:Groceries'
+type accountBuilder struct {
+	spendTypes map[uint]db.SpendType
+}
+
+func newAccountBuilder(spendTypes []db.SpendType) accountBuilder {
+	b := accountBuilder{
+		spendTypes: make(map[uint]db.SpendType, len(spendTypes)),
+	}
+	for _, t := range spendTypes {
+		b.spendTypes[t.ID] = t
+	}
+	return b
+}
+
+func (b accountBuilder) Income(title string) string {
+	return incomeAccount + ":" + accountComponent(title)
+}
+
+func (b accountBuilder) Expenses(spendType *db.SpendType) string {
+	if spendType == nil {
+		return expensesAccount + ":" + uncategorizedAccount
+	}
+
+	components := []string{accountComponent(spendType.Name)}
+	for parentID := spendType.ParentID; parentID != 0; {
+		parent, ok := b.spendTypes[parentID]
+		if !ok || len(components) > len(b.spendTypes) {
+			// Stop on an unknown type or on a cycle
+			break
+		}
+		components = append(components, accountComponent(parent.Name))
+		parentID = parent.ParentID
+	}
+
+	res := expensesAccount
+	for i := len(components) - 1; i >= 0; i-- {
+		res += ":" + components[i]
+	}
+	return res
+}
+
+// accountComponent converts a name to a valid account component: all words are capitalized
+// and joined with '-', other characters are removed
+func accountComponent(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	if len(words) == 0 {
+		return unknownAccount
+	}
+
+	for i, word := range words {
+		runes := []rune(word)
+		runes[0] = unicode.ToUpper(runes[0])
+		words[i] = string(runes)
+	}
+	return strings.Join(words, "-")
+}
@@ -0,0 +1,134 @@
+package ledger
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+func TestWrite(t *testing.T) {
+	t.Parallel()
+
+	spendTypes := []db.SpendType{
+		{ID: 1, Name: "food"},
+		{ID: 2, Name: "groceries", ParentID: 1},
+		{ID: 3, Name: "house & utilities"},
+	}
+	months := []db.Month{
+		{
+			MonthOverview: db.MonthOverview{Year: 2020, Month: 7},
+			Incomes: []db.Income{
+				{Title: "salary", Notes: "july #work", Income: money.FromInt(2500)},
+			},
+			MonthlyPayments: []db.MonthlyPayment{
+				{Title: "rent", Type: &spendTypes[2], Cost: money.FromInt(1000)},
+			},
+		},
+	}
+	spends := []db.Spend{
+		{Year: 2020, Month: 7, Day: 5, Title: `"lidl"`, Type: &spendTypes[1], Notes: "#food #food", Cost: money.FromFloat(20.5)},
+		{Year: 2020, Month: 7, Day: 3, Title: "book", Cost: money.FromInt(7)},
+	}
+
+	tests := []struct {
+		desc string
+		opts Options
+		want string
+	}{
+		{
+			desc: "ledger",
+			opts: Options{Format: Ledger, Account: "Assets:Cash"},
+			want: `2020-07-01 * salary
+    ; july #work
+    ; work:
+    Income:Salary    -2500.00
+    Assets:Cash
+
+2020-07-01 * rent
+    Expenses:House-Utilities    1000.00
+    Assets:Cash
+
+2020-07-03 * book
+    Expenses:Uncategorized    7.00
+    Assets:Cash
+
+2020-07-05 * "lidl"
+    ; #food #food
+    ; food:
+    Expenses:Food:Groceries    20.50
+    Assets:Cash
+`,
+		},
+		{
+			desc: "beancount",
+			opts: Options{Format: Beancount, Currency: "USD", Account: "Assets:Cash"},
+			want: `2020-07-01 open Assets:Cash
+2020-07-01 open Expenses:Food:Groceries
+2020-07-01 open Expenses:House-Utilities
+2020-07-01 open Expenses:Uncategorized
+2020-07-01 open Income:Salary
+
+2020-07-01 * "salary" #work
+  notes: "july #work"
+  Income:Salary  -2500.00 USD
+  Assets:Cash
+
+2020-07-01 * "rent"
+  Expenses:House-Utilities  1000.00 USD
+  Assets:Cash
+
+2020-07-03 * "book"
+  Expenses:Uncategorized  7.00 USD
+  Assets:Cash
+
+2020-07-05 * "\"lidl\"" #food
+  notes: "#food #food"
+  Expenses:Food:Groceries  20.50 USD
+  Assets:Cash
+`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			t.Parallel()
+
+			buf := &bytes.Buffer{}
+			err := Write(buf, tt.opts, months, spends, spendTypes)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestWrite_InvalidOptions(t *testing.T) {
+	t.Parallel()
+
+	err := Write(&bytes.Buffer{}, Options{Format: Ledger}, nil, nil, nil)
+	require.EqualError(t, err, "account can't be empty")
+
+	err = Write(&bytes.Buffer{}, Options{Format: Beancount, Account: "Assets:Cash"}, nil, nil, nil)
+	require.EqualError(t, err, "currency is required for Beancount")
+}
+
+func TestAccountComponent(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name string
+		want string
+	}{
+		{name: "food", want: "Food"},
+		{name: "fast food", want: "Fast-Food"},
+		{name: "  house & utilities ", want: "House-Utilities"},
+		{name: "продукты", want: "Продукты"},
+		{name: "2fa keys", want: "2fa-Keys"},
+		{name: "!!!", want: "Unknown"},
+	} {
+		require.Equal(t, tt.want, accountComponent(tt.name), "name: %q", tt.name)
+	}
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type GetAPITokensResp struct {
+	BaseResponse
+
+	APITokens []db.APIToken `json:"api_tokens"`
+}
+
+type AddAPITokenReq struct {
+	BaseRequest
+
+	Name string `json:"name" validate:"required" example:"backup script"`
+	// ReadOnly tokens can be used only for GET and HEAD requests
+	ReadOnly bool `json:"read_only"`
+	// Endpoints is a list of API paths the token can be used for. Nested paths are allowed too.
+	// Empty list means all API endpoints
+	Endpoints []string `json:"endpoints" example:"/api/spends"`
+}
+
+func (req *AddAPITokenReq) SanitizeAndCheck() error {
+	sanitizeString(&req.Name)
+	for i := range req.Endpoints {
+		sanitizeString(&req.Endpoints[i])
+		req.Endpoints[i] = strings.TrimSuffix(req.Endpoints[i], "/")
+	}
+
+	var errs fieldErrors
+	if req.Name == "" {
+		errs.add(emptyFieldError("name"))
+	}
+	for _, endpoint := range req.Endpoints {
+		if !strings.HasPrefix(endpoint, "/api/") {
+			errs.add(invalidFieldError("endpoints", "invalid endpoint %q: it must start with '/api/'", endpoint))
+		}
+	}
+	return errs.toError()
+}
+
+type AddAPITokenResp struct {
+	BaseResponse
+
+	ID uint `json:"id"`
+	// Token is returned only once, after creation
+	Token string `json:"token"`
+}
+
+type RemoveAPITokenReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *RemoveAPITokenReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
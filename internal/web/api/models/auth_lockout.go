@@ -0,0 +1,25 @@
+package models
+
+import (
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type GetAuthLockoutsResp struct {
+	BaseResponse
+
+	AuthLockouts []db.AuthLockout `json:"auth_lockouts"`
+}
+
+type RemoveAuthLockoutReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *RemoveAuthLockoutReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
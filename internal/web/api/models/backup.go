@@ -0,0 +1,33 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type RestoreBackupReq struct {
+	BaseRequest
+
+	db.Backup
+}
+
+func (req *RestoreBackupReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if err := req.Backup.Validate(); err != nil {
+		field := "backup"
+		var backupErr *db.BackupError
+		if errors.As(err, &backupErr) {
+			field = backupErr.Field
+		}
+		errs.add(invalidFieldError(field, "invalid backup: %s", err))
+	}
+	return errs.toError()
+}
+
+type RestoreBackupResp struct {
+	BaseResponse
+
+	// Stats contains the number of restored entities
+	Stats db.BackupStats `json:"stats"`
+}
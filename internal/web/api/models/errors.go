@@ -0,0 +1,151 @@
+package models
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+// ErrorCode is a machine-readable code of an error. Codes are stable and can be used by clients,
+// unlike error messages
+type ErrorCode string
+
+// General error codes. They are used for errors without a more specific code
+const (
+	ErrorCodeInternal         ErrorCode = "internal_error"
+	ErrorCodeInvalidRequest   ErrorCode = "invalid_request"
+	ErrorCodeValidationFailed ErrorCode = "validation_failed"
+	ErrorCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrorCodeForbidden        ErrorCode = "forbidden"
+	ErrorCodeNotFound         ErrorCode = "not_found"
+	ErrorCodeMethodNotAllowed ErrorCode = "method_not_allowed"
+	ErrorCodeConflict         ErrorCode = "conflict"
+	ErrorCodeTooManyRequests  ErrorCode = "too_many_requests"
+)
+
+// Specific error codes
+const (
+	ErrorCodeMonthNotFound          ErrorCode = "month_not_found"
+	ErrorCodeDayNotFound            ErrorCode = "day_not_found"
+	ErrorCodeIncomeNotFound         ErrorCode = "income_not_found"
+	ErrorCodeMonthlyPaymentNotFound ErrorCode = "monthly_payment_not_found"
+	ErrorCodeSpendNotFound          ErrorCode = "spend_not_found"
+	ErrorCodeSpendTypeNotFound      ErrorCode = "spend_type_not_found"
+	ErrorCodeSpendTypeIsUsed        ErrorCode = "spend_type_is_used"
+	ErrorCodeSpendRuleNotFound      ErrorCode = "spend_rule_not_found"
+	ErrorCodeSavedSearchNotFound    ErrorCode = "saved_search_not_found"
+	ErrorCodeWebhookNotFound        ErrorCode = "webhook_not_found"
+	ErrorCodeAPITokenNotFound       ErrorCode = "api_token_not_found"
+	ErrorCodeAuthLockoutNotFound    ErrorCode = "auth_lockout_not_found"
+	ErrorCodeVersionConflict        ErrorCode = "version_conflict"
+	ErrorCodeIdempotencyKeyInFlight ErrorCode = "idempotency_key_in_flight"
+	ErrorCodeIdempotencyKeyReused   ErrorCode = "idempotency_key_reused"
+	ErrorCodeCSRFCheckFailed        ErrorCode = "csrf_check_failed"
+)
+
+//nolint:gochecknoglobals
+var dbErrorCodes = []struct {
+	err  error
+	code ErrorCode
+}{
+	{db.ErrMonthNotExist, ErrorCodeMonthNotFound},
+	{db.ErrDayNotExist, ErrorCodeDayNotFound},
+	{db.ErrIncomeNotExist, ErrorCodeIncomeNotFound},
+	{db.ErrMonthlyPaymentNotExist, ErrorCodeMonthlyPaymentNotFound},
+	{db.ErrSpendNotExist, ErrorCodeSpendNotFound},
+	{db.ErrSpendTypeNotExist, ErrorCodeSpendTypeNotFound},
+	{db.ErrSpendTypeIsUsed, ErrorCodeSpendTypeIsUsed},
+	{db.ErrSpendRuleNotExist, ErrorCodeSpendRuleNotFound},
+	{db.ErrSavedSearchNotExist, ErrorCodeSavedSearchNotFound},
+	{db.ErrWebhookNotExist, ErrorCodeWebhookNotFound},
+	{db.ErrAPITokenNotExist, ErrorCodeAPITokenNotFound},
+	{db.ErrAuthLockoutNotExist, ErrorCodeAuthLockoutNotFound},
+	{db.ErrVersionConflict, ErrorCodeVersionConflict},
+}
+
+// GetErrorCode returns a specific code of the error. It returns false if there's no such code
+func GetErrorCode(err error) (ErrorCode, bool) {
+	var validationErr *ValidationError
+	if errors.As(err, &validationErr) {
+		return ErrorCodeValidationFailed, true
+	}
+	for _, c := range dbErrorCodes {
+		if errors.Is(err, c.err) {
+			return c.code, true
+		}
+	}
+	return "", false
+}
+
+// FieldErrorCode is a machine-readable code of a field error
+type FieldErrorCode string
+
+const (
+	FieldErrorCodeRequired    FieldErrorCode = "required"
+	FieldErrorCodeNotPositive FieldErrorCode = "not_positive"
+	FieldErrorCodeNegative    FieldErrorCode = "negative"
+	FieldErrorCodeInvalid     FieldErrorCode = "invalid"
+)
+
+// FieldError describes an invalid field of a request
+type FieldError struct {
+	// Field is a name of the field. Fields of nested objects are separated by dots
+	Field   string         `json:"field" example:"title"`
+	Code    FieldErrorCode `json:"code" swaggertype:"string" enums:"required,not_positive,negative,invalid"`
+	Message string         `json:"message" example:"title can't be empty"`
+}
+
+func (e FieldError) Error() string {
+	return e.Message
+}
+
+// ValidationError is returned by 'SanitizeAndCheck' methods. It contains errors of all invalid fields
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.Message)
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// fieldErrors is used to collect errors of all invalid fields
+type fieldErrors []FieldError
+
+func (errs *fieldErrors) add(err FieldError) {
+	*errs = append(*errs, err)
+}
+
+// addNested adds errors of a nested object. Their field names are prefixed with the field name of the object
+func (errs *fieldErrors) addNested(fieldName string, err error) {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		if err != nil {
+			errs.add(invalidFieldError(fieldName, "%s", err))
+		}
+		return
+	}
+	for _, f := range validationErr.Fields {
+		if fieldName != "" {
+			f.Field = fieldName + "." + f.Field
+		}
+		errs.add(f)
+	}
+}
+
+// NewInvalidFieldError returns a validation error of a field that can't be checked by 'SanitizeAndCheck'.
+// For example, a query can be parsed only with Spend Types from the db
+func NewInvalidFieldError(fieldName string, err error) error {
+	return &ValidationError{Fields: []FieldError{invalidFieldError(fieldName, "%s", err)}}
+}
+
+func (errs fieldErrors) toError() error {
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ValidationError{Fields: errs}
+}
@@ -0,0 +1,46 @@
+package models
+
+import "strings"
+
+// ExportLedgerReq is used to export data in plain-text accounting formats. Spends are filtered with
+// the search args, Incomes and Monthly Payments are filtered only by the date range. Sort and Order are ignored
+type ExportLedgerReq struct {
+	SearchSpendsReq
+
+	// Format is a format of the export. 'ledger' is also supported by hledger
+	Format string `json:"format" enums:"ledger,beancount" default:"ledger"`
+	// Currency is added to all amounts. It is required for 'beancount'
+	Currency string `json:"currency" example:"USD"`
+	// Account receives Incomes and pays for Monthly Payments and Spends
+	Account string `json:"account" default:"Assets:Cash"`
+}
+
+func (req *ExportLedgerReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	errs.addNested("", req.SearchSpendsReq.SanitizeAndCheck())
+
+	sanitizeString(&req.Format)
+	sanitizeString(&req.Currency)
+	sanitizeString(&req.Account)
+
+	switch req.Format {
+	case "":
+		req.Format = "ledger"
+	case "ledger", "beancount":
+	default:
+		errs.add(invalidFieldError("format", "invalid format"))
+	}
+	if req.Format == "beancount" && req.Currency == "" {
+		errs.add(newFieldError("currency", FieldErrorCodeRequired, "currency is required for beancount format"))
+	}
+	if strings.ContainsAny(req.Currency, " \t") {
+		errs.add(invalidFieldError("currency", "invalid currency"))
+	}
+	if req.Account == "" {
+		req.Account = "Assets:Cash"
+	}
+	if strings.ContainsAny(req.Account, " \t") {
+		errs.add(invalidFieldError("account", "invalid account"))
+	}
+	return errs.toError()
+}
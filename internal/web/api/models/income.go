@@ -1,5 +1,7 @@
 package models
 
+import "github.com/ShoshinNikita/budget-manager/internal/db"
+
 type AddIncomeReq struct {
 	BaseRequest
 
@@ -13,17 +15,18 @@ func (req *AddIncomeReq) SanitizeAndCheck() error {
 	sanitizeString(&req.Title)
 	sanitizeString(&req.Notes)
 
+	var errs fieldErrors
 	if req.MonthID == 0 {
-		return emptyOrZeroFieldError("month_id")
+		errs.add(emptyOrZeroFieldError("month_id"))
 	}
 	if req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Notes
 	if req.Income <= 0 {
-		return notPositiveFieldError("income")
+		errs.add(notPositiveFieldError("income"))
 	}
-	return nil
+	return errs.toError()
 }
 
 type AddIncomeResp struct {
@@ -39,23 +42,27 @@ type EditIncomeReq struct {
 	Title  *string  `json:"title"`
 	Notes  *string  `json:"notes"`
 	Income *float64 `json:"income"`
+	// Version is an expected version of the record. 'If-Match' header can be used instead
+	Version *int `json:"version" example:"1"`
 }
 
 func (req *EditIncomeReq) SanitizeAndCheck() error {
 	sanitizeString(req.Title)
 	sanitizeString(req.Notes)
 
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
 	if req.Title != nil && *req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Notes
 	if req.Income != nil && *req.Income <= 0 {
-		return notPositiveFieldError("income")
+		errs.add(notPositiveFieldError("income"))
 	}
-	return nil
+	checkVersion(&errs, req.Version)
+	return errs.toError()
 }
 
 type RemoveIncomeReq struct {
@@ -65,8 +72,56 @@ type RemoveIncomeReq struct {
 }
 
 func (req *RemoveIncomeReq) SanitizeAndCheck() error {
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type GetIncomeReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetIncomeReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type EditIncomeResp struct {
+	BaseResponse
+
+	// Version is a new version of the Income
+	Version int `json:"version"`
+}
+
+type GetIncomeResp struct {
+	BaseResponse
+
+	Income db.Income `json:"income"`
+}
+
+type GetIncomesReq struct {
+	BaseRequest
+
+	MonthID uint `json:"month_id" validate:"required" example:"1"`
+}
+
+func (req *GetIncomesReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.MonthID == 0 {
+		errs.add(emptyOrZeroFieldError("month_id"))
 	}
-	return nil
+	return errs.toError()
+}
+
+type GetIncomesResp struct {
+	BaseResponse
+
+	Incomes []db.Income `json:"incomes"`
 }
@@ -2,10 +2,10 @@
 package models
 
 import (
-	"errors"
 	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 )
 
 // All requests implement the Request interface
@@ -31,12 +31,21 @@ type BaseResponse struct {
 	Success   bool   `json:"success"`
 	// Error is specified only when success if false
 	Error string `json:"error,omitempty"`
+	// ErrorCode is a machine-readable code of the error. It is specified only when success if false
+	ErrorCode ErrorCode `json:"error_code,omitempty" swaggertype:"string" enums:"internal_error,invalid_request,validation_failed,unauthorized,forbidden,not_found,method_not_allowed,conflict,too_many_requests,month_not_found,day_not_found,income_not_found,monthly_payment_not_found,spend_not_found,spend_type_not_found,spend_type_is_used,spend_rule_not_found,saved_search_not_found,webhook_not_found,api_token_not_found,auth_lockout_not_found,version_conflict,idempotency_key_in_flight,idempotency_key_reused,csrf_check_failed"` //nolint:lll
+	// FieldErrors contains errors of all invalid fields. It is specified only for 'validation_failed' error code
+	FieldErrors []FieldError `json:"field_errors,omitempty"`
 }
 
 func (r *BaseResponse) SetBaseResponse(newResp BaseResponse) {
 	*r = newResp
 }
 
+// ErrorResp is used to describe error responses in the API documentation
+type ErrorResp struct {
+	BaseResponse
+}
+
 // -------------------------------------------------
 // Month
 // -------------------------------------------------
@@ -49,13 +58,14 @@ type GetMonthByDateReq struct {
 }
 
 func (req *GetMonthByDateReq) SanitizeAndCheck() error {
+	var errs fieldErrors
 	if req.Year == 0 {
-		return emptyOrZeroFieldError("year")
+		errs.add(emptyOrZeroFieldError("year"))
 	}
 	if !(time.January <= req.Month && req.Month <= time.December) {
-		return errors.New("invalid month")
+		errs.add(invalidFieldError("month", "invalid month"))
 	}
-	return nil
+	return errs.toError()
 }
 
 type GetMonthResp struct {
@@ -63,3 +73,51 @@ type GetMonthResp struct {
 
 	Month db.Month `json:"month"`
 }
+
+// GetMonthsReq is used to get overviews of months which first day is within the date range
+type GetMonthsReq struct {
+	BaseRequest
+
+	// From must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8)
+	From time.Time `json:"from" format:"date"`
+	// To must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8)
+	To time.Time `json:"to" format:"date"`
+}
+
+func (req *GetMonthsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if !req.From.IsZero() && !req.To.IsZero() && req.From.After(req.To) {
+		errs.add(invalidFieldError("from", "from can't be after to"))
+	}
+	return errs.toError()
+}
+
+type GetMonthsResp struct {
+	BaseResponse
+
+	Months []db.MonthOverview `json:"months"`
+	Total  MonthsTotal        `json:"total"`
+}
+
+// MonthsTotal contains aggregated values of months
+type MonthsTotal struct {
+	TotalIncome money.Money `json:"total_income" swaggertype:"number"`
+	// TotalSpend is a cost of all Monthly Payments and Spends
+	TotalSpend money.Money `json:"total_spend" swaggertype:"number"`
+	// Result is TotalIncome - TotalSpend
+	Result money.Money `json:"result" swaggertype:"number"`
+}
+
+type GetMonthEventsReq struct {
+	BaseRequest
+
+	MonthID uint `json:"month_id" validate:"required" example:"1"`
+}
+
+func (req *GetMonthEventsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.MonthID == 0 {
+		errs.add(emptyOrZeroFieldError("month_id"))
+	}
+	return errs.toError()
+}
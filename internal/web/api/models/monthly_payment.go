@@ -1,5 +1,7 @@
 package models
 
+import "github.com/ShoshinNikita/budget-manager/internal/db"
+
 type AddMonthlyPaymentReq struct {
 	BaseRequest
 
@@ -9,24 +11,28 @@ type AddMonthlyPaymentReq struct {
 	TypeID uint    `json:"type_id"`
 	Notes  string  `json:"notes"`
 	Cost   float64 `json:"cost" validate:"required" example:"1500"`
+	// DueDay is a day of month when the payment is due. It is optional
+	DueDay uint `json:"due_day" example:"5"`
 }
 
 func (req *AddMonthlyPaymentReq) SanitizeAndCheck() error {
 	sanitizeString(&req.Title)
 	sanitizeString(&req.Notes)
 
+	var errs fieldErrors
 	if req.MonthID == 0 {
-		return emptyOrZeroFieldError("month_id")
+		errs.add(emptyOrZeroFieldError("month_id"))
 	}
 	if req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Type
 	// Skip Notes
 	if req.Cost <= 0 {
-		return notPositiveFieldError("cost")
+		errs.add(notPositiveFieldError("cost"))
 	}
-	return nil
+	checkDueDay(&errs, req.DueDay)
+	return errs.toError()
 }
 
 type AddMonthlyPaymentResp struct {
@@ -43,24 +49,33 @@ type EditMonthlyPaymentReq struct {
 	TypeID *uint    `json:"type_id"`
 	Notes  *string  `json:"notes"`
 	Cost   *float64 `json:"cost"`
+	// DueDay can be set to 0 to remove the due day
+	DueDay *uint `json:"due_day"`
+	// Version is an expected version of the record. 'If-Match' header can be used instead
+	Version *int `json:"version" example:"1"`
 }
 
 func (req *EditMonthlyPaymentReq) SanitizeAndCheck() error {
 	sanitizeString(req.Title)
 	sanitizeString(req.Notes)
 
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
 	if req.Title != nil && *req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Type
 	// Skip Notes
 	if req.Cost != nil && *req.Cost <= 0 {
-		return notPositiveFieldError("cost")
+		errs.add(notPositiveFieldError("cost"))
+	}
+	if req.DueDay != nil {
+		checkDueDay(&errs, *req.DueDay)
 	}
-	return nil
+	checkVersion(&errs, req.Version)
+	return errs.toError()
 }
 
 type RemoveMonthlyPaymentReq struct {
@@ -70,8 +85,84 @@ type RemoveMonthlyPaymentReq struct {
 }
 
 func (req *RemoveMonthlyPaymentReq) SanitizeAndCheck() error {
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+const maxCalendarMonths = 12
+
+// GetMonthlyPaymentsCalendarReq is used to get the calendar feed of Monthly Payments
+type GetMonthlyPaymentsCalendarReq struct {
+	BaseRequest
+
+	// Months is a number of months in the feed starting from the current one
+	Months int `json:"months" default:"2" minimum:"1" maximum:"12"`
+}
+
+func (req *GetMonthlyPaymentsCalendarReq) SanitizeAndCheck() error {
+	if req.Months == 0 {
+		req.Months = 2
+	}
+
+	var errs fieldErrors
+	if req.Months < 1 || req.Months > maxCalendarMonths {
+		errs.add(invalidFieldError("months", "months must be in range [1, 12]"))
 	}
-	return nil
+	return errs.toError()
+}
+
+func checkDueDay(errs *fieldErrors, dueDay uint) {
+	if dueDay > 31 {
+		errs.add(invalidFieldError("due_day", "due_day must be in range [1, 31]"))
+	}
+}
+
+type GetMonthlyPaymentReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetMonthlyPaymentReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type EditMonthlyPaymentResp struct {
+	BaseResponse
+
+	// Version is a new version of the Monthly Payment
+	Version int `json:"version"`
+}
+
+type GetMonthlyPaymentResp struct {
+	BaseResponse
+
+	MonthlyPayment db.MonthlyPayment `json:"monthly_payment"`
+}
+
+type GetMonthlyPaymentsReq struct {
+	BaseRequest
+
+	MonthID uint `json:"month_id" validate:"required" example:"1"`
+}
+
+func (req *GetMonthlyPaymentsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.MonthID == 0 {
+		errs.add(emptyOrZeroFieldError("month_id"))
+	}
+	return errs.toError()
+}
+
+type GetMonthlyPaymentsResp struct {
+	BaseResponse
+
+	MonthlyPayments []db.MonthlyPayment `json:"monthly_payments"`
 }
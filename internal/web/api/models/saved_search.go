@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type GetSavedSearchesResp struct {
+	BaseResponse
+
+	SavedSearches []db.SavedSearch `json:"saved_searches"`
+}
+
+type GetSavedSearchReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetSavedSearchReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type GetSavedSearchResp struct {
+	BaseResponse
+
+	SavedSearch db.SavedSearch `json:"saved_search"`
+}
+
+type AddSavedSearchReq struct {
+	BaseRequest
+
+	Name string `json:"name" validate:"required" example:"Coffee"`
+	// Pinned Saved Searches are shown on the months page
+	Pinned bool `json:"pinned"`
+	// Search contains the same args as the Spend search. Query is parsed when a Saved Search is saved
+	Search SearchSpendsReq `json:"search"`
+}
+
+func (req *AddSavedSearchReq) SanitizeAndCheck() error {
+	sanitizeString(&req.Name)
+
+	var errs fieldErrors
+	if req.Name == "" {
+		errs.add(emptyFieldError("name"))
+	}
+	errs.addNested("search", req.Search.SanitizeAndCheck())
+	return errs.toError()
+}
+
+type AddSavedSearchResp struct {
+	BaseResponse
+
+	ID uint `json:"id"`
+}
+
+type EditSavedSearchReq struct {
+	BaseRequest
+
+	ID     uint             `json:"id" validate:"required" example:"1"`
+	Name   *string          `json:"name" example:"Coffee"`
+	Pinned *bool            `json:"pinned"`
+	Search *SearchSpendsReq `json:"search"`
+}
+
+func (req *EditSavedSearchReq) SanitizeAndCheck() error {
+	sanitizeString(req.Name)
+
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	if req.Name != nil && *req.Name == "" {
+		errs.add(emptyFieldError("name"))
+	}
+	if req.Search != nil {
+		errs.addNested("search", req.Search.SanitizeAndCheck())
+	}
+	return errs.toError()
+}
+
+type RemoveSavedSearchReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *RemoveSavedSearchReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+// GetSavedSearchReportsReq is used to get reports of pinned Saved Searches. The current month is used by default
+type GetSavedSearchReportsReq struct {
+	BaseRequest
+
+	Year  int        `json:"year" example:"2020"`
+	Month time.Month `json:"month" swaggertype:"integer" example:"7"`
+}
+
+func (req *GetSavedSearchReportsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if (req.Year == 0) != (req.Month == 0) {
+		errs.add(invalidFieldError("month", "year and month must be passed together"))
+	}
+	if req.Month != 0 && !(time.January <= req.Month && req.Month <= time.December) {
+		errs.add(invalidFieldError("month", "invalid month"))
+	}
+	if req.Year < 0 {
+		errs.add(invalidFieldError("year", "invalid year"))
+	}
+	return errs.toError()
+}
+
+type GetSavedSearchReportsResp struct {
+	BaseResponse
+
+	Reports []db.SavedSearchReport `json:"reports"`
+}
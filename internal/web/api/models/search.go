@@ -1,10 +1,10 @@
 package models
 
 import (
-	"fmt"
 	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 )
 
 // SearchSpendsReq is used to search for spends
@@ -15,6 +15,9 @@ type SearchSpendsReq struct {
 	Title string `json:"title"`
 	// Notes can be in any case. Search will be performed by lowercased value
 	Notes string `json:"notes"`
+	// Text is a full-text query. Spends match if their titles or notes contain all words of the query.
+	// Words are compared by their stems, for example, 'coffee' matches 'Coffees'
+	Text string `json:"text"`
 
 	// TitleExactly defines should we search exactly for the given title
 	TitleExactly bool `json:"title_exactly" default:"false"`
@@ -32,27 +35,176 @@ type SearchSpendsReq struct {
 	// TypeIDs is a list of Spend Type ids to search for. Use id '0' to search for Spends without type
 	TypeIDs []uint `json:"type_ids"`
 
-	// Sort specify field to sort by
-	Sort string `json:"sort" enums:"title,cost,date" default:"date"`
+	// Sort specify field to sort by. Spends are sorted by relevance by default if Text is passed.
+	// The most relevant Spends go first, Order is ignored
+	Sort string `json:"sort" enums:"title,cost,date,relevance" default:"date"`
 	// Order specify sort order
 	Order string `json:"order" enums:"asc,desc" default:"asc"`
+
+	// Limit is a max number of returned Spends. Zero means no limit
+	Limit int `json:"limit" minimum:"0"`
+	// Offset is a number of Spends to skip. It can be used only with Limit
+	Offset int `json:"offset" minimum:"0"`
+
+	// Query is a search query, for example: 'title:coffee -title:decaf cost>5 type:"Food/Cafe"'.
+	// It is combined with other args. See the README for the syntax
+	Query string `json:"query"`
 }
 
 func (req *SearchSpendsReq) SanitizeAndCheck() error {
 	sanitizeString(&req.Title)
 	sanitizeString(&req.Notes)
+	sanitizeString(&req.Text)
 	sanitizeString(&req.Sort)
 	sanitizeString(&req.Order)
+	sanitizeString(&req.Query)
 
+	var errs fieldErrors
 	if req.MinCost != 0 && req.MaxCost != 0 && req.MinCost > req.MaxCost {
-		return fmt.Errorf("min_cost can't be greater than max_cost")
+		errs.add(invalidFieldError("min_cost", "min_cost can't be greater than max_cost"))
 	}
-
-	return nil
+	if req.Limit < 0 {
+		errs.add(newFieldError("limit", FieldErrorCodeNegative, "limit can't be negative"))
+	}
+	if req.Offset < 0 {
+		errs.add(newFieldError("offset", FieldErrorCodeNegative, "offset can't be negative"))
+	}
+	if req.Offset != 0 && req.Limit == 0 {
+		errs.add(invalidFieldError("offset", "offset can't be used without limit"))
+	}
+	return errs.toError()
 }
 
 type SearchSpendsResp struct {
 	BaseResponse
 
 	Spends []db.Spend `json:"spends"`
+	// Total contains info about all found Spends regardless of Limit and Offset
+	Total db.SearchSpendsTotal `json:"total"`
+}
+
+// SearchIncomesReq is used to search for incomes
+type SearchIncomesReq struct {
+	BaseRequest
+
+	// Title can be in any case. Search will be performed by lowercased value
+	Title string `json:"title"`
+	// Notes can be in any case. Search will be performed by lowercased value
+	Notes string `json:"notes"`
+
+	// TitleExactly defines should we search exactly for the given title
+	TitleExactly bool `json:"title_exactly" default:"false"`
+	// NotesExactly defines should we search exactly for the given notes
+	NotesExactly bool `json:"notes_exactly" default:"false"`
+
+	// After must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8).
+	// A month matches if its first day is within the range
+	After time.Time `json:"after" format:"date"`
+	// Before must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8)
+	Before time.Time `json:"before" format:"date"`
+
+	MinIncome float64 `json:"min_income"`
+	MaxIncome float64 `json:"max_income"`
+
+	// Order specify sort order. Incomes are sorted by date
+	Order string `json:"order" enums:"asc,desc" default:"asc"`
+}
+
+func (req *SearchIncomesReq) SanitizeAndCheck() error {
+	sanitizeString(&req.Title)
+	sanitizeString(&req.Notes)
+	sanitizeString(&req.Order)
+
+	var errs fieldErrors
+	if req.MinIncome != 0 && req.MaxIncome != 0 && req.MinIncome > req.MaxIncome {
+		errs.add(invalidFieldError("min_income", "min_income can't be greater than max_income"))
+	}
+	return errs.toError()
+}
+
+type SearchIncomesResp struct {
+	BaseResponse
+
+	Incomes []db.Income `json:"incomes"`
+}
+
+// SearchMonthlyPaymentsReq is used to search for monthly payments
+type SearchMonthlyPaymentsReq struct {
+	BaseRequest
+
+	// Title can be in any case. Search will be performed by lowercased value
+	Title string `json:"title"`
+	// Notes can be in any case. Search will be performed by lowercased value
+	Notes string `json:"notes"`
+
+	// TitleExactly defines should we search exactly for the given title
+	TitleExactly bool `json:"title_exactly" default:"false"`
+	// NotesExactly defines should we search exactly for the given notes
+	NotesExactly bool `json:"notes_exactly" default:"false"`
+
+	// After must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8).
+	// A month matches if its first day is within the range
+	After time.Time `json:"after" format:"date"`
+	// Before must be in the RFC3339 format (https://tools.ietf.org/html/rfc3339#section-5.8)
+	Before time.Time `json:"before" format:"date"`
+
+	MinCost float64 `json:"min_cost"`
+	MaxCost float64 `json:"max_cost"`
+
+	// TypeIDs is a list of Spend Type ids to search for. Use id '0' to search for Monthly Payments without type
+	TypeIDs []uint `json:"type_ids"`
+
+	// Order specify sort order. Monthly Payments are sorted by date
+	Order string `json:"order" enums:"asc,desc" default:"asc"`
+}
+
+func (req *SearchMonthlyPaymentsReq) SanitizeAndCheck() error {
+	sanitizeString(&req.Title)
+	sanitizeString(&req.Notes)
+	sanitizeString(&req.Order)
+
+	var errs fieldErrors
+	if req.MinCost != 0 && req.MaxCost != 0 && req.MinCost > req.MaxCost {
+		errs.add(invalidFieldError("min_cost", "min_cost can't be greater than max_cost"))
+	}
+	return errs.toError()
+}
+
+type SearchMonthlyPaymentsResp struct {
+	BaseResponse
+
+	MonthlyPayments []db.MonthlyPayment `json:"monthly_payments"`
+}
+
+// SearchAllReq is used to search for Incomes, Monthly Payments and Spends at once
+type SearchAllReq struct {
+	// SearchMonthlyPaymentsReq contains the same filters. MinCost and MaxCost are compared
+	// with incomes too. Incomes are skipped if TypeIDs is specified because they don't have types
+	SearchMonthlyPaymentsReq
+}
+
+// MoneyMovement is an Income, a Monthly Payment or a Spend
+type MoneyMovement struct {
+	Kind string `json:"kind" enums:"income,monthly_payment,spend"`
+	ID   uint   `json:"id"`
+
+	Year  int        `json:"year"`
+	Month time.Month `json:"month" swaggertype:"integer"`
+	// Day is specified only for Spends
+	Day int `json:"day,omitempty"`
+
+	Title string        `json:"title"`
+	Type  *db.SpendType `json:"type,omitempty"`
+	Notes string        `json:"notes,omitempty"`
+	// Amount is positive for Incomes and negative for Monthly Payments and Spends
+	Amount money.Money `json:"amount" swaggertype:"number"`
+}
+
+type SearchAllResp struct {
+	BaseResponse
+
+	// MoneyMovements are sorted by date. Incomes and Monthly Payments go before Spends of the same month
+	MoneyMovements []MoneyMovement `json:"money_movements"`
+	// Total is a sum of all amounts
+	Total money.Money `json:"total" swaggertype:"number"`
 }
@@ -1,5 +1,7 @@
 package models
 
+import "github.com/ShoshinNikita/budget-manager/internal/db"
+
 type AddSpendReq struct {
 	BaseRequest
 
@@ -15,18 +17,19 @@ func (req *AddSpendReq) SanitizeAndCheck() error {
 	sanitizeString(&req.Title)
 	sanitizeString(&req.Notes)
 
+	var errs fieldErrors
 	if req.DayID == 0 {
-		return emptyOrZeroFieldError("day_id")
+		errs.add(emptyOrZeroFieldError("day_id"))
 	}
 	if req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Type
 	// Skip Notes
 	if req.Cost < 0 {
-		return negativeFieldError("cost")
+		errs.add(negativeFieldError("cost"))
 	}
-	return nil
+	return errs.toError()
 }
 
 type AddSpendResp struct {
@@ -43,24 +46,28 @@ type EditSpendReq struct {
 	TypeID *uint    `json:"type_id"`
 	Notes  *string  `json:"notes"`
 	Cost   *float64 `json:"cost"`
+	// Version is an expected version of the record. 'If-Match' header can be used instead
+	Version *int `json:"version" example:"1"`
 }
 
 func (req *EditSpendReq) SanitizeAndCheck() error {
 	sanitizeString(req.Title)
 	sanitizeString(req.Notes)
 
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
 	if req.Title != nil && *req.Title == "" {
-		return emptyFieldError("title")
+		errs.add(emptyFieldError("title"))
 	}
 	// Skip Type
 	// Skip Notes
 	if req.Cost != nil && *req.Cost < 0 {
-		return negativeFieldError("cost")
+		errs.add(negativeFieldError("cost"))
 	}
-	return nil
+	checkVersion(&errs, req.Version)
+	return errs.toError()
 }
 
 type RemoveSpendReq struct {
@@ -70,8 +77,56 @@ type RemoveSpendReq struct {
 }
 
 func (req *RemoveSpendReq) SanitizeAndCheck() error {
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
-	return nil
+	return errs.toError()
+}
+
+type GetSpendReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetSpendReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type EditSpendResp struct {
+	BaseResponse
+
+	// Version is a new version of the Spend
+	Version int `json:"version"`
+}
+
+type GetSpendResp struct {
+	BaseResponse
+
+	Spend db.Spend `json:"spend"`
+}
+
+type GetSpendsReq struct {
+	BaseRequest
+
+	MonthID uint `json:"month_id" validate:"required" example:"1"`
+}
+
+func (req *GetSpendsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.MonthID == 0 {
+		errs.add(emptyOrZeroFieldError("month_id"))
+	}
+	return errs.toError()
+}
+
+type GetSpendsResp struct {
+	BaseResponse
+
+	Spends []db.Spend `json:"spends"`
 }
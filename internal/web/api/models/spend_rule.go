@@ -0,0 +1,104 @@
+package models
+
+import (
+	"regexp"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type GetSpendRulesResp struct {
+	BaseResponse
+
+	SpendRules []db.SpendRule `json:"spend_rules"`
+}
+
+type AddSpendRuleReq struct {
+	BaseRequest
+
+	// TitlePattern is a regular expression in the RE2 syntax (https://github.com/google/re2/wiki/Syntax)
+	TitlePattern string `json:"title_pattern" validate:"required" example:"(?i)lidl|aldi"`
+	TypeID       uint   `json:"type_id" validate:"required" example:"1"`
+	// Notes are appended to notes of matched Spends
+	Notes string `json:"notes"`
+}
+
+func (req *AddSpendRuleReq) SanitizeAndCheck() error {
+	sanitizeString(&req.TitlePattern)
+	sanitizeString(&req.Notes)
+
+	var errs fieldErrors
+	if req.TitlePattern == "" {
+		errs.add(emptyFieldError("title_pattern"))
+	} else {
+		checkTitlePattern(&errs, req.TitlePattern)
+	}
+	if req.TypeID == 0 {
+		errs.add(emptyOrZeroFieldError("type_id"))
+	}
+	// Skip Notes
+	return errs.toError()
+}
+
+type AddSpendRuleResp struct {
+	BaseResponse
+
+	ID uint `json:"id"`
+}
+
+type EditSpendRuleReq struct {
+	BaseRequest
+
+	ID           uint    `json:"id" validate:"required" example:"1"`
+	TitlePattern *string `json:"title_pattern" example:"(?i)coffee"`
+	TypeID       *uint   `json:"type_id" example:"1"`
+	Notes        *string `json:"notes"`
+}
+
+func (req *EditSpendRuleReq) SanitizeAndCheck() error {
+	sanitizeString(req.TitlePattern)
+	sanitizeString(req.Notes)
+
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	if req.TitlePattern != nil {
+		if *req.TitlePattern == "" {
+			errs.add(emptyFieldError("title_pattern"))
+		} else {
+			checkTitlePattern(&errs, *req.TitlePattern)
+		}
+	}
+	if req.TypeID != nil && *req.TypeID == 0 {
+		errs.add(emptyOrZeroFieldError("type_id"))
+	}
+	// Skip Notes
+	return errs.toError()
+}
+
+type RemoveSpendRuleReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *RemoveSpendRuleReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type ApplySpendRulesResp struct {
+	BaseResponse
+
+	// Count is a number of categorized Spends
+	Count int `json:"count"`
+}
+
+func checkTitlePattern(errs *fieldErrors, pattern string) {
+	if _, err := regexp.Compile(pattern); err != nil {
+		errs.add(invalidFieldError("title_pattern", "title_pattern is invalid: %s", err))
+	}
+}
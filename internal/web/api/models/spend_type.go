@@ -4,6 +4,26 @@ import (
 	"github.com/ShoshinNikita/budget-manager/internal/db"
 )
 
+type GetSpendTypeReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetSpendTypeReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type GetSpendTypeResp struct {
+	BaseResponse
+
+	SpendType db.SpendType `json:"spend_type"`
+}
+
 type GetSpendTypesResp struct {
 	BaseResponse
 
@@ -20,10 +40,11 @@ type AddSpendTypeReq struct {
 func (req *AddSpendTypeReq) SanitizeAndCheck() error {
 	sanitizeString(&req.Name)
 
+	var errs fieldErrors
 	if req.Name == "" {
-		return emptyFieldError("name")
+		errs.add(emptyFieldError("name"))
 	}
-	return nil
+	return errs.toError()
 }
 
 type AddSpendTypeResp struct {
@@ -38,18 +59,29 @@ type EditSpendTypeReq struct {
 	ID       uint    `json:"id" validate:"required" example:"1"`
 	Name     *string `json:"name" example:"Vegetables"`
 	ParentID *uint   `json:"parent_id" example:"1"`
+	// Version is an expected version of the record. 'If-Match' header can be used instead
+	Version *int `json:"version" example:"1"`
 }
 
 func (req *EditSpendTypeReq) SanitizeAndCheck() error {
 	sanitizeString(req.Name)
 
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
 	if req.Name != nil && *req.Name == "" {
-		return emptyFieldError("name")
+		errs.add(emptyFieldError("name"))
 	}
-	return nil
+	checkVersion(&errs, req.Version)
+	return errs.toError()
+}
+
+type EditSpendTypeResp struct {
+	BaseResponse
+
+	// Version is a new version of the Spend Type
+	Version int `json:"version"`
 }
 
 type RemoveSpendTypeReq struct {
@@ -59,8 +91,9 @@ type RemoveSpendTypeReq struct {
 }
 
 func (req *RemoveSpendTypeReq) SanitizeAndCheck() error {
+	var errs fieldErrors
 	if req.ID == 0 {
-		return emptyOrZeroFieldError("id")
+		errs.add(emptyOrZeroFieldError("id"))
 	}
-	return nil
+	return errs.toError()
 }
@@ -0,0 +1,49 @@
+package models
+
+import "github.com/ShoshinNikita/budget-manager/internal/web/pages/statistics"
+
+// GetCostIntervalsReq is used to get cost intervals of Spends filtered with the search args.
+// Sort and Order are ignored
+type GetCostIntervalsReq struct {
+	SearchSpendsReq
+
+	// IntervalNumber is a max number of intervals. The actual number can be lower
+	IntervalNumber int `json:"interval_number" default:"15" minimum:"1" maximum:"100"`
+}
+
+func (req *GetCostIntervalsReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	errs.addNested("", req.SearchSpendsReq.SanitizeAndCheck())
+
+	if req.IntervalNumber == 0 {
+		req.IntervalNumber = statistics.DefaultCostIntervalNumber
+	}
+	if req.IntervalNumber < 1 || req.IntervalNumber > statistics.MaxCostIntervalNumber {
+		errs.add(invalidFieldError(
+			"interval_number", "interval_number must be in range [1, %d]", statistics.MaxCostIntervalNumber,
+		))
+	}
+	return errs.toError()
+}
+
+type GetCostIntervalsResp struct {
+	BaseResponse
+
+	Intervals []statistics.CostInterval `json:"intervals"`
+}
+
+type GetSpentBySpendTypeResp struct {
+	BaseResponse
+
+	// Datasets contains a dataset for every level of Spend Types. The first dataset contains
+	// Spend Types without parents, the second one - their children and so on. Elements without
+	// names are used to align children with their parents
+	Datasets []statistics.SpentBySpendTypeDataset `json:"datasets"`
+}
+
+type GetSpentByDayResp struct {
+	BaseResponse
+
+	// Dataset contains all days within the date range, including days without Spends
+	Dataset statistics.SpentByDayDataset `json:"dataset"`
+}
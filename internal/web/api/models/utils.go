@@ -1,9 +1,8 @@
 package models
 
 import (
+	"fmt"
 	"strings"
-
-	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 )
 
 func sanitizeString(s *string) {
@@ -14,22 +13,42 @@ func sanitizeString(s *string) {
 	*s = strings.TrimSpace(*s)
 }
 
+// checkVersion checks an optional expected version of a record
+func checkVersion(errs *fieldErrors, version *int) {
+	if version != nil && *version <= 0 {
+		errs.add(notPositiveFieldError("version"))
+	}
+}
+
 // emptyFieldError must be used when field of type string is empty
-func emptyFieldError(fieldName string) error {
-	return errors.Errorf("%s can't be empty", fieldName)
+func emptyFieldError(fieldName string) FieldError {
+	return newFieldError(fieldName, FieldErrorCodeRequired, "%s can't be empty", fieldName)
 }
 
 // emptyOrZeroFieldError must be used when field of type int or float is empty or zero
-func emptyOrZeroFieldError(fieldName string) error {
-	return errors.Errorf("%s can't be empty or zero", fieldName)
+func emptyOrZeroFieldError(fieldName string) FieldError {
+	return newFieldError(fieldName, FieldErrorCodeRequired, "%s can't be empty or zero", fieldName)
 }
 
 // notPositiveFieldError must be used when field is negative or zero (<= 0)
-func notPositiveFieldError(fieldName string) error {
-	return errors.Errorf("%s must be greater than zero", fieldName)
+func notPositiveFieldError(fieldName string) FieldError {
+	return newFieldError(fieldName, FieldErrorCodeNotPositive, "%s must be greater than zero", fieldName)
 }
 
 // negativeFieldError must be used when field is negative (< 0)
-func negativeFieldError(fieldName string) error {
-	return errors.Errorf("%s must be greater or equal to zero", fieldName)
+func negativeFieldError(fieldName string) FieldError {
+	return newFieldError(fieldName, FieldErrorCodeNegative, "%s must be greater or equal to zero", fieldName)
+}
+
+// invalidFieldError must be used when field has an invalid value
+func invalidFieldError(fieldName string, format string, args ...interface{}) FieldError {
+	return newFieldError(fieldName, FieldErrorCodeInvalid, format, args...)
+}
+
+func newFieldError(fieldName string, code FieldErrorCode, format string, args ...interface{}) FieldError {
+	return FieldError{
+		Field:   fieldName,
+		Code:    code,
+		Message: fmt.Sprintf(format, args...),
+	}
 }
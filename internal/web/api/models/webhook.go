@@ -0,0 +1,176 @@
+package models
+
+import (
+	"net/url"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+type GetWebhooksResp struct {
+	BaseResponse
+
+	Webhooks []db.Webhook `json:"webhooks"`
+}
+
+type GetWebhookReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *GetWebhookReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type GetWebhookResp struct {
+	BaseResponse
+
+	Webhook db.Webhook `json:"webhook"`
+}
+
+type AddWebhookReq struct {
+	BaseRequest
+
+	URL string `json:"url" validate:"required" example:"https://example.com/hook"`
+	// Secret is used to sign payloads. A random secret is generated if it is empty
+	Secret string `json:"secret"`
+	// Events is a list of events that trigger the Webhook
+	Events  []db.WebhookEvent `json:"events" validate:"required" swaggertype:"array,string" example:"spend.created"`
+	Enabled bool              `json:"enabled"`
+}
+
+func (req *AddWebhookReq) SanitizeAndCheck() error {
+	sanitizeString(&req.URL)
+
+	var errs fieldErrors
+	checkWebhookURL(&errs, req.URL)
+	checkWebhookEvents(&errs, req.Events)
+	return errs.toError()
+}
+
+type AddWebhookResp struct {
+	BaseResponse
+
+	ID uint `json:"id"`
+	// Secret is returned only once, after creation
+	Secret string `json:"secret"`
+}
+
+type EditWebhookReq struct {
+	BaseRequest
+
+	ID      uint               `json:"id" validate:"required" example:"1"`
+	URL     *string            `json:"url" example:"https://example.com/hook"`
+	Secret  *string            `json:"secret"`
+	Events  *[]db.WebhookEvent `json:"events" swaggertype:"array,string" example:"spend.created"`
+	Enabled *bool              `json:"enabled"`
+}
+
+func (req *EditWebhookReq) SanitizeAndCheck() error {
+	sanitizeString(req.URL)
+
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	if req.URL != nil {
+		checkWebhookURL(&errs, *req.URL)
+	}
+	if req.Secret != nil && *req.Secret == "" {
+		errs.add(emptyFieldError("secret"))
+	}
+	if req.Events != nil {
+		checkWebhookEvents(&errs, *req.Events)
+	}
+	return errs.toError()
+}
+
+type RemoveWebhookReq struct {
+	BaseRequest
+
+	ID uint `json:"id" validate:"required" example:"1"`
+}
+
+func (req *RemoveWebhookReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	if req.ID == 0 {
+		errs.add(emptyOrZeroFieldError("id"))
+	}
+	return errs.toError()
+}
+
+type GetWebhookDeliveriesReq struct {
+	BaseRequest
+
+	// WebhookID is used to filter deliveries
+	WebhookID uint `json:"webhook_id" example:"1"`
+	// Status is used to filter deliveries
+	Status db.WebhookDeliveryStatus `json:"status" swaggertype:"string" enums:"pending,succeeded,failed"`
+	// Limit is a max number of returned deliveries. 100 by default
+	Limit int `json:"limit" minimum:"0"`
+}
+
+const defaultWebhookDeliveriesLimit = 100
+
+func (req *GetWebhookDeliveriesReq) SanitizeAndCheck() error {
+	var errs fieldErrors
+	switch req.Status {
+	case "", db.WebhookDeliveryPending, db.WebhookDeliverySucceeded, db.WebhookDeliveryFailed:
+		// Ok
+	default:
+		errs.add(invalidFieldError("status", "invalid status: %q", req.Status))
+	}
+	if req.Limit < 0 {
+		errs.add(negativeFieldError("limit"))
+	}
+	if req.Limit == 0 {
+		req.Limit = defaultWebhookDeliveriesLimit
+	}
+	return errs.toError()
+}
+
+type GetWebhookDeliveriesResp struct {
+	BaseResponse
+
+	Deliveries []db.WebhookDelivery `json:"deliveries"`
+}
+
+func checkWebhookURL(errs *fieldErrors, rawURL string) {
+	if rawURL == "" {
+		errs.add(emptyFieldError("url"))
+		return
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		errs.add(invalidFieldError("url", "url is invalid: %s", err))
+		return
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		errs.add(invalidFieldError("url", "url must be an absolute http or https url"))
+	}
+}
+
+func checkWebhookEvents(errs *fieldErrors, events []db.WebhookEvent) {
+	if len(events) == 0 {
+		errs.add(emptyFieldError("events"))
+		return
+	}
+	for _, e := range events {
+		if !isWebhookEvent(e) {
+			errs.add(invalidFieldError("events", "invalid event: %q", e))
+		}
+	}
+}
+
+func isWebhookEvent(event db.WebhookEvent) bool {
+	for _, e := range db.WebhookEvents() {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
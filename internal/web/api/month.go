@@ -20,6 +20,7 @@ type MonthsHandlers struct {
 
 type MonthsDB interface {
 	GetMonthByDate(ctx context.Context, year int, month time.Month) (db.Month, error)
+	GetMonthsInRange(ctx context.Context, after, before time.Time) ([]db.MonthOverview, error)
 }
 
 // @Summary Get Month by date
@@ -28,9 +29,9 @@ type MonthsDB interface {
 // @Param params query models.GetMonthByDateReq true "Date"
 // @Produce json
 // @Success 200 {object} models.GetMonthResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Month doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h MonthsHandlers) GetMonthByDate(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -60,3 +61,42 @@ func (h MonthsHandlers) GetMonthByDate(w http.ResponseWriter, r *http.Request) {
 	}
 	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
 }
+
+// @Summary Get Months
+// @Description Get overviews of months which first day is within the date range and their aggregated totals
+// @Tags Months
+// @Router /api/months [get]
+// @Param params query models.GetMonthsReq true "Date range"
+// @Produce json
+// @Success 200 {object} models.GetMonthsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h MonthsHandlers) GetMonths(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetMonthsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	months, err := h.db.GetMonthsInRange(ctx, req.From, req.To)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Months", err)
+		return
+	}
+
+	resp := &models.GetMonthsResp{
+		Months: months,
+	}
+	for _, m := range months {
+		resp.Total.TotalIncome = resp.Total.TotalIncome.Add(m.TotalIncome)
+		resp.Total.TotalSpend = resp.Total.TotalSpend.Add(m.TotalSpend)
+		resp.Total.Result = resp.Total.Result.Add(m.Result)
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
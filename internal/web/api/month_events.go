@@ -0,0 +1,119 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+// monthEventsKeepAliveInterval defines how often a comment is sent to keep the connection open
+const monthEventsKeepAliveInterval = 30 * time.Second
+
+// monthChangeEventName is a name of Server-Sent Events with month changes
+const monthChangeEventName = "month_change"
+
+type MonthEventsHandlers struct {
+	db     MonthEventsDB
+	events MonthEvents
+	log    logger.Logger
+}
+
+type MonthEventsDB interface {
+	GetMonthOverview(ctx context.Context, id uint) (db.MonthOverview, error)
+}
+
+type MonthEvents interface {
+	Subscribe(monthID uint) (changes <-chan db.MonthChange, unsubscribe func())
+}
+
+// @Summary Stream Month changes
+// @Description Server-Sent Events stream. Every change of Incomes, Monthly Payments or Spends of the Month
+// @Description is sent as 'month_change' event, its data is a JSON-encoded db.MonthChange
+// @Tags Months
+// @Router /api/months/events [get]
+// @Param params query models.GetMonthEventsReq true "Month id"
+// @Produce text/event-stream
+// @Success 200 {object} db.MonthChange
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h MonthEventsHandlers) GetMonthEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetMonthEventsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.EncodeInternalError(ctx, w, log, "couldn't stream events", errors.New("streaming is not supported"))
+		return
+	}
+
+	// Process
+	if _, err := h.db.GetMonthOverview(ctx, req.MonthID); err != nil {
+		switch {
+		case errors.Is(err, db.ErrMonthNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Month", err)
+		}
+		return
+	}
+
+	changes, unsubscribe := h.events.Subscribe(req.MonthID)
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	// Send a comment to flush the headers
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	log.Debug("start streaming month changes")
+
+	keepAlive := time.NewTicker(monthEventsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case change, ok := <-changes:
+			if !ok {
+				log.Debug("events are closed")
+				return
+			}
+
+			data, err := json.Marshal(change)
+			if err != nil {
+				log.WithError(err).Error("couldn't marshal month change")
+				continue
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", monthChangeEventName, data)
+			flusher.Flush()
+
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+			flusher.Flush()
+
+		case <-ctx.Done():
+			log.Debug("client has disconnected")
+			return
+		}
+	}
+}
@@ -19,21 +19,106 @@ type MonthlyPaymentsHandlers struct {
 }
 
 type MonthlyPaymentsDB interface {
+	GetMonthlyPayment(ctx context.Context, id uint) (db.MonthlyPayment, error)
+	GetMonthlyPayments(ctx context.Context, monthID uint) ([]db.MonthlyPayment, error)
 	AddMonthlyPayment(ctx context.Context, args db.AddMonthlyPaymentArgs) (id uint, err error)
-	EditMonthlyPayment(ctx context.Context, args db.EditMonthlyPaymentArgs) error
+	EditMonthlyPayment(ctx context.Context, args db.EditMonthlyPaymentArgs) (version int, err error)
 	RemoveMonthlyPayment(ctx context.Context, id uint) error
 }
 
+// @Summary Get Monthly Payment
+// @Tags Monthly Payments
+// @Router /api/monthly-payments/{id} [get]
+// @Param id path int true "Monthly Payment id"
+// @Produce json
+// @Success 200 {object} models.GetMonthlyPaymentResp
+// @Header 200 {string} ETag "Version of the Monthly Payment"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Monthly Payment doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h MonthlyPaymentsHandlers) GetMonthlyPayment(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetMonthlyPaymentReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	mp, err := h.db.GetMonthlyPayment(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrMonthlyPaymentNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Monthly Payment", err)
+		}
+		return
+	}
+
+	utils.SetETag(w, mp.Version)
+	resp := &models.GetMonthlyPaymentResp{
+		MonthlyPayment: mp,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get Monthly Payments of Month
+// @Tags Monthly Payments
+// @Router /api/monthly-payments [get]
+// @Param params query models.GetMonthlyPaymentsReq true "Month id"
+// @Produce json
+// @Success 200 {object} models.GetMonthlyPaymentsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h MonthlyPaymentsHandlers) GetMonthlyPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetMonthlyPaymentsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	mps, err := h.db.GetMonthlyPayments(ctx, req.MonthID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrMonthNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Monthly Payments", err)
+		}
+		return
+	}
+
+	resp := &models.GetMonthlyPaymentsResp{
+		MonthlyPayments: mps,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
 // @Summary Create Monthly Payment
 // @Tags Monthly Payments
 // @Router /api/monthly-payments [post]
 // @Accept json
 // @Param body body models.AddMonthlyPaymentReq true "New Monthly Payment"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
 // @Produce json
 // @Success 201 {object} models.AddMonthlyPaymentResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Month doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h MonthlyPaymentsHandlers) AddMonthlyPayment(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -53,6 +138,7 @@ func (h MonthlyPaymentsHandlers) AddMonthlyPayment(w http.ResponseWriter, r *htt
 		TypeID:  req.TypeID,
 		Notes:   req.Notes,
 		Cost:    money.FromFloat(req.Cost),
+		DueDay:  req.DueDay,
 	}
 	id, err := h.db.AddMonthlyPayment(ctx, args)
 	if err != nil {
@@ -80,11 +166,14 @@ func (h MonthlyPaymentsHandlers) AddMonthlyPayment(w http.ResponseWriter, r *htt
 // @Router /api/monthly-payments [put]
 // @Accept json
 // @Param body body models.EditMonthlyPaymentReq true "Updated Monthly Payment"
+// @Param If-Match header string false "Expected version of the Monthly Payment"
 // @Produce json
-// @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Monthly Payment doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Success 200 {object} models.EditMonthlyPaymentResp
+// @Header 200 {string} ETag "New version of the Monthly Payment"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Monthly Payment doesn't exist"
+// @Failure 409 {object} models.GetMonthlyPaymentResp "Monthly Payment was changed by another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h MonthlyPaymentsHandlers) EditMonthlyPayment(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -97,24 +186,34 @@ func (h MonthlyPaymentsHandlers) EditMonthlyPayment(w http.ResponseWriter, r *ht
 	}
 	log = log.WithRequest(req)
 
+	version, err := utils.ExpectedVersion(r, req.Version)
+	if err != nil {
+		utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		return
+	}
+
 	// Process
 	args := db.EditMonthlyPaymentArgs{
-		ID:     req.ID,
-		Title:  req.Title,
-		Notes:  req.Notes,
-		TypeID: req.TypeID,
+		ID:      req.ID,
+		Title:   req.Title,
+		Notes:   req.Notes,
+		TypeID:  req.TypeID,
+		DueDay:  req.DueDay,
+		Version: version,
 	}
 	if req.Cost != nil {
 		cost := money.FromFloat(*req.Cost)
 		args.Cost = &cost
 	}
-	err := h.db.EditMonthlyPayment(ctx, args)
+	newVersion, err := h.db.EditMonthlyPayment(ctx, args)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrMonthlyPaymentNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
 		case errors.Is(err, db.ErrSpendTypeNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		case errors.Is(err, db.ErrVersionConflict):
+			h.encodeVersionConflict(ctx, w, log, err, req.ID)
 		default:
 			utils.EncodeInternalError(ctx, w, log, "couldn't edit Monthly Payment", err)
 		}
@@ -122,7 +221,28 @@ func (h MonthlyPaymentsHandlers) EditMonthlyPayment(w http.ResponseWriter, r *ht
 	}
 	log.Debug("Monthly Payment was successfully edited")
 
-	utils.Encode(ctx, w, log)
+	utils.SetETag(w, newVersion)
+	resp := &models.EditMonthlyPaymentResp{
+		Version: newVersion,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// encodeVersionConflict encodes the version conflict error with the current Monthly Payment
+func (h MonthlyPaymentsHandlers) encodeVersionConflict(ctx context.Context, w http.ResponseWriter, log logger.Logger,
+	err error, id uint) {
+
+	mp, getErr := h.db.GetMonthlyPayment(ctx, id)
+	if getErr != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get the current Monthly Payment", getErr)
+		return
+	}
+
+	utils.SetETag(w, mp.Version)
+	resp := &models.GetMonthlyPaymentResp{
+		MonthlyPayment: mp,
+	}
+	utils.EncodeError(ctx, w, log, err, http.StatusConflict, utils.EncodeResponse(resp))
 }
 
 // @Summary Remove Monthly Payment
@@ -132,9 +252,9 @@ func (h MonthlyPaymentsHandlers) EditMonthlyPayment(w http.ResponseWriter, r *ht
 // @Param body body models.RemoveMonthlyPaymentReq true "Monthly Payment id"
 // @Produce json
 // @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Monthly Payment doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Monthly Payment doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h MonthlyPaymentsHandlers) RemoveMonthlyPayment(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
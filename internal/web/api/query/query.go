@@ -0,0 +1,502 @@
+// Package query parses search queries for Spends.
+//
+// A query consists of terms separated by spaces. All terms must match. Supported terms:
+//
+//   - coffee, "coffee beans" - title contains the text
+//   - title:coffee, title="coffee beans" - title contains (':') or equals ('=') the text
+//   - notes:trip, notes="work trip" - the same for notes
+//   - cost:5, cost>5, cost>=5, cost<5, cost<=5 - compare cost
+//   - date:2026-01-02, date>2026-01-02 and so on - compare date
+//   - after:2026-01-01, before:2026-01-31 - date is within the range (inclusive)
+//   - type:Food, type:"Food/Cafe" - Spend Type or its children. Use '/' to separate names of parents and children
+//
+// Terms can be negated with '-' (-title:decaf), combined with OR (or '|') and grouped with parentheses:
+//
+//	title:coffee -title:decaf (cost>5 OR type:"Food/Cafe")
+//
+// AND has higher precedence than OR. Queries are limited by length and by the depth of groups and negations.
+package query
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+const (
+	dateLayout = "2006-01-02"
+
+	// MaxLength is a max number of characters in a query
+	MaxLength = 1000
+	// MaxDepth is a max number of nested groups and negations
+	MaxDepth = 20
+	// MaxCost is a max absolute value of cost. Larger values can't be converted to Money without
+	// losing precision
+	MaxCost = 1_000_000_000_000
+)
+
+// ErrInvalidQuery is returned for all syntax errors and unknown Spend Types
+var ErrInvalidQuery = errors.New("invalid query")
+
+func newError(format string, args ...interface{}) error {
+	return errors.Errorf("%w: %s", ErrInvalidQuery, fmt.Sprintf(format, args...))
+}
+
+// Parse parses the query. Spend Types are used to resolve their names. It returns nil for an empty query
+func Parse(query string, spendTypes []db.SpendType) (*db.SearchSpendsExpr, error) {
+	if n := len([]rune(query)); n > MaxLength {
+		return nil, newError("query is too long: %d characters, max is %d", n, MaxLength)
+	}
+
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+
+	p := &parser{
+		tokens:     tokens,
+		spendTypes: newSpendTypeResolver(spendTypes),
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, newError("unexpected %s", tok)
+	}
+	return &expr, nil
+}
+
+// ----------------------------------------------------
+// Tokenizer
+// ----------------------------------------------------
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+	// tokenTerm is a text or a field with an operator and a value
+	tokenTerm
+)
+
+type token struct {
+	kind tokenKind
+
+	// field and op are empty for text terms
+	field string
+	op    string
+	value string
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of query"
+	case tokenOr:
+		return "'OR'"
+	case tokenNot:
+		return "'-'"
+	case tokenLeftParen:
+		return "'('"
+	case tokenRightParen:
+		return "')'"
+	default:
+		return "'" + t.field + t.op + t.value + "'"
+	}
+}
+
+// operators are sorted by length, so the longest one is matched first
+var operators = []string{">=", "<=", ":", "=", ">", "<"}
+
+func tokenize(query string) ([]token, error) {
+	var (
+		tokens []token
+		runes  = []rune(query)
+	)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen})
+			i++
+
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen})
+			i++
+
+		case r == '|':
+			tokens = append(tokens, token{kind: tokenOr})
+			i++
+
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]):
+			tokens = append(tokens, token{kind: tokenNot})
+			i++
+
+		case r == '"':
+			value, next, err := readQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenTerm, value: value})
+			i = next
+
+		default:
+			tok, next, err := readTerm(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, tok)
+			i = next
+		}
+	}
+	return tokens, nil
+}
+
+// readQuoted reads a quoted string that starts at index i. Quotes can be escaped with '\'
+func readQuoted(runes []rune, i int) (value string, next int, err error) {
+	var b strings.Builder
+	for i++; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case r == '\\' && i+1 < len(runes):
+			i++
+			b.WriteRune(runes[i])
+		case r == '"':
+			return b.String(), i + 1, nil
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return "", 0, newError("unclosed quote")
+}
+
+// readTerm reads a text or a field term that starts at index i
+func readTerm(runes []rune, i int) (tok token, next int, err error) {
+	start := i
+	for i < len(runes) && !isTermEnd(runes[i]) {
+		i++
+	}
+	word := string(runes[start:i])
+
+	if word == "OR" {
+		return token{kind: tokenOr}, i, nil
+	}
+
+	field, op, value, ok := splitFieldTerm(word)
+	if !ok {
+		return token{kind: tokenTerm, value: word}, i, nil
+	}
+	if value == "" && i < len(runes) && runes[i] == '"' {
+		value, i, err = readQuoted(runes, i)
+		if err != nil {
+			return token{}, 0, err
+		}
+	}
+	return token{kind: tokenTerm, field: field, op: op, value: value}, i, nil
+}
+
+func isTermEnd(r rune) bool {
+	return unicode.IsSpace(r) || r == '(' || r == ')' || r == '"'
+}
+
+// splitFieldTerm splits terms like 'cost>=5'. Field must consist of letters
+func splitFieldTerm(word string) (field, op, value string, ok bool) {
+	end := strings.IndexFunc(word, func(r rune) bool { return !unicode.IsLetter(r) })
+	if end <= 0 {
+		return "", "", "", false
+	}
+	for _, op := range operators {
+		if strings.HasPrefix(word[end:], op) {
+			return strings.ToLower(word[:end]), op, word[end+len(op):], true
+		}
+	}
+	return "", "", "", false
+}
+
+// ----------------------------------------------------
+// Parser
+// ----------------------------------------------------
+
+type parser struct {
+	tokens     []token
+	pos        int
+	depth      int
+	spendTypes spendTypeResolver
+}
+
+func (p *parser) peek() token {
+	if p.pos >= len(p.tokens) {
+		return token{kind: tokenEOF}
+	}
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.peek()
+	p.pos++
+	return tok
+}
+
+// parseOr parses expressions separated by OR
+func (p *parser) parseOr() (db.SearchSpendsExpr, error) {
+	var exprs []db.SearchSpendsExpr
+	for {
+		expr, err := p.parseAnd()
+		if err != nil {
+			return db.SearchSpendsExpr{}, err
+		}
+		exprs = append(exprs, expr)
+
+		if p.peek().kind != tokenOr {
+			break
+		}
+		p.next()
+	}
+
+	if len(exprs) == 1 {
+		return exprs[0], nil
+	}
+	return db.SearchSpendsExpr{Or: exprs}, nil
+}
+
+// parseAnd parses a sequence of expressions
+func (p *parser) parseAnd() (db.SearchSpendsExpr, error) {
+	var exprs []db.SearchSpendsExpr
+	for {
+		kind := p.peek().kind
+		if kind == tokenEOF || kind == tokenOr || kind == tokenRightParen {
+			break
+		}
+
+		expr, err := p.parseUnary()
+		if err != nil {
+			return db.SearchSpendsExpr{}, err
+		}
+		exprs = append(exprs, expr)
+	}
+
+	switch len(exprs) {
+	case 0:
+		return db.SearchSpendsExpr{}, newError("unexpected %s", p.peek())
+	case 1:
+		return exprs[0], nil
+	default:
+		return db.SearchSpendsExpr{And: exprs}, nil
+	}
+}
+
+// parseUnary parses a negation, a group or a single term
+func (p *parser) parseUnary() (db.SearchSpendsExpr, error) {
+	tok := p.next()
+	if tok.kind == tokenNot || tok.kind == tokenLeftParen {
+		// Limit the recursion and the depth of the SQL query
+		p.depth++
+		defer func() { p.depth-- }()
+
+		if p.depth > MaxDepth {
+			return db.SearchSpendsExpr{}, newError("query is nested too deeply, max depth is %d", MaxDepth)
+		}
+	}
+
+	switch tok.kind {
+	case tokenNot:
+		expr, err := p.parseUnary()
+		if err != nil {
+			return db.SearchSpendsExpr{}, err
+		}
+		return db.SearchSpendsExpr{Not: &expr}, nil
+
+	case tokenLeftParen:
+		expr, err := p.parseOr()
+		if err != nil {
+			return db.SearchSpendsExpr{}, err
+		}
+		if tok := p.next(); tok.kind != tokenRightParen {
+			return db.SearchSpendsExpr{}, newError("expected ')', got %s", tok)
+		}
+		return expr, nil
+
+	case tokenTerm:
+		cond, err := p.parseCond(tok)
+		if err != nil {
+			return db.SearchSpendsExpr{}, err
+		}
+		return db.SearchSpendsExpr{Cond: &cond}, nil
+
+	default:
+		return db.SearchSpendsExpr{}, newError("unexpected %s", tok)
+	}
+}
+
+//nolint:funlen
+func (p *parser) parseCond(tok token) (db.SearchSpendsCond, error) {
+	switch tok.field {
+	case "":
+		return db.SearchSpendsCond{
+			Field: db.SearchSpendsByTitle,
+			Op:    db.OpContains,
+			Text:  strings.ToLower(tok.value),
+		}, nil
+
+	case "title", "notes":
+		field := db.SearchSpendsByTitle
+		if tok.field == "notes" {
+			field = db.SearchSpendsByNotes
+		}
+
+		var op db.SearchSpendsOp
+		switch tok.op {
+		case ":":
+			op = db.OpContains
+		case "=":
+			op = db.OpEqual
+		default:
+			return db.SearchSpendsCond{}, newError("operator '%s' can't be used with '%s'", tok.op, tok.field)
+		}
+		return db.SearchSpendsCond{Field: field, Op: op, Text: strings.ToLower(tok.value)}, nil
+
+	case "cost":
+		op, err := parseComparisonOp(tok)
+		if err != nil {
+			return db.SearchSpendsCond{}, err
+		}
+		cost, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil || math.IsNaN(cost) || math.IsInf(cost, 0) {
+			return db.SearchSpendsCond{}, newError("invalid cost '%s'", tok.value)
+		}
+		if math.Abs(cost) > MaxCost {
+			return db.SearchSpendsCond{}, newError("cost '%s' is out of range, max is %d", tok.value, MaxCost)
+		}
+		return db.SearchSpendsCond{Field: db.SearchSpendsByCost, Op: op, Cost: money.FromFloat(cost)}, nil
+
+	case "date", "after", "before":
+		var op db.SearchSpendsOp
+		switch {
+		case tok.field == "date":
+			var err error
+			op, err = parseComparisonOp(tok)
+			if err != nil {
+				return db.SearchSpendsCond{}, err
+			}
+		case tok.op != ":":
+			return db.SearchSpendsCond{}, newError("operator '%s' can't be used with '%s'", tok.op, tok.field)
+		case tok.field == "after":
+			op = db.OpGreaterOrEqual
+		default:
+			op = db.OpLessOrEqual
+		}
+
+		date, err := time.Parse(dateLayout, tok.value)
+		if err != nil {
+			return db.SearchSpendsCond{}, newError("invalid date '%s', use format yyyy-mm-dd", tok.value)
+		}
+		return db.SearchSpendsCond{Field: db.SearchSpendsByDate, Op: op, Date: date}, nil
+
+	case "type":
+		if tok.op != ":" && tok.op != "=" {
+			return db.SearchSpendsCond{}, newError("operator '%s' can't be used with '%s'", tok.op, tok.field)
+		}
+		ids := p.spendTypes.Resolve(tok.value)
+		if len(ids) == 0 {
+			return db.SearchSpendsCond{}, newError("unknown Spend Type '%s'", tok.value)
+		}
+		return db.SearchSpendsCond{Field: db.SearchSpendsByType, Op: db.OpEqual, TypeIDs: ids}, nil
+
+	default:
+		return db.SearchSpendsCond{}, newError("unknown field '%s'", tok.field)
+	}
+}
+
+func parseComparisonOp(tok token) (db.SearchSpendsOp, error) {
+	switch tok.op {
+	case ":", "=":
+		return db.OpEqual, nil
+	case "<":
+		return db.OpLess, nil
+	case "<=":
+		return db.OpLessOrEqual, nil
+	case ">":
+		return db.OpGreater, nil
+	case ">=":
+		return db.OpGreaterOrEqual, nil
+	default:
+		return 0, newError("operator '%s' can't be used with '%s'", tok.op, tok.field)
+	}
+}
+
+// ----------------------------------------------------
+// Spend Types
+// ----------------------------------------------------
+
+type spendTypeResolver struct {
+	spendTypes []db.SpendType
+	// fullNames contains lowercased full names of Spend Types: 'food/cafe'
+	fullNames map[uint]string
+}
+
+func newSpendTypeResolver(spendTypes []db.SpendType) spendTypeResolver {
+	byID := make(map[uint]db.SpendType, len(spendTypes))
+	for _, t := range spendTypes {
+		byID[t.ID] = t
+	}
+
+	// Limit the depth to handle cycles
+	const maxDepth = 15
+
+	fullNames := make(map[uint]string, len(spendTypes))
+	for _, t := range spendTypes {
+		names := []string{normalizeName(t.Name)}
+		for parentID, depth := t.ParentID, 0; parentID != 0 && depth < maxDepth; depth++ {
+			parent, ok := byID[parentID]
+			if !ok {
+				break
+			}
+			names = append([]string{normalizeName(parent.Name)}, names...)
+			parentID = parent.ParentID
+		}
+		fullNames[t.ID] = strings.Join(names, "/")
+	}
+
+	return spendTypeResolver{
+		spendTypes: spendTypes,
+		fullNames:  fullNames,
+	}
+}
+
+// Resolve returns ids of the Spend Type with the passed full name and of all its children
+func (r spendTypeResolver) Resolve(name string) []uint {
+	parts := strings.Split(name, "/")
+	for i := range parts {
+		parts[i] = normalizeName(parts[i])
+	}
+	name = strings.Join(parts, "/")
+
+	var ids []uint
+	for _, t := range r.spendTypes {
+		fullName := r.fullNames[t.ID]
+		if fullName == name || strings.HasPrefix(fullName, name+"/") {
+			ids = append(ids, t.ID)
+		}
+	}
+	return ids
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
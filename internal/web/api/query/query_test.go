@@ -0,0 +1,161 @@
+package query
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	spendTypes := []db.SpendType{
+		{ID: 1, Name: "Food"},
+		{ID: 2, Name: "Cafe", ParentID: 1},
+		{ID: 3, Name: "Groceries", ParentID: 1},
+		{ID: 4, Name: "Travel"},
+	}
+
+	cond := func(c db.SearchSpendsCond) db.SearchSpendsExpr {
+		return db.SearchSpendsExpr{Cond: &c}
+	}
+	not := func(e db.SearchSpendsExpr) db.SearchSpendsExpr {
+		return db.SearchSpendsExpr{Not: &e}
+	}
+	title := func(text string) db.SearchSpendsExpr {
+		return cond(db.SearchSpendsCond{Field: db.SearchSpendsByTitle, Op: db.OpContains, Text: text})
+	}
+
+	tests := []struct {
+		query string
+		want  *db.SearchSpendsExpr
+	}{
+		{query: "", want: nil},
+		{query: "   ", want: nil},
+		{query: "Coffee", want: &db.SearchSpendsExpr{Cond: title("coffee").Cond}},
+		{
+			query: `title:coffee -title:decaf cost>5 type:"Food/Cafe" after:2026-01-01 notes:"work trip"`,
+			want: &db.SearchSpendsExpr{
+				And: []db.SearchSpendsExpr{
+					title("coffee"),
+					not(title("decaf")),
+					cond(db.SearchSpendsCond{Field: db.SearchSpendsByCost, Op: db.OpGreater, Cost: money.FromInt(5)}),
+					cond(db.SearchSpendsCond{Field: db.SearchSpendsByType, Op: db.OpEqual, TypeIDs: []uint{2}}),
+					cond(db.SearchSpendsCond{
+						Field: db.SearchSpendsByDate, Op: db.OpGreaterOrEqual,
+						Date: time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC),
+					}),
+					cond(db.SearchSpendsCond{Field: db.SearchSpendsByNotes, Op: db.OpContains, Text: "work trip"}),
+				},
+			},
+		},
+		{
+			query: `a b OR c | -(d OR e)`,
+			want: &db.SearchSpendsExpr{
+				Or: []db.SearchSpendsExpr{
+					{And: []db.SearchSpendsExpr{title("a"), title("b")}},
+					title("c"),
+					not(db.SearchSpendsExpr{Or: []db.SearchSpendsExpr{title("d"), title("e")}}),
+				},
+			},
+		},
+		{
+			query: `title="Coffee Beans" cost<=2.5 date:2026-02-03 before:2026-03-01`,
+			want: &db.SearchSpendsExpr{
+				And: []db.SearchSpendsExpr{
+					cond(db.SearchSpendsCond{Field: db.SearchSpendsByTitle, Op: db.OpEqual, Text: "coffee beans"}),
+					cond(db.SearchSpendsCond{Field: db.SearchSpendsByCost, Op: db.OpLessOrEqual, Cost: money.FromFloat(2.5)}),
+					cond(db.SearchSpendsCond{
+						Field: db.SearchSpendsByDate, Op: db.OpEqual,
+						Date: time.Date(2026, time.February, 3, 0, 0, 0, 0, time.UTC),
+					}),
+					cond(db.SearchSpendsCond{
+						Field: db.SearchSpendsByDate, Op: db.OpLessOrEqual,
+						Date: time.Date(2026, time.March, 1, 0, 0, 0, 0, time.UTC),
+					}),
+				},
+			},
+		},
+		{
+			// Max cost
+			query: `cost<=1e12`,
+			want: &db.SearchSpendsExpr{
+				Cond: &db.SearchSpendsCond{
+					Field: db.SearchSpendsByCost, Op: db.OpLessOrEqual, Cost: money.FromInt(1_000_000_000_000),
+				},
+			},
+		},
+		{
+			// Parent types include children. Names are case-insensitive
+			query: `type:food`,
+			want: &db.SearchSpendsExpr{
+				Cond: &db.SearchSpendsCond{Field: db.SearchSpendsByType, Op: db.OpEqual, TypeIDs: []uint{1, 2, 3}},
+			},
+		},
+		{
+			query: `"escaped \" quote" re-run`,
+			want: &db.SearchSpendsExpr{
+				And: []db.SearchSpendsExpr{title(`escaped " quote`), title("re-run")},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.query, func(t *testing.T) {
+			got, err := Parse(tt.query, spendTypes)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParse_Errors(t *testing.T) {
+	t.Parallel()
+
+	spendTypes := []db.SpendType{{ID: 1, Name: "Food"}}
+
+	for query, wantErr := range map[string]string{
+		`title:"coffee`:       "invalid query: unclosed quote",
+		`(coffee`:             "invalid query: expected ')', got end of query",
+		`coffee)`:             "invalid query: unexpected ')'",
+		`OR coffee`:           "invalid query: unexpected 'OR'",
+		`()`:                  "invalid query: unexpected ')'",
+		`price>5`:             "invalid query: unknown field 'price'",
+		`cost>abc`:            "invalid query: invalid cost 'abc'",
+		`cost>NaN`:            "invalid query: invalid cost 'NaN'",
+		`cost<Inf`:            "invalid query: invalid cost 'Inf'",
+		`cost>-infinity`:      "invalid query: invalid cost '-infinity'",
+		`cost<1e400`:          "invalid query: invalid cost '1e400'",
+		`cost<1e308`:          "invalid query: cost '1e308' is out of range, max is 1000000000000",
+		`cost>-1000000000001`: "invalid query: cost '-1000000000001' is out of range, max is 1000000000000",
+		`title>5`:             "invalid query: operator '>' can't be used with 'title'",
+		`after>2020-01-01`:    "invalid query: operator '>' can't be used with 'after'",
+		`date:01.01.2020`:     "invalid query: invalid date '01.01.2020', use format yyyy-mm-dd",
+		`type:"Food/Pizza"`:   "invalid query: unknown Spend Type 'Food/Pizza'",
+	} {
+		_, err := Parse(query, spendTypes)
+		require.EqualError(t, err, wantErr, query)
+		require.True(t, errors.Is(err, ErrInvalidQuery))
+	}
+
+	// Limits
+	for query, wantErr := range map[string]string{
+		strings.Repeat("a", MaxLength+1):      "invalid query: query is too long: 1001 characters, max is 1000",
+		strings.Repeat("(", MaxDepth+1) + "a": "invalid query: query is nested too deeply, max depth is 20",
+		strings.Repeat("-", 100) + "a":        "invalid query: query is nested too deeply, max depth is 20",
+		strings.Repeat("(", 400):              "invalid query: query is nested too deeply, max depth is 20",
+	} {
+		_, err := Parse(query, spendTypes)
+		require.EqualError(t, err, wantErr)
+		require.True(t, errors.Is(err, ErrInvalidQuery))
+	}
+
+	_, err := Parse(strings.Repeat("(", MaxDepth)+"a"+strings.Repeat(")", MaxDepth), spendTypes)
+	require.NoError(t, err)
+}
@@ -0,0 +1,269 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type SavedSearchesHandlers struct {
+	db  SavedSearchesDB
+	log logger.Logger
+}
+
+type SavedSearchesDB interface {
+	GetSavedSearches(ctx context.Context) ([]db.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id uint) (db.SavedSearch, error)
+	AddSavedSearch(ctx context.Context, args db.AddSavedSearchArgs) (id uint, err error)
+	EditSavedSearch(ctx context.Context, args db.EditSavedSearchArgs) error
+	RemoveSavedSearch(ctx context.Context, id uint) error
+	GetSavedSearchReports(ctx context.Context, year int, month time.Month) ([]db.SavedSearchReport, error)
+	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
+}
+
+// @Summary Get All Saved Searches
+// @Tags Saved Searches
+// @Router /api/saved-searches [get]
+// @Produce json
+// @Success 200 {object} models.GetSavedSearchesResp
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) GetSavedSearches(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	searches, err := h.db.GetSavedSearches(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Saved Searches", err)
+		return
+	}
+
+	resp := &models.GetSavedSearchesResp{
+		SavedSearches: searches,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get Saved Search
+// @Tags Saved Searches
+// @Router /api/saved-searches/{id} [get]
+// @Param id path int true "Saved Search id"
+// @Produce json
+// @Success 200 {object} models.GetSavedSearchResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Saved Search doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) GetSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetSavedSearchReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	search, err := h.db.GetSavedSearch(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSavedSearchNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Saved Search", err)
+		}
+		return
+	}
+
+	resp := &models.GetSavedSearchResp{
+		SavedSearch: search,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Create Saved Search
+// @Tags Saved Searches
+// @Router /api/saved-searches [post]
+// @Accept json
+// @Param body body models.AddSavedSearchReq true "New Saved Search"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 201 {object} models.AddSavedSearchResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) AddSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.AddSavedSearchReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	searchArgs, err := newSearchSpendsArgs(ctx, h.db, &req.Search)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+	args := db.AddSavedSearchArgs{
+		Name:   req.Name,
+		Pinned: req.Pinned,
+		Args:   searchArgs,
+	}
+	id, err := h.db.AddSavedSearch(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't add Saved Search", err)
+		return
+	}
+	log = log.WithField("id", id)
+	log.Debug("Saved Search was successfully added")
+
+	resp := &models.AddSavedSearchResp{
+		ID: id,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp), utils.EncodeStatusCode(http.StatusCreated))
+}
+
+// @Summary Edit Saved Search
+// @Tags Saved Searches
+// @Router /api/saved-searches [put]
+// @Accept json
+// @Param body body models.EditSavedSearchReq true "Updated Saved Search"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Saved Search doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) EditSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.EditSavedSearchReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args := db.EditSavedSearchArgs{
+		ID:     req.ID,
+		Name:   req.Name,
+		Pinned: req.Pinned,
+	}
+	if req.Search != nil {
+		searchArgs, err := newSearchSpendsArgs(ctx, h.db, req.Search)
+		if err != nil {
+			encodeSearchSpendsArgsError(ctx, w, log, err)
+			return
+		}
+		args.Args = &searchArgs
+	}
+	err := h.db.EditSavedSearch(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSavedSearchNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't edit Saved Search", err)
+		}
+		return
+	}
+	log.Debug("Saved Search was successfully edited")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Remove Saved Search
+// @Tags Saved Searches
+// @Router /api/saved-searches [delete]
+// @Accept json
+// @Param body body models.RemoveSavedSearchReq true "Saved Search id"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Saved Search doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) RemoveSavedSearch(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RemoveSavedSearchReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	err := h.db.RemoveSavedSearch(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSavedSearchNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't remove Saved Search", err)
+		}
+		return
+	}
+	log.Debug("Saved Search was successfully removed")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Get Reports of pinned Saved Searches
+// @Description Reports contain totals of found Spends for a month and for the previous one.
+// @Description Dates of Saved Searches are narrowed to the months
+// @Tags Saved Searches
+// @Router /api/saved-searches/reports [get]
+// @Param params query models.GetSavedSearchReportsReq true "Month"
+// @Produce json
+// @Success 200 {object} models.GetSavedSearchReportsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SavedSearchesHandlers) GetSavedSearchReports(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetSavedSearchReportsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	year, month := req.Year, req.Month
+	if year == 0 {
+		year, month, _ = time.Now().Date()
+	}
+	reports, err := h.db.GetSavedSearchReports(ctx, year, month)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get reports of Saved Searches", err)
+		return
+	}
+
+	resp := &models.GetSavedSearchReportsResp{
+		Reports: reports,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
@@ -3,13 +3,17 @@ package api
 import (
 	"context"
 	"net/http"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
 	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/query"
 	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
 )
 
@@ -20,16 +24,22 @@ type SearchHandlers struct {
 
 type SearchDB interface {
 	SearchSpends(ctx context.Context, args db.SearchSpendsArgs) ([]db.Spend, error)
+	GetSearchSpendsTotal(ctx context.Context, args db.SearchSpendsArgs) (db.SearchSpendsTotal, error)
+	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
+	SearchIncomes(ctx context.Context, args db.SearchIncomesArgs) ([]db.Income, error)
+	SearchMonthlyPayments(ctx context.Context, args db.SearchMonthlyPaymentsArgs) ([]db.MonthlyPayment, error)
 }
 
 // @Summary Search Spends
+// @Description Use Limit and Offset to paginate the results. Total contains a number and a cost
+// @Description of all found Spends
 // @Tags Search
 // @Router /api/search/spends [get]
 // @Param params query models.SearchSpendsReq true "Search args"
 // @Produce json
 // @Success 200 {object} models.SearchSpendsResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SearchHandlers) SearchSpends(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -43,9 +53,46 @@ func (h SearchHandlers) SearchSpends(w http.ResponseWriter, r *http.Request) {
 	log = log.WithRequest(req)
 
 	// Process
+	args, err := newSearchSpendsArgs(ctx, h.db, req)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+
+	spends, err := h.db.SearchSpends(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't search for Spends", err)
+		return
+	}
+	total, err := h.db.GetSearchSpendsTotal(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get total of found Spends", err)
+		return
+	}
+	log.WithField("spend_number", len(spends)).
+		WithField("total_spend_number", total.Count).
+		Debug("finish Spend search")
+
+	resp := &models.SearchSpendsResp{
+		Spends: spends,
+		Total:  total,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+type spendTypesGetter interface {
+	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
+}
+
+// newSearchSpendsArgs converts the request to search args. Spend Types are used to parse
+// the query, so they are loaded only if the query is specified
+func newSearchSpendsArgs(ctx context.Context, spendTypes spendTypesGetter,
+	req *models.SearchSpendsReq) (db.SearchSpendsArgs, error) {
+
 	args := db.SearchSpendsArgs{
 		Title:        strings.ToLower(req.Title),
 		Notes:        strings.ToLower(req.Notes),
+		Text:         req.Text,
 		TitleExactly: req.TitleExactly,
 		NotesExactly: req.NotesExactly,
 		After:        req.After,
@@ -53,14 +100,23 @@ func (h SearchHandlers) SearchSpends(w http.ResponseWriter, r *http.Request) {
 		MinCost:      money.FromFloat(req.MinCost),
 		MaxCost:      money.FromFloat(req.MaxCost),
 		TypeIDs:      req.TypeIDs,
+		Limit:        req.Limit,
+		Offset:       req.Offset,
 	}
 	switch req.Sort {
 	case "title":
 		args.Sort = db.SortSpendsByTitle
 	case "cost":
 		args.Sort = db.SortSpendsByCost
+	case "relevance":
+		args.Sort = db.SortSpendsByRelevance
+	case "date":
+		args.Sort = db.SortSpendsByDate
 	default:
 		args.Sort = db.SortSpendsByDate
+		if req.Text != "" {
+			args.Sort = db.SortSpendsByRelevance
+		}
 	}
 	switch req.Order {
 	case "desc":
@@ -69,15 +125,297 @@ func (h SearchHandlers) SearchSpends(w http.ResponseWriter, r *http.Request) {
 		args.Order = db.OrderByAsc
 	}
 
-	spends, err := h.db.SearchSpends(ctx, args)
+	if req.Query != "" {
+		types, err := spendTypes.GetSpendTypes(ctx)
+		if err != nil {
+			return db.SearchSpendsArgs{}, errors.Wrap(err, "couldn't get Spend Types")
+		}
+		args.Expr, err = query.Parse(req.Query, types)
+		if err != nil {
+			return db.SearchSpendsArgs{}, err
+		}
+	}
+
+	return args, nil
+}
+
+// encodeSearchSpendsArgsError encodes an error returned by newSearchSpendsArgs
+func encodeSearchSpendsArgsError(ctx context.Context, w http.ResponseWriter, log logger.Logger, err error) {
+	if errors.Is(err, query.ErrInvalidQuery) {
+		utils.EncodeError(ctx, w, log, models.NewInvalidFieldError("query", err), http.StatusBadRequest)
+		return
+	}
+	utils.EncodeInternalError(ctx, w, log, "couldn't prepare search args", err)
+}
+
+// @Summary Search Incomes
+// @Description Incomes are sorted by date
+// @Tags Search
+// @Router /api/search/incomes [get]
+// @Param params query models.SearchIncomesReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.SearchIncomesResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SearchHandlers) SearchIncomes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.SearchIncomesReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	incomes, err := h.db.SearchIncomes(ctx, newSearchIncomesArgs(req))
 	if err != nil {
-		utils.EncodeInternalError(ctx, w, log, "couldn't search for Spends", err)
+		utils.EncodeInternalError(ctx, w, log, "couldn't search for Incomes", err)
 		return
 	}
-	log.WithField("spend_number", len(spends)).Debug("finish Spend search")
+	log.WithField("income_number", len(incomes)).Debug("finish Income search")
 
-	resp := &models.SearchSpendsResp{
-		Spends: spends,
+	resp := &models.SearchIncomesResp{
+		Incomes: incomes,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Search Monthly Payments
+// @Description Monthly Payments are sorted by date
+// @Tags Search
+// @Router /api/search/monthly-payments [get]
+// @Param params query models.SearchMonthlyPaymentsReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.SearchMonthlyPaymentsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SearchHandlers) SearchMonthlyPayments(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.SearchMonthlyPaymentsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	mps, err := h.db.SearchMonthlyPayments(ctx, newSearchMonthlyPaymentsArgs(req))
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't search for Monthly Payments", err)
+		return
+	}
+	log.WithField("monthly_payment_number", len(mps)).Debug("finish Monthly Payment search")
+
+	resp := &models.SearchMonthlyPaymentsResp{
+		MonthlyPayments: mps,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Search Incomes, Monthly Payments and Spends
+// @Description Returns a single list of all money movements sorted by date. Incomes and Monthly Payments
+// @Description don't have days, so all records are filtered by whole months: 'after' and 'before' are extended
+// @Description to the first day of the month of 'after' and to the last day of the month of 'before'.
+// @Description Incomes are skipped if 'type_ids' is specified
+// @Tags Search
+// @Router /api/search/all [get]
+// @Param params query models.SearchAllReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.SearchAllResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SearchHandlers) SearchAll(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.SearchAllReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	movements, err := h.searchMoneyMovements(ctx, &req.SearchMonthlyPaymentsReq)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't search for money movements", err)
+		return
+	}
+	if req.Order == "desc" {
+		for i, j := 0, len(movements)-1; i < j; i, j = i+1, j-1 {
+			movements[i], movements[j] = movements[j], movements[i]
+		}
+	}
+	log.WithField("money_movement_number", len(movements)).Debug("finish search")
+
+	resp := &models.SearchAllResp{
+		MoneyMovements: movements,
+	}
+	for _, m := range movements {
+		resp.Total = resp.Total.Add(m.Amount)
 	}
 	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
 }
+
+// searchMoneyMovements searches for Incomes, Monthly Payments and Spends and returns them
+// as money movements sorted by date in ascending order
+func (h SearchHandlers) searchMoneyMovements(ctx context.Context,
+	req *models.SearchMonthlyPaymentsReq) ([]models.MoneyMovement, error) {
+
+	mpArgs := newSearchMonthlyPaymentsArgs(req)
+	// Records are merged in ascending order. The requested order is applied by the caller
+	mpArgs.Order = db.OrderByAsc
+	// Use the same months for all records. Otherwise, Spends of a month could be found without
+	// its Incomes and Monthly Payments
+	mpArgs.After, mpArgs.Before = extendToWholeMonths(mpArgs.After, mpArgs.Before)
+
+	var incomes []db.Income
+	if len(mpArgs.TypeIDs) == 0 {
+		var err error
+		incomes, err = h.db.SearchIncomes(ctx, db.SearchIncomesArgs{
+			Title:        mpArgs.Title,
+			Notes:        mpArgs.Notes,
+			TitleExactly: mpArgs.TitleExactly,
+			NotesExactly: mpArgs.NotesExactly,
+			After:        mpArgs.After,
+			Before:       mpArgs.Before,
+			MinIncome:    mpArgs.MinCost,
+			MaxIncome:    mpArgs.MaxCost,
+			Order:        db.OrderByAsc,
+		})
+		if err != nil {
+			return nil, errors.Wrap(err, "couldn't search for Incomes")
+		}
+	}
+
+	mps, err := h.db.SearchMonthlyPayments(ctx, mpArgs)
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't search for Monthly Payments")
+	}
+
+	spends, err := h.db.SearchSpends(ctx, db.SearchSpendsArgs{
+		Title:        mpArgs.Title,
+		Notes:        mpArgs.Notes,
+		TitleExactly: mpArgs.TitleExactly,
+		NotesExactly: mpArgs.NotesExactly,
+		After:        mpArgs.After,
+		Before:       mpArgs.Before,
+		MinCost:      mpArgs.MinCost,
+		MaxCost:      mpArgs.MaxCost,
+		TypeIDs:      mpArgs.TypeIDs,
+		Sort:         db.SortSpendsByDate,
+		Order:        db.OrderByAsc,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't search for Spends")
+	}
+
+	return mergeMoneyMovements(incomes, mps, spends), nil
+}
+
+// extendToWholeMonths returns the first day of the month of after and the last day of the month of before.
+// Zero values are not changed
+func extendToWholeMonths(after, before time.Time) (time.Time, time.Time) {
+	if !after.IsZero() {
+		after = time.Date(after.Year(), after.Month(), 1, 0, 0, 0, 0, after.Location())
+	}
+	if !before.IsZero() {
+		before = time.Date(before.Year(), before.Month()+1, 0, 0, 0, 0, 0, before.Location())
+	}
+	return after, before
+}
+
+// mergeMoneyMovements converts records to money movements and sorts them by date in ascending order.
+// Records must be sorted by date in ascending order
+func mergeMoneyMovements(incomes []db.Income, mps []db.MonthlyPayment, spends []db.Spend) []models.MoneyMovement {
+	res := make([]models.MoneyMovement, 0, len(incomes)+len(mps)+len(spends))
+	for _, in := range incomes {
+		res = append(res, models.MoneyMovement{
+			Kind:   "income",
+			ID:     in.ID,
+			Year:   in.Year,
+			Month:  in.Month,
+			Title:  in.Title,
+			Notes:  in.Notes,
+			Amount: in.Income,
+		})
+	}
+	for _, mp := range mps {
+		res = append(res, models.MoneyMovement{
+			Kind:   "monthly_payment",
+			ID:     mp.ID,
+			Year:   mp.Year,
+			Month:  mp.Month,
+			Title:  mp.Title,
+			Type:   mp.Type,
+			Notes:  mp.Notes,
+			Amount: money.Money(0).Sub(mp.Cost),
+		})
+	}
+	for _, spend := range spends {
+		res = append(res, models.MoneyMovement{
+			Kind:   "spend",
+			ID:     spend.ID,
+			Year:   spend.Year,
+			Month:  spend.Month,
+			Day:    spend.Day,
+			Title:  spend.Title,
+			Type:   spend.Type,
+			Notes:  spend.Notes,
+			Amount: money.Money(0).Sub(spend.Cost),
+		})
+	}
+
+	// Incomes and Monthly Payments don't have days, so they go before Spends of the same month
+	dateIndex := func(m models.MoneyMovement) int {
+		return m.Year*10000 + int(m.Month)*100 + m.Day
+	}
+	sort.SliceStable(res, func(i, j int) bool {
+		return dateIndex(res[i]) < dateIndex(res[j])
+	})
+	return res
+}
+
+func newSearchIncomesArgs(req *models.SearchIncomesReq) db.SearchIncomesArgs {
+	args := db.SearchIncomesArgs{
+		Title:        strings.ToLower(req.Title),
+		Notes:        strings.ToLower(req.Notes),
+		TitleExactly: req.TitleExactly,
+		NotesExactly: req.NotesExactly,
+		After:        req.After,
+		Before:       req.Before,
+		MinIncome:    money.FromFloat(req.MinIncome),
+		MaxIncome:    money.FromFloat(req.MaxIncome),
+		Order:        db.OrderByAsc,
+	}
+	if req.Order == "desc" {
+		args.Order = db.OrderByDesc
+	}
+	return args
+}
+
+func newSearchMonthlyPaymentsArgs(req *models.SearchMonthlyPaymentsReq) db.SearchMonthlyPaymentsArgs {
+	args := db.SearchMonthlyPaymentsArgs{
+		Title:        strings.ToLower(req.Title),
+		Notes:        strings.ToLower(req.Notes),
+		TitleExactly: req.TitleExactly,
+		NotesExactly: req.NotesExactly,
+		After:        req.After,
+		Before:       req.Before,
+		MinCost:      money.FromFloat(req.MinCost),
+		MaxCost:      money.FromFloat(req.MaxCost),
+		TypeIDs:      req.TypeIDs,
+		Order:        db.OrderByAsc,
+	}
+	if req.Order == "desc" {
+		args.Order = db.OrderByDesc
+	}
+	return args
+}
@@ -19,21 +19,106 @@ type SpendsHandlers struct {
 }
 
 type SpendsDB interface {
+	GetSpend(ctx context.Context, id uint) (db.Spend, error)
+	GetSpends(ctx context.Context, monthID uint) ([]db.Spend, error)
 	AddSpend(ctx context.Context, args db.AddSpendArgs) (id uint, err error)
-	EditSpend(ctx context.Context, args db.EditSpendArgs) error
+	EditSpend(ctx context.Context, args db.EditSpendArgs) (version int, err error)
 	RemoveSpend(ctx context.Context, id uint) error
 }
 
+// @Summary Get Spend
+// @Tags Spends
+// @Router /api/spends/{id} [get]
+// @Param id path int true "Spend id"
+// @Produce json
+// @Success 200 {object} models.GetSpendResp
+// @Header 200 {string} ETag "Version of the Spend"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendsHandlers) GetSpend(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetSpendReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	spend, err := h.db.GetSpend(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpendNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Spend", err)
+		}
+		return
+	}
+
+	utils.SetETag(w, spend.Version)
+	resp := &models.GetSpendResp{
+		Spend: spend,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get Spends of Month
+// @Tags Spends
+// @Router /api/spends [get]
+// @Param params query models.GetSpendsReq true "Month id"
+// @Produce json
+// @Success 200 {object} models.GetSpendsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Month doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendsHandlers) GetSpends(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetSpendsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	spends, err := h.db.GetSpends(ctx, req.MonthID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrMonthNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Spends", err)
+		}
+		return
+	}
+
+	resp := &models.GetSpendsResp{
+		Spends: spends,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
 // @Summary Create Spend
 // @Tags Spends
 // @Router /api/spends [post]
 // @Accept json
 // @Param body body models.AddSpendReq true "New Spend"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
 // @Produce json
 // @Success 201 {object} models.AddSpendResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Day doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Day doesn't exist"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendsHandlers) AddSpend(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -80,11 +165,14 @@ func (h SpendsHandlers) AddSpend(w http.ResponseWriter, r *http.Request) {
 // @Router /api/spends [put]
 // @Accept json
 // @Param body body models.EditSpendReq true "Updated Spend"
+// @Param If-Match header string false "Expected version of the Spend"
 // @Produce json
-// @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Spend doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Success 200 {object} models.EditSpendResp
+// @Header 200 {string} ETag "New version of the Spend"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend doesn't exist"
+// @Failure 409 {object} models.GetSpendResp "Spend was changed by another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendsHandlers) EditSpend(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -97,24 +185,33 @@ func (h SpendsHandlers) EditSpend(w http.ResponseWriter, r *http.Request) {
 	}
 	log = log.WithRequest(req)
 
+	version, err := utils.ExpectedVersion(r, req.Version)
+	if err != nil {
+		utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		return
+	}
+
 	// Process
 	args := db.EditSpendArgs{
-		ID:     req.ID,
-		Title:  req.Title,
-		Notes:  req.Notes,
-		TypeID: req.TypeID,
+		ID:      req.ID,
+		Title:   req.Title,
+		Notes:   req.Notes,
+		TypeID:  req.TypeID,
+		Version: version,
 	}
 	if req.Cost != nil {
 		cost := money.FromFloat(*req.Cost)
 		args.Cost = &cost
 	}
-	err := h.db.EditSpend(ctx, args)
+	newVersion, err := h.db.EditSpend(ctx, args)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrSpendNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
 		case errors.Is(err, db.ErrSpendTypeNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		case errors.Is(err, db.ErrVersionConflict):
+			h.encodeVersionConflict(ctx, w, log, err, req.ID)
 		default:
 			utils.EncodeInternalError(ctx, w, log, "couldn't edit Spend", err)
 		}
@@ -122,7 +219,28 @@ func (h SpendsHandlers) EditSpend(w http.ResponseWriter, r *http.Request) {
 	}
 	log.Debug("Spend was successfully edited")
 
-	utils.Encode(ctx, w, log)
+	utils.SetETag(w, newVersion)
+	resp := &models.EditSpendResp{
+		Version: newVersion,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// encodeVersionConflict encodes the version conflict error with the current Spend
+func (h SpendsHandlers) encodeVersionConflict(ctx context.Context, w http.ResponseWriter, log logger.Logger,
+	err error, id uint) {
+
+	spend, getErr := h.db.GetSpend(ctx, id)
+	if getErr != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get the current Spend", getErr)
+		return
+	}
+
+	utils.SetETag(w, spend.Version)
+	resp := &models.GetSpendResp{
+		Spend: spend,
+	}
+	utils.EncodeError(ctx, w, log, err, http.StatusConflict, utils.EncodeResponse(resp))
 }
 
 // @Summary Remove Spend
@@ -132,9 +250,9 @@ func (h SpendsHandlers) EditSpend(w http.ResponseWriter, r *http.Request) {
 // @Param body body models.RemoveSpendReq true "Updated Spend"
 // @Produce json
 // @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Spend doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendsHandlers) RemoveSpend(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -0,0 +1,211 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type SpendRulesHandlers struct {
+	db  SpendRulesDB
+	log logger.Logger
+}
+
+type SpendRulesDB interface {
+	GetSpendRules(ctx context.Context) ([]db.SpendRule, error)
+	AddSpendRule(ctx context.Context, args db.AddSpendRuleArgs) (id uint, err error)
+	EditSpendRule(ctx context.Context, args db.EditSpendRuleArgs) error
+	RemoveSpendRule(ctx context.Context, id uint) error
+	ApplySpendRules(ctx context.Context) (count int, err error)
+}
+
+// @Summary Get All Spend Rules
+// @Tags Spend Rules
+// @Router /api/spend-rules [get]
+// @Produce json
+// @Success 200 {object} models.GetSpendRulesResp
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendRulesHandlers) GetSpendRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	rules, err := h.db.GetSpendRules(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Spend Rules", err)
+		return
+	}
+
+	resp := &models.GetSpendRulesResp{
+		SpendRules: rules,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Create Spend Rule
+// @Tags Spend Rules
+// @Router /api/spend-rules [post]
+// @Accept json
+// @Param body body models.AddSpendRuleReq true "New Spend Rule"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 201 {object} models.AddSpendRuleResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendRulesHandlers) AddSpendRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.AddSpendRuleReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args := db.AddSpendRuleArgs{
+		TitlePattern: req.TitlePattern,
+		TypeID:       req.TypeID,
+		Notes:        req.Notes,
+	}
+	id, err := h.db.AddSpendRule(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpendTypeNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't add Spend Rule", err)
+		}
+		return
+	}
+	log = log.WithField("id", id)
+	log.Debug("Spend Rule was successfully added")
+
+	resp := &models.AddSpendRuleResp{
+		ID: id,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp), utils.EncodeStatusCode(http.StatusCreated))
+}
+
+// @Summary Edit Spend Rule
+// @Tags Spend Rules
+// @Router /api/spend-rules [put]
+// @Accept json
+// @Param body body models.EditSpendRuleReq true "Updated Spend Rule"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend Rule doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendRulesHandlers) EditSpendRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.EditSpendRuleReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args := db.EditSpendRuleArgs{
+		ID:           req.ID,
+		TitlePattern: req.TitlePattern,
+		TypeID:       req.TypeID,
+		Notes:        req.Notes,
+	}
+	err := h.db.EditSpendRule(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpendRuleNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		case errors.Is(err, db.ErrSpendTypeNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't edit Spend Rule", err)
+		}
+		return
+	}
+	log.Debug("Spend Rule was successfully edited")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Remove Spend Rule
+// @Tags Spend Rules
+// @Router /api/spend-rules [delete]
+// @Accept json
+// @Param body body models.RemoveSpendRuleReq true "Spend Rule id"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend Rule doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendRulesHandlers) RemoveSpendRule(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RemoveSpendRuleReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	err := h.db.RemoveSpendRule(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpendRuleNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't remove Spend Rule", err)
+		}
+		return
+	}
+	log.Debug("Spend Rule was successfully removed")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Apply Spend Rules to Spends without type
+// @Tags Spend Rules
+// @Router /api/spend-rules/apply [post]
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 200 {object} models.ApplySpendRulesResp
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendRulesHandlers) ApplySpendRules(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	count, err := h.db.ApplySpendRules(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't apply Spend Rules", err)
+		return
+	}
+	log.WithField("count", count).Debug("Spend Rules were successfully applied")
+
+	resp := &models.ApplySpendRulesResp{
+		Count: count,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
@@ -18,18 +18,60 @@ type SpendTypesHandlers struct {
 }
 
 type SpendTypesDB interface {
+	GetSpendType(ctx context.Context, id uint) (db.SpendType, error)
 	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
 	AddSpendType(ctx context.Context, args db.AddSpendTypeArgs) (id uint, err error)
-	EditSpendType(ctx context.Context, args db.EditSpendTypeArgs) error
+	EditSpendType(ctx context.Context, args db.EditSpendTypeArgs) (version int, err error)
 	RemoveSpendType(ctx context.Context, id uint) error
 }
 
+// @Summary Get Spend Type
+// @Tags Spend Types
+// @Router /api/spend-types/{id} [get]
+// @Param id path int true "Spend Type id"
+// @Produce json
+// @Success 200 {object} models.GetSpendTypeResp
+// @Header 200 {string} ETag "Version of the Spend Type"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend Type doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h SpendTypesHandlers) GetSpendType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetSpendTypeReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	spendType, err := h.db.GetSpendType(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrSpendTypeNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Spend Type", err)
+		}
+		return
+	}
+
+	utils.SetETag(w, spendType.Version)
+	resp := &models.GetSpendTypeResp{
+		SpendType: spendType,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
 // @Summary Get All Spend Types
 // @Tags Spend Types
 // @Router /api/spend-types [get]
 // @Produce json
 // @Success 200 {object} models.GetSpendTypesResp
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendTypesHandlers) GetSpendTypes(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -53,10 +95,13 @@ func (h SpendTypesHandlers) GetSpendTypes(w http.ResponseWriter, r *http.Request
 // @Router /api/spend-types [post]
 // @Accept json
 // @Param body body models.AddSpendTypeReq true "New Spend Type"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
 // @Produce json
 // @Success 201 {object} models.AddSpendTypeResp
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendTypesHandlers) AddSpendType(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -93,11 +138,14 @@ func (h SpendTypesHandlers) AddSpendType(w http.ResponseWriter, r *http.Request)
 // @Router /api/spend-types [put]
 // @Accept json
 // @Param body body models.EditSpendTypeReq true "Updated Spend Type"
+// @Param If-Match header string false "Expected version of the Spend Type"
 // @Produce json
-// @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Spend Type doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Success 200 {object} models.EditSpendTypeResp
+// @Header 200 {string} ETag "New version of the Spend Type"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend Type doesn't exist"
+// @Failure 409 {object} models.GetSpendTypeResp "Spend Type was changed by another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendTypesHandlers) EditSpendType(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
@@ -129,17 +177,26 @@ func (h SpendTypesHandlers) EditSpendType(w http.ResponseWriter, r *http.Request
 		}
 	}
 
+	version, err := utils.ExpectedVersion(r, req.Version)
+	if err != nil {
+		utils.EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		return
+	}
+
 	// Process
 	args := db.EditSpendTypeArgs{
 		ID:       req.ID,
 		Name:     req.Name,
 		ParentID: req.ParentID,
+		Version:  version,
 	}
-	err := h.db.EditSpendType(ctx, args)
+	newVersion, err := h.db.EditSpendType(ctx, args)
 	if err != nil {
 		switch {
 		case errors.Is(err, db.ErrSpendTypeNotExist):
 			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		case errors.Is(err, db.ErrVersionConflict):
+			h.encodeVersionConflict(ctx, w, log, err, req.ID)
 		default:
 			utils.EncodeInternalError(ctx, w, log, "couldn't edit Spend Type", err)
 		}
@@ -147,7 +204,28 @@ func (h SpendTypesHandlers) EditSpendType(w http.ResponseWriter, r *http.Request
 	}
 	log.Debug("Spend Type was successfully edited")
 
-	utils.Encode(ctx, w, log)
+	utils.SetETag(w, newVersion)
+	resp := &models.EditSpendTypeResp{
+		Version: newVersion,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// encodeVersionConflict encodes the version conflict error with the current Spend Type
+func (h SpendTypesHandlers) encodeVersionConflict(ctx context.Context, w http.ResponseWriter, log logger.Logger,
+	err error, id uint) {
+
+	spendType, getErr := h.db.GetSpendType(ctx, id)
+	if getErr != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get the current Spend Type", getErr)
+		return
+	}
+
+	utils.SetETag(w, spendType.Version)
+	resp := &models.GetSpendTypeResp{
+		SpendType: spendType,
+	}
+	utils.EncodeError(ctx, w, log, err, http.StatusConflict, utils.EncodeResponse(resp))
 }
 
 func checkSpendTypeForCycle(spendTypesSlice []db.SpendType, originalID, newParentID uint) (hasCycle bool, _ error) {
@@ -185,9 +263,9 @@ func checkSpendTypeForCycle(spendTypesSlice []db.SpendType, originalID, newParen
 // @Param body body models.RemoveSpendTypeReq true "Spend Type id"
 // @Produce json
 // @Success 200 {object} models.Response
-// @Failure 400 {object} models.Response "Invalid request"
-// @Failure 404 {object} models.Response "Spend Type doesn't exist"
-// @Failure 500 {object} models.Response "Internal error"
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Spend Type doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
 //
 func (h SpendTypesHandlers) RemoveSpendType(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
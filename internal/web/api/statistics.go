@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/pages/statistics"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type StatisticsHandlers struct {
+	db  StatisticsDB
+	log logger.Logger
+}
+
+type StatisticsDB interface {
+	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
+	GetSearchSpendsStats(ctx context.Context, args db.SearchSpendsArgs) (db.SearchSpendsStats, error)
+}
+
+// @Summary Get amounts spent by Spend Type
+// @Description Spends are filtered with the search args. Sort, Order, Limit and Offset are ignored
+// @Tags Statistics
+// @Router /api/statistics/spent-by-spend-type [get]
+// @Param params query models.SearchSpendsReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.GetSpentBySpendTypeResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h StatisticsHandlers) GetSpentBySpendType(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.SearchSpendsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args, err := newSearchSpendsArgs(ctx, h.db, req)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+	stats, err := h.db.GetSearchSpendsStats(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't calculate statistics", err)
+		return
+	}
+	spendTypes, err := h.db.GetSpendTypes(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Spend Types", err)
+		return
+	}
+
+	resp := &models.GetSpentBySpendTypeResp{
+		Datasets: statistics.CalculateSpentBySpendType(spendTypes, stats.SpentByType),
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get amounts spent by day
+// @Description Spends are filtered with the search args. Sort, Order, Limit and Offset are ignored
+// @Tags Statistics
+// @Router /api/statistics/spent-by-day [get]
+// @Param params query models.SearchSpendsReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.GetSpentByDayResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h StatisticsHandlers) GetSpentByDay(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.SearchSpendsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args, err := newSearchSpendsArgs(ctx, h.db, req)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+	stats, err := h.db.GetSearchSpendsStats(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't calculate statistics", err)
+		return
+	}
+
+	resp := &models.GetSpentByDayResp{
+		Dataset: statistics.CalculateSpentByDay(stats.SpentByDay, req.After, req.Before),
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get cost intervals
+// @Description Spends are filtered with the search args and grouped into intervals by cost.
+// @Description Costs less than the 5th percentile and greater than the 95th percentile are ignored.
+// @Description Sort, Order, Limit and Offset are ignored
+// @Tags Statistics
+// @Router /api/statistics/cost-intervals [get]
+// @Param params query models.GetCostIntervalsReq true "Search args"
+// @Produce json
+// @Success 200 {object} models.GetCostIntervalsResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h StatisticsHandlers) GetCostIntervals(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetCostIntervalsReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args, err := newSearchSpendsArgs(ctx, h.db, &req.SearchSpendsReq)
+	if err != nil {
+		encodeSearchSpendsArgsError(ctx, w, log, err)
+		return
+	}
+	stats, err := h.db.GetSearchSpendsStats(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't calculate statistics", err)
+		return
+	}
+
+	resp := &models.GetCostIntervalsResp{
+		Intervals: statistics.CalculateCostIntervals(stats.Costs, req.IntervalNumber),
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
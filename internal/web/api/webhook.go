@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+type WebhooksHandlers struct {
+	db  WebhooksDB
+	log logger.Logger
+}
+
+type WebhooksDB interface {
+	GetWebhooks(ctx context.Context) ([]db.Webhook, error)
+	GetWebhook(ctx context.Context, id uint) (db.Webhook, error)
+	AddWebhook(ctx context.Context, args db.AddWebhookArgs) (id uint, err error)
+	EditWebhook(ctx context.Context, args db.EditWebhookArgs) error
+	RemoveWebhook(ctx context.Context, id uint) error
+	GetWebhookDeliveries(ctx context.Context, args db.GetWebhookDeliveriesArgs) ([]db.WebhookDelivery, error)
+}
+
+// @Summary Get All Webhooks
+// @Tags Webhooks
+// @Router /api/webhooks [get]
+// @Produce json
+// @Success 200 {object} models.GetWebhooksResp
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Process
+	webhooks, err := h.db.GetWebhooks(ctx)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't get Webhooks", err)
+		return
+	}
+
+	resp := &models.GetWebhooksResp{
+		Webhooks: webhooks,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Get Webhook
+// @Tags Webhooks
+// @Router /api/webhooks/{id} [get]
+// @Param id path int true "Webhook id"
+// @Produce json
+// @Success 200 {object} models.GetWebhookResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Webhook doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetWebhookReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	webhook, err := h.db.GetWebhook(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrWebhookNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Webhook", err)
+		}
+		return
+	}
+
+	resp := &models.GetWebhookResp{
+		Webhook: webhook,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// @Summary Create Webhook
+// @Description Webhook is called with POST request on changes of Incomes, Monthly Payments and Spends.
+// @Description The body is signed with HMAC-SHA256, see README for details
+// @Tags Webhooks
+// @Router /api/webhooks [post]
+// @Accept json
+// @Param body body models.AddWebhookReq true "New Webhook"
+// @Param Idempotency-Key header string false "Unique key to safely retry the request"
+// @Produce json
+// @Success 201 {object} models.AddWebhookResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 409 {object} models.ErrorResp "Request with the same Idempotency-Key is being processed"
+// @Failure 422 {object} models.ErrorResp "Idempotency-Key was already used for another request"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) AddWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.AddWebhookReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	// Don't log the secret
+	log = log.WithFields(logger.Fields{"url": req.URL, "events": req.Events, "enabled": req.Enabled})
+
+	// Process
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		secret, err = generateWebhookSecret()
+		if err != nil {
+			utils.EncodeInternalError(ctx, w, log, "couldn't generate Webhook secret", err)
+			return
+		}
+	}
+	args := db.AddWebhookArgs{
+		URL:     req.URL,
+		Secret:  secret,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+	}
+	id, err := h.db.AddWebhook(ctx, args)
+	if err != nil {
+		utils.EncodeInternalError(ctx, w, log, "couldn't add Webhook", err)
+		return
+	}
+	log = log.WithField("id", id)
+	log.Debug("Webhook was successfully added")
+
+	resp := &models.AddWebhookResp{
+		ID:     id,
+		Secret: secret,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp), utils.EncodeStatusCode(http.StatusCreated),
+		utils.EncodeNoStore())
+}
+
+// @Summary Edit Webhook
+// @Tags Webhooks
+// @Router /api/webhooks [put]
+// @Accept json
+// @Param body body models.EditWebhookReq true "Updated Webhook"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Webhook doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) EditWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.EditWebhookReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	// Don't log the secret
+	log = log.WithFields(logger.Fields{"id": req.ID, "url": req.URL, "events": req.Events, "enabled": req.Enabled})
+
+	// Process
+	args := db.EditWebhookArgs{
+		ID:      req.ID,
+		URL:     req.URL,
+		Secret:  req.Secret,
+		Events:  req.Events,
+		Enabled: req.Enabled,
+	}
+	err := h.db.EditWebhook(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrWebhookNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't edit Webhook", err)
+		}
+		return
+	}
+	log.Debug("Webhook was successfully edited")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Remove Webhook
+// @Description All deliveries of the Webhook are removed too
+// @Tags Webhooks
+// @Router /api/webhooks [delete]
+// @Accept json
+// @Param body body models.RemoveWebhookReq true "Webhook id"
+// @Produce json
+// @Success 200 {object} models.Response
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Webhook doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) RemoveWebhook(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.RemoveWebhookReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	err := h.db.RemoveWebhook(ctx, req.ID)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrWebhookNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't remove Webhook", err)
+		}
+		return
+	}
+	log.Debug("Webhook was successfully removed")
+
+	utils.Encode(ctx, w, log)
+}
+
+// @Summary Get Webhook deliveries
+// @Description Delivery log, the newest deliveries go first
+// @Tags Webhooks
+// @Router /api/webhooks/deliveries [get]
+// @Param params query models.GetWebhookDeliveriesReq true "Filters"
+// @Produce json
+// @Success 200 {object} models.GetWebhookDeliveriesResp
+// @Failure 400 {object} models.ErrorResp "Invalid request"
+// @Failure 404 {object} models.ErrorResp "Webhook doesn't exist"
+// @Failure 500 {object} models.ErrorResp "Internal error"
+//
+func (h WebhooksHandlers) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	// Decode
+	req := &models.GetWebhookDeliveriesReq{}
+	if ok := utils.DecodeRequest(w, r, log, req); !ok {
+		return
+	}
+	log = log.WithRequest(req)
+
+	// Process
+	args := db.GetWebhookDeliveriesArgs{
+		WebhookID: req.WebhookID,
+		Status:    req.Status,
+		Limit:     req.Limit,
+	}
+	deliveries, err := h.db.GetWebhookDeliveries(ctx, args)
+	if err != nil {
+		switch {
+		case errors.Is(err, db.ErrWebhookNotExist):
+			utils.EncodeError(ctx, w, log, err, http.StatusNotFound)
+		default:
+			utils.EncodeInternalError(ctx, w, log, "couldn't get Webhook deliveries", err)
+		}
+		return
+	}
+
+	resp := &models.GetWebhookDeliveriesResp{
+		Deliveries: deliveries,
+	}
+	utils.Encode(ctx, w, log, utils.EncodeResponse(resp))
+}
+
+// generateWebhookSecret returns a random hex-encoded 32-byte secret
+func generateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -3,7 +3,11 @@ package web
 import (
 	"encoding"
 	"errors"
+	"net"
 	"strings"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
 )
 
 type Config struct { //nolint:maligned
@@ -15,8 +19,20 @@ type Config struct { //nolint:maligned
 	// EnableProfiling can be used to enable pprof handlers
 	EnableProfiling bool
 
+	// TrustedProxies is a list of ips and subnets of proxies that are allowed to pass the client ip
+	// in 'X-Forwarded-For' header
+	TrustedProxies TrustedProxies
+
 	// Auth contains auth configuration
 	Auth AuthConfig
+
+	// CalendarReminder defines when a reminder is triggered before a due date of a Monthly Payment
+	// in the calendar feed. Zero value disables reminders
+	CalendarReminder time.Duration
+
+	// IdempotencyKeyTTL defines how long responses of POST requests with 'Idempotency-Key' header are stored.
+	// Zero value disables idempotency keys
+	IdempotencyKeyTTL time.Duration
 }
 
 type AuthConfig struct {
@@ -24,11 +40,68 @@ type AuthConfig struct {
 	Disable bool
 
 	// BasicAuthCreds is a list of pairs 'login:password' separated by comma.
-	// Passwords must be hashed using BCrypt
+	// Passwords must be hashed using BCrypt. Suffix ':totp' makes two-factor authentication
+	// mandatory for the user
 	BasicAuthCreds Credentials
+
+	// SessionTTL defines how long a session lasts after login
+	SessionTTL time.Duration
+
+	// RememberMeTTL defines how long a session lasts after login with 'Remember me' option
+	RememberMeTTL time.Duration
+
+	// SecureCookie defines whether the session cookie is sent only over HTTPS
+	SecureCookie bool
+
+	// Lockout defines when ips and usernames are locked after failed auth attempts
+	Lockout lockout.Config
+
+	// Proxy configures auth with a header set by a reverse proxy
+	Proxy ProxyAuthConfig
+}
+
+// ProxyAuthConfig configures auth with a header set by a reverse proxy that has already authenticated
+// the user, for example Authelia or oauth2-proxy
+type ProxyAuthConfig struct {
+	// Header is a name of the header with a username, for example 'Remote-User'. Empty value disables
+	// proxy auth
+	Header string
+
+	// TrustedProxies is a list of ips and subnets of proxies that are allowed to pass the header
+	TrustedProxies TrustedProxies
+}
+
+// Validate checks that the header and the proxies are configured together. A header without proxies
+// could be passed by anyone
+func (c ProxyAuthConfig) Validate() error {
+	switch {
+	case c.Header == "" && len(c.TrustedProxies) > 0:
+		return errors.New("trusted proxies are specified, but header is empty")
+	case c.Header != "" && len(c.TrustedProxies) == 0:
+		return errors.New("header is specified, but list of trusted proxies is empty")
+	case c.Header != "" && !isValidHeaderName(c.Header):
+		return errors.New("invalid header name")
+	}
+	return nil
 }
 
-type Credentials map[string]string
+func isValidHeaderName(name string) bool {
+	for _, r := range name {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+type Credentials map[string]Credential
+
+// Credential contains a password hash of the user and auth requirements
+type Credential struct {
+	PasswordHash string
+	// RequireTOTP defines whether the user must use two-factor authentication
+	RequireTOTP bool
+}
 
 var _ encoding.TextUnmarshaler = (*Credentials)(nil)
 
@@ -38,7 +111,7 @@ func (c *Credentials) UnmarshalText(text []byte) error {
 	pairs := strings.Split(string(text), ",")
 	for _, pair := range pairs {
 		split := strings.Split(pair, ":")
-		if len(split) != 2 {
+		if len(split) != 2 && len(split) != 3 {
 			return errors.New("invalid credential pair")
 		}
 
@@ -48,7 +121,15 @@ func (c *Credentials) UnmarshalText(text []byte) error {
 			return errors.New("credentials can't be empty")
 		}
 
-		m[login] = password
+		cred := Credential{PasswordHash: password}
+		if len(split) == 3 {
+			if split[2] != "totp" {
+				return errors.New("invalid credential option, only 'totp' is supported")
+			}
+			cred.RequireTOTP = true
+		}
+
+		m[login] = cred
 	}
 
 	*c = m
@@ -57,6 +138,58 @@ func (c *Credentials) UnmarshalText(text []byte) error {
 }
 
 func (c Credentials) Get(username string) (secret string, ok bool) {
-	secret, ok = c[username]
-	return secret, ok
+	cred, ok := c[username]
+	return cred.PasswordHash, ok
+}
+
+func (c Credentials) RequiresTOTP(username string) bool {
+	return c[username].RequireTOTP
+}
+
+// TrustedProxies is a list of ips and subnets separated by comma
+type TrustedProxies []*net.IPNet
+
+var _ encoding.TextUnmarshaler = (*TrustedProxies)(nil)
+
+func (p *TrustedProxies) UnmarshalText(text []byte) error {
+	var res TrustedProxies
+	for _, value := range strings.Split(string(text), ",") {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			continue
+		}
+
+		if !strings.Contains(value, "/") {
+			ip := net.ParseIP(value)
+			if ip == nil {
+				return errors.New("invalid trusted proxy ip")
+			}
+			bits := 8 * net.IPv6len
+			if ip4 := ip.To4(); ip4 != nil {
+				ip = ip4
+				bits = 8 * net.IPv4len
+			}
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, subnet, err := net.ParseCIDR(value)
+		if err != nil {
+			return errors.New("invalid trusted proxy subnet")
+		}
+		res = append(res, subnet)
+	}
+
+	*p = res
+
+	return nil
+}
+
+func (p TrustedProxies) IsTrusted(ip net.IP) bool {
+	for _, subnet := range p {
+		if subnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,33 @@
+package web
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProxyAuthConfig_Validate(t *testing.T) {
+	t.Parallel()
+
+	var proxies TrustedProxies
+	require.NoError(t, proxies.UnmarshalText([]byte("10.0.0.0/8")))
+
+	for _, tt := range []struct {
+		name    string
+		cfg     ProxyAuthConfig
+		wantErr bool
+	}{
+		{name: "disabled", cfg: ProxyAuthConfig{}},
+		{name: "valid", cfg: ProxyAuthConfig{Header: "Remote-User", TrustedProxies: proxies}},
+		{name: "no proxies", cfg: ProxyAuthConfig{Header: "Remote-User"}, wantErr: true},
+		{name: "no header", cfg: ProxyAuthConfig{TrustedProxies: proxies}, wantErr: true},
+		{name: "invalid header", cfg: ProxyAuthConfig{Header: "Remote User", TrustedProxies: proxies}, wantErr: true},
+	} {
+		err := tt.cfg.Validate()
+		if tt.wantErr {
+			require.Error(t, err, tt.name)
+		} else {
+			require.NoError(t, err, tt.name)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package lockout tracks failed auth attempts and temporarily locks ips and usernames after
+// too many failed attempts in a row
+package lockout
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+type Config struct {
+	// Threshold is a number of failed attempts in a row after which auth attempts are locked.
+	// Zero value disables lockouts
+	Threshold int
+
+	// Duration is a duration of the first lockout. Every next failed attempt doubles the duration
+	Duration time.Duration
+
+	// MaxDuration limits the duration of a lockout. Failed attempts are forgotten after this time
+	MaxDuration time.Duration
+}
+
+type DB interface {
+	GetAuthLockout(ctx context.Context, kind db.AuthLockoutKind, value string) (db.AuthLockout, error)
+	AddAuthFailure(ctx context.Context, args db.AddAuthFailureArgs) (db.AuthLockout, error)
+	ResetAuthLockout(ctx context.Context, kind db.AuthLockoutKind, value string) error
+}
+
+// LockedError is returned when auth attempts are locked
+type LockedError struct {
+	Until time.Time
+}
+
+func (LockedError) Error() string {
+	return "too many failed auth attempts"
+}
+
+// RetryAfter returns a number of seconds for 'Retry-After' header
+func (e LockedError) RetryAfter(now time.Time) int {
+	seconds := int(math.Ceil(e.Until.Sub(now).Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	return seconds
+}
+
+// Key identifies an ip or a username
+type Key struct {
+	Kind  db.AuthLockoutKind
+	Value string
+}
+
+// IPKey returns a key for the ip of the request. Use it after 'RealIPMiddleware'
+func IPKey(r *http.Request) Key {
+	ip := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	return Key{Kind: db.AuthLockoutIP, Value: ip}
+}
+
+func UsernameKey(username string) Key {
+	return Key{Kind: db.AuthLockoutUsername, Value: username}
+}
+
+type Limiter struct {
+	config Config
+	db     DB
+}
+
+func NewLimiter(cfg Config, db DB) *Limiter {
+	return &Limiter{
+		config: cfg,
+		db:     db,
+	}
+}
+
+// Attempt is an auth attempt with a set of keys. It must be finished with 'Failed' or 'Succeeded' call
+type Attempt struct {
+	limiter  *Limiter
+	lockouts map[Key]db.AuthLockout
+	// reserved defines whether the attempt has been already recorded as failed
+	reserved bool
+}
+
+// Check returns a new auth attempt for passed keys. It returns 'LockedError' if any key is locked,
+// so the credentials must not be checked
+func (l *Limiter) Check(ctx context.Context, keys ...Key) (*Attempt, error) {
+	attempt := &Attempt{
+		limiter:  l,
+		lockouts: make(map[Key]db.AuthLockout, len(keys)),
+	}
+	if l.config.Threshold <= 0 {
+		return attempt, nil
+	}
+
+	now := time.Now()
+	var lockedUntil time.Time
+	for _, key := range keys {
+		lockout, err := l.db.GetAuthLockout(ctx, key.Kind, key.Value)
+		if err != nil && !errors.Is(err, db.ErrAuthLockoutNotExist) {
+			return nil, errors.Wrap(err, "couldn't get Auth Lockout")
+		}
+		// Empty lockout is saved for the missing keys as well
+		attempt.lockouts[key] = lockout
+
+		if lockout.IsLocked(now) && lockout.LockedUntil.After(lockedUntil) {
+			lockedUntil = *lockout.LockedUntil
+		}
+	}
+	if !lockedUntil.IsZero() {
+		return nil, LockedError{Until: lockedUntil}
+	}
+	return attempt, nil
+}
+
+// Reserve returns a new auth attempt for passed keys that is recorded as failed before the credentials
+// are checked. So, concurrent attempts can't check more credentials than the threshold allows: the attempt
+// that reaches the threshold locks the keys before its credentials are checked. 'Succeeded' call removes
+// the failure. It returns 'LockedError' if any key is locked, the credentials must not be checked
+func (l *Limiter) Reserve(ctx context.Context, keys ...Key) (*Attempt, error) {
+	attempt, err := l.Check(ctx, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if len(attempt.lockouts) == 0 {
+		return attempt, nil
+	}
+
+	attempt.reserved = true
+	if err := attempt.addFailures(ctx); err != nil {
+		return nil, err
+	}
+	return attempt, nil
+}
+
+// Failed records a failed attempt for all keys. Reserved attempts are already recorded
+func (a *Attempt) Failed(ctx context.Context) error {
+	if a.reserved {
+		return nil
+	}
+
+	err := a.addFailures(ctx)
+	var lockedErr LockedError
+	if errors.As(err, &lockedErr) {
+		// A concurrent attempt has locked the keys
+		return nil
+	}
+	return err
+}
+
+// addFailures records a failed attempt for all keys. Failures are counted by the db, so concurrent attempts
+// can't bypass the threshold. It returns 'LockedError' if any key has been locked by a concurrent attempt
+func (a *Attempt) addFailures(ctx context.Context) error {
+	now := time.Now()
+	var lockedUntil time.Time
+	for key := range a.lockouts {
+		lockout, err := a.limiter.db.AddAuthFailure(ctx, db.AddAuthFailureArgs{
+			Kind:              key.Kind,
+			Value:             key.Value,
+			FailedAt:          now,
+			RemoveStaleBefore: now.Add(-a.limiter.config.MaxDuration),
+			LockedUntil: func(failures int) *time.Time {
+				return a.limiter.config.lockedUntil(failures, now)
+			},
+		})
+		switch {
+		case errors.Is(err, db.ErrAuthLockoutLocked):
+			if lockout.LockedUntil != nil && lockout.LockedUntil.After(lockedUntil) {
+				lockedUntil = *lockout.LockedUntil
+			}
+		case err != nil:
+			return errors.Wrap(err, "couldn't save failed attempt")
+		}
+	}
+	if !lockedUntil.IsZero() {
+		return LockedError{Until: lockedUntil}
+	}
+	return nil
+}
+
+// Succeeded resets failed attempts of all keys. The db is not touched if there were no failed attempts
+func (a *Attempt) Succeeded(ctx context.Context) error {
+	for key, lockout := range a.lockouts {
+		if lockout.Failures == 0 && !a.reserved {
+			continue
+		}
+		if err := a.limiter.db.ResetAuthLockout(ctx, key.Kind, key.Value); err != nil {
+			return errors.Wrap(err, "couldn't reset Auth Lockout")
+		}
+	}
+	return nil
+}
+
+// CheckPassword checks the password of the user. Attempts are reserved before the check and tracked for
+// the request ip and the username if the user exists. It returns 'LockedError' if auth attempts are locked,
+// the password is not checked in this case
+func (l *Limiter) CheckPassword(r *http.Request, creds auth.Credentials, username, password string) (bool, error) {
+	ctx := r.Context()
+
+	keys := []Key{IPKey(r)}
+	if _, ok := creds.Get(username); ok {
+		keys = append(keys, UsernameKey(username))
+	}
+	attempt, err := l.Reserve(ctx, keys...)
+	if err != nil {
+		return false, err
+	}
+
+	if !auth.CheckPassword(creds, username, password) {
+		return false, attempt.Failed(ctx)
+	}
+	return true, attempt.Succeeded(ctx)
+}
+
+// lockedUntil returns the end of the lockout after the passed number of failed attempts. It returns nil
+// if the number is below the threshold. Every failed attempt after the threshold doubles the duration
+func (cfg Config) lockedUntil(failures int, now time.Time) *time.Time {
+	if failures < cfg.Threshold {
+		return nil
+	}
+
+	duration := cfg.Duration
+	for i := cfg.Threshold; i < failures && duration < cfg.MaxDuration; i++ {
+		duration *= 2
+	}
+	if duration > cfg.MaxDuration {
+		duration = cfg.MaxDuration
+	}
+	until := now.Add(duration)
+	return &until
+}
@@ -0,0 +1,197 @@
+package lockout
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+)
+
+func TestLockedUntil(t *testing.T) {
+	t.Parallel()
+
+	cfg := Config{Threshold: 3, Duration: time.Minute, MaxDuration: 10 * time.Minute}
+	now := time.Date(2021, time.March, 10, 12, 0, 0, 0, time.UTC)
+	timePtr := func(t time.Time) *time.Time { return &t }
+
+	for _, tt := range []struct {
+		desc            string
+		failures        int
+		wantLockedUntil *time.Time
+	}{
+		{
+			desc:     "first failure",
+			failures: 1,
+		},
+		{
+			desc:     "below threshold",
+			failures: 2,
+		},
+		{
+			desc:            "first lockout",
+			failures:        3,
+			wantLockedUntil: timePtr(now.Add(time.Minute)),
+		},
+		{
+			desc:            "doubled lockout",
+			failures:        5,
+			wantLockedUntil: timePtr(now.Add(4 * time.Minute)),
+		},
+		{
+			desc:            "max lockout",
+			failures:        101,
+			wantLockedUntil: timePtr(now.Add(10 * time.Minute)),
+		},
+	} {
+		require.Equal(t, tt.wantLockedUntil, cfg.lockedUntil(tt.failures, now), tt.desc)
+	}
+}
+
+type mockDB struct {
+	lockouts map[Key]db.AuthLockout
+}
+
+func (m *mockDB) GetAuthLockout(_ context.Context, kind db.AuthLockoutKind, value string) (db.AuthLockout, error) {
+	lockout, ok := m.lockouts[Key{kind, value}]
+	if !ok {
+		return db.AuthLockout{}, db.ErrAuthLockoutNotExist
+	}
+	return lockout, nil
+}
+
+func (m *mockDB) AddAuthFailure(_ context.Context, args db.AddAuthFailureArgs) (db.AuthLockout, error) {
+	key := Key{args.Kind, args.Value}
+	lockout := m.lockouts[key]
+	if lockout.IsLocked(args.FailedAt) {
+		return lockout, db.ErrAuthLockoutLocked
+	}
+	lockout.Kind, lockout.Value = args.Kind, args.Value
+	lockout.Failures++
+	lockout.LastFailedAt = args.FailedAt
+	if until := args.LockedUntil(lockout.Failures); until != nil {
+		lockout.LockedUntil = until
+	}
+	m.lockouts[key] = lockout
+	return lockout, nil
+}
+
+func (m *mockDB) ResetAuthLockout(_ context.Context, kind db.AuthLockoutKind, value string) error {
+	delete(m.lockouts, Key{kind, value})
+	return nil
+}
+
+func TestLimiter(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	var (
+		ctx     = context.Background()
+		storage = &mockDB{lockouts: make(map[Key]db.AuthLockout)}
+		limiter = NewLimiter(Config{Threshold: 2, Duration: time.Minute, MaxDuration: time.Hour}, storage)
+		ip      = Key{db.AuthLockoutIP, "127.0.0.1"}
+		user    = UsernameKey("user")
+	)
+
+	attempt, err := limiter.Check(ctx, ip, user)
+	require.Nil(err)
+	require.Nil(attempt.Failed(ctx))
+
+	// Another ip is not locked
+	attempt, err = limiter.Check(ctx, Key{db.AuthLockoutIP, "127.0.0.2"})
+	require.Nil(err)
+	require.Nil(attempt.Succeeded(ctx))
+
+	attempt, err = limiter.Check(ctx, ip, user)
+	require.Nil(err)
+	require.Nil(attempt.Failed(ctx))
+
+	_, err = limiter.Check(ctx, user)
+	require.IsType(LockedError{}, err)
+	require.Equal(60, err.(LockedError).RetryAfter(time.Now())) //nolint:errorlint
+
+	// Reset a lockout
+	require.Nil(storage.ResetAuthLockout(ctx, user.Kind, user.Value))
+	require.Nil(storage.ResetAuthLockout(ctx, ip.Kind, ip.Value))
+	attempt, err = limiter.Check(ctx, ip, user)
+	require.Nil(err)
+	require.Nil(attempt.Failed(ctx))
+
+	attempt, err = limiter.Check(ctx, ip, user)
+	require.Nil(err)
+	require.Nil(attempt.Succeeded(ctx))
+	require.Empty(storage.lockouts)
+
+	// Lockouts are disabled
+	limiter = NewLimiter(Config{}, storage)
+	for i := 0; i < 5; i++ {
+		attempt, err = limiter.Check(ctx, ip, user)
+		require.Nil(err)
+		require.Nil(attempt.Failed(ctx))
+	}
+	require.Empty(storage.lockouts)
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	var (
+		ctx     = context.Background()
+		storage = &mockDB{lockouts: make(map[Key]db.AuthLockout)}
+		limiter = NewLimiter(Config{Threshold: 2, Duration: time.Minute, MaxDuration: time.Hour}, storage)
+		ip      = Key{db.AuthLockoutIP, "127.0.0.1"}
+		user    = UsernameKey("user")
+	)
+
+	// The attempt is recorded as failed before the credentials are checked
+	attempt, err := limiter.Reserve(ctx, ip, user)
+	require.Nil(err)
+	require.Equal(1, storage.lockouts[ip].Failures)
+	require.Equal(1, storage.lockouts[user].Failures)
+
+	// Success removes the failure
+	require.Nil(attempt.Succeeded(ctx))
+	require.Empty(storage.lockouts)
+
+	// Concurrent attempts: the second one reaches the threshold and locks the keys before
+	// the credentials of both attempts are checked
+	first, err := limiter.Reserve(ctx, ip, user)
+	require.Nil(err)
+	second, err := limiter.Reserve(ctx, ip, user)
+	require.Nil(err)
+	require.True(storage.lockouts[user].IsLocked(time.Now()))
+
+	_, err = limiter.Reserve(ctx, ip, user)
+	require.IsType(LockedError{}, err)
+
+	// Failed calls don't record the reserved attempts again
+	require.Nil(first.Failed(ctx))
+	require.Nil(second.Failed(ctx))
+	require.Equal(2, storage.lockouts[user].Failures)
+
+	// Attempt that has checked the keys before the lock can't add more failures
+	require.Nil(storage.ResetAuthLockout(ctx, user.Kind, user.Value))
+	require.Nil(storage.ResetAuthLockout(ctx, ip.Kind, ip.Value))
+
+	stale, err := limiter.Check(ctx, ip)
+	require.Nil(err)
+	for i := 0; i < 2; i++ {
+		_, err := limiter.Reserve(ctx, ip)
+		require.Nil(err)
+	}
+	require.Nil(stale.Failed(ctx))
+	require.Equal(2, storage.lockouts[ip].Failures)
+
+	// Lockouts are disabled
+	limiter = NewLimiter(Config{}, storage)
+	require.Nil(storage.ResetAuthLockout(ctx, ip.Kind, ip.Value))
+	attempt, err = limiter.Reserve(ctx, ip, user)
+	require.Nil(err)
+	require.Empty(storage.lockouts)
+	require.Nil(attempt.Succeeded(ctx))
+}
@@ -1,50 +1,333 @@
 package middlewares
 
 import (
+	"context"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
-	"golang.org/x/crypto/bcrypt"
-
+	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
 	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
 )
 
-type Credentials interface {
-	Get(username string) (secret string, ok bool)
+type APITokensDB interface {
+	GetAPITokenByHash(ctx context.Context, tokenHash string) (db.APIToken, error)
+	UpdateAPITokenLastUsedAt(ctx context.Context, id uint, lastUsedAt time.Time) error
 }
 
-func BasicAuthMiddleware(h http.Handler, creds Credentials, log logger.Logger) http.Handler {
-	errUnauthorized := errors.New("unauthorized")
+type SessionsDB interface {
+	GetSession(ctx context.Context, tokenHash string, now time.Time) (db.Session, error)
+}
 
-	checkAuth := func(r *http.Request) bool {
-		username, password, ok := r.BasicAuth()
-		if !ok {
-			return false
-		}
-		hashedPassword, ok := creds.Get(username)
-		if !ok {
-			return false
-		}
+type TOTPDB interface {
+	GetTOTP(ctx context.Context, username string) (db.TOTP, error)
+}
 
-		return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password)) == nil
-	}
+type AuthDB interface {
+	APITokensDB
+	SessionsDB
+	TOTPDB
+}
+
+// ProxyAuth configures auth with a header set by a reverse proxy
+type ProxyAuth struct {
+	// Header is a name of the header with a username. Empty value disables proxy auth
+	Header string
+	// Proxies is a list of proxies allowed to pass the header
+	Proxies TrustedProxies
+}
+
+// apiTokenLastUsedAtPrecision is used to not update the last usage time of API Token on every request
+const apiTokenLastUsedAtPrecision = time.Minute
+
+var (
+	errUnauthorized     = errors.New("unauthorized")
+	errInvalidAPIToken  = errors.New("invalid API Token")
+	errAPITokenReadOnly = errors.New("API Token is read-only")
+	errAPITokenEndpoint = errors.New("API Token doesn't have access to this endpoint")
+	errTOTPRequired     = errors.New("two-factor authentication is required")
+	errBasicAuthTOTP    = errors.New("Basic Auth can't be used with two-factor authentication, use API Tokens instead")
+	errUntrustedProxy   = errors.New("auth header can be passed only by trusted proxies")
+	errInvalidProxyUser = errors.New("auth header must contain a single known username")
+)
+
+// AuthMiddleware checks that a request is authorized with a header set by a trusted proxy, a session
+// cookie, an API Token passed in 'Authorization: Bearer' header or with Basic Auth. The authenticated user
+// is added to the request context. Unauthorized page requests are redirected to the login page, and users
+// that haven't passed two-factor authentication - to the verification page. Failed Basic Auth and API Token
+// attempts are tracked by the limiter
+func AuthMiddleware(h http.Handler, creds auth.Credentials, storage AuthDB, proxyAuth ProxyAuth,
+	limiter *lockout.Limiter, log logger.Logger) http.Handler {
 
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		log := reqid.FromContextToLogger(ctx, log)
 		log = log.WithFields(logger.Fields{"ip": r.RemoteAddr})
 
-		if !checkAuth(r) {
-			log.Warn("invalid auth request")
+		user, err := authenticate(r, creds, storage, proxyAuth, limiter)
+		if isPublicPath(r.URL.Path) {
+			// Public pages can use the user if it is known
+			if err == nil {
+				r = r.WithContext(auth.ToContext(ctx, user))
+			}
+			h.ServeHTTP(w, r)
+			return
+		}
 
-			w.Header().Set("WWW-Authenticate", `Basic realm="Budget Manager"`)
-			utils.EncodeError(ctx, w, log, errUnauthorized, http.StatusUnauthorized)
+		if user.Username != "" {
+			log = log.WithField("username", user.Username)
+		}
+		if err == nil && user.TOTPPending {
+			err = errTOTPRequired
+		}
+		if err != nil {
+			writeAuthError(w, r, log, user, err)
 			return
 		}
 
 		log.Debug("successful auth request")
-		h.ServeHTTP(w, r)
+		h.ServeHTTP(w, r.WithContext(auth.ToContext(ctx, user)))
 	})
 }
+
+func writeAuthError(w http.ResponseWriter, r *http.Request, log logger.Logger, user auth.User, err error) {
+	ctx := r.Context()
+
+	var lockedErr lockout.LockedError
+	switch {
+	case errors.As(err, &lockedErr):
+		log.WithField("locked_until", lockedErr.Until).Warn("auth attempts are locked")
+
+		w.Header().Set("Retry-After", strconv.Itoa(lockedErr.RetryAfter(time.Now())))
+		utils.EncodeError(ctx, w, log, lockedErr, http.StatusTooManyRequests)
+	case errors.Is(err, errUnauthorized):
+		log.Warn("invalid auth request")
+
+		if r.Method == http.MethodGet && !strings.HasPrefix(r.URL.Path, "/api/") {
+			http.Redirect(w, r, "/login?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		w.Header().Set("WWW-Authenticate", `Basic realm="Budget Manager"`)
+		utils.EncodeError(ctx, w, log, err, http.StatusUnauthorized)
+	case errors.Is(err, errTOTPRequired):
+		log.Debug("two-factor authentication is required")
+
+		if r.Method == http.MethodGet && !strings.HasPrefix(r.URL.Path, "/api/") {
+			http.Redirect(w, r, "/login/totp?next="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+			return
+		}
+		utils.EncodeError(ctx, w, log, err, http.StatusUnauthorized)
+	case errors.Is(err, errBasicAuthTOTP):
+		log.Warn("Basic Auth request of user with two-factor authentication")
+
+		utils.EncodeError(ctx, w, log, err, http.StatusUnauthorized)
+	case errors.Is(err, errUntrustedProxy), errors.Is(err, errInvalidProxyUser):
+		log.WithField("peer_addr", getPeerAddr(r)).Warn("invalid proxy auth request")
+
+		utils.EncodeError(ctx, w, log, err, http.StatusUnauthorized)
+	case errors.Is(err, errInvalidAPIToken):
+		log.Warn("invalid API Token")
+
+		w.Header().Set("WWW-Authenticate", `Bearer realm="Budget Manager", error="invalid_token"`)
+		utils.EncodeError(ctx, w, log, err, http.StatusUnauthorized)
+	case errors.Is(err, errAPITokenReadOnly), errors.Is(err, errAPITokenEndpoint):
+		log.WithField("api_token_id", user.APITokenID).Warn("request is out of API Token scope")
+
+		utils.EncodeError(ctx, w, log, err, http.StatusForbidden)
+	default:
+		utils.EncodeInternalError(ctx, w, log, "couldn't check auth", err)
+	}
+}
+
+// isPublicPath checks whether the path can be accessed without auth
+func isPublicPath(path string) bool {
+	return path == "/login" || path == "/login/totp" || path == "/logout" || strings.HasPrefix(path, "/static/")
+}
+
+// authenticate checks the proxy header, a session cookie, API Token and Basic Auth credentials. Basic Auth
+// and API Tokens are checked even if the session has expired. Other methods are not checked if the proxy
+// header is passed
+func authenticate(r *http.Request, creds auth.Credentials, storage AuthDB, proxyAuth ProxyAuth,
+	limiter *lockout.Limiter) (auth.User, error) {
+
+	if user, ok, err := checkProxyHeader(r, creds, proxyAuth); ok {
+		return user, err
+	}
+	if cookie, cookieErr := r.Cookie(auth.SessionCookieName); cookieErr == nil {
+		user, err := checkSession(r.Context(), cookie.Value, creds, storage)
+		if !errors.Is(err, errUnauthorized) {
+			return user, err
+		}
+	}
+	if token, ok := getBearerToken(r); ok {
+		return checkAPITokenWithLimiter(r, token, creds, storage, limiter)
+	}
+	return checkBasicAuth(r, creds, storage, limiter)
+}
+
+// checkProxyHeader checks the username passed by a trusted proxy. It returns false if proxy auth is disabled
+// or the header is not passed. The header from other hosts is rejected: otherwise, anyone could pass it
+// by accessing the app directly
+func checkProxyHeader(r *http.Request, creds auth.Credentials, proxyAuth ProxyAuth) (auth.User, bool, error) {
+	if proxyAuth.Header == "" {
+		return auth.User{}, false, nil
+	}
+	values := r.Header.Values(proxyAuth.Header)
+	if len(values) == 0 {
+		return auth.User{}, false, nil
+	}
+	if !isTrustedAddr(getPeerAddr(r), proxyAuth.Proxies) {
+		return auth.User{}, true, errUntrustedProxy
+	}
+	// Multiple values mean that the proxy has appended its header to the one passed by the client
+	if len(values) != 1 {
+		return auth.User{}, true, errInvalidProxyUser
+	}
+
+	user := auth.User{Username: strings.TrimSpace(values[0])}
+	if _, ok := creds.Get(user.Username); !ok {
+		return user, true, errInvalidProxyUser
+	}
+	return user, true, nil
+}
+
+// checkBasicAuth checks Basic Auth credentials. Requests without credentials are not counted as failed attempts.
+// Basic Auth can't be used by users with two-factor authentication because every request contains the password
+func checkBasicAuth(r *http.Request, creds auth.Credentials, totps TOTPDB,
+	limiter *lockout.Limiter) (auth.User, error) {
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return auth.User{}, errUnauthorized
+	}
+	ok, err := limiter.CheckPassword(r, creds, username, password)
+	if err != nil {
+		return auth.User{}, err
+	}
+	if !ok {
+		return auth.User{}, errUnauthorized
+	}
+
+	user := auth.User{Username: username}
+	if creds.RequiresTOTP(username) {
+		return user, errBasicAuthTOTP
+	}
+	totp, err := totps.GetTOTP(r.Context(), username)
+	switch {
+	case errors.Is(err, db.ErrTOTPNotExist):
+		return user, nil
+	case err != nil:
+		return user, errors.Wrap(err, "couldn't get TOTP")
+	case totp.Enabled:
+		return user, errBasicAuthTOTP
+	}
+	return user, nil
+}
+
+// checkAPITokenWithLimiter tracks failed API Token attempts for the request ip. Tokens are long enough
+// to not be guessed, but invalid tokens still mean that somebody tries to get access
+func checkAPITokenWithLimiter(r *http.Request, token string, creds auth.Credentials, tokens APITokensDB,
+	limiter *lockout.Limiter) (auth.User, error) {
+
+	ctx := r.Context()
+
+	attempt, err := limiter.Check(ctx, lockout.IPKey(r))
+	if err != nil {
+		return auth.User{}, err
+	}
+	user, err := checkAPIToken(r, token, creds, tokens)
+	switch {
+	case errors.Is(err, errInvalidAPIToken):
+		if failedErr := attempt.Failed(ctx); failedErr != nil {
+			return user, failedErr
+		}
+	case err == nil:
+		if succeededErr := attempt.Succeeded(ctx); succeededErr != nil {
+			return user, succeededErr
+		}
+	}
+	return user, err
+}
+
+func checkSession(ctx context.Context, token string, creds auth.Credentials, sessions SessionsDB) (auth.User, error) {
+	session, err := sessions.GetSession(ctx, auth.HashToken(token), time.Now())
+	if err != nil {
+		if errors.Is(err, db.ErrSessionNotExist) {
+			return auth.User{}, errUnauthorized
+		}
+		return auth.User{}, errors.Wrap(err, "couldn't get Session")
+	}
+	// The user could be removed after the login
+	if _, ok := creds.Get(session.Username); !ok {
+		return auth.User{}, errUnauthorized
+	}
+	return auth.User{Username: session.Username, SessionID: session.ID, TOTPPending: session.TOTPPending}, nil
+}
+
+// checkAPIToken checks that API Token exists and allows the request. It updates the last usage time of the token
+func checkAPIToken(r *http.Request, token string, creds auth.Credentials, tokens APITokensDB) (auth.User, error) {
+	ctx := r.Context()
+
+	apiToken, err := tokens.GetAPITokenByHash(ctx, auth.HashToken(token))
+	if err != nil {
+		if errors.Is(err, db.ErrAPITokenNotExist) {
+			return auth.User{}, errInvalidAPIToken
+		}
+		return auth.User{}, errors.Wrap(err, "couldn't get API Token")
+	}
+	// The user could be removed after the token creation
+	if _, ok := creds.Get(apiToken.Username); !ok {
+		return auth.User{}, errInvalidAPIToken
+	}
+
+	user := auth.User{Username: apiToken.Username, APITokenID: apiToken.ID}
+	if !isAPITokenEndpoint(apiToken, r.URL.Path) {
+		return user, errAPITokenEndpoint
+	}
+	if apiToken.ReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return user, errAPITokenReadOnly
+	}
+
+	now := time.Now()
+	if apiToken.LastUsedAt == nil || now.Sub(*apiToken.LastUsedAt) >= apiTokenLastUsedAtPrecision {
+		if err := tokens.UpdateAPITokenLastUsedAt(ctx, apiToken.ID, now); err != nil {
+			return user, errors.Wrap(err, "couldn't update last usage time of API Token")
+		}
+	}
+	return user, nil
+}
+
+// isAPITokenEndpoint checks whether API Token can be used for the passed path. Tokens can be used
+// only for API requests
+func isAPITokenEndpoint(token db.APIToken, path string) bool {
+	if !strings.HasPrefix(path, "/api/") {
+		return false
+	}
+	if len(token.Endpoints) == 0 {
+		return true
+	}
+	for _, endpoint := range token.Endpoints {
+		if path == endpoint || strings.HasPrefix(path, endpoint+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// getBearerToken returns a token from 'Authorization: Bearer <token>' header
+func getBearerToken(r *http.Request) (string, bool) {
+	const prefix = "Bearer "
+
+	header := r.Header.Get("Authorization")
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	return strings.TrimSpace(header[len(prefix):]), true
+}
@@ -0,0 +1,153 @@
+package middlewares
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/csrf"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+var (
+	errInvalidCSRFToken = errors.New("invalid CSRF token")
+	errCrossOrigin      = errors.New("cross-origin requests are not allowed")
+)
+
+// CSRFMiddleware protects state-changing requests of browsers from Cross-Site Request Forgery. The token
+// of the request is added to the context, so it can be embedded into pages. Requests with methods other than
+// GET, HEAD and OPTIONS must pass the token in 'X-CSRF-Token' header or 'csrf_token' form field, and their
+// 'Origin' or 'Referer' header must match the host.
+//
+// Requests authenticated with API Tokens are not checked because browsers don't send the tokens automatically.
+// Requests without cookies, 'Origin' and 'Referer' headers are considered to be sent by non-browser clients,
+// for example, scripts with Basic Auth, so they are not checked either
+func CSRFMiddleware(h http.Handler, secureCookie bool, log logger.Logger) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log := reqid.FromContextToLogger(ctx, log)
+
+		token, cookie, err := getCSRFToken(r, secureCookie)
+		if err != nil {
+			utils.EncodeInternalError(ctx, w, log, "couldn't get CSRF token", err)
+			return
+		}
+		if cookie != nil {
+			http.SetCookie(w, cookie)
+		}
+		r = r.WithContext(csrf.ToContext(ctx, token))
+
+		if err := checkCSRF(r, token); err != nil {
+			log.WithFields(logger.Fields{
+				"origin":  r.Header.Get("Origin"),
+				"referer": r.Header.Get("Referer"),
+			}).WithError(err).Warn("CSRF check failed")
+
+			utils.EncodeError(ctx, w, log, err, http.StatusForbidden,
+				utils.EncodeErrorCode(models.ErrorCodeCSRFCheckFailed))
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// getCSRFToken returns the token of the request. Login sessions have their own tokens. Other requests use
+// a random token from the cookie. A new cookie is returned for requests of pages that don't have a valid one
+func getCSRFToken(r *http.Request, secureCookie bool) (token string, newCookie *http.Cookie, err error) {
+	if cookie, cookieErr := r.Cookie(auth.SessionCookieName); cookieErr == nil && cookie.Value != "" {
+		return csrf.SessionToken(cookie.Value), nil, nil
+	}
+	if cookie, cookieErr := r.Cookie(csrf.CookieName); cookieErr == nil && csrf.IsValidToken(cookie.Value) {
+		return cookie.Value, nil, nil
+	}
+	if !isSafeMethod(r.Method) {
+		// The token can't be passed without the cookie
+		return "", nil, nil
+	}
+
+	token, err = csrf.NewToken()
+	if err != nil {
+		return "", nil, err
+	}
+	newCookie = &http.Cookie{
+		Name:     csrf.CookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   secureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+	return token, newCookie, nil
+}
+
+func checkCSRF(r *http.Request, token string) error {
+	if isSafeMethod(r.Method) {
+		return nil
+	}
+	if user, ok := auth.FromContext(r.Context()); ok && user.APITokenID != 0 {
+		return nil
+	}
+
+	origin, ok := getRequestOrigin(r)
+	if ok && !strings.EqualFold(origin, r.Host) {
+		return errCrossOrigin
+	}
+	if !ok && len(r.Cookies()) == 0 {
+		// Non-browser client
+		return nil
+	}
+
+	if !csrf.Equal(token, getPassedCSRFToken(r)) {
+		return errInvalidCSRFToken
+	}
+	return nil
+}
+
+func isSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return true
+	default:
+		return false
+	}
+}
+
+// getRequestOrigin returns the host of the page that has sent the request. Browsers send 'Origin' header
+// with all cross-origin requests. 'Referer' header is used for older browsers
+func getRequestOrigin(r *http.Request) (host string, ok bool) {
+	value := r.Header.Get("Origin")
+	if value == "" {
+		value = r.Header.Get("Referer")
+	}
+	if value == "" {
+		return "", false
+	}
+
+	// 'Origin: null' is sent by sandboxed pages and for some redirects. Its host is empty, so it never matches
+	u, err := url.Parse(value)
+	if err != nil {
+		return "", true
+	}
+	return u.Host, true
+}
+
+// getPassedCSRFToken returns the token from the header or the form field. The form is parsed only
+// for url-encoded bodies, so handlers can still read other bodies
+func getPassedCSRFToken(r *http.Request) string {
+	if token := r.Header.Get(csrf.HeaderName); token != "" {
+		return token
+	}
+	if mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil &&
+		mediaType == "application/x-www-form-urlencoded" {
+
+		return r.PostFormValue(csrf.FormFieldName)
+	}
+	return ""
+}
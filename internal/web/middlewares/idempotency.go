@@ -0,0 +1,205 @@
+package middlewares
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
+)
+
+const (
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader is set for responses that are returned from the storage
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	maxIdempotencyKeyLength = 255
+)
+
+type IdempotencyKeysDB interface {
+	GetIdempotencyKey(ctx context.Context, key string, now time.Time) (db.IdempotencyKey, error)
+	AddIdempotencyKey(ctx context.Context, args db.AddIdempotencyKeyArgs) error
+	SaveIdempotentResponse(ctx context.Context, args db.SaveIdempotentResponseArgs) error
+	RemoveIdempotencyKey(ctx context.Context, key string) error
+}
+
+// IdempotencyMiddleware handles 'Idempotency-Key' header of POST API requests. The response of the first
+// request with a key is stored for the passed ttl. Retries with the same key get the stored response
+// instead of being processed again. Responses with 5xx status codes and responses with secrets
+// ("Cache-Control: no-store" header) are not stored. Keys are scoped by users, so a user can't get
+// a response to a request of another user
+func IdempotencyMiddleware(h http.Handler, storage IdempotencyKeysDB, ttl time.Duration,
+	log logger.Logger) http.Handler {
+
+	var (
+		errInvalidKey  = errors.New("invalid Idempotency-Key header")
+		errKeyReused   = errors.New("Idempotency-Key was already used for another request")
+		errKeyInFlight = errors.New("request with the same Idempotency-Key is being processed")
+
+		keyReusedCode   = utils.EncodeErrorCode(models.ErrorCodeIdempotencyKeyReused)
+		keyInFlightCode = utils.EncodeErrorCode(models.ErrorCodeIdempotencyKeyInFlight)
+	)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get(IdempotencyKeyHeader)
+		if key == "" || r.Method != http.MethodPost || !strings.HasPrefix(r.URL.Path, "/api/") {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		log := reqid.FromContextToLogger(ctx, log)
+		log = log.WithField("idempotency_key", key)
+
+		if !isValidIdempotencyKey(key) {
+			utils.EncodeError(ctx, w, log, errInvalidKey, http.StatusBadRequest)
+			return
+		}
+
+		body, err := io.ReadAll(utils.LimitRequestBody(w, r))
+		if err != nil {
+			if utils.IsRequestBodyTooLarge(err) {
+				utils.EncodeRequestBodyTooLargeError(ctx, w, log)
+				return
+			}
+			utils.EncodeError(ctx, w, log, errors.Wrap(err, "couldn't read body"), http.StatusBadRequest)
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		requestHash := hashIdempotentRequest(r, body)
+		key = scopeIdempotencyKey(ctx, key)
+
+		now := time.Now()
+		err = storage.AddIdempotencyKey(ctx, db.AddIdempotencyKeyArgs{
+			Key:         key,
+			RequestHash: requestHash,
+			CreatedAt:   now,
+			ExpiresAt:   now.Add(ttl),
+		})
+		if err != nil {
+			if !errors.Is(err, db.ErrIdempotencyKeyExists) {
+				utils.EncodeInternalError(ctx, w, log, "couldn't save Idempotency-Key", err)
+				return
+			}
+
+			stored, getErr := storage.GetIdempotencyKey(ctx, key, now)
+			switch {
+			case errors.Is(getErr, db.ErrIdempotencyKeyNotExist):
+				// The key has been removed after a failed request or has just expired
+				utils.EncodeError(ctx, w, log, errKeyInFlight, http.StatusConflict, keyInFlightCode)
+			case getErr != nil:
+				utils.EncodeInternalError(ctx, w, log, "couldn't get Idempotency-Key", getErr)
+			case stored.RequestHash != requestHash:
+				utils.EncodeError(ctx, w, log, errKeyReused, http.StatusUnprocessableEntity, keyReusedCode)
+			case stored.StatusCode == 0:
+				utils.EncodeError(ctx, w, log, errKeyInFlight, http.StatusConflict, keyInFlightCode)
+			default:
+				log.Debug("replay stored response")
+
+				w.Header().Set("Content-Type", stored.ContentType)
+				w.Header().Set(IdempotentReplayedHeader, "true")
+				w.WriteHeader(stored.StatusCode)
+				_, _ = io.WriteString(w, stored.ResponseBody)
+			}
+			return
+		}
+
+		recorder := newResponseRecorder(w)
+		h.ServeHTTP(recorder, r)
+
+		// Use a new context because the request one can be already canceled
+		ctx = context.Background()
+		if recorder.statusCode >= http.StatusInternalServerError || isNoStoreResponse(recorder.Header()) {
+			if err := storage.RemoveIdempotencyKey(ctx, key); err != nil {
+				log.WithError(err).Error("couldn't remove Idempotency-Key of a response that can't be stored")
+			}
+			return
+		}
+		err = storage.SaveIdempotentResponse(ctx, db.SaveIdempotentResponseArgs{
+			Key:          key,
+			StatusCode:   recorder.statusCode,
+			ContentType:  recorder.Header().Get("Content-Type"),
+			ResponseBody: recorder.body.String(),
+		})
+		if err != nil {
+			log.WithError(err).Error("couldn't save response for Idempotency-Key")
+		}
+	})
+}
+
+func isValidIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLength {
+		return false
+	}
+	for _, c := range key {
+		if c < '!' || c > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// isNoStoreResponse reports whether the response contains secrets that must not be stored
+func isNoStoreResponse(header http.Header) bool {
+	for _, directive := range strings.Split(header.Get("Cache-Control"), ",") {
+		if strings.EqualFold(strings.TrimSpace(directive), "no-store") {
+			return true
+		}
+	}
+	return false
+}
+
+// scopeIdempotencyKey prefixes the key with the username. Keys can't contain spaces, so the username
+// and the key are separated by a space
+func scopeIdempotencyKey(ctx context.Context, key string) string {
+	if user, ok := auth.FromContext(ctx); ok {
+		return user.Username + " " + key
+	}
+	return key
+}
+
+// hashIdempotentRequest returns a hash of method, url and body of the request
+func hashIdempotentRequest(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(r.Method + " " + r.URL.RequestURI() + "\n"))
+	hash.Write([]byte(strconv.Itoa(len(body)) + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// responseRecorder passes the response to the original 'http.ResponseWriter' and saves a copy
+type responseRecorder struct {
+	http.ResponseWriter
+
+	statusCode int
+	body       bytes.Buffer
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{
+		ResponseWriter: w,
+		statusCode:     http.StatusOK,
+	}
+}
+
+func (w *responseRecorder) WriteHeader(statusCode int) {
+	w.statusCode = statusCode
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
@@ -64,3 +64,10 @@ func (w *responseWriter) Write(data []byte) (int, error) {
 	w.contentLength += len(data)
 	return w.ResponseWriter.Write(data)
 }
+
+// Flush implements 'http.Flusher' interface. It is required to stream responses, for example, Server-Sent Events
+func (w *responseWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,76 @@
+package middlewares
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const forwardedForHeader = "X-Forwarded-For"
+
+type TrustedProxies interface {
+	IsTrusted(ip net.IP) bool
+}
+
+type peerAddrContextKey struct{}
+
+// RealIPMiddleware replaces the remote address of requests from trusted proxies with the client ip
+// from 'X-Forwarded-For' header. The header is read from right to left, the first untrusted ip is
+// considered to be the client ip. The header is ignored for requests from other addresses. The original
+// remote address can be retrieved with 'getPeerAddr'
+func RealIPMiddleware(h http.Handler, proxies TrustedProxies) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ip, ok := getRealIP(r, proxies); ok {
+			r = r.WithContext(context.WithValue(r.Context(), peerAddrContextKey{}, r.RemoteAddr))
+			r.RemoteAddr = ip
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+// getPeerAddr returns the address of the host that has sent the request. It differs from the remote address
+// if the request has been sent by a trusted proxy
+func getPeerAddr(r *http.Request) string {
+	if addr, ok := r.Context().Value(peerAddrContextKey{}).(string); ok {
+		return addr
+	}
+	return r.RemoteAddr
+}
+
+// isTrustedAddr checks whether the ip of the passed address is trusted
+func isTrustedAddr(addr string, proxies TrustedProxies) bool {
+	host := addr
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && proxies.IsTrusted(ip)
+}
+
+func getRealIP(r *http.Request, proxies TrustedProxies) (string, bool) {
+	if !isTrustedAddr(r.RemoteAddr, proxies) {
+		return "", false
+	}
+
+	// Values of multiple headers are concatenated
+	addrs := strings.Split(strings.Join(r.Header.Values(forwardedForHeader), ","), ",")
+
+	var realIP string
+	for i := len(addrs) - 1; i >= 0; i-- {
+		addr := strings.TrimSpace(addrs[i])
+		if addr == "" {
+			continue
+		}
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			// The rest of the header can't be trusted
+			break
+		}
+		realIP = ip.String()
+		if !proxies.IsTrusted(ip) {
+			break
+		}
+	}
+	return realIP, realIP != ""
+}
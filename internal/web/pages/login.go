@@ -0,0 +1,215 @@
+package pages
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
+)
+
+const (
+	invalidCredentialsMessage = "Invalid username or password"
+	lockedMessage             = "Too many failed login attempts. Try again later"
+)
+
+type loginPageData struct {
+	Username string
+	// Next is a url the user is redirected to after login
+	Next  string
+	Error string
+	//
+	Footer FooterTemplateData
+}
+
+// GET /login?next=/months
+//
+// Authorized users are redirected to the next page. Users that haven't passed two-factor authentication
+// can log in again
+func (h Handlers) LoginPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	next := getNextURL(r)
+	if user, ok := auth.FromContext(ctx); (ok && !user.TOTPPending) || h.auth.Disable {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	h.executeLoginPage(ctx, log, w, loginPageData{Next: next})
+}
+
+// POST /login
+//
+// The form contains 'username', 'password', 'remember' and 'next' fields. A new session is created after
+// a successful login. The session cookie is persistent only if 'remember' is set. Users with two-factor
+// authentication get a pending session and are redirected to the verification page
+func (h Handlers) Login(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	next := getNextURL(r)
+	if h.auth.Disable {
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return
+	}
+
+	username := strings.TrimSpace(r.PostFormValue("username"))
+	password := r.PostFormValue("password")
+	remember := r.PostFormValue("remember") != ""
+	log = log.WithFields(logger.Fields{"username": username, "remember": remember})
+
+	ok, err := h.auth.Limiter.CheckPassword(r, h.auth.Creds, username, password)
+	if err != nil {
+		var lockedErr lockout.LockedError
+		if !errors.As(err, &lockedErr) {
+			h.processInternalErrorWithPage(ctx, log, w, "couldn't check password", err)
+			return
+		}
+		writeLockedHeaders(log, w, lockedErr)
+		h.executeLoginPage(ctx, log, w, loginPageData{Username: username, Next: next, Error: lockedMessage})
+		return
+	}
+	if !ok {
+		log.Warn("invalid login request")
+
+		h.executeLoginPage(ctx, log, w, loginPageData{
+			Username: username,
+			Next:     next,
+			Error:    invalidCredentialsMessage,
+		})
+		return
+	}
+
+	totpRequired, err := h.isTOTPRequired(ctx, username)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get TOTP"), err)
+		return
+	}
+	cookie, err := h.addSession(ctx, username, remember, totpRequired)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't add session"), err)
+		return
+	}
+	http.SetCookie(w, cookie)
+
+	if totpRequired {
+		log.Debug("user has entered the password, two-factor authentication is required")
+
+		http.Redirect(w, r, newTOTPVerificationURL(next, remember), http.StatusSeeOther)
+		return
+	}
+	log.Debug("user has logged in")
+
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// POST /logout
+//
+// The current session is removed and the user is redirected to the login page
+func (h Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	if cookie, cookieErr := r.Cookie(auth.SessionCookieName); cookieErr == nil {
+		if err := h.db.RemoveSession(ctx, auth.HashToken(cookie.Value)); err != nil {
+			h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't remove session"), err)
+			return
+		}
+	}
+	log.Debug("user has logged out")
+
+	// Remove the cookie
+	cookie := h.newSessionCookie("")
+	cookie.MaxAge = -1
+	http.SetCookie(w, cookie)
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+func (h Handlers) executeLoginPage(ctx context.Context, log logger.Logger, w http.ResponseWriter,
+	data loginPageData) {
+
+	data.Footer = h.newFooterTemplateData(ctx)
+	if err := h.tplExecutor.Execute(ctx, w, loginTemplateName, data); err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, executeErrorMessage, err)
+	}
+}
+
+// addSession creates a new session and returns its cookie. Pending sessions live only until the user
+// passes two-factor authentication, so their cookies are never persistent
+func (h Handlers) addSession(ctx context.Context, username string, remember, totpPending bool) (*http.Cookie, error) {
+	token, err := auth.NewSessionToken()
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't generate session token")
+	}
+
+	ttl := h.auth.SessionTTL
+	switch {
+	case totpPending:
+		ttl = totpPendingSessionTTL
+		remember = false
+	case remember:
+		ttl = h.auth.RememberMeTTL
+	}
+	now := time.Now()
+	args := db.AddSessionArgs{
+		Username:    username,
+		TokenHash:   auth.HashToken(token),
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(ttl),
+		TOTPPending: totpPending,
+	}
+	if _, err = h.db.AddSession(ctx, args); err != nil {
+		return nil, errors.Wrap(err, "couldn't save session")
+	}
+
+	cookie := h.newSessionCookie(token)
+	if remember {
+		cookie.Expires = args.ExpiresAt
+		cookie.MaxAge = int(ttl.Seconds())
+	}
+	return cookie, nil
+}
+
+// writeLockedHeaders writes headers of a response to a locked auth attempt. The page must be written after it
+func writeLockedHeaders(log logger.Logger, w http.ResponseWriter, lockedErr lockout.LockedError) {
+	log.WithField("locked_until", lockedErr.Until).Warn("login attempts are locked")
+
+	w.Header().Set("Retry-After", strconv.Itoa(lockedErr.RetryAfter(time.Now())))
+	w.WriteHeader(http.StatusTooManyRequests)
+}
+
+func (h Handlers) newSessionCookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     auth.SessionCookieName,
+		Value:    token,
+		Path:     "/",
+		Secure:   h.auth.SecureCookie,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// getNextURL returns 'next' param if it is a local url. Otherwise, it returns '/'. It prevents
+// redirects to other sites
+func getNextURL(r *http.Request) string {
+	const defaultURL = "/"
+
+	next := r.FormValue("next")
+	if !strings.HasPrefix(next, "/") || strings.HasPrefix(next, "//") || strings.HasPrefix(next, "/\\") {
+		return defaultURL
+	}
+	u, err := url.Parse(next)
+	if err != nil || u.Scheme != "" || u.Host != "" || u.Path == "/login" {
+		return defaultURL
+	}
+	return next
+}
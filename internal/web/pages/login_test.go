@@ -0,0 +1,33 @@
+package pages
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetNextURL(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		next string
+		want string
+	}{
+		{next: "", want: "/"},
+		{next: "/months", want: "/months"},
+		{next: "/search/spends?title=abc&sort=cost", want: "/search/spends?title=abc&sort=cost"},
+		{next: "months", want: "/"},
+		{next: "https://example.com/months", want: "/"},
+		{next: "//example.com/months", want: "/"},
+		{next: "/\\example.com/months", want: "/"},
+		{next: "/login", want: "/"},
+	} {
+		r := httptest.NewRequest("GET", "/login", nil)
+		q := r.URL.Query()
+		q.Set("next", tt.next)
+		r.URL.RawQuery = q.Encode()
+
+		require.Equal(t, tt.want, getNextURL(r), "next: %q", tt.next)
+	}
+}
@@ -13,8 +13,11 @@ import (
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/query"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
 	"github.com/ShoshinNikita/budget-manager/internal/web/pages/statistics"
 )
 
@@ -22,11 +25,18 @@ const (
 	monthsTemplateName       = "months.html"
 	monthTemplateName        = "month.html"
 	searchSpendsTemplateName = "search_spends.html"
+	apiDocsTemplateName      = "api_docs.html"
+	loginTemplateName        = "login.html"
+	totpTemplateName         = "totp.html"
 	errorPageTemplateName    = "error_page.html"
 )
 
+// searchSpendsPageSize is a max number of Spends rendered on the search page
+const searchSpendsPageSize = 100
+
 type Handlers struct {
 	db          DB
+	auth        AuthOptions
 	tplExecutor *templateExecutor
 	log         logger.Logger
 
@@ -41,11 +51,46 @@ type DB interface {
 	GetSpendTypes(ctx context.Context) ([]db.SpendType, error)
 
 	SearchSpends(ctx context.Context, args db.SearchSpendsArgs) ([]db.Spend, error)
+	GetSearchSpendsTotal(ctx context.Context, args db.SearchSpendsArgs) (db.SearchSpendsTotal, error)
+	GetSearchSpendsStats(ctx context.Context, args db.SearchSpendsArgs) (db.SearchSpendsStats, error)
+
+	GetSavedSearches(ctx context.Context) ([]db.SavedSearch, error)
+	GetSavedSearch(ctx context.Context, id uint) (db.SavedSearch, error)
+	GetSavedSearchReports(ctx context.Context, year int, month time.Month) ([]db.SavedSearchReport, error)
+
+	AddSession(ctx context.Context, args db.AddSessionArgs) (id uint, err error)
+	RemoveSession(ctx context.Context, tokenHash string) error
+
+	GetTOTP(ctx context.Context, username string) (db.TOTP, error)
+	SetTOTPSecret(ctx context.Context, username, secret string, createdAt time.Time) error
+	EnableTOTP(ctx context.Context, args db.EnableTOTPArgs) error
+	UseTOTPStep(ctx context.Context, username string, step int64) error
+	UseRecoveryCode(ctx context.Context, username, codeHash string) error
+	ReplaceRecoveryCodes(ctx context.Context, username string, codeHashes []string) error
+	RemoveTOTP(ctx context.Context, username string) error
+}
+
+// AuthOptions contains options of the login page and sessions
+type AuthOptions struct {
+	// Disable disables the login page
+	Disable bool
+	Creds   auth.Credentials
+	// SessionTTL defines how long a session lasts after login
+	SessionTTL time.Duration
+	// RememberMeTTL defines how long a session lasts after login with 'Remember me' option
+	RememberMeTTL time.Duration
+	// SecureCookie defines whether the session cookie is sent only over HTTPS
+	SecureCookie bool
+	// Limiter tracks failed login attempts
+	Limiter *lockout.Limiter
 }
 
-func NewHandlers(db DB, log logger.Logger, cacheTemplates bool, version, gitHash string) *Handlers {
+func NewHandlers(db DB, authOpts AuthOptions, log logger.Logger, cacheTemplates bool,
+	version, gitHash string) *Handlers {
+
 	return &Handlers{
 		db:          db,
+		auth:        authOpts,
 		tplExecutor: newTemplateExecutor(log, cacheTemplates, commonTemplateFuncs()),
 		log:         log,
 		//
@@ -80,6 +125,7 @@ func (h Handlers) IndexPage(w http.ResponseWriter, r *http.Request) {
 
 // GET /months?offset=0
 //
+//nolint:funlen
 func (h Handlers) MonthsPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := reqid.FromContextToLogger(ctx, h.log)
@@ -108,6 +154,12 @@ func (h Handlers) MonthsPage(w http.ResponseWriter, r *http.Request) {
 
 	months = getLastTwelveMonths(endYear, now.Month(), months)
 
+	savedSearchReports, err := h.db.GetSavedSearchReports(ctx, now.Year(), now.Month())
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get reports of Saved Searches"), err)
+		return
+	}
+
 	var totalIncome money.Money
 	for _, m := range months {
 		totalIncome = totalIncome.Add(m.TotalIncome)
@@ -136,6 +188,8 @@ func (h Handlers) MonthsPage(w http.ResponseWriter, r *http.Request) {
 		TotalSpend  money.Money
 		Result      money.Money
 		//
+		SavedSearchReports []db.SavedSearchReport
+		//
 		Footer FooterTemplateData
 		//
 		Add func(int, int) int
@@ -148,10 +202,9 @@ func (h Handlers) MonthsPage(w http.ResponseWriter, r *http.Request) {
 		TotalSpend:  totalSpend,
 		Result:      result,
 		//
-		Footer: FooterTemplateData{
-			Version: h.version,
-			GitHash: h.gitHash,
-		},
+		SavedSearchReports: savedSearchReports,
+		//
+		Footer: h.newFooterTemplateData(ctx),
 		//
 		Add: func(a, b int) int { return a + b },
 	}
@@ -264,10 +317,7 @@ func (h Handlers) MonthPage(w http.ResponseWriter, r *http.Request) {
 		MonthlyPaymentsTotalCost: monthlyPaymentsTotalCost,
 		SpendTypes:               spendTypes,
 		//
-		Footer: FooterTemplateData{
-			Version: h.version,
-			GitHash: h.gitHash,
-		},
+		Footer: h.newFooterTemplateData(ctx),
 		//
 		ToShortMonth:  toShortMonth,
 		SumSpendCosts: sumSpendCosts,
@@ -289,79 +339,174 @@ func (h Handlers) MonthPage(w http.ResponseWriter, r *http.Request) {
 // GET /search/spends
 //
 // Query Params:
+//   - query - search query (see package 'query' for the syntax)
 //   - title - spend title
 //   - notes - spend notes
+//   - text - full-text query, it is matched against titles and notes
 //   - min_cost - minimal const
 //   - max_cost - maximal cost
 //   - after - date in format 'yyyy-mm-dd'
 //   - before - date in format 'yyyy-mm-dd'
 //   - type_id - Spend Type id to search (can be passed multiple times: ?type_id=56&type_id=58).
 //               Use id '0' to search for Spends without type
-//   - sort - sort type: 'title', 'date' or 'cost'
+//   - sort - sort type: 'title', 'date', 'cost' or 'relevance'. 'relevance' is used by default if 'text' is passed
 //   - order - sort order: 'asc' or 'desc'
+//   - interval_number - max number of cost intervals
+//   - page - number of the page with Spends, starts from 1. Statistics are calculated for all found Spends
+//   - saved_search - id of a Saved Search. Other search params are ignored if it is passed
 //
+//nolint:funlen
 func (h Handlers) SearchSpendsPage(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	log := reqid.FromContextToLogger(ctx, h.log)
 
-	args := parseSearchSpendsArgs(r, log)
-	spends, err := h.db.SearchSpends(ctx, args)
+	dbSpendTypes, err := h.db.GetSpendTypes(ctx)
 	if err != nil {
-		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't complete Spend search"), err)
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get Spend Types"), err)
 		return
 	}
+	spendTypes := getSpendTypesWithFullNames(dbSpendTypes)
 
-	dbSpendTypes, err := h.db.GetSpendTypes(ctx)
+	savedSearches, err := h.db.GetSavedSearches(ctx)
 	if err != nil {
-		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get Spend Types"), err)
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get Saved Searches"), err)
+		return
+	}
+
+	var (
+		args             db.SearchSpendsArgs
+		savedSearch      *db.SavedSearch
+		savedSearchParam = r.FormValue("saved_search")
+	)
+	if savedSearchParam != "" {
+		savedSearch, err = h.getSavedSearch(ctx, savedSearchParam)
+		if err != nil {
+			h.processSavedSearchError(ctx, log, w, err)
+			return
+		}
+		args = savedSearch.Args
+	} else {
+		args = parseSearchSpendsArgs(r, log)
+		args.Expr, err = query.Parse(r.FormValue("query"), dbSpendTypes)
+		if err != nil {
+			h.processErrorWithPage(ctx, log, w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Statistics are calculated by the db for all found Spends, only Spends of the requested page are loaded
+	total, err := h.db.GetSearchSpendsTotal(ctx, args)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't count found Spends"), err)
+		return
+	}
+	stats, err := h.db.GetSearchSpendsStats(ctx, args)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't calculate statistics"), err)
+		return
+	}
+
+	pagination := newPagination(r, log, total.Count)
+	args.Limit = searchSpendsPageSize
+	args.Offset = (pagination.Page - 1) * searchSpendsPageSize
+
+	spends, err := h.db.SearchSpends(ctx, args)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't complete Spend search"), err)
 		return
 	}
-	spendTypes := getSpendTypesWithFullNames(dbSpendTypes)
 
 	populateSpendsWithFullSpendTypeNames(spendTypes, spends)
 
-	spentBySpendTypeDatasets := statistics.CalculateSpentBySpendType(dbSpendTypes, spends)
-	spentByDayDataset := statistics.CalculateSpentByDay(spends, args.After, args.Before)
-	// TODO: support custom interval number?
-	const costIntervalNumber = 15
-	costIntervals := statistics.CalculateCostIntervals(spends, costIntervalNumber)
+	spentBySpendTypeDatasets := statistics.CalculateSpentBySpendType(dbSpendTypes, stats.SpentByType)
+	spentByDayDataset := statistics.CalculateSpentByDay(stats.SpentByDay, args.After, args.Before)
+	costIntervals := statistics.CalculateCostIntervals(stats.Costs, parseCostIntervalNumber(r, log))
 
 	// Execute the template
 	resp := struct {
 		// Spends
-		Spends []db.Spend
+		Spends      []db.Spend
+		SpendNumber int
+		Pagination  Pagination
 		// Statistics
 		SpentBySpendTypeDatasets []statistics.SpentBySpendTypeDataset
 		SpentByDayDataset        statistics.SpentByDayDataset
 		CostIntervals            []statistics.CostInterval
 		TotalCost                money.Money
 		//
-		SpendTypes []SpendType
-		Footer     FooterTemplateData
+		SpendTypes    []SpendType
+		SavedSearches []db.SavedSearch
+		// SavedSearch is the current Saved Search. It is nil when filters are passed in the url
+		SavedSearch *db.SavedSearch
+		Footer      FooterTemplateData
 	}{
-		Spends: spends,
+		Spends:      spends,
+		SpendNumber: total.Count,
+		Pagination:  pagination,
 		//
 		SpentBySpendTypeDatasets: spentBySpendTypeDatasets,
 		SpentByDayDataset:        spentByDayDataset,
 		CostIntervals:            costIntervals,
-		TotalCost:                sumSpendCosts(spends),
+		TotalCost:                total.Cost,
 		//
-		SpendTypes: spendTypes,
-		Footer: FooterTemplateData{
-			Version: h.version,
-			GitHash: h.gitHash,
-		},
+		SpendTypes:    spendTypes,
+		SavedSearches: savedSearches,
+		SavedSearch:   savedSearch,
+		Footer:        h.newFooterTemplateData(ctx),
 	}
 	if err := h.tplExecutor.Execute(ctx, w, searchSpendsTemplateName, resp); err != nil {
 		h.processInternalErrorWithPage(ctx, log, w, executeErrorMessage, err)
 	}
 }
 
+// GET /docs/api
+//
+// The page loads the API documentation from '/api/docs/swagger.json' and allows to send requests
+func (h Handlers) APIDocsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	resp := struct {
+		Footer FooterTemplateData
+	}{
+		Footer: h.newFooterTemplateData(ctx),
+	}
+	if err := h.tplExecutor.Execute(ctx, w, apiDocsTemplateName, resp); err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, executeErrorMessage, err)
+	}
+}
+
+var errInvalidSavedSearchID = errors.New(newInvalidURLMessage("invalid saved_search value"))
+
+func (h Handlers) getSavedSearch(ctx context.Context, param string) (*db.SavedSearch, error) {
+	id, err := strconv.ParseUint(param, 10, 0)
+	if err != nil || id == 0 {
+		return nil, errInvalidSavedSearchID
+	}
+	search, err := h.db.GetSavedSearch(ctx, uint(id))
+	if err != nil {
+		return nil, err
+	}
+	return &search, nil
+}
+
+func (h Handlers) processSavedSearchError(ctx context.Context, log logger.Logger, w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, errInvalidSavedSearchID):
+		h.processErrorWithPage(ctx, log, w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, db.ErrSavedSearchNotExist):
+		h.processErrorWithPage(ctx, log, w, err.Error(), http.StatusNotFound)
+	default:
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get Saved Search"), err)
+	}
+}
+
 //nolint:funlen
 func parseSearchSpendsArgs(r *http.Request, log logger.Logger) db.SearchSpendsArgs {
 	// Title and Notes
 	title := strings.ToLower(strings.TrimSpace(r.FormValue("title")))
 	notes := strings.ToLower(strings.TrimSpace(r.FormValue("notes")))
+	text := strings.TrimSpace(r.FormValue("text"))
 
 	// Min and Max Costs
 	parseCost := func(paramName string) money.Money {
@@ -420,6 +565,12 @@ func parseSearchSpendsArgs(r *http.Request, log logger.Logger) db.SearchSpendsAr
 		sortType = db.SortSpendsByTitle
 	case "cost":
 		sortType = db.SortSpendsByCost
+	case "relevance":
+		sortType = db.SortSpendsByRelevance
+	case "":
+		if text != "" {
+			sortType = db.SortSpendsByRelevance
+		}
 	}
 
 	// Order
@@ -431,6 +582,7 @@ func parseSearchSpendsArgs(r *http.Request, log logger.Logger) db.SearchSpendsAr
 	return db.SearchSpendsArgs{
 		Title:   title,
 		Notes:   notes,
+		Text:    text,
 		After:   after,
 		Before:  before,
 		MinCost: minCost,
@@ -444,9 +596,84 @@ func parseSearchSpendsArgs(r *http.Request, log logger.Logger) db.SearchSpendsAr
 	}
 }
 
+func parseCostIntervalNumber(r *http.Request, log logger.Logger) int {
+	param := r.FormValue("interval_number")
+	if param == "" {
+		return statistics.DefaultCostIntervalNumber
+	}
+
+	number, err := strconv.Atoi(param)
+	if err != nil || number < 1 || number > statistics.MaxCostIntervalNumber {
+		log.WithField("interval_number", param).Warn("invalid 'interval_number' param")
+		return statistics.DefaultCostIntervalNumber
+	}
+	return number
+}
+
+type Pagination struct {
+	Page      int
+	PageCount int
+	// PrevPageURL and NextPageURL are empty for the first and the last pages respectively
+	PrevPageURL string
+	NextPageURL string
+}
+
+// newPagination returns pagination for the page passed with query param 'page'. The first page is used
+// if the param is invalid, the last one - if the page number is too large
+func newPagination(r *http.Request, log logger.Logger, spendNumber int) Pagination {
+	pageCount := (spendNumber + searchSpendsPageSize - 1) / searchSpendsPageSize
+	if pageCount == 0 {
+		pageCount = 1
+	}
+
+	page := 1
+	if param := r.FormValue("page"); param != "" {
+		number, err := strconv.Atoi(param)
+		if err != nil || number < 1 {
+			log.WithField("page", param).Warn("invalid 'page' param")
+			number = 1
+		}
+		page = number
+	}
+	if page > pageCount {
+		page = pageCount
+	}
+
+	getPageURL := func(page int) string {
+		query := r.URL.Query()
+		query.Set("page", strconv.Itoa(page))
+		return r.URL.Path + "?" + query.Encode()
+	}
+	pagination := Pagination{
+		Page:      page,
+		PageCount: pageCount,
+	}
+	if page > 1 {
+		pagination.PrevPageURL = getPageURL(page - 1)
+	}
+	if page < pageCount {
+		pagination.NextPageURL = getPageURL(page + 1)
+	}
+	return pagination
+}
+
 type FooterTemplateData struct {
 	Version string
 	GitHash string
+	// Username is specified only for users logged in with the login page. It is used to show the logout button
+	// and the link to two-factor authentication settings
+	Username string
+}
+
+func (h Handlers) newFooterTemplateData(ctx context.Context) FooterTemplateData {
+	data := FooterTemplateData{
+		Version: h.version,
+		GitHash: h.gitHash,
+	}
+	if user, ok := auth.FromContext(ctx); ok && user.SessionID != 0 && !user.TOTPPending {
+		data.Username = user.Username
+	}
+	return data
 }
 
 const (
@@ -0,0 +1,74 @@
+package pages
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+)
+
+func TestNewPagination(t *testing.T) {
+	t.Parallel()
+
+	spendNumber := searchSpendsPageSize*2 + 5
+
+	tests := []struct {
+		desc           string
+		url            string
+		spendNumber    int
+		wantPagination Pagination
+	}{
+		{
+			desc:        "no page",
+			url:         "/search/spends?title=abc",
+			spendNumber: spendNumber,
+			wantPagination: Pagination{
+				Page: 1, PageCount: 3,
+				NextPageURL: "/search/spends?page=2&title=abc",
+			},
+		},
+		{
+			desc:        "middle page",
+			url:         "/search/spends?page=2&title=abc",
+			spendNumber: spendNumber,
+			wantPagination: Pagination{
+				Page: 2, PageCount: 3,
+				PrevPageURL: "/search/spends?page=1&title=abc",
+				NextPageURL: "/search/spends?page=3&title=abc",
+			},
+		},
+		{
+			desc:        "too large page",
+			url:         "/search/spends?page=10",
+			spendNumber: spendNumber,
+			wantPagination: Pagination{
+				Page: 3, PageCount: 3,
+				PrevPageURL: "/search/spends?page=2",
+			},
+		},
+		{
+			desc:        "invalid page",
+			url:         "/search/spends?page=-1",
+			spendNumber: spendNumber,
+			wantPagination: Pagination{
+				Page: 1, PageCount: 3,
+				NextPageURL: "/search/spends?page=2",
+			},
+		},
+		{
+			desc:           "no spends",
+			url:            "/search/spends?page=2",
+			wantPagination: Pagination{Page: 1, PageCount: 1},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.desc, func(t *testing.T) {
+			r := httptest.NewRequest("GET", tt.url, nil)
+			pagination := newPagination(r, logger.New(logger.Config{Level: "fatal"}), tt.spendNumber)
+			require.Equal(t, tt.wantPagination, pagination)
+		})
+	}
+}
@@ -2,12 +2,18 @@ package statistics
 
 import (
 	"math"
-	"sort"
 
 	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 )
 
+const (
+	// DefaultCostIntervalNumber is a default max number of cost intervals
+	DefaultCostIntervalNumber = 15
+	// MaxCostIntervalNumber is a max number of cost intervals that can be requested
+	MaxCostIntervalNumber = 100
+)
+
 type CostInterval struct {
 	From money.Money `json:"from"`
 	To   money.Money `json:"to"`
@@ -16,12 +22,13 @@ type CostInterval struct {
 	Total money.Money `json:"total"`
 }
 
-func CalculateCostIntervals(spends []db.Spend, maxIntervalNumber int) (intervals []CostInterval) {
-	if len(spends) == 0 {
+// CalculateCostIntervals groups costs into intervals. It uses numbers of Spends with every cost
+// (see db.SearchSpendsStats), costs must be sorted
+func CalculateCostIntervals(costs []db.CostCount, maxIntervalNumber int) (intervals []CostInterval) {
+	if len(costs) == 0 {
 		return nil
 	}
 
-	costs := extractSortedCosts(spends)
 	for intervalNumber := maxIntervalNumber; intervalNumber > 0; intervalNumber-- {
 		intervals = prepareIntervals(costs, intervalNumber)
 		intervals = fillIntervals(costs, intervals)
@@ -51,20 +58,8 @@ func CalculateCostIntervals(spends []db.Spend, maxIntervalNumber int) (intervals
 	return intervals
 }
 
-// extractSortedCosts returns a sorted slice of Spend costs
-func extractSortedCosts(spends []db.Spend) []money.Money {
-	res := make([]money.Money, 0, len(spends))
-	for _, s := range spends {
-		res = append(res, s.Cost)
-	}
-	sort.Slice(res, func(i, j int) bool {
-		return res[i] < res[j]
-	})
-	return res
-}
-
 // prepareIntervals prepares cost intervals excluding costs less than p5 and greater than p95 (p - percentile)
-func prepareIntervals(costs []money.Money, intervalNumber int) []CostInterval {
+func prepareIntervals(costs []db.CostCount, intervalNumber int) []CostInterval {
 	min := getPercentileValue(costs, 5).Floor()
 	max := getPercentileValue(costs, 95).Ceil()
 
@@ -89,17 +84,28 @@ func prepareIntervals(costs []money.Money, intervalNumber int) []CostInterval {
 
 // getPercentileValue returns a value at the nth percentile. It uses the nearest rank method to
 // find the percentile rank - https://en.wikipedia.org/wiki/Percentile#The_nearest-rank_method
-func getPercentileValue(costs []money.Money, n int) money.Money {
-	i := float64(n) / 100 * float64(len(costs))
+func getPercentileValue(costs []db.CostCount, n int) money.Money {
+	var total int
+	for _, c := range costs {
+		total += c.Count
+	}
+
+	i := float64(n) / 100 * float64(total)
 	index := int(math.Ceil(i)) - 1
 	switch {
 	case index < 0:
 		index = 0
-	case index >= len(costs):
-		index = len(costs) - 1
+	case index >= total:
+		index = total - 1
 	}
 
-	return costs[index]
+	for _, c := range costs {
+		if index < c.Count {
+			return c.Cost
+		}
+		index -= c.Count
+	}
+	return costs[len(costs)-1].Cost
 }
 
 // biggestValueBefore returns the biggest value before 'm'. It can be used to represent open intervals - (a, b)
@@ -108,12 +114,12 @@ func biggestValueBefore(m money.Money) money.Money {
 	return m - 1
 }
 
-func fillIntervals(costs []money.Money, intervals []CostInterval) []CostInterval {
-	for _, cost := range costs {
+func fillIntervals(costs []db.CostCount, intervals []CostInterval) []CostInterval {
+	for _, c := range costs {
 		for i := range intervals {
-			if intervals[i].From <= cost && cost <= intervals[i].To {
-				intervals[i].Count++
-				intervals[i].Total = intervals[i].Total.Add(cost)
+			if intervals[i].From <= c.Cost && c.Cost <= intervals[i].To {
+				intervals[i].Count += c.Count
+				intervals[i].Total = intervals[i].Total.Add(c.Cost.Mul(int64(c.Count)))
 				break
 			}
 		}
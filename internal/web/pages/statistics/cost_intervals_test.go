@@ -1,6 +1,7 @@
 package statistics
 
 import (
+	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -94,7 +95,7 @@ func TestCalculateCostIntervals(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run("", func(t *testing.T) {
-			got := CalculateCostIntervals(tt.spends, tt.intervals)
+			got := CalculateCostIntervals(countCosts(tt.spends), tt.intervals)
 			require.Equal(t, tt.want, got)
 		})
 	}
@@ -170,7 +171,7 @@ func TestPrepareIntervals(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run("", func(t *testing.T) {
-			got := prepareIntervals(tt.costs, tt.intervals)
+			got := prepareIntervals(toCostCounts(tt.costs), tt.intervals)
 			require.Equal(t, tt.want, got)
 		})
 	}
@@ -230,9 +231,39 @@ func TestGetPercentileValue(t *testing.T) {
 		tt := tt
 		t.Run("", func(t *testing.T) {
 			for _, check := range tt.checks {
-				got := getPercentileValue(tt.data, check.p)
+				got := getPercentileValue(toCostCounts(tt.data), check.p)
 				require.Equal(t, check.want, got)
 			}
 		})
 	}
 }
+
+// toCostCounts converts sorted costs to numbers of Spends with every cost
+func toCostCounts(costs []money.Money) []db.CostCount {
+	var res []db.CostCount
+	for _, cost := range costs {
+		if len(res) != 0 && res[len(res)-1].Cost == cost {
+			res[len(res)-1].Count++
+			continue
+		}
+		res = append(res, db.CostCount{Cost: cost, Count: 1})
+	}
+	return res
+}
+
+// countCosts returns numbers of Spends with every cost sorted by cost
+func countCosts(spends []db.Spend) []db.CostCount {
+	counts := make(map[money.Money]int)
+	for _, s := range spends {
+		counts[s.Cost]++
+	}
+
+	res := make([]db.CostCount, 0, len(counts))
+	for cost, count := range counts {
+		res = append(res, db.CostCount{Cost: cost, Count: count})
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Cost < res[j].Cost
+	})
+	return res
+}
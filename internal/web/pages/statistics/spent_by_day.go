@@ -4,7 +4,6 @@ import (
 	"sort"
 	"time"
 
-	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
 )
 
@@ -18,24 +17,25 @@ type SpentByDayData struct {
 	Spent money.Money `json:"spent"`
 }
 
-func CalculateSpentByDay(spends []db.Spend, startDate, endDate time.Time) SpentByDayDataset {
-	spentByDay := make(map[time.Time]money.Money)
+// CalculateSpentByDay returns costs for every day between the dates. It uses costs of Spends by their
+// dates in UTC (see db.SearchSpendsStats)
+func CalculateSpentByDay(spentByDay map[time.Time]money.Money,
+	startDate, endDate time.Time) SpentByDayDataset {
+
+	// Don't modify the passed map
+	spentByDay = copySpentByDay(spentByDay)
+
 	var (
 		minDate = time.Date(40000, 0, 0, 0, 0, 0, 0, time.UTC)
 		maxDate = time.Date(0, 0, 0, 0, 0, 0, 0, time.UTC)
 	)
-	for _, spend := range spends {
-		t := time.Date(spend.Year, spend.Month, spend.Day, 0, 0, 0, 0, time.UTC)
+	for t := range spentByDay {
 		if t.Before(minDate) {
 			minDate = t
 		}
 		if t.After(maxDate) {
 			maxDate = t
 		}
-
-		spent := spentByDay[t]
-		spent = spent.Add(spend.Cost)
-		spentByDay[t] = spent
 	}
 
 	if !startDate.IsZero() {
@@ -73,3 +73,11 @@ func CalculateSpentByDay(spends []db.Spend, startDate, endDate time.Time) SpentB
 
 	return res
 }
+
+func copySpentByDay(spentByDay map[time.Time]money.Money) map[time.Time]money.Money {
+	res := make(map[time.Time]money.Money, len(spentByDay))
+	for t, spent := range spentByDay {
+		res[t] = spent
+	}
+	return res
+}
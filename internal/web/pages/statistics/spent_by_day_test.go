@@ -94,8 +94,18 @@ func TestCalculateSpentByDay(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run("", func(t *testing.T) {
-			got := CalculateSpentByDay(tt.spends, tt.startDate, tt.endDate)
+			got := CalculateSpentByDay(sumSpendCostsByDay(tt.spends), tt.startDate, tt.endDate)
 			require.Equal(t, tt.want, got)
 		})
 	}
 }
+
+// sumSpendCostsByDay returns costs of Spends by their dates
+func sumSpendCostsByDay(spends []db.Spend) map[time.Time]money.Money {
+	res := make(map[time.Time]money.Money)
+	for _, spend := range spends {
+		t := time.Date(spend.Year, spend.Month, spend.Day, 0, 0, 0, 0, time.UTC)
+		res[t] = res[t].Add(spend.Cost)
+	}
+	return res
+}
@@ -14,8 +14,12 @@ type SpentBySpendTypeData struct {
 	Spent         money.Money `json:"spent"`
 }
 
-func CalculateSpentBySpendType(spendTypes []db.SpendType, spends []db.Spend) []SpentBySpendTypeDataset {
-	types, depth := prepareSpendTypesForDatasets(spendTypes, spends)
+// CalculateSpentBySpendType returns datasets with costs by Spend Types and their children. It uses costs
+// of Spends by ids of their Spend Types (see db.SearchSpendsStats)
+func CalculateSpentBySpendType(spendTypes []db.SpendType,
+	spentByType map[uint]money.Money) []SpentBySpendTypeDataset {
+
+	types, depth := prepareSpendTypesForDatasets(spendTypes, spentByType)
 
 	return createSpentBySpendTypeDatasets(types, depth)
 }
@@ -30,7 +34,7 @@ type spendType struct {
 }
 
 func prepareSpendTypesForDatasets(spendTypes []db.SpendType,
-	spends []db.Spend) (types map[uint]spendType, maxChildDepth int) {
+	spentByType map[uint]money.Money) (types map[uint]spendType, maxChildDepth int) {
 
 	// Init Spend Types. Use Spend Type with id 0 for Spends without a type
 	types = make(map[uint]spendType, len(spendTypes)+1)
@@ -41,19 +45,14 @@ func prepareSpendTypesForDatasets(spendTypes []db.SpendType,
 		types[t.ID] = spendType{SpendType: t}
 	}
 
-	// Sum spend costs by Spend Type. If Spend Type has a parent, it also will be updated
-	for _, spend := range spends {
-		var typeID uint
-		if spend.Type != nil {
-			typeID = spend.Type.ID
-		}
-
+	// Sum costs by Spend Type. If Spend Type has a parent, it also will be updated
+	for typeID, spent := range spentByType {
 		t := types[typeID]
-		t.Spent = t.Spent.Add(spend.Cost)
+		t.Spent = t.Spent.Add(spent)
 		types[typeID] = t
 		for parentID := t.ParentID; parentID != 0; parentID = types[parentID].ParentID {
 			parentType := types[parentID]
-			parentType.Spent = parentType.Spent.Add(spend.Cost)
+			parentType.Spent = parentType.Spent.Add(spent)
 			types[parentID] = parentType
 		}
 	}
@@ -159,7 +159,7 @@ func TestCalculateSpentBySpendType(t *testing.T) {
 	for _, tt := range tests {
 		tt := tt
 		t.Run("", func(t *testing.T) {
-			gotTypes, gotDepth := prepareSpendTypesForDatasets(tt.spendTypes, tt.spends)
+			gotTypes, gotDepth := prepareSpendTypesForDatasets(tt.spendTypes, sumSpendCostsByType(tt.spends))
 			require.Equal(t, tt.wantDepth, gotDepth)
 			require.Equal(t, tt.wantTypes, gotTypes)
 
@@ -168,3 +168,16 @@ func TestCalculateSpentBySpendType(t *testing.T) {
 		})
 	}
 }
+
+// sumSpendCostsByType returns costs of Spends by ids of their Spend Types. Id 0 is used for Spends without type
+func sumSpendCostsByType(spends []db.Spend) map[uint]money.Money {
+	res := make(map[uint]money.Money)
+	for _, spend := range spends {
+		var typeID uint
+		if spend.Type != nil {
+			typeID = spend.Type.ID
+		}
+		res[typeID] = res[typeID].Add(spend.Cost)
+	}
+	return res
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/csrf"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
 	"github.com/ShoshinNikita/budget-manager/templates"
@@ -43,7 +44,12 @@ func (e *templateExecutor) Execute(ctx context.Context, w io.Writer, name string
 		return errors.Wrap(err, "couldn't load templates")
 	}
 
-	tpl = tpl.Lookup(name)
+	// The loaded templates are never executed, so they can be cloned to embed the request-specific values
+	tpl, err = tpl.Clone()
+	if err != nil {
+		return errors.Wrap(err, "couldn't clone templates")
+	}
+	tpl = tpl.Funcs(getRequestFuncs(ctx)).Lookup(name)
 	if tpl == nil {
 		return errors.Errorf("no template with name '%s'", name)
 	}
@@ -78,13 +84,23 @@ func (e *templateExecutor) loadTemplates() (_ *template.Template, err error) {
 }
 
 func (e *templateExecutor) getCommonFuncs() template.FuncMap {
-	res := make(template.FuncMap, len(e.commonFuncs))
+	res := getRequestFuncs(context.Background())
 	for k, v := range e.commonFuncs {
 		res[k] = v
 	}
 	return res
 }
 
+// getRequestFuncs returns functions that depend on the request. They must be registered before parsing,
+// so they are registered with an empty context and replaced before every execution
+func getRequestFuncs(ctx context.Context) template.FuncMap {
+	return template.FuncMap{
+		"csrfToken": func() string {
+			return csrf.FromContext(ctx)
+		},
+	}
+}
+
 // executeTemplate executes passed template. It checks for errors before writing into w: it executes
 // template into temporary buffer and copies data if everything is fine
 func executeTemplate(log logger.Logger, tpl *template.Template, w io.Writer, data interface{}) error {
@@ -0,0 +1,528 @@
+package pages
+
+import (
+	"context"
+	"html/template"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/auth"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/qr"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/totp"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
+)
+
+const (
+	// totpPendingSessionTTL defines how long the user has to pass two-factor authentication after
+	// entering the password
+	totpPendingSessionTTL = 10 * time.Minute
+	// recoveryCodesNumber is a number of recovery codes generated at once
+	recoveryCodesNumber = 10
+	// totpIssuer is shown in authenticator apps
+	totpIssuer = "Budget Manager"
+	// qrCodeModuleSize is a size of a single QR Code module in pixels
+	qrCodeModuleSize = 5
+
+	invalidTOTPCodeMessage = "Invalid code"
+	totpEnforcedMessage    = "Two-factor authentication is required for this user and can't be disabled"
+	authDisabledMessage    = "Two-factor authentication isn't available when auth is disabled"
+)
+
+type totpPageMode string
+
+const (
+	// totpVerifyMode asks for a code after login
+	totpVerifyMode totpPageMode = "verify"
+	// totpEnrollMode shows a QR Code with a new secret and asks for a code to confirm it
+	totpEnrollMode totpPageMode = "enroll"
+	// totpRecoveryCodesMode shows new recovery codes
+	totpRecoveryCodesMode totpPageMode = "recovery-codes"
+	// totpSettingsMode allows to disable two-factor authentication and to generate new recovery codes
+	totpSettingsMode totpPageMode = "settings"
+)
+
+type totpPageData struct {
+	Mode totpPageMode
+	// Action is a url the form with a code is sent to in 'verify' and 'enroll' modes
+	Action string
+	// Next is a url the user is redirected to after verification
+	Next     string
+	Remember bool
+	// Secret and QRCode are shown during enrollment
+	Secret string
+	QRCode template.HTML
+	// RecoveryCodes are shown only once after generation
+	RecoveryCodes []string
+	// RecoveryCodesLeft is a number of unused recovery codes
+	RecoveryCodesLeft int
+	// Required defines whether the user must use two-factor authentication
+	Required bool
+	Error    string
+	//
+	Footer FooterTemplateData
+}
+
+// GET /login/totp?next=/months&remember=true
+//
+// The page asks for a TOTP code or a recovery code after login. Users that must use two-factor
+// authentication, but haven't set it up yet, are asked to do it
+func (h Handlers) TOTPVerificationPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	next := getNextURL(r)
+	user, ok := h.getTOTPPendingUser(w, r, next)
+	if !ok {
+		return
+	}
+
+	data := totpPageData{
+		Mode:     totpVerifyMode,
+		Action:   "/login/totp",
+		Next:     next,
+		Remember: r.FormValue("remember") != "",
+		Required: h.auth.Creds.RequiresTOTP(user.Username),
+	}
+	h.executeTOTPPageForUser(ctx, log, w, user.Username, data)
+}
+
+// POST /login/totp
+//
+// The form contains 'code', 'remember' and 'next' fields. The pending session is replaced with a new one
+// after successful verification. Recovery codes are shown if two-factor authentication has just been set up
+func (h Handlers) VerifyTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	next := getNextURL(r)
+	user, ok := h.getTOTPPendingUser(w, r, next)
+	if !ok {
+		return
+	}
+	log = log.WithField("username", user.Username)
+
+	userTOTP, err := h.getTOTP(ctx, user.Username)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get TOTP"), err)
+		return
+	}
+
+	var recoveryCodes []string
+	code := r.PostFormValue("code")
+	ok, err = h.checkCodeWithLimiter(r, user.Username, func() (ok bool, err error) {
+		if userTOTP.Enabled {
+			return h.useCode(ctx, userTOTP, code)
+		}
+		// The user must set up two-factor authentication
+		recoveryCodes, ok, err = h.enableTOTP(ctx, userTOTP, code)
+		return ok, err
+	})
+	data := totpPageData{
+		Mode:     totpVerifyMode,
+		Action:   "/login/totp",
+		Next:     next,
+		Remember: r.PostFormValue("remember") != "",
+		Required: h.auth.Creds.RequiresTOTP(user.Username),
+	}
+	if !h.processCodeCheckResult(ctx, log, w, user.Username, ok, err, data) {
+		return
+	}
+
+	// Replace the pending session
+	if cookie, cookieErr := r.Cookie(auth.SessionCookieName); cookieErr == nil {
+		if err := h.db.RemoveSession(ctx, auth.HashToken(cookie.Value)); err != nil {
+			h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't remove session"), err)
+			return
+		}
+	}
+	cookie, err := h.addSession(ctx, user.Username, data.Remember, false)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't add session"), err)
+		return
+	}
+	http.SetCookie(w, cookie)
+
+	log.Debug("user has passed two-factor authentication")
+
+	if len(recoveryCodes) > 0 {
+		h.executeTOTPPage(ctx, log, w, totpPageData{
+			Mode:          totpRecoveryCodesMode,
+			Next:          next,
+			RecoveryCodes: recoveryCodes,
+		})
+		return
+	}
+	http.Redirect(w, r, next, http.StatusSeeOther)
+}
+
+// GET /settings/totp
+//
+// The page allows to set up two-factor authentication, to generate new recovery codes and to disable it
+func (h Handlers) TOTPSettingsPage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	user, ok := h.getTOTPSettingsUser(ctx, log, w)
+	if !ok {
+		return
+	}
+
+	data := totpPageData{
+		Mode:     totpSettingsMode,
+		Action:   "/settings/totp/enable",
+		Required: h.auth.Creds.RequiresTOTP(user.Username),
+	}
+	h.executeTOTPPageForUser(ctx, log, w, user.Username, data)
+}
+
+// POST /settings/totp/enable
+//
+// The form contains 'code' field. It must be generated with the secret shown on the settings page
+func (h Handlers) EnableTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	user, ok := h.getTOTPSettingsUser(ctx, log, w)
+	if !ok {
+		return
+	}
+	log = log.WithField("username", user.Username)
+
+	userTOTP, err := h.getTOTP(ctx, user.Username)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get TOTP"), err)
+		return
+	}
+	if userTOTP.Enabled {
+		http.Redirect(w, r, "/settings/totp", http.StatusSeeOther)
+		return
+	}
+
+	var recoveryCodes []string
+	ok, err = h.checkCodeWithLimiter(r, user.Username, func() (ok bool, err error) {
+		recoveryCodes, ok, err = h.enableTOTP(ctx, userTOTP, r.PostFormValue("code"))
+		return ok, err
+	})
+	data := totpPageData{
+		Mode:     totpSettingsMode,
+		Action:   "/settings/totp/enable",
+		Required: h.auth.Creds.RequiresTOTP(user.Username),
+	}
+	if !h.processCodeCheckResult(ctx, log, w, user.Username, ok, err, data) {
+		return
+	}
+	log.Info("two-factor authentication has been enabled")
+
+	h.executeTOTPPage(ctx, log, w, totpPageData{
+		Mode:          totpRecoveryCodesMode,
+		Next:          "/settings/totp",
+		RecoveryCodes: recoveryCodes,
+	})
+}
+
+// POST /settings/totp/disable
+//
+// The form contains 'code' field with a TOTP code or a recovery code. Two-factor authentication
+// can't be disabled if it is required in the credentials configuration
+func (h Handlers) DisableTOTP(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	user, ok := h.getTOTPSettingsUser(ctx, log, w)
+	if !ok {
+		return
+	}
+	log = log.WithField("username", user.Username)
+
+	if h.auth.Creds.RequiresTOTP(user.Username) {
+		h.processErrorWithPage(ctx, log, w, totpEnforcedMessage, http.StatusForbidden)
+		return
+	}
+	if !h.checkSettingsCode(w, r, log, user) {
+		return
+	}
+
+	if err := h.db.RemoveTOTP(ctx, user.Username); err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't remove TOTP"), err)
+		return
+	}
+	log.Info("two-factor authentication has been disabled")
+
+	http.Redirect(w, r, "/settings/totp", http.StatusSeeOther)
+}
+
+// POST /settings/totp/recovery-codes
+//
+// The form contains 'code' field with a TOTP code or a recovery code. All unused recovery codes
+// are replaced with new ones
+func (h Handlers) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	log := reqid.FromContextToLogger(ctx, h.log)
+
+	user, ok := h.getTOTPSettingsUser(ctx, log, w)
+	if !ok {
+		return
+	}
+	log = log.WithField("username", user.Username)
+
+	if !h.checkSettingsCode(w, r, log, user) {
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, "couldn't generate recovery codes", err)
+		return
+	}
+	if err := h.db.ReplaceRecoveryCodes(ctx, user.Username, hashes); err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't save recovery codes"), err)
+		return
+	}
+	log.Info("recovery codes have been regenerated")
+
+	h.executeTOTPPage(ctx, log, w, totpPageData{
+		Mode:          totpRecoveryCodesMode,
+		Next:          "/settings/totp",
+		RecoveryCodes: codes,
+	})
+}
+
+// checkSettingsCode checks a code passed to change two-factor authentication settings. It shows
+// the settings page with an error if the code is invalid
+func (h Handlers) checkSettingsCode(w http.ResponseWriter, r *http.Request, log logger.Logger, user auth.User) bool {
+	ctx := r.Context()
+
+	userTOTP, err := h.getTOTP(ctx, user.Username)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get TOTP"), err)
+		return false
+	}
+	if !userTOTP.Enabled {
+		http.Redirect(w, r, "/settings/totp", http.StatusSeeOther)
+		return false
+	}
+
+	ok, err := h.checkCodeWithLimiter(r, user.Username, func() (bool, error) {
+		return h.useCode(ctx, userTOTP, r.PostFormValue("code"))
+	})
+	data := totpPageData{
+		Mode:     totpSettingsMode,
+		Action:   "/settings/totp/enable",
+		Required: h.auth.Creds.RequiresTOTP(user.Username),
+	}
+	if !h.processCodeCheckResult(ctx, log, w, user.Username, ok, err, data) {
+		return false
+	}
+	return true
+}
+
+// getTOTPPendingUser returns the user that has to pass two-factor authentication. Other users are
+// redirected: authorized ones to the next page, unauthorized ones to the login page
+func (h Handlers) getTOTPPendingUser(w http.ResponseWriter, r *http.Request, next string) (auth.User, bool) {
+	user, ok := auth.FromContext(r.Context())
+	switch {
+	case h.auth.Disable || (ok && !user.TOTPPending):
+		http.Redirect(w, r, next, http.StatusSeeOther)
+		return auth.User{}, false
+	case !ok:
+		http.Redirect(w, r, "/login?next="+url.QueryEscape(next), http.StatusSeeOther)
+		return auth.User{}, false
+	}
+	return user, true
+}
+
+// getTOTPSettingsUser returns the authorized user. The auth middleware doesn't let pending users
+// access the settings
+func (h Handlers) getTOTPSettingsUser(ctx context.Context, log logger.Logger, w http.ResponseWriter) (auth.User, bool) {
+	user, ok := auth.FromContext(ctx)
+	if !ok {
+		h.processErrorWithPage(ctx, log, w, authDisabledMessage, http.StatusNotFound)
+		return auth.User{}, false
+	}
+	return user, true
+}
+
+// isTOTPRequired checks whether the user has to pass two-factor authentication after login
+func (h Handlers) isTOTPRequired(ctx context.Context, username string) (bool, error) {
+	if h.auth.Creds.RequiresTOTP(username) {
+		return true, nil
+	}
+	userTOTP, err := h.getTOTP(ctx, username)
+	if err != nil {
+		return false, err
+	}
+	return userTOTP.Enabled, nil
+}
+
+// getTOTP returns TOTP of the user. It returns an empty TOTP if the user hasn't set it up
+func (h Handlers) getTOTP(ctx context.Context, username string) (db.TOTP, error) {
+	userTOTP, err := h.db.GetTOTP(ctx, username)
+	if err != nil && !errors.Is(err, db.ErrTOTPNotExist) {
+		return db.TOTP{}, err
+	}
+	return userTOTP, nil
+}
+
+// checkCodeWithLimiter calls check and tracks failed attempts the same way as failed logins
+func (h Handlers) checkCodeWithLimiter(r *http.Request, username string, check func() (bool, error)) (bool, error) {
+	ctx := r.Context()
+
+	attempt, err := h.auth.Limiter.Reserve(ctx, lockout.IPKey(r), lockout.UsernameKey(username))
+	if err != nil {
+		return false, err
+	}
+	ok, err := check()
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		return false, attempt.Failed(ctx)
+	}
+	return true, attempt.Succeeded(ctx)
+}
+
+// processCodeCheckResult processes the result of 'checkCodeWithLimiter'. It returns true if the code is valid.
+// Otherwise, it shows the page with an error or the error page
+func (h Handlers) processCodeCheckResult(ctx context.Context, log logger.Logger, w http.ResponseWriter,
+	username string, ok bool, err error, data totpPageData) bool {
+
+	var lockedErr lockout.LockedError
+	switch {
+	case err == nil && ok:
+		return true
+	case errors.As(err, &lockedErr):
+		writeLockedHeaders(log, w, lockedErr)
+		data.Error = lockedMessage
+	case err != nil:
+		h.processInternalErrorWithPage(ctx, log, w, "couldn't check code", err)
+		return false
+	default:
+		log.Warn("invalid two-factor authentication code")
+		data.Error = invalidTOTPCodeMessage
+	}
+	h.executeTOTPPageForUser(ctx, log, w, username, data)
+	return false
+}
+
+// useCode checks a TOTP code or a recovery code. Used codes can't be used again
+func (h Handlers) useCode(ctx context.Context, userTOTP db.TOTP, code string) (bool, error) {
+	if totp.IsRecoveryCode(code) {
+		hash := auth.HashToken(totp.NormalizeRecoveryCode(code))
+		err := h.db.UseRecoveryCode(ctx, userTOTP.Username, hash)
+		if errors.Is(err, db.ErrRecoveryCodeNotExist) {
+			return false, nil
+		}
+		return err == nil, err
+	}
+
+	step, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return false, nil
+	}
+	err := h.db.UseTOTPStep(ctx, userTOTP.Username, step)
+	if errors.Is(err, db.ErrTOTPCodeAlreadyUsed) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// enableTOTP checks the code generated with the new secret and enables TOTP. It returns new recovery codes
+func (h Handlers) enableTOTP(ctx context.Context, userTOTP db.TOTP, code string) ([]string, bool, error) {
+	if userTOTP.Secret == "" {
+		return nil, false, nil
+	}
+	step, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return nil, false, nil
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		return nil, false, errors.Wrap(err, "couldn't generate recovery codes")
+	}
+	args := db.EnableTOTPArgs{
+		Username:           userTOTP.Username,
+		Step:               step,
+		RecoveryCodeHashes: hashes,
+	}
+	if err := h.db.EnableTOTP(ctx, args); err != nil {
+		return nil, false, errors.Wrap(err, "couldn't enable TOTP")
+	}
+	return codes, true, nil
+}
+
+// newRecoveryCodes generates new recovery codes and their hashes that are stored in the db
+func newRecoveryCodes() (codes, hashes []string, err error) {
+	codes, err = totp.NewRecoveryCodes(recoveryCodesNumber)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes = make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashToken(code))
+	}
+	return codes, hashes, nil
+}
+
+// executeTOTPPageForUser shows the page in the passed mode if TOTP of the user is enabled. Otherwise,
+// the user is asked to set it up. A new secret is generated if needed
+func (h Handlers) executeTOTPPageForUser(ctx context.Context, log logger.Logger, w http.ResponseWriter,
+	username string, data totpPageData) {
+
+	userTOTP, err := h.getTOTP(ctx, username)
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't get TOTP"), err)
+		return
+	}
+	if userTOTP.Enabled {
+		data.RecoveryCodesLeft = userTOTP.RecoveryCodes
+		h.executeTOTPPage(ctx, log, w, data)
+		return
+	}
+
+	secret := userTOTP.Secret
+	if secret == "" {
+		secret, err = totp.NewSecret()
+		if err != nil {
+			h.processInternalErrorWithPage(ctx, log, w, "couldn't generate TOTP secret", err)
+			return
+		}
+		if err := h.db.SetTOTPSecret(ctx, username, secret, time.Now()); err != nil {
+			h.processInternalErrorWithPage(ctx, log, w, newDBErrorMessage("couldn't save TOTP secret"), err)
+			return
+		}
+	}
+	code, err := qr.Encode([]byte(totp.URI(totpIssuer, username, secret)))
+	if err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, "couldn't generate QR Code", err)
+		return
+	}
+
+	data.Mode = totpEnrollMode
+	data.Secret = secret
+	data.QRCode = template.HTML(code.SVG(qrCodeModuleSize)) //nolint:gosec
+	h.executeTOTPPage(ctx, log, w, data)
+}
+
+func (h Handlers) executeTOTPPage(ctx context.Context, log logger.Logger, w http.ResponseWriter,
+	data totpPageData) {
+
+	data.Footer = h.newFooterTemplateData(ctx)
+	if err := h.tplExecutor.Execute(ctx, w, totpTemplateName, data); err != nil {
+		h.processInternalErrorWithPage(ctx, log, w, executeErrorMessage, err)
+	}
+}
+
+// newTOTPVerificationURL returns a url of the verification page
+func newTOTPVerificationURL(next string, remember bool) string {
+	params := url.Values{"next": {next}}
+	if remember {
+		params.Set("remember", "true")
+	}
+	return "/login/totp?" + params.Encode()
+}
@@ -28,10 +28,7 @@ func (h Handlers) processErrorWithPage(ctx context.Context, log logger.Logger, w
 		RequestID: reqid.FromContext(ctx),
 		Message:   respMsg,
 		//
-		Footer: FooterTemplateData{
-			Version: h.version,
-			GitHash: h.gitHash,
-		},
+		Footer: h.newFooterTemplateData(ctx),
 	}
 	if err := h.tplExecutor.Execute(ctx, w, errorPageTemplateName, data); err != nil {
 		utils.EncodeInternalError(ctx, w, log, executeErrorMessage, err)
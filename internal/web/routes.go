@@ -4,6 +4,8 @@ import (
 	"errors"
 	"net/http"
 	"net/http/pprof"
+	"strconv"
+	"strings"
 
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/reqid"
 	"github.com/ShoshinNikita/budget-manager/internal/web/api"
@@ -11,6 +13,9 @@ import (
 	"github.com/ShoshinNikita/budget-manager/internal/web/utils"
 )
 
+// idPathParam is a placeholder for a record id in API patterns: '/api/spends/{id}'
+const idPathParam = "{id}"
+
 //nolint:funlen
 func (s Server) addRoutes(mux *http.ServeMux) {
 	var (
@@ -30,11 +35,20 @@ func (s Server) addRoutes(mux *http.ServeMux) {
 		utils.EncodeError(ctx, w, log, errMethodNowAllowed, http.StatusMethodNotAllowed)
 	}
 
-	pageHandlers := pages.NewHandlers(s.db, s.log, s.config.UseEmbed, s.version, s.gitHash)
+	pageAuth := pages.AuthOptions{
+		Disable:       s.config.Auth.Disable,
+		Creds:         s.config.Auth.BasicAuthCreds,
+		SessionTTL:    s.config.Auth.SessionTTL,
+		RememberMeTTL: s.config.Auth.RememberMeTTL,
+		SecureCookie:  s.config.Auth.SecureCookie,
+		Limiter:       s.limiter,
+	}
+	pageHandlers := pages.NewHandlers(s.db, pageAuth, s.log, s.config.UseEmbed, s.version, s.gitHash)
 
 	// Register the main handler. It serves pages and handles all requests with an unrecognized pattern
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		var handler http.HandlerFunc
+		method := http.MethodGet
 		switch r.URL.Path {
 		case "/":
 			handler = pageHandlers.IndexPage
@@ -44,13 +58,35 @@ func (s Server) addRoutes(mux *http.ServeMux) {
 			handler = pageHandlers.MonthPage
 		case "/search/spends":
 			handler = pageHandlers.SearchSpendsPage
+		case "/docs/api":
+			handler = pageHandlers.APIDocsPage
+		case "/login":
+			handler = pageHandlers.LoginPage
+			if r.Method == http.MethodPost {
+				handler, method = pageHandlers.Login, http.MethodPost
+			}
+		case "/login/totp":
+			handler = pageHandlers.TOTPVerificationPage
+			if r.Method == http.MethodPost {
+				handler, method = pageHandlers.VerifyTOTP, http.MethodPost
+			}
+		case "/logout":
+			handler, method = pageHandlers.Logout, http.MethodPost
+		case "/settings/totp":
+			handler = pageHandlers.TOTPSettingsPage
+		case "/settings/totp/enable":
+			handler, method = pageHandlers.EnableTOTP, http.MethodPost
+		case "/settings/totp/disable":
+			handler, method = pageHandlers.DisableTOTP, http.MethodPost
+		case "/settings/totp/recovery-codes":
+			handler, method = pageHandlers.RegenerateRecoveryCodes, http.MethodPost
 		default:
 			writeUnknownPathError(w, r)
 			return
 		}
 
-		// Only GET requests are allowed
-		if r.Method != http.MethodGet {
+		// Pages accept only GET requests, except for forms
+		if r.Method != method {
 			writeMethodNowAllowedError(w, r)
 			return
 		}
@@ -58,54 +94,181 @@ func (s Server) addRoutes(mux *http.ServeMux) {
 		handler.ServeHTTP(w, r)
 	})
 
-	apiHandlers := api.NewHandlers(s.db, s.log)
+	apiHandlers := api.NewHandlers(s.db, s.events, s.log, s.config.CalendarReminder, s.config.UseEmbed)
 
 	// Register API handlers
-	for pattern, routes := range map[string]map[string]http.HandlerFunc{
+	for pattern, routes := range getAPIRoutes(apiHandlers) {
+		pattern := pattern
+		routes := routes
+
+		// Patterns with an id are registered as subtrees. The id is passed to handlers as query param 'id',
+		// so it is decoded with other params
+		prefix, withID := pattern, false
+		if strings.HasSuffix(pattern, "/"+idPathParam) {
+			prefix, withID = strings.TrimSuffix(pattern, idPathParam), true
+		}
+
+		mux.HandleFunc(prefix, func(w http.ResponseWriter, r *http.Request) {
+			switch {
+			case withID:
+				id := strings.TrimPrefix(r.URL.Path, prefix)
+				if _, err := strconv.ParseUint(id, 10, 64); err != nil {
+					writeUnknownPathError(w, r)
+					return
+				}
+
+				query := r.URL.Query()
+				query.Set("id", id)
+				r.URL.RawQuery = query.Encode()
+
+			case r.URL.Path != pattern:
+				writeUnknownPathError(w, r)
+				return
+			}
+
+			handler, ok := routes[r.Method]
+			if !ok {
+				writeMethodNowAllowedError(w, r)
+				return
+			}
+
+			handler.ServeHTTP(w, r)
+		})
+	}
+}
+
+// getAPIRoutes returns handlers of API routes. Keys are patterns, values - handlers for HTTP methods.
+// All routes must be described in the API documentation
+//
+//nolint:funlen
+func getAPIRoutes(apiHandlers *api.Handlers) map[string]map[string]http.HandlerFunc {
+	return map[string]map[string]http.HandlerFunc{
+		"/api/months": {
+			http.MethodGet: apiHandlers.GetMonths,
+		},
 		"/api/months/date": {
 			http.MethodGet: apiHandlers.GetMonthByDate,
 		},
+		"/api/months/events": {
+			http.MethodGet: apiHandlers.GetMonthEvents,
+		},
 		"/api/incomes": {
+			http.MethodGet:    apiHandlers.GetIncomes,
 			http.MethodPost:   apiHandlers.AddIncome,
 			http.MethodPut:    apiHandlers.EditIncome,
 			http.MethodDelete: apiHandlers.RemoveIncome,
 		},
+		"/api/incomes/{id}": {
+			http.MethodGet: apiHandlers.GetIncome,
+		},
 		"/api/monthly-payments": {
+			http.MethodGet:    apiHandlers.GetMonthlyPayments,
 			http.MethodPost:   apiHandlers.AddMonthlyPayment,
 			http.MethodPut:    apiHandlers.EditMonthlyPayment,
 			http.MethodDelete: apiHandlers.RemoveMonthlyPayment,
 		},
+		"/api/monthly-payments/{id}": {
+			http.MethodGet: apiHandlers.GetMonthlyPayment,
+		},
+		"/api/monthly-payments/calendar.ics": {
+			http.MethodGet: apiHandlers.GetMonthlyPaymentsCalendar,
+		},
 		"/api/spends": {
+			http.MethodGet:    apiHandlers.GetSpends,
 			http.MethodPost:   apiHandlers.AddSpend,
 			http.MethodPut:    apiHandlers.EditSpend,
 			http.MethodDelete: apiHandlers.RemoveSpend,
 		},
+		"/api/spends/{id}": {
+			http.MethodGet: apiHandlers.GetSpend,
+		},
 		"/api/spend-types": {
 			http.MethodGet:    apiHandlers.GetSpendTypes,
 			http.MethodPost:   apiHandlers.AddSpendType,
 			http.MethodPut:    apiHandlers.EditSpendType,
 			http.MethodDelete: apiHandlers.RemoveSpendType,
 		},
+		"/api/spend-types/{id}": {
+			http.MethodGet: apiHandlers.GetSpendType,
+		},
+		"/api/spend-rules": {
+			http.MethodGet:    apiHandlers.GetSpendRules,
+			http.MethodPost:   apiHandlers.AddSpendRule,
+			http.MethodPut:    apiHandlers.EditSpendRule,
+			http.MethodDelete: apiHandlers.RemoveSpendRule,
+		},
+		"/api/spend-rules/apply": {
+			http.MethodPost: apiHandlers.ApplySpendRules,
+		},
+		"/api/saved-searches": {
+			http.MethodGet:    apiHandlers.GetSavedSearches,
+			http.MethodPost:   apiHandlers.AddSavedSearch,
+			http.MethodPut:    apiHandlers.EditSavedSearch,
+			http.MethodDelete: apiHandlers.RemoveSavedSearch,
+		},
+		"/api/saved-searches/{id}": {
+			http.MethodGet: apiHandlers.GetSavedSearch,
+		},
+		"/api/saved-searches/reports": {
+			http.MethodGet: apiHandlers.GetSavedSearchReports,
+		},
+		"/api/webhooks": {
+			http.MethodGet:    apiHandlers.GetWebhooks,
+			http.MethodPost:   apiHandlers.AddWebhook,
+			http.MethodPut:    apiHandlers.EditWebhook,
+			http.MethodDelete: apiHandlers.RemoveWebhook,
+		},
+		"/api/webhooks/{id}": {
+			http.MethodGet: apiHandlers.GetWebhook,
+		},
+		"/api/webhooks/deliveries": {
+			http.MethodGet: apiHandlers.GetWebhookDeliveries,
+		},
+		"/api/tokens": {
+			http.MethodGet:    apiHandlers.GetAPITokens,
+			http.MethodPost:   apiHandlers.AddAPIToken,
+			http.MethodDelete: apiHandlers.RemoveAPIToken,
+		},
+		"/api/auth/lockouts": {
+			http.MethodGet:    apiHandlers.GetAuthLockouts,
+			http.MethodDelete: apiHandlers.RemoveAuthLockout,
+		},
 		"/api/search/spends": {
 			http.MethodGet: apiHandlers.SearchSpends,
 		},
-	} {
-		pattern := pattern
-		routes := routes
-		mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-			if r.URL.Path != pattern {
-				writeUnknownPathError(w, r)
-				return
-			}
-
-			handler, ok := routes[r.Method]
-			if !ok {
-				writeMethodNowAllowedError(w, r)
-				return
-			}
-
-			handler.ServeHTTP(w, r)
-		})
+		"/api/search/incomes": {
+			http.MethodGet: apiHandlers.SearchIncomes,
+		},
+		"/api/search/monthly-payments": {
+			http.MethodGet: apiHandlers.SearchMonthlyPayments,
+		},
+		"/api/search/all": {
+			http.MethodGet: apiHandlers.SearchAll,
+		},
+		"/api/statistics/spent-by-spend-type": {
+			http.MethodGet: apiHandlers.GetSpentBySpendType,
+		},
+		"/api/statistics/spent-by-day": {
+			http.MethodGet: apiHandlers.GetSpentByDay,
+		},
+		"/api/statistics/cost-intervals": {
+			http.MethodGet: apiHandlers.GetCostIntervals,
+		},
+		"/api/backup": {
+			http.MethodGet: apiHandlers.ExportBackup,
+		},
+		"/api/backup/restore": {
+			http.MethodPost: apiHandlers.RestoreBackup,
+		},
+		"/api/export/ledger": {
+			http.MethodGet: apiHandlers.ExportLedger,
+		},
+		"/api/docs/swagger.yaml": {
+			http.MethodGet: apiHandlers.GetSwaggerYAML,
+		},
+		"/api/docs/swagger.json": {
+			http.MethodGet: apiHandlers.GetSwaggerJSON,
+		},
 	}
 }
 
@@ -0,0 +1,44 @@
+package web
+
+import (
+	"io/fs"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+
+	"github.com/ShoshinNikita/budget-manager/docs"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api"
+)
+
+func TestAPIRoutesAreDocumented(t *testing.T) {
+	t.Parallel()
+
+	data, err := fs.ReadFile(docs.New(true), docs.SwaggerFileName)
+	require.NoError(t, err)
+
+	var spec struct {
+		Paths map[string]map[string]interface{} `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(data, &spec))
+
+	log := logger.New(logger.Config{Level: "fatal"})
+	routes := getAPIRoutes(api.NewHandlers(nil, nil, log, 0, true))
+
+	for pattern, handlers := range routes {
+		for method := range handlers {
+			_, ok := spec.Paths[pattern][strings.ToLower(method)]
+			require.Truef(t, ok, "route '%s %s' is not described in the API documentation", method, pattern)
+		}
+	}
+
+	// Check the opposite direction too, so the documentation doesn't contain removed routes
+	for path, operations := range spec.Paths {
+		for method := range operations {
+			_, ok := routes[path][strings.ToUpper(method)]
+			require.Truef(t, ok, "route '%s %s' from the API documentation is not registered", method, path)
+		}
+	}
+}
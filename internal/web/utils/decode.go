@@ -1,16 +1,26 @@
 package utils
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	"net/http"
 	"net/url"
+	"strings"
 
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
 	"github.com/ShoshinNikita/budget-manager/internal/web/utils/schema"
 )
 
+// MaxRequestBodySize is the max size of a request body. Larger requests are rejected
+// with 413 status code
+const MaxRequestBodySize = 1 << 20 // 1 MiB
+
+//nolint:gochecknoglobals
+var errRequestBodyTooLarge = errors.Errorf("request body must not be larger than %d bytes", MaxRequestBodySize)
+
 type Request interface {
 	SanitizeAndCheck() error
 }
@@ -29,21 +39,43 @@ func DecodeRequest(w http.ResponseWriter, r *http.Request, log logger.Logger, re
 	case http.MethodGet, http.MethodHead:
 		err = decodeQueryRequest(r.Form, req)
 	default:
-		err = decodeJSONRequest(r.Body, req)
+		err = decodeJSONRequest(LimitRequestBody(w, r), req)
 	}
 	if err != nil {
+		if IsRequestBodyTooLarge(err) {
+			EncodeRequestBodyTooLargeError(ctx, w, log)
+			return false
+		}
 		EncodeError(ctx, w, log, errors.Wrap(err, "couldn't decode request"), http.StatusBadRequest)
 		return false
 	}
 
 	if err := req.SanitizeAndCheck(); err != nil {
-		EncodeError(ctx, w, log, err, http.StatusBadRequest)
+		EncodeError(ctx, w, log, err, http.StatusBadRequest, EncodeErrorCode(models.ErrorCodeValidationFailed))
 		return false
 	}
 
 	return true
 }
 
+// LimitRequestBody replaces the request body with a reader that returns an error after
+// MaxRequestBodySize bytes and returns the new body
+func LimitRequestBody(w http.ResponseWriter, r *http.Request) io.Reader {
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestBodySize)
+	return r.Body
+}
+
+// IsRequestBodyTooLarge reports whether the error is caused by a body limited by LimitRequestBody.
+// 'http.MaxBytesReader' doesn't return a typed error, so only the message can be checked
+func IsRequestBodyTooLarge(err error) bool {
+	return err != nil && strings.HasSuffix(err.Error(), "http: request body too large")
+}
+
+// EncodeRequestBodyTooLargeError encodes an error for a request which body exceeds MaxRequestBodySize
+func EncodeRequestBodyTooLargeError(ctx context.Context, w http.ResponseWriter, log logger.Logger) {
+	EncodeError(ctx, w, log, errRequestBodyTooLarge, http.StatusRequestEntityTooLarge)
+}
+
 func decodeJSONRequest(body io.Reader, req interface{}) error {
 	decoder := json.NewDecoder(body)
 	decoder.DisallowUnknownFields()
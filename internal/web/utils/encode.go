@@ -3,6 +3,7 @@ package utils
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
@@ -11,10 +12,13 @@ import (
 )
 
 type responseEncoder struct {
-	resp         models.Response
-	statusCode   int
-	success      bool
-	respErrorMsg string
+	resp          models.Response
+	statusCode    int
+	success       bool
+	respErrorMsg  string
+	respErrorCode models.ErrorCode
+	respFieldErrs []models.FieldError
+	noStore       bool
 }
 
 type EncodeOption func(*responseEncoder)
@@ -33,6 +37,22 @@ func EncodeStatusCode(statusCode int) EncodeOption {
 	}
 }
 
+// EncodeErrorCode can be used to write a custom error code. By default, the code is determined
+// by the error and the status code
+func EncodeErrorCode(code models.ErrorCode) EncodeOption {
+	return func(enc *responseEncoder) {
+		enc.respErrorCode = code
+	}
+}
+
+// EncodeNoStore must be used for responses with secrets. It sets "Cache-Control: no-store" header,
+// so such responses are not cached by clients and not stored for Idempotency Keys
+func EncodeNoStore() EncodeOption {
+	return func(enc *responseEncoder) {
+		enc.noStore = true
+	}
+}
+
 // Encode is a helper function to encode API responses. It writes http.StatusOK and
 // encodes a base response by default. The fields of the base response are automatically filled
 // with values for a "successful" response. Use encode options or other Encode... functions
@@ -50,12 +70,17 @@ func Encode(ctx context.Context, w http.ResponseWriter,
 	}
 
 	enc.resp.SetBaseResponse(models.BaseResponse{
-		RequestID: reqid.FromContext(ctx).ToString(),
-		Success:   enc.success,
-		Error:     enc.respErrorMsg,
+		RequestID:   reqid.FromContext(ctx).ToString(),
+		Success:     enc.success,
+		Error:       enc.respErrorMsg,
+		ErrorCode:   enc.respErrorCode,
+		FieldErrors: enc.respFieldErrs,
 	})
 
 	w.Header().Set("Content-Type", "application/json")
+	if enc.noStore {
+		w.Header().Set("Cache-Control", "no-store")
+	}
 	w.WriteHeader(enc.statusCode)
 	if err := json.NewEncoder(w).Encode(enc.resp); err != nil {
 		LogInternalError(log, "couldn't encode response", err)
@@ -72,6 +97,14 @@ func EncodeError(ctx context.Context, w http.ResponseWriter, log logger.Logger,
 		enc.statusCode = statusCode
 		enc.success = false
 		enc.respErrorMsg = err.Error()
+		if enc.respErrorCode == "" {
+			enc.respErrorCode = getErrorCode(err, statusCode)
+		}
+
+		var validationErr *models.ValidationError
+		if errors.As(err, &validationErr) {
+			enc.respFieldErrs = validationErr.Fields
+		}
 	})
 	return Encode(ctx, w, log, options...)
 }
@@ -86,6 +119,34 @@ func EncodeInternalError(ctx context.Context, w http.ResponseWriter, log logger.
 		enc.statusCode = http.StatusInternalServerError
 		enc.success = false
 		enc.respErrorMsg = respMsg
+		enc.respErrorCode = models.ErrorCodeInternal
 	})
 	return Encode(ctx, w, log, options...)
 }
+
+// getErrorCode returns a specific code of the error or a general code for the status code
+func getErrorCode(err error, statusCode int) models.ErrorCode {
+	if code, ok := models.GetErrorCode(err); ok {
+		return code
+	}
+
+	switch statusCode {
+	case http.StatusUnauthorized:
+		return models.ErrorCodeUnauthorized
+	case http.StatusForbidden:
+		return models.ErrorCodeForbidden
+	case http.StatusNotFound:
+		return models.ErrorCodeNotFound
+	case http.StatusMethodNotAllowed:
+		return models.ErrorCodeMethodNotAllowed
+	case http.StatusConflict:
+		return models.ErrorCodeConflict
+	case http.StatusTooManyRequests:
+		return models.ErrorCodeTooManyRequests
+	default:
+		if statusCode >= http.StatusInternalServerError {
+			return models.ErrorCodeInternal
+		}
+		return models.ErrorCodeInvalidRequest
+	}
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestGetErrorCode(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		err        error
+		statusCode int
+		//
+		want models.ErrorCode
+	}{
+		{err: db.ErrMonthNotExist, statusCode: http.StatusNotFound, want: models.ErrorCodeMonthNotFound},
+		{err: errors.Wrap(db.ErrVersionConflict, "couldn't edit Spend"), statusCode: http.StatusConflict, want: models.ErrorCodeVersionConflict},
+		{err: &models.ValidationError{}, statusCode: http.StatusBadRequest, want: models.ErrorCodeValidationFailed},
+		{err: errors.New("unknown path"), statusCode: http.StatusNotFound, want: models.ErrorCodeNotFound},
+		{err: errors.New("invalid request"), statusCode: http.StatusBadRequest, want: models.ErrorCodeInvalidRequest},
+		{err: errors.New("unauthorized"), statusCode: http.StatusUnauthorized, want: models.ErrorCodeUnauthorized},
+		{err: errors.New("locked"), statusCode: http.StatusTooManyRequests, want: models.ErrorCodeTooManyRequests},
+		{err: errors.New("bad gateway"), statusCode: http.StatusBadGateway, want: models.ErrorCodeInternal},
+	} {
+		require.Equal(t, tt.want, getErrorCode(tt.err, tt.statusCode))
+	}
+}
@@ -0,0 +1,54 @@
+package utils
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// SetETag sets 'ETag' header to the version of a record
+func SetETag(w http.ResponseWriter, version int) {
+	w.Header().Set("ETag", `"`+strconv.Itoa(version)+`"`)
+}
+
+// ExpectedVersion returns an expected version of a record passed in the request body or in 'If-Match'
+// header. It returns nil if the version is not passed. Both values must be equal if both are passed
+func ExpectedVersion(r *http.Request, bodyVersion *int) (*int, error) {
+	headerVersion, err := parseIfMatch(r.Header.Get("If-Match"))
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case headerVersion == 0:
+		return bodyVersion, nil
+	case bodyVersion == nil:
+		return &headerVersion, nil
+	case headerVersion != *bodyVersion:
+		return nil, errors.New("version and If-Match header don't match")
+	default:
+		return bodyVersion, nil
+	}
+}
+
+// parseIfMatch parses a single ETag set by SetETag. It returns 0 for an empty value and '*'
+func parseIfMatch(value string) (version int, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "*" {
+		return 0, nil
+	}
+
+	errInvalidHeader := errors.New("invalid If-Match header")
+
+	value = strings.TrimPrefix(value, "W/")
+	if len(value) < 2 || value[0] != '"' || value[len(value)-1] != '"' {
+		return 0, errInvalidHeader
+	}
+	version, err = strconv.Atoi(value[1 : len(value)-1])
+	if err != nil || version <= 0 {
+		return 0, errInvalidHeader
+	}
+	return version, nil
+}
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSetETag(t *testing.T) {
+	t.Parallel()
+
+	w := httptest.NewRecorder()
+	SetETag(w, 15)
+	require.Equal(t, `"15"`, w.Header().Get("ETag"))
+}
+
+func TestExpectedVersion(t *testing.T) {
+	t.Parallel()
+
+	ptr := func(v int) *int { return &v }
+
+	for _, tt := range []struct {
+		ifMatch     string
+		bodyVersion *int
+		//
+		want    *int
+		wantErr string
+	}{
+		{ifMatch: "", bodyVersion: nil, want: nil},
+		{ifMatch: "*", bodyVersion: nil, want: nil},
+		{ifMatch: "", bodyVersion: ptr(2), want: ptr(2)},
+		{ifMatch: `"3"`, bodyVersion: nil, want: ptr(3)},
+		{ifMatch: ` W/"3" `, bodyVersion: nil, want: ptr(3)},
+		{ifMatch: `"3"`, bodyVersion: ptr(3), want: ptr(3)},
+		{ifMatch: `"3"`, bodyVersion: ptr(2), wantErr: "version and If-Match header don't match"},
+		{ifMatch: `3`, wantErr: "invalid If-Match header"},
+		{ifMatch: `"abc"`, wantErr: "invalid If-Match header"},
+		{ifMatch: `"0"`, wantErr: "invalid If-Match header"},
+		{ifMatch: `"1", "2"`, wantErr: "invalid If-Match header"},
+	} {
+		r := httptest.NewRequest("PUT", "/api/spends", nil)
+		if tt.ifMatch != "" {
+			r.Header.Set("If-Match", tt.ifMatch)
+		}
+
+		got, err := ExpectedVersion(r, tt.bodyVersion)
+		if tt.wantErr != "" {
+			require.EqualError(t, err, tt.wantErr, "If-Match: %s", tt.ifMatch)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, tt.want, got, "If-Match: %s", tt.ifMatch)
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
 	"github.com/ShoshinNikita/budget-manager/internal/web/api"
+	"github.com/ShoshinNikita/budget-manager/internal/web/lockout"
 	"github.com/ShoshinNikita/budget-manager/internal/web/middlewares"
 	"github.com/ShoshinNikita/budget-manager/internal/web/pages"
 	"github.com/ShoshinNikita/budget-manager/static"
@@ -18,6 +19,10 @@ type Server struct {
 	config Config
 	log    logger.Logger
 	db     Database
+	events api.MonthEvents
+
+	// limiter tracks failed auth attempts of Basic Auth, API Tokens and the login page
+	limiter *lockout.Limiter
 
 	server *http.Server
 
@@ -28,14 +33,20 @@ type Server struct {
 type Database interface {
 	api.DB
 	pages.DB
+	middlewares.IdempotencyKeysDB
+	middlewares.AuthDB
+	lockout.DB
 }
 
-func NewServer(cfg Config, db Database, log logger.Logger, version, gitHash string) *Server {
+func NewServer(cfg Config, db Database, events api.MonthEvents, log logger.Logger, version, gitHash string) *Server {
 	s := &Server{
 		config: cfg,
 		db:     db,
+		events: events,
 		log:    log,
 		//
+		limiter: lockout.NewLimiter(cfg.Auth.Lockout, db),
+		//
 		version: version,
 		gitHash: gitHash,
 	}
@@ -70,15 +81,35 @@ func (s *Server) buildServerHandler() http.Handler {
 
 	// Wrap the handler in middlewares. The last middleware will be called first and so on
 	var handler http.Handler = router
+	if s.config.IdempotencyKeyTTL > 0 {
+		handler = middlewares.IdempotencyMiddleware(handler, s.db, s.config.IdempotencyKeyTTL, s.log)
+	} else {
+		s.log.Warn("idempotency keys are disabled")
+	}
+	// CSRF middleware is called after the auth one to skip checks for requests with API Tokens
+	handler = middlewares.CSRFMiddleware(handler, s.config.Auth.SecureCookie, s.log)
 	if !s.config.Auth.Disable {
-		handler = middlewares.BasicAuthMiddleware(handler, s.config.Auth.BasicAuthCreds, s.log)
+		proxyAuth := middlewares.ProxyAuth{
+			Header:  s.config.Auth.Proxy.Header,
+			Proxies: s.config.Auth.Proxy.TrustedProxies,
+		}
+		handler = middlewares.AuthMiddleware(handler, s.config.Auth.BasicAuthCreds, s.db, proxyAuth, s.limiter, s.log)
+		if proxyAuth.Header != "" {
+			s.log.WithField("header", proxyAuth.Header).Info("proxy auth is enabled")
+		}
 		if len(s.config.Auth.BasicAuthCreds) == 0 {
 			s.log.Warn("auth is enabled, but list of creds is empty")
 		}
+		if s.config.Auth.Lockout.Threshold <= 0 {
+			s.log.Warn("auth lockouts are disabled")
+		}
 	} else {
 		s.log.Warn("auth is disabled")
 	}
 	handler = middlewares.LoggingMiddleware(handler, s.log)
+	if len(s.config.TrustedProxies) > 0 {
+		handler = middlewares.RealIPMiddleware(handler, s.config.TrustedProxies)
+	}
 	handler = middlewares.RequestIDMeddleware(handler)
 
 	return handler
@@ -0,0 +1,218 @@
+// Package webhooks sends deliveries of outgoing Webhooks
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/errors"
+)
+
+// Headers of Webhook requests
+const (
+	EventHeader     = "X-Budget-Manager-Event"
+	DeliveryHeader  = "X-Budget-Manager-Delivery"
+	TimestampHeader = "X-Budget-Manager-Timestamp"
+	// SignatureHeader contains 'sha256=' and a hex-encoded HMAC-SHA256 of '<timestamp>.<body>'
+	// signed with the Webhook secret
+	SignatureHeader = "X-Budget-Manager-Signature"
+)
+
+const (
+	batchSize = 50
+
+	// cleanupInterval defines how often old deliveries are removed
+	cleanupInterval = time.Hour
+)
+
+type Config struct {
+	// PollInterval defines how often pending deliveries are checked
+	PollInterval time.Duration
+
+	// Timeout is a timeout of a single request
+	Timeout time.Duration
+
+	// MaxAttempts is a number of attempts after which a delivery is marked as failed
+	MaxAttempts int
+
+	// RetryDelay is a delay before the first retry. Every next delay is doubled
+	RetryDelay time.Duration
+
+	// MaxRetryDelay limits the delay between retries
+	MaxRetryDelay time.Duration
+
+	// RetentionPeriod defines how long succeeded and failed deliveries are kept. They are
+	// never removed if it is 0
+	RetentionPeriod time.Duration
+}
+
+type DB interface {
+	GetDueWebhookDeliveries(ctx context.Context, now time.Time, limit int) ([]db.DueWebhookDelivery, error)
+	UpdateWebhookDelivery(ctx context.Context, args db.UpdateWebhookDeliveryArgs) error
+	RemoveOldWebhookDeliveries(ctx context.Context, before time.Time) (removed int, err error)
+}
+
+type Sender struct {
+	config Config
+	db     DB
+	log    logger.Logger
+	client *http.Client
+
+	lastCleanup time.Time
+}
+
+// NewSender returns a new Sender
+func NewSender(cfg Config, db DB, log logger.Logger) *Sender {
+	return &Sender{
+		config: cfg,
+		db:     db,
+		log:    log,
+		client: &http.Client{
+			Timeout: cfg.Timeout,
+		},
+	}
+}
+
+// SendDueDeliveries sends all deliveries which next attempt is due and saves the results.
+// Old deliveries are removed at most once per cleanupInterval
+func (s *Sender) SendDueDeliveries(ctx context.Context) error {
+	if err := s.removeOldDeliveries(ctx, time.Now()); err != nil {
+		return err
+	}
+
+	for {
+		deliveries, err := s.db.GetDueWebhookDeliveries(ctx, time.Now(), batchSize)
+		if err != nil {
+			return errors.Wrap(err, "couldn't get due deliveries")
+		}
+
+		for _, d := range deliveries {
+			if err := s.send(ctx, d); err != nil {
+				return err
+			}
+		}
+
+		if len(deliveries) < batchSize {
+			return nil
+		}
+	}
+}
+
+func (s *Sender) removeOldDeliveries(ctx context.Context, now time.Time) error {
+	if s.config.RetentionPeriod <= 0 || now.Sub(s.lastCleanup) < cleanupInterval {
+		return nil
+	}
+
+	removed, err := s.db.RemoveOldWebhookDeliveries(ctx, now.Add(-s.config.RetentionPeriod))
+	if err != nil {
+		return errors.Wrap(err, "couldn't remove old deliveries")
+	}
+	s.lastCleanup = now
+
+	if removed > 0 {
+		s.log.WithField("removed", removed).Debug("old webhook deliveries were removed")
+	}
+	return nil
+}
+
+func (s *Sender) send(ctx context.Context, d db.DueWebhookDelivery) error {
+	log := s.log.WithFields(logger.Fields{
+		"delivery_id": d.ID,
+		"webhook_id":  d.WebhookID,
+		"event":       d.Event,
+		"attempt":     d.Attempts + 1,
+	})
+
+	now := time.Now()
+	code, sendErr := s.sendRequest(ctx, d, now)
+
+	args := db.UpdateWebhookDeliveryArgs{
+		ID:            d.ID,
+		Status:        db.WebhookDeliverySucceeded,
+		Attempts:      d.Attempts + 1,
+		ResponseCode:  code,
+		NextAttemptAt: d.NextAttemptAt,
+	}
+	if sendErr != nil {
+		args.Error = sendErr.Error()
+		if args.Attempts >= s.config.MaxAttempts {
+			args.Status = db.WebhookDeliveryFailed
+			log.WithError(sendErr).Warn("couldn't send webhook, no attempts left")
+		} else {
+			args.Status = db.WebhookDeliveryPending
+			args.NextAttemptAt = now.Add(s.retryDelay(args.Attempts))
+			log.WithError(sendErr).Debug("couldn't send webhook, retry later")
+		}
+	} else {
+		log.Debug("webhook was successfully sent")
+	}
+
+	if err := s.db.UpdateWebhookDelivery(ctx, args); err != nil {
+		return errors.Wrapf(err, "couldn't update delivery with id %d", d.ID)
+	}
+	return nil
+}
+
+// sendRequest sends the payload of the delivery and returns a status code of the response
+func (s *Sender) sendRequest(ctx context.Context, d db.DueWebhookDelivery, now time.Time) (code int, err error) {
+	body := []byte(d.Payload)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't prepare request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, string(d.Event))
+	req.Header.Set(DeliveryHeader, strconv.FormatUint(uint64(d.ID), 10))
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(d.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, errors.Wrap(err, "couldn't send request")
+	}
+	defer resp.Body.Close()
+
+	// Read the body to reuse the connection
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, errors.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
+
+// retryDelay returns a delay before the next attempt. The delay is doubled after every failed attempt
+func (s *Sender) retryDelay(attempts int) time.Duration {
+	delay := s.config.RetryDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= s.config.MaxRetryDelay {
+			return s.config.MaxRetryDelay
+		}
+	}
+	if delay > s.config.MaxRetryDelay {
+		return s.config.MaxRetryDelay
+	}
+	return delay
+}
+
+// Sign returns a signature of the Webhook request. Receivers should compute the same signature
+// and compare it with the value of the signature header
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,182 @@
+package webhooks
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+)
+
+func TestSign(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	// echo -n '1600000000.{"event":"spend.created"}' | openssl dgst -sha256 -hmac 'secret'
+	got := Sign("secret", "1600000000", []byte(`{"event":"spend.created"}`))
+	require.Equal("sha256=fa908c279aa3cb25fa01b07b3998a6cfef41c29375cc53173a193e0e04a63959", got)
+
+	require.NotEqual(got, Sign("another secret", "1600000000", []byte(`{"event":"spend.created"}`)))
+	require.NotEqual(got, Sign("secret", "1600000001", []byte(`{"event":"spend.created"}`)))
+}
+
+func TestRetryDelay(t *testing.T) {
+	t.Parallel()
+
+	s := NewSender(Config{RetryDelay: time.Second, MaxRetryDelay: 10 * time.Second}, nil, nil)
+	for _, tt := range []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 3, want: 4 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 5, want: 10 * time.Second},
+		{attempts: 100, want: 10 * time.Second},
+	} {
+		require.Equal(t, tt.want, s.retryDelay(tt.attempts), "attempts: %d", tt.attempts)
+	}
+}
+
+type mockDB struct {
+	deliveries []db.DueWebhookDelivery
+	updates    []db.UpdateWebhookDeliveryArgs
+	removals   []time.Time
+}
+
+func (m *mockDB) GetDueWebhookDeliveries(context.Context, time.Time, int) ([]db.DueWebhookDelivery, error) {
+	res := m.deliveries
+	m.deliveries = nil
+	return res, nil
+}
+
+func (m *mockDB) UpdateWebhookDelivery(_ context.Context, args db.UpdateWebhookDeliveryArgs) error {
+	m.updates = append(m.updates, args)
+	return nil
+}
+
+func (m *mockDB) RemoveOldWebhookDeliveries(_ context.Context, before time.Time) (int, error) {
+	m.removals = append(m.removals, before)
+	return 0, nil
+}
+
+func TestSender(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	const payload = `{"event":"spend.created"}`
+
+	var (
+		mu       sync.Mutex
+		requests = make(map[string]*http.Request)
+		bodies   = make(map[string]string)
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		id := r.Header.Get(DeliveryHeader)
+		mu.Lock()
+		requests[id] = r
+		bodies[id] = string(body)
+		mu.Unlock()
+
+		if id == "1" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	mock := &mockDB{
+		deliveries: []db.DueWebhookDelivery{
+			{
+				WebhookDelivery: db.WebhookDelivery{ID: 1, Event: db.WebhookEventSpendCreated, Payload: payload},
+				URL:             server.URL,
+				Secret:          "secret",
+			},
+			{
+				WebhookDelivery: db.WebhookDelivery{ID: 2, Event: db.WebhookEventSpendCreated, Payload: payload},
+				URL:             server.URL,
+			},
+			{
+				WebhookDelivery: db.WebhookDelivery{
+					ID: 3, Event: db.WebhookEventSpendCreated, Payload: payload, Attempts: 2,
+				},
+				URL: server.URL,
+			},
+		},
+	}
+	cfg := Config{
+		Timeout:       time.Second,
+		MaxAttempts:   3,
+		RetryDelay:    time.Minute,
+		MaxRetryDelay: time.Hour,
+	}
+	s := NewSender(cfg, mock, logger.New(logger.Config{Mode: "prod", Level: "fatal"}))
+
+	start := time.Now()
+	err := s.SendDueDeliveries(context.Background())
+	require.NoError(err)
+	require.Len(mock.updates, 3)
+
+	req := requests["1"]
+	require.NotNil(req)
+	require.Equal(payload, bodies["1"])
+	require.Equal("application/json", req.Header.Get("Content-Type"))
+	require.Equal("spend.created", req.Header.Get(EventHeader))
+	require.Equal(Sign("secret", req.Header.Get(TimestampHeader), []byte(payload)), req.Header.Get(SignatureHeader))
+
+	require.Equal(db.WebhookDeliverySucceeded, mock.updates[0].Status)
+	require.Equal(1, mock.updates[0].Attempts)
+	require.Equal(http.StatusNoContent, mock.updates[0].ResponseCode)
+	require.Empty(mock.updates[0].Error)
+
+	require.Equal(db.WebhookDeliveryPending, mock.updates[1].Status)
+	require.Equal(1, mock.updates[1].Attempts)
+	require.Equal(http.StatusInternalServerError, mock.updates[1].ResponseCode)
+	require.Equal("unexpected status code: 500", mock.updates[1].Error)
+	require.WithinDuration(start.Add(time.Minute), mock.updates[1].NextAttemptAt, 5*time.Second)
+
+	require.Equal(db.WebhookDeliveryFailed, mock.updates[2].Status)
+	require.Equal(3, mock.updates[2].Attempts)
+}
+
+func TestSender_RemoveOldDeliveries(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	mock := &mockDB{}
+	s := NewSender(Config{RetentionPeriod: 24 * time.Hour}, mock, logger.New(logger.Config{Level: "fatal"}))
+
+	now := time.Now()
+	require.NoError(s.removeOldDeliveries(context.Background(), now))
+	require.Equal([]time.Time{now.Add(-24 * time.Hour)}, mock.removals)
+
+	// Too early for the next cleanup
+	require.NoError(s.removeOldDeliveries(context.Background(), now.Add(time.Minute)))
+	require.Len(mock.removals, 1)
+
+	require.NoError(s.removeOldDeliveries(context.Background(), now.Add(cleanupInterval)))
+	require.Len(mock.removals, 2)
+	require.Equal(now.Add(cleanupInterval).Add(-24*time.Hour), mock.removals[1])
+
+	t.Run("disabled", func(t *testing.T) {
+		mock := &mockDB{}
+		s := NewSender(Config{}, mock, nil)
+		assert.NoError(t, s.removeOldDeliveries(context.Background(), time.Now()))
+		assert.Empty(t, mock.removals)
+	})
+}
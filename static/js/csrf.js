@@ -0,0 +1,6 @@
+// getCSRFToken returns the token embedded into the page. It must be passed in 'X-CSRF-Token' header
+// with all requests that change data
+function getCSRFToken() {
+	const meta = document.querySelector("meta[name='csrf-token']");
+	return meta !== null ? meta.content : "";
+}
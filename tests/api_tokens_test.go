@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestAPITokens(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "usage", Fn: testAPITokens_Usage},
+		{Name: "scopes", Fn: testAPITokens_Scopes},
+		{Name: "errors", Fn: testAPITokens_Errors},
+	}, func(env *TestEnv) {
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user":  {PasswordHash: "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC"}, // user:qwerty
+			"admin": {PasswordHash: "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC"}, // admin:qwerty
+		}
+	})
+}
+
+func TestAPITokensWithDisabledAuth(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestFn(func(t *testing.T, host string) {
+		code, _, body := sendWithHeader(t, host, GET, APITokensPath, nil, nil)
+		Request{
+			StatusCode: http.StatusForbidden, Err: "API Tokens can't be used when auth is disabled",
+		}.checkResponse(t, code, body, nil)
+	}))
+}
+
+func testAPITokens_Usage(t *testing.T, host string) {
+	require := require.New(t)
+
+	token := addAPIToken(t, host, "user", models.AddAPITokenReq{Name: "script"})
+
+	tokens := getAPITokens(t, host, "user")
+	require.Len(tokens, 1)
+	require.Equal("script", tokens[0].Name)
+	require.False(tokens[0].ReadOnly)
+	require.Empty(tokens[0].Endpoints)
+	require.Nil(tokens[0].LastUsedAt)
+
+	// Tokens of other users are not returned
+	require.Empty(getAPITokens(t, host, "admin"))
+
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, bearerHeader(token.Token), nil)
+	require.Equal(http.StatusOK, code)
+	code, _, _ = sendWithHeader(t, host, POST, SpendsPath, bearerHeader(token.Token), models.AddSpendReq{
+		DayID: 1, Title: "bread", Cost: 2,
+	})
+	require.Equal(http.StatusCreated, code)
+
+	tokens = getAPITokens(t, host, "user")
+	require.Len(tokens, 1)
+	require.NotNil(tokens[0].LastUsedAt)
+
+	// Other users can't revoke the token
+	code, _, body := sendWithHeader(t, host, DELETE, APITokensPath, basicAuthHeader("admin"), models.RemoveAPITokenReq{
+		ID: token.ID,
+	})
+	Request{StatusCode: http.StatusNotFound, Err: "such API Token doesn't exist"}.checkResponse(t, code, body, nil)
+
+	code, _, body = sendWithHeader(t, host, DELETE, APITokensPath, basicAuthHeader("user"), models.RemoveAPITokenReq{
+		ID: token.ID,
+	})
+	Request{StatusCode: http.StatusOK}.checkResponse(t, code, body, nil)
+	require.Empty(getAPITokens(t, host, "user"))
+
+	code, respHeader, body := sendWithHeader(t, host, GET, SearchSpendsPath, bearerHeader(token.Token), nil)
+	Request{StatusCode: http.StatusUnauthorized, Err: "invalid API Token"}.checkResponse(t, code, body, nil)
+	require.Equal(`Bearer realm="Budget Manager", error="invalid_token"`, respHeader.Get("WWW-Authenticate"))
+}
+
+func testAPITokens_Scopes(t *testing.T, host string) {
+	readOnly := addAPIToken(t, host, "user", models.AddAPITokenReq{Name: "read-only", ReadOnly: true})
+	search := addAPIToken(t, host, "user", models.AddAPITokenReq{
+		Name: "search", Endpoints: []string{" /api/search/ ", "/api/months"},
+	})
+
+	const (
+		errReadOnly = "API Token is read-only"
+		errEndpoint = "API Token doesn't have access to this endpoint"
+		errManage   = "API Tokens can't be managed with API Token"
+	)
+
+	spend := models.AddSpendReq{DayID: 1, Title: "bread", Cost: 2}
+	for _, tt := range []struct {
+		token  string
+		method Method
+		path   Path
+		req    interface{}
+		code   int
+		err    string
+	}{
+		{token: readOnly.Token, method: GET, path: SearchSpendsPath, code: http.StatusOK},
+		{token: readOnly.Token, method: POST, path: SpendsPath, req: spend, code: http.StatusForbidden, err: errReadOnly},
+		{token: search.Token, method: GET, path: SearchSpendsPath, code: http.StatusOK},
+		{token: search.Token, method: GET, path: AllMonthsPath, code: http.StatusOK},
+		{token: search.Token, method: GET, path: SavedSearchesPath, code: http.StatusForbidden, err: errEndpoint},
+		{token: search.Token, method: GET, path: "/api/searches", code: http.StatusForbidden, err: errEndpoint},
+		{token: search.Token, method: GET, path: "/", code: http.StatusForbidden, err: errEndpoint},
+		{token: search.Token, method: POST, path: SpendsPath, req: spend, code: http.StatusForbidden, err: errEndpoint},
+		{token: readOnly.Token, method: GET, path: APITokensPath, code: http.StatusForbidden, err: errManage},
+	} {
+		code, _, body := sendWithHeader(t, host, tt.method, tt.path, bearerHeader(tt.token), tt.req)
+		Request{StatusCode: tt.code, Err: tt.err}.checkResponse(t, code, body, nil)
+	}
+
+	tokens := getAPITokens(t, host, "user")
+	require.Len(t, tokens, 2)
+	require.Equal(t, []string{"/api/search", "/api/months"}, tokens[1].Endpoints)
+}
+
+func testAPITokens_Errors(t *testing.T, host string) {
+	for _, tt := range []struct {
+		req models.AddAPITokenReq
+		err string
+	}{
+		{req: models.AddAPITokenReq{Name: " "}, err: "name can't be empty"},
+		{
+			req: models.AddAPITokenReq{Name: "script", Endpoints: []string{"spends"}},
+			err: `invalid endpoint "spends": it must start with '/api/'`,
+		},
+	} {
+		code, _, body := sendWithHeader(t, host, POST, APITokensPath, basicAuthHeader("user"), tt.req)
+		Request{StatusCode: http.StatusBadRequest, Err: tt.err}.checkResponse(t, code, body, nil)
+	}
+
+	for _, header := range []string{"Bearer bm_123", "bearer qwerty"} {
+		code, _, body := sendWithHeader(t, host, GET, SearchSpendsPath, http.Header{"Authorization": {header}}, nil)
+		Request{StatusCode: http.StatusUnauthorized, Err: "invalid API Token"}.checkResponse(t, code, body, nil)
+	}
+
+	code, _, body := sendWithHeader(t, host, DELETE, APITokensPath, basicAuthHeader("user"), models.RemoveAPITokenReq{
+		ID: 1000,
+	})
+	Request{StatusCode: http.StatusNotFound, Err: "such API Token doesn't exist"}.checkResponse(t, code, body, nil)
+}
+
+func addAPIToken(t *testing.T, host string, username string, req models.AddAPITokenReq) models.AddAPITokenResp {
+	code, _, body := sendWithHeader(t, host, POST, APITokensPath, basicAuthHeader(username), req)
+	require.Equal(t, http.StatusCreated, code)
+
+	var resp models.AddAPITokenResp
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.NotEmpty(t, resp.Token)
+	return resp
+}
+
+func getAPITokens(t *testing.T, host string, username string) []db.APIToken {
+	code, _, body := sendWithHeader(t, host, GET, APITokensPath, basicAuthHeader(username), nil)
+	require.Equal(t, http.StatusOK, code)
+
+	var resp models.GetAPITokensResp
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.APITokens
+}
+
+// basicAuthHeader returns a header with credentials of the user. All users have the same password
+func basicAuthHeader(username string) http.Header {
+	req := &http.Request{Header: http.Header{}}
+	req.SetBasicAuth(username, "qwerty")
+	return req.Header
+}
+
+func bearerHeader(token string) http.Header {
+	return http.Header{"Authorization": {"Bearer " + token}}
+}
@@ -0,0 +1,242 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestAuthLockouts(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "basic auth", Fn: testAuthLockouts_BasicAuth},
+		{Name: "api token", Fn: testAuthLockouts_APIToken},
+		{Name: "login page", Fn: testAuthLockouts_LoginPage},
+		{Name: "concurrent attempts", Fn: testAuthLockouts_ConcurrentAttempts},
+	}, func(env *TestEnv) {
+		const hash = "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC" // qwerty
+
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user":  {PasswordHash: hash},
+			"admin": {PasswordHash: hash},
+			"guest": {PasswordHash: hash},
+		}
+		env.Cfg.Server.Auth.Lockout.Threshold = 3
+		env.Cfg.Server.Auth.Lockout.Duration = time.Minute
+		env.Cfg.Server.Auth.Lockout.MaxDuration = time.Hour
+		require.NoError(t, env.Cfg.Server.TrustedProxies.UnmarshalText([]byte("127.0.0.1,::1")))
+	})
+}
+
+func testAuthLockouts_BasicAuth(t *testing.T, host string) {
+	require := require.New(t)
+
+	send := func(username, password, ip string) (int, http.Header) {
+		req := &http.Request{Header: http.Header{}}
+		req.SetBasicAuth(username, password)
+		req.Header.Set("X-Forwarded-For", ip)
+
+		code, header, _ := sendWithHeader(t, host, GET, SearchSpendsPath, req.Header, nil)
+		return code, header
+	}
+
+	for i := 0; i < 3; i++ {
+		code, _ := send("user", "123", "10.0.0.1")
+		require.Equal(http.StatusUnauthorized, code)
+	}
+
+	// The password is not checked during the lockout
+	code, header := send("user", "qwerty", "10.0.0.1")
+	require.Equal(http.StatusTooManyRequests, code)
+	retryAfter, err := strconv.Atoi(header.Get("Retry-After"))
+	require.NoError(err)
+	require.InDelta(60, retryAfter, 2)
+
+	// The username is locked for all ips
+	code, _ = send("user", "qwerty", "10.0.0.2")
+	require.Equal(http.StatusTooManyRequests, code)
+
+	// The ip is locked for all usernames. The last untrusted ip is used
+	code, _ = send("admin", "qwerty", "10.0.0.1")
+	require.Equal(http.StatusTooManyRequests, code)
+	code, _ = send("admin", "qwerty", "10.0.0.3, 10.0.0.1, 127.0.0.1")
+	require.Equal(http.StatusTooManyRequests, code)
+
+	code, _ = send("admin", "qwerty", "10.0.0.3")
+	require.Equal(http.StatusOK, code)
+
+	// Unknown usernames are not tracked
+	code, _ = send("unknown", "qwerty", "10.0.0.4")
+	require.Equal(http.StatusUnauthorized, code)
+
+	// Lockouts of the same attempt have the same time, so their order is not defined
+	lockouts := getAuthLockouts(t, host)
+	require.Len(lockouts, 3)
+	require.Equal("10.0.0.4", lockouts[0].Value)
+
+	for _, want := range []struct {
+		kind     db.AuthLockoutKind
+		value    string
+		failures int
+		locked   bool
+	}{
+		{kind: db.AuthLockoutIP, value: "10.0.0.4", failures: 1, locked: false},
+		{kind: db.AuthLockoutUsername, value: "user", failures: 3, locked: true},
+		{kind: db.AuthLockoutIP, value: "10.0.0.1", failures: 3, locked: true},
+	} {
+		var found bool
+		for _, got := range lockouts {
+			if got.Kind != want.kind || got.Value != want.value {
+				continue
+			}
+			found = true
+			require.Equal(want.failures, got.Failures)
+			require.Equal(want.locked, got.IsLocked(time.Now()))
+		}
+		require.True(found, "lockout of %s %q not found", want.kind, want.value)
+	}
+
+	// Clear lockouts
+	for _, lockout := range lockouts {
+		require.Equal(http.StatusOK, removeAuthLockout(t, host, lockout.ID))
+	}
+	require.Empty(getAuthLockouts(t, host))
+
+	code = removeAuthLockout(t, host, lockouts[0].ID)
+	require.Equal(http.StatusNotFound, code)
+
+	code, _ = send("user", "qwerty", "10.0.0.1")
+	require.Equal(http.StatusOK, code)
+}
+
+func testAuthLockouts_APIToken(t *testing.T, host string) {
+	require := require.New(t)
+
+	header := bearerHeader("bm_invalid")
+	header.Set("X-Forwarded-For", "10.0.1.1")
+
+	for i := 0; i < 3; i++ {
+		code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, header, nil)
+		require.Equal(http.StatusUnauthorized, code)
+	}
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, header, nil)
+	require.Equal(http.StatusTooManyRequests, code)
+
+	lockouts := getAuthLockouts(t, host)
+	require.Len(lockouts, 1)
+	require.Equal(db.AuthLockoutIP, lockouts[0].Kind)
+	require.Equal("10.0.1.1", lockouts[0].Value)
+
+	code = removeAuthLockout(t, host, lockouts[0].ID)
+	require.Equal(http.StatusOK, code)
+}
+
+func testAuthLockouts_LoginPage(t *testing.T, host string) {
+	require := require.New(t)
+
+	for i := 0; i < 3; i++ {
+		resp, body := sendPageRequest(t, host, POST, "/login", url.Values{
+			"username": {"guest"}, "password": {"123"},
+		}, nil)
+		require.Equal(http.StatusOK, resp.StatusCode)
+		require.Contains(body, "Invalid username or password")
+	}
+
+	resp, body := sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"guest"}, "password": {"qwerty"},
+	}, nil)
+	require.Equal(http.StatusTooManyRequests, resp.StatusCode)
+	require.NotEmpty(resp.Header.Get("Retry-After"))
+	require.Contains(body, "Too many failed login attempts. Try again later")
+	require.Empty(resp.Cookies())
+
+	// Requests without 'X-Forwarded-For' header use the proxy ip
+	lockouts := getAuthLockouts(t, host)
+	require.Len(lockouts, 2)
+	for _, lockout := range lockouts {
+		require.Contains([]string{"guest", "127.0.0.1", "::1"}, lockout.Value)
+
+		require.Equal(http.StatusOK, removeAuthLockout(t, host, lockout.ID))
+	}
+
+	resp, _ = sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"guest"}, "password": {"qwerty"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+}
+
+func testAuthLockouts_ConcurrentAttempts(t *testing.T, host string) {
+	require := require.New(t)
+
+	const attempts = 10
+
+	header := basicAuthHeader("unknown")
+	header.Set("X-Forwarded-For", "10.0.2.1")
+
+	codes := make(chan int, attempts)
+	var wg sync.WaitGroup
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, header, nil)
+			codes <- code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	var failed int
+	for code := range codes {
+		require.Contains([]int{http.StatusUnauthorized, http.StatusTooManyRequests}, code)
+		if code == http.StatusUnauthorized {
+			failed++
+		}
+	}
+
+	// Attempts are reserved before the password is checked, so concurrent requests can't check more
+	// passwords than the threshold allows. Every checked password is counted
+	require.Equal(3, failed)
+
+	lockouts := getAuthLockouts(t, host)
+	require.Len(lockouts, 1)
+	require.Equal(failed, lockouts[0].Failures)
+	require.True(lockouts[0].IsLocked(time.Now()))
+
+	require.Equal(http.StatusOK, removeAuthLockout(t, host, lockouts[0].ID))
+}
+
+func getAuthLockouts(t *testing.T, host string) []db.AuthLockout {
+	code, _, body := sendWithHeader(t, host, GET, AuthLockoutsPath, authLockoutsAdminHeader(), nil)
+	require.Equal(t, http.StatusOK, code)
+
+	var resp models.GetAuthLockoutsResp
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.AuthLockouts
+}
+
+func removeAuthLockout(t *testing.T, host string, id uint) (statusCode int) {
+	req := models.RemoveAuthLockoutReq{ID: id}
+	statusCode, _, _ = sendWithHeader(t, host, DELETE, AuthLockoutsPath, authLockoutsAdminHeader(), req)
+	return statusCode
+}
+
+// authLockoutsAdminHeader returns a header for requests from an ip that is never locked
+func authLockoutsAdminHeader() http.Header {
+	header := basicAuthHeader("admin")
+	header.Set("X-Forwarded-For", "10.0.9.9")
+	return header
+}
@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestBackup(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "export and restore", Fn: testBackup_ExportAndRestore},
+		{Name: "invalid backup", Fn: testBackup_InvalidBackup},
+	})
+}
+
+func testBackup_ExportAndRestore(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},                   // 1
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "groceries", ParentID: 1}}, // 2
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "house"}},                  // 3
+		//
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "(?i)lidl", TypeID: 2, Notes: "auto"}},
+		//
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 2500}},
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "cashback", Notes: "123", Income: 10}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", TypeID: 3, Cost: 1000}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "lidl", Cost: 20}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "pizza", TypeID: 1, Notes: "friday", Cost: 15}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 5, Title: "book", Cost: 7}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	now := time.Now()
+	getMonth := func() db.Month {
+		var resp models.GetMonthResp
+		RequestOK{GET, MonthsPath, models.GetMonthByDateReq{Year: now.Year(), Month: now.Month()}}.Send(t, host, &resp)
+		return resp.Month
+	}
+	monthBefore := getMonth()
+
+	backup := exportBackup(t, host)
+	require.Equal(db.BackupVersion, backup.Version)
+	require.Equal(
+		[]db.BackupSpendType{
+			{ID: 1, Name: "food"},
+			{ID: 2, Name: "groceries", ParentID: 1},
+			{ID: 3, Name: "house"},
+		},
+		backup.SpendTypes,
+	)
+	require.Equal([]db.BackupSpendRule{{TitlePattern: "(?i)lidl", TypeID: 2, Notes: "auto"}}, backup.SpendRules)
+	require.Len(backup.Months, 1)
+	require.Equal(
+		[]db.BackupDay{
+			{
+				Day: 1,
+				Spends: []db.BackupSpend{
+					{Title: "lidl", TypeID: 2, Notes: "auto", Cost: money.FromInt(20)},
+					{Title: "pizza", TypeID: 1, Notes: "friday", Cost: money.FromInt(15)},
+				},
+			},
+			{
+				Day:    5,
+				Spends: []db.BackupSpend{{Title: "book", Cost: money.FromInt(7)}},
+			},
+		},
+		backup.Months[0].Days,
+	)
+
+	// Change data to check that restore replaces it
+	for _, req := range []RequestOK{
+		{DELETE, IncomesPath, models.RemoveIncomeReq{ID: 1}},
+		{DELETE, SpendRulesPath, models.RemoveSpendRuleReq{ID: 1}},
+	} {
+		req.Send(t, host, nil)
+	}
+	RequestCreated{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "temp"}}.Send(t, host, nil)
+
+	var resp models.RestoreBackupResp
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{Backup: backup}}.Send(t, host, &resp)
+	require.Equal(
+		db.BackupStats{SpendTypes: 3, SpendRules: 1, Months: 1, Incomes: 2, MonthlyPayments: 1, Spends: 3},
+		resp.Stats,
+	)
+
+	// Ids are rebuilt, so compare only the content
+	restoredBackup := exportBackup(t, host)
+	require.Equal(normalizeBackup(backup), normalizeBackup(restoredBackup))
+
+	monthAfter := getMonth()
+	require.Equal(monthBefore.TotalIncome, monthAfter.TotalIncome)
+	require.Equal(monthBefore.TotalSpend, monthAfter.TotalSpend)
+	require.Equal(monthBefore.DailyBudget, monthAfter.DailyBudget)
+	require.Equal(monthBefore.Result, monthAfter.Result)
+	require.Len(monthAfter.Days, len(monthBefore.Days))
+	for i := range monthBefore.Days {
+		require.Equal(monthBefore.Days[i].Saldo, monthAfter.Days[i].Saldo)
+	}
+}
+
+func testBackup_InvalidBackup(t *testing.T, host string) {
+	for _, req := range []Request{
+		{
+			POST, RestoreBackupPath, models.RestoreBackupReq{Backup: db.Backup{Version: 100}},
+			http.StatusBadRequest, "invalid backup: unsupported backup version 100, expected 1",
+		},
+		{
+			POST, RestoreBackupPath,
+			models.RestoreBackupReq{Backup: db.Backup{
+				Version:    1,
+				SpendTypes: []db.BackupSpendType{{ID: 1, Name: "a", ParentID: 2}, {ID: 2, Name: "b", ParentID: 1}},
+			}},
+			http.StatusBadRequest, "invalid backup: parents of Spend Type with id 1 have a cycle",
+		},
+		{
+			POST, RestoreBackupPath,
+			models.RestoreBackupReq{Backup: db.Backup{
+				Version: 1,
+				Months: []db.BackupMonth{
+					{Year: 2020, Month: time.February, Days: []db.BackupDay{{Day: 30}}},
+				},
+			}},
+			http.StatusBadRequest, "invalid backup: invalid month 2020-02: invalid day 30",
+		},
+		{
+			POST, RestoreBackupPath,
+			models.RestoreBackupReq{Backup: db.Backup{
+				Version: 1,
+				Months: []db.BackupMonth{
+					{
+						Year: 2020, Month: time.February,
+						Days: []db.BackupDay{{Day: 1, Spends: []db.BackupSpend{{Title: "a", TypeID: 1}}}},
+					},
+				},
+			}},
+			http.StatusBadRequest, "invalid backup: invalid month 2020-02: invalid Spend \"a\": there's no Spend Type with id 1",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+}
+
+func exportBackup(t *testing.T, host string) db.Backup {
+	require := require.New(t)
+
+	statusCode, body := Request{Method: GET, Path: BackupPath}.send(t, http.DefaultClient, host)
+	require.Equal(http.StatusOK, statusCode)
+
+	var backup db.Backup
+	require.NoError(json.Unmarshal(body, &backup))
+	return backup
+}
+
+// normalizeBackup resets the creation time and replaces Spend Type ids with their indexes
+func normalizeBackup(backup db.Backup) db.Backup {
+	backup.CreatedAt = time.Time{}
+
+	ids := make(map[uint]uint, len(backup.SpendTypes))
+	for i, t := range backup.SpendTypes {
+		ids[t.ID] = uint(i + 1)
+	}
+
+	spendTypes := make([]db.BackupSpendType, 0, len(backup.SpendTypes))
+	for _, t := range backup.SpendTypes {
+		t.ID, t.ParentID = ids[t.ID], ids[t.ParentID]
+		spendTypes = append(spendTypes, t)
+	}
+	backup.SpendTypes = spendTypes
+
+	spendRules := make([]db.BackupSpendRule, 0, len(backup.SpendRules))
+	for _, r := range backup.SpendRules {
+		r.TypeID = ids[r.TypeID]
+		spendRules = append(spendRules, r)
+	}
+	backup.SpendRules = spendRules
+
+	months := make([]db.BackupMonth, 0, len(backup.Months))
+	for _, m := range backup.Months {
+		mps := make([]db.BackupMonthlyPayment, 0, len(m.MonthlyPayments))
+		for _, mp := range m.MonthlyPayments {
+			mp.TypeID = ids[mp.TypeID]
+			mps = append(mps, mp)
+		}
+		m.MonthlyPayments = mps
+
+		days := make([]db.BackupDay, 0, len(m.Days))
+		for _, d := range m.Days {
+			spends := make([]db.BackupSpend, 0, len(d.Spends))
+			for _, s := range d.Spends {
+				s.TypeID = ids[s.TypeID]
+				spends = append(spends, s)
+			}
+			d.Spends = spends
+			days = append(days, d)
+		}
+		m.Days = days
+		months = append(months, m)
+	}
+	backup.Months = months
+
+	return backup
+}
@@ -55,12 +55,12 @@ func testBasicUsage_SpendTypes(t *testing.T, host string) {
 	RequestOK{GET, SpendTypesPath, nil}.Send(t, host, &resp)
 	require.Equal(
 		[]db.SpendType{
-			{ID: 1, Name: "food"},
-			{ID: 2, Name: "fastfood", ParentID: 1},
-			{ID: 3, Name: "pizza", ParentID: 1},
-			{ID: 4, Name: "travel"},
-			{ID: 6, Name: "house"},
-			{ID: 7, Name: "entertainment"},
+			{ID: 1, Name: "food", Version: 2},
+			{ID: 2, Name: "fastfood", ParentID: 1, Version: 1},
+			{ID: 3, Name: "pizza", ParentID: 1, Version: 2},
+			{ID: 4, Name: "travel", Version: 1},
+			{ID: 6, Name: "house", Version: 1},
+			{ID: 7, Name: "entertainment", Version: 1},
 		},
 		resp.SpendTypes,
 	)
@@ -94,9 +94,9 @@ func testBasicUsage_Incomes(t *testing.T, host string) {
 	month := getCurrentMonth(t, host)
 
 	expectedIncomes := []db.Income{
-		{ID: 1, Title: "salary", Income: money.FromInt(2500)},
-		{ID: 2, Title: "gift", Notes: "from friends", Income: money.FromInt(500)},
-		{ID: 4, Title: "cashback", Notes: "123", Income: money.FromInt(50)},
+		{ID: 1, Title: "salary", Income: money.FromInt(2500), Version: 1},
+		{ID: 2, Title: "gift", Notes: "from friends", Income: money.FromInt(500), Version: 2},
+		{ID: 4, Title: "cashback", Notes: "123", Income: money.FromInt(50), Version: 2},
 	}
 	for i := range expectedIncomes {
 		expectedIncomes[i].Year = month.Year
@@ -136,9 +136,12 @@ func testBasicUsage_MonthlyPayments(t *testing.T, host string) {
 	month := getCurrentMonth(t, host)
 
 	expectedMonthlyPayments := []db.MonthlyPayment{
-		{ID: 1, Title: "rent", Cost: money.FromInt(800)},
-		{ID: 2, Title: "patreon", Notes: "with VAT", Cost: money.FromInt(50)},
-		{ID: 3, Title: "netflix", Type: &db.SpendType{ID: 7, Name: "entertainment"}, Cost: money.FromInt(30)},
+		{ID: 1, Title: "rent", Cost: money.FromInt(800), Version: 2},
+		{ID: 2, Title: "patreon", Notes: "with VAT", Cost: money.FromInt(50), Version: 2},
+		{
+			ID: 3, Title: "netflix", Type: &db.SpendType{ID: 7, Name: "entertainment"}, Cost: money.FromInt(30),
+			Version: 2,
+		},
 	}
 	for i := range expectedMonthlyPayments {
 		expectedMonthlyPayments[i].Year = month.Year
@@ -200,7 +203,7 @@ func testBasicUsage_Spends(t *testing.T, host string) {
 		}},
 		{ID: 2, Spends: []db.Spend{}},
 		{ID: 3, Spends: []db.Spend{
-			{ID: 4, Title: "oil", Cost: money.FromInt(7)},
+			{ID: 4, Title: "oil", Cost: money.FromInt(7), Version: 2},
 			{ID: 5, Title: "dinner in KFC", Type: &db.SpendType{ID: 2, Name: "fastfood", ParentID: 1}, Cost: money.FromInt(15)},
 		}},
 		{ID: 4, Spends: []db.Spend{}},
@@ -214,10 +217,16 @@ func testBasicUsage_Spends(t *testing.T, host string) {
 		}},
 		{ID: 11, Spends: []db.Spend{
 			{ID: 7, Title: "meat", Type: &db.SpendType{ID: 1, Name: "food"}, Cost: money.FromInt(20)},
-			{ID: 8, Title: "eggs", Type: &db.SpendType{ID: 1, Name: "food"}, Notes: "10 count", Cost: money.FromInt(8)},
+			{
+				ID: 8, Title: "eggs", Type: &db.SpendType{ID: 1, Name: "food"}, Notes: "10 count", Cost: money.FromInt(8),
+				Version: 2,
+			},
 		}},
 		{ID: 12, Spends: []db.Spend{
-			{ID: 9, Title: "pizza", Type: &db.SpendType{ID: 3, Name: "pizza", ParentID: 1}, Cost: money.FromInt(100)},
+			{
+				ID: 9, Title: "pizza", Type: &db.SpendType{ID: 3, Name: "pizza", ParentID: 1}, Cost: money.FromInt(100),
+				Version: 2,
+			},
 		}},
 		{ID: 13, Spends: []db.Spend{}},
 		{ID: 14, Spends: []db.Spend{}},
@@ -240,6 +249,10 @@ func testBasicUsage_Spends(t *testing.T, host string) {
 			expectedDays[i].Spends[j].Year = expectedDays[i].Year
 			expectedDays[i].Spends[j].Month = expectedDays[i].Month
 			expectedDays[i].Spends[j].Day = expectedDays[i].Day
+			// Only edited Spends have a version other than 1
+			if expectedDays[i].Spends[j].Version == 0 {
+				expectedDays[i].Spends[j].Version = 1
+			}
 		}
 
 		var prevSaldo money.Money
@@ -364,6 +377,21 @@ func testBasicUsage_SearchSpends(t *testing.T, host string) {
 			req:  models.SearchSpendsReq{TypeIDs: []uint{1}, Sort: "title"},
 			ids:  []uint{1, 8, 2, 7, 3},
 		},
+		{
+			name: "limit",
+			req:  models.SearchSpendsReq{Limit: 3},
+			ids:  []uint{1, 2, 3},
+		},
+		{
+			name: "limit and offset",
+			req:  models.SearchSpendsReq{Sort: "cost", Order: "desc", Limit: 3, Offset: 2},
+			ids:  []uint{9, 12, 10},
+		},
+		{
+			name: "offset beyond the last spend",
+			req:  models.SearchSpendsReq{Limit: 3, Offset: 20},
+			ids:  []uint{},
+		},
 	} {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
@@ -374,6 +402,20 @@ func testBasicUsage_SearchSpends(t *testing.T, host string) {
 			require.Equal(getSpends(tt.ids...), resp.Spends)
 		})
 	}
+
+	t.Run("total", func(t *testing.T) {
+		require := require.New(t)
+
+		// Total must be calculated for all found spends
+		var resp models.SearchSpendsResp
+		RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{TypeIDs: []uint{1}, Limit: 2}}.Send(t, host, &resp)
+		require.Len(resp.Spends, 2)
+		require.Equal(db.SearchSpendsTotal{Count: 5, Cost: money.FromInt(42)}, resp.Total)
+
+		RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{Title: "unknown"}}.Send(t, host, &resp)
+		require.Empty(resp.Spends)
+		require.Equal(db.SearchSpendsTotal{}, resp.Total)
+	})
 }
 
 func getCurrentMonth(t *testing.T, host string) db.Month {
@@ -0,0 +1,121 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils/schema"
+)
+
+func TestCalendar(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "monthly payments", Fn: testCalendar_MonthlyPayments},
+	})
+}
+
+func testCalendar_MonthlyPayments(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "house"}},
+		//
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", TypeID: 1, Cost: 1000, DueDay: 31}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "internet", Cost: 10}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "phone", Notes: "a, b", Cost: 5, DueDay: 5}},
+	} {
+		req.Send(t, host, nil)
+	}
+	for _, req := range []Request{
+		{
+			PUT, MonthlyPaymentsPath, models.EditMonthlyPaymentReq{ID: 2, DueDay: ptrUint(10)},
+			http.StatusOK, "",
+		},
+		{
+			PUT, MonthlyPaymentsPath, models.EditMonthlyPaymentReq{ID: 3, DueDay: ptrUint(0)},
+			http.StatusOK, "",
+		},
+		{
+			POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "gym", Cost: 5, DueDay: 32},
+			http.StatusBadRequest, "due_day must be in range [1, 31]",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	now := time.Now()
+	first := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	var resp models.GetMonthResp
+	RequestOK{GET, MonthsPath, models.GetMonthByDateReq{Year: now.Year(), Month: now.Month()}}.Send(t, host, &resp)
+	require.Len(resp.Month.MonthlyPayments, 3)
+	require.Equal(uint(31), resp.Month.MonthlyPayments[0].DueDay)
+	require.Equal(uint(10), resp.Month.MonthlyPayments[1].DueDay)
+	require.Equal(uint(0), resp.Month.MonthlyPayments[2].DueDay)
+
+	statusCode, body := getCalendar(t, host, models.GetMonthlyPaymentsCalendarReq{})
+	require.Equal(http.StatusOK, statusCode)
+	require.True(strings.HasPrefix(body, "BEGIN:VCALENDAR\r\n"))
+	require.Equal(4, strings.Count(body, "BEGIN:VEVENT"))
+	require.Equal(4, strings.Count(body, "TRIGGER:-P1D\r\n"))
+	require.NotContains(body, "phone")
+
+	// The next month doesn't exist, so Monthly Payments of the current month are used
+	for _, month := range []time.Time{first, first.AddDate(0, 1, 0)} {
+		lastDay := month.AddDate(0, 1, -1)
+
+		require.Contains(body, fmt.Sprintf(
+			"UID:monthly-payment-1-%s@budget-manager\r\n", month.Format("200601"),
+		))
+		require.Contains(body, fmt.Sprintf(
+			"DTSTART;VALUE=DATE:%s\r\n", lastDay.Format("20060102"),
+		))
+		require.Contains(body, fmt.Sprintf(
+			"DTSTART;VALUE=DATE:%s\r\n", month.AddDate(0, 0, 9).Format("20060102"),
+		))
+	}
+	require.Contains(body, "SUMMARY:rent: 1000.00\r\n")
+	require.Contains(body, `DESCRIPTION:Cost: 1000.00\nType: house`+"\r\n")
+	require.Contains(body, "CATEGORIES:house\r\n")
+	require.Contains(body, "SUMMARY:internet: 10.00\r\n")
+
+	statusCode, body = getCalendar(t, host, models.GetMonthlyPaymentsCalendarReq{Months: 1})
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(2, strings.Count(body, "BEGIN:VEVENT"))
+
+	statusCode, _ = getCalendar(t, host, models.GetMonthlyPaymentsCalendarReq{Months: 13})
+	require.Equal(http.StatusBadRequest, statusCode)
+}
+
+func getCalendar(t *testing.T, host string, req models.GetMonthlyPaymentsCalendarReq) (statusCode int, body string) {
+	require := require.New(t)
+
+	query := url.Values{}
+	require.NoError(schema.Encode(req, query))
+
+	u := &url.URL{Scheme: "http", Host: host, Path: string(CalendarPath), RawQuery: query.Encode()}
+	httpReq, cancel := newRequest(t, GET, u.String(), nil)
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		require.Equal("text/calendar; charset=utf-8", resp.Header.Get("Content-Type"))
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(err)
+
+	return resp.StatusCode, string(data)
+}
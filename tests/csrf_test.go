@@ -0,0 +1,148 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/csrf"
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestCSRF(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "session", Fn: testCSRF_Session},
+		{Name: "without session", Fn: testCSRF_WithoutSession},
+		{Name: "non-browser clients", Fn: testCSRF_NonBrowserClients},
+	}, func(env *TestEnv) {
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user": {PasswordHash: "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC"}, // user:qwerty
+		}
+	})
+}
+
+func testCSRF_Session(t *testing.T, host string) {
+	require := require.New(t)
+
+	resp, _ := sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"qwerty"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	cookie := getSessionCookie(t, resp)
+	token := csrf.SessionToken(cookie.Value)
+
+	// The token is embedded into pages
+	_, body := sendPageRequest(t, host, GET, "/search/spends", nil, cookie)
+	require.Contains(body, `<meta name="csrf-token" content="`+token+`">`)
+	require.Contains(body, `<input type="hidden" name="csrf_token" value="`+token+`">`)
+
+	send := func(header http.Header) (int, string) {
+		header.Set("Cookie", cookie.String())
+		code, _, body := sendWithHeader(t, host, POST, SpendTypesPath, header, models.AddSpendTypeReq{Name: "food"})
+		return code, decodeCSRFError(t, body)
+	}
+
+	code, errMsg := send(http.Header{})
+	require.Equal(http.StatusForbidden, code)
+	require.Equal("invalid CSRF token", errMsg)
+
+	code, errMsg = send(http.Header{"X-Csrf-Token": {"123"}})
+	require.Equal(http.StatusForbidden, code)
+	require.Equal("invalid CSRF token", errMsg)
+
+	// Forged requests from other sites are rejected even with a valid token
+	for _, header := range []http.Header{
+		{"Origin": {"https://example.com"}},
+		{"Origin": {"null"}},
+		{"Referer": {"https://example.com/page"}},
+	} {
+		header.Set("X-Csrf-Token", token)
+		code, errMsg = send(header)
+		require.Equal(http.StatusForbidden, code)
+		require.Equal("cross-origin requests are not allowed", errMsg)
+	}
+
+	code, _ = send(http.Header{"X-Csrf-Token": {token}, "Origin": {"http://" + host}})
+	require.Equal(http.StatusCreated, code)
+
+	// Forms
+	resp, _ = sendPageRequest(t, host, POST, "/logout", nil, cookie)
+	require.Equal(http.StatusForbidden, resp.StatusCode)
+
+	resp, _ = sendPageRequest(t, host, POST, "/logout", url.Values{"csrf_token": {token}}, cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+}
+
+func testCSRF_WithoutSession(t *testing.T, host string) {
+	require := require.New(t)
+
+	// The login page sets a cookie with a random token
+	resp, body := sendPageRequest(t, host, GET, "/login", nil, nil)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	var cookie *http.Cookie
+	for _, c := range resp.Cookies() {
+		if c.Name == "csrf" {
+			cookie = c
+		}
+	}
+	require.NotNil(cookie)
+	require.True(cookie.HttpOnly)
+	require.Equal(http.SameSiteLaxMode, cookie.SameSite)
+	require.Contains(body, `<input type="hidden" name="csrf_token" value="`+cookie.Value+`">`)
+
+	form := url.Values{"username": {"user"}, "password": {"qwerty"}, "next": {"/months"}}
+	resp, _ = sendPageRequest(t, host, POST, "/login", form, cookie)
+	require.Equal(http.StatusForbidden, resp.StatusCode)
+
+	form.Set("csrf_token", cookie.Value)
+	resp, _ = sendPageRequest(t, host, POST, "/login", form, cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+}
+
+func testCSRF_NonBrowserClients(t *testing.T, host string) {
+	require := require.New(t)
+
+	// Requests without cookies and origin
+	code, _, _ := sendWithHeader(t, host, POST, SpendTypesPath, basicAuthHeader("user"), models.AddSpendTypeReq{
+		Name: "food",
+	})
+	require.Equal(http.StatusCreated, code)
+
+	// API Tokens are not checked
+	token := addAPIToken(t, host, "user", models.AddAPITokenReq{Name: "script"})
+	header := bearerHeader(token.Token)
+	header.Set("Origin", "https://example.com")
+	header.Set("Cookie", "csrf=123")
+	code, _, _ = sendWithHeader(t, host, POST, SpendTypesPath, header, models.AddSpendTypeReq{Name: "house"})
+	require.Equal(http.StatusCreated, code)
+
+	// Basic Auth credentials can be cached by browsers
+	header = basicAuthHeader("user")
+	header.Set("Origin", "https://example.com")
+	code, _, body := sendWithHeader(t, host, POST, SpendTypesPath, header, models.AddSpendTypeReq{Name: "house"})
+	require.Equal(http.StatusForbidden, code)
+	require.Equal("cross-origin requests are not allowed", decodeCSRFError(t, body))
+}
+
+// withCSRFToken adds the token of the session to the form
+func withCSRFToken(form url.Values, sessionCookie *http.Cookie) url.Values {
+	res := url.Values{}
+	for k, v := range form {
+		res[k] = v
+	}
+	res.Set("csrf_token", csrf.SessionToken(sessionCookie.Value))
+	return res
+}
+
+func decodeCSRFError(t *testing.T, body []byte) string {
+	var resp models.BaseResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Error
+}
@@ -0,0 +1,40 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocs(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestFn(testDocs))
+}
+
+func testDocs(t *testing.T, host string) {
+	require := require.New(t)
+
+	code, body := getPage(t, host, "/api/docs/swagger.yaml")
+	require.Equal(http.StatusOK, code)
+	require.True(strings.HasPrefix(body, "basePath: /"))
+
+	code, body = getPage(t, host, "/api/docs/swagger.json")
+	require.Equal(http.StatusOK, code)
+
+	var spec struct {
+		Swagger string                     `json:"swagger"`
+		Paths   map[string]json.RawMessage `json:"paths"`
+	}
+	require.NoError(json.Unmarshal([]byte(body), &spec))
+	require.Equal("2.0", spec.Swagger)
+	require.Contains(spec.Paths, "/api/spends")
+	require.Contains(spec.Paths, "/api/docs/swagger.json")
+
+	code, body = getPage(t, host, "/docs/api")
+	require.Equal(http.StatusOK, code)
+	require.Contains(body, "/api/docs/swagger.json")
+}
@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
 	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
 )
 
@@ -17,6 +21,7 @@ func TestBadRequests(t *testing.T) {
 		{Name: "add", Fn: testErrors_AddRequests},
 		{Name: "edit", Fn: testErrors_EditRequests},
 		{Name: "remove", Fn: testErrors_RemoveRequests},
+		{Name: "codes", Fn: testErrors_Codes},
 	})
 }
 
@@ -45,6 +50,9 @@ func testErrors_GetRequests(t *testing.T, host string) {
 		code int
 	}{
 		{SearchSpendsPath, models.SearchSpendsReq{MinCost: 10, MaxCost: 5}, "min_cost can't be greater than max_cost", http.StatusBadRequest},
+		{SearchSpendsPath, models.SearchSpendsReq{Limit: -1}, "limit can't be negative", http.StatusBadRequest},
+		{SearchSpendsPath, models.SearchSpendsReq{Limit: 1, Offset: -1}, "offset can't be negative", http.StatusBadRequest},
+		{SearchSpendsPath, models.SearchSpendsReq{Offset: 1}, "offset can't be used without limit", http.StatusBadRequest},
 		{MonthsPath, models.GetMonthByDateReq{Year: 2020, Month: time.January}, "such Month doesn't exist", http.StatusNotFound},
 		{MonthsPath, models.GetMonthByDateReq{Year: 2020, Month: -1}, "invalid month", http.StatusBadRequest},
 		{MonthsPath, models.GetMonthByDateReq{Year: 2020, Month: 0}, "invalid month", http.StatusBadRequest},
@@ -139,9 +147,86 @@ func testErrors_RemoveRequests(t *testing.T, host string) {
 		{SpendsPath, models.RemoveSpendReq{ID: 10}, http.StatusNotFound, "such Spend doesn't exist"},
 		{SpendTypesPath, models.RemoveSpendTypeReq{ID: 10}, http.StatusNotFound, "such Spend Type doesn't exist"},
 		// Bad Request
-		{SpendTypesPath, models.RemoveSpendTypeReq{ID: 3}, http.StatusBadRequest, "Spend Type is used by Monthly Payment or Spend"},
-		{SpendTypesPath, models.RemoveSpendTypeReq{ID: 4}, http.StatusBadRequest, "Spend Type is used by Monthly Payment or Spend"},
+		{SpendTypesPath, models.RemoveSpendTypeReq{ID: 3}, http.StatusBadRequest, "Spend Type is used by Monthly Payment, Spend, Spend Rule or Saved Search"},
+		{SpendTypesPath, models.RemoveSpendTypeReq{ID: 4}, http.StatusBadRequest, "Spend Type is used by Monthly Payment, Spend, Spend Rule or Saved Search"},
 	} {
 		Request{DELETE, tt.path, tt.req, tt.status, tt.err}.Send(t, host, nil)
 	}
 }
+
+func testErrors_Codes(t *testing.T, host string) {
+	for _, tt := range []struct {
+		method      Method
+		path        Path
+		req         interface{}
+		status      int
+		code        models.ErrorCode
+		fieldErrors []models.FieldError
+	}{
+		{
+			method: POST, path: IncomesPath, req: models.AddIncomeReq{Income: -1},
+			status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed,
+			fieldErrors: []models.FieldError{
+				{Field: "month_id", Code: models.FieldErrorCodeRequired, Message: "month_id can't be empty or zero"},
+				{Field: "title", Code: models.FieldErrorCodeRequired, Message: "title can't be empty"},
+				{Field: "income", Code: models.FieldErrorCodeNotPositive, Message: "income must be greater than zero"},
+			},
+		},
+		{
+			method: POST, path: SavedSearchesPath,
+			req:    models.AddSavedSearchReq{Name: "name", Search: models.SearchSpendsReq{Limit: -1}},
+			status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed,
+			fieldErrors: []models.FieldError{
+				{Field: "search.limit", Code: models.FieldErrorCodeNegative, Message: "limit can't be negative"},
+			},
+		},
+		{
+			method: POST, path: RestoreBackupPath, req: models.RestoreBackupReq{Backup: db.Backup{Version: 100}},
+			status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed,
+			fieldErrors: []models.FieldError{
+				{
+					Field: "version", Code: models.FieldErrorCodeInvalid,
+					Message: "invalid backup: unsupported backup version 100, expected 1",
+				},
+			},
+		},
+		{
+			method: GET, path: SearchSpendsPath, req: models.SearchSpendsReq{Query: "(coffee"},
+			status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed,
+			fieldErrors: []models.FieldError{
+				{Field: "query", Code: models.FieldErrorCodeInvalid, Message: "invalid query: expected ')', got end of query"},
+			},
+		},
+		{
+			method: GET, path: SearchSpendsPath, req: models.SearchSpendsReq{Query: "cost>NaN"},
+			status: http.StatusBadRequest, code: models.ErrorCodeValidationFailed,
+			fieldErrors: []models.FieldError{
+				{Field: "query", Code: models.FieldErrorCodeInvalid, Message: "invalid query: invalid cost 'NaN'"},
+			},
+		},
+		{
+			method: GET, path: MonthsPath, req: models.GetMonthByDateReq{Year: 2020, Month: time.January},
+			status: http.StatusNotFound, code: models.ErrorCodeMonthNotFound,
+		},
+		{
+			method: PUT, path: SpendsPath, req: models.EditSpendReq{ID: 10, Title: ptrStr("new")},
+			status: http.StatusNotFound, code: models.ErrorCodeSpendNotFound,
+		},
+		{
+			method: DELETE, path: SpendTypesPath, req: models.RemoveSpendTypeReq{ID: 3},
+			status: http.StatusBadRequest, code: models.ErrorCodeSpendTypeIsUsed,
+		},
+		{
+			method: GET, path: "/api/unknown",
+			status: http.StatusNotFound, code: models.ErrorCodeNotFound,
+		},
+	} {
+		statusCode, body := Request{Method: tt.method, Path: tt.path, Request: tt.req}.send(t, http.DefaultClient, host)
+		require.Equal(t, tt.status, statusCode)
+
+		var resp models.BaseResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		require.Equal(t, tt.code, resp.ErrorCode)
+		require.Equal(t, tt.fieldErrors, resp.FieldErrors)
+	}
+}
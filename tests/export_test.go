@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/utils/schema"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "ledger", Fn: testExport_Ledger},
+	})
+}
+
+func testExport_Ledger(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},                   // 1
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "groceries", ParentID: 1}}, // 2
+		//
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Notes: "#work", Income: 2500}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", Cost: 1000}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 2, Title: "lidl", TypeID: 2, Notes: "milk", Cost: 20}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "book", Cost: 7}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	now := time.Now()
+	date := func(day int) string {
+		return time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+	}
+
+	statusCode, body := exportLedger(t, host, models.ExportLedgerReq{})
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(
+		fmt.Sprintf(`%[1]s * salary
+    ; #work
+    ; work:
+    Income:Salary    -2500.00
+    Assets:Cash
+
+%[1]s * rent
+    Expenses:Uncategorized    1000.00
+    Assets:Cash
+
+%[1]s * book
+    Expenses:Uncategorized    7.00
+    Assets:Cash
+
+%[2]s * lidl
+    ; milk
+    Expenses:Food:Groceries    20.00
+    Assets:Cash
+`, date(1), date(2)),
+		body,
+	)
+
+	// Pagination is ignored
+	fullBody := body
+	statusCode, body = exportLedger(t, host, models.ExportLedgerReq{
+		SearchSpendsReq: models.SearchSpendsReq{Limit: 1, Offset: 1},
+	})
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(fullBody, body)
+
+	// Filter Spends
+	statusCode, body = exportLedger(t, host, models.ExportLedgerReq{
+		SearchSpendsReq: models.SearchSpendsReq{TypeIDs: []uint{2}},
+		Format:          "beancount",
+		Currency:        "USD",
+		Account:         "Assets:Bank",
+	})
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(
+		fmt.Sprintf(`%[1]s open Assets:Bank
+%[1]s open Expenses:Food:Groceries
+%[1]s open Expenses:Uncategorized
+%[1]s open Income:Salary
+
+%[1]s * "salary" #work
+  notes: "#work"
+  Income:Salary  -2500.00 USD
+  Assets:Bank
+
+%[1]s * "rent"
+  Expenses:Uncategorized  1000.00 USD
+  Assets:Bank
+
+%[2]s * "lidl"
+  notes: "milk"
+  Expenses:Food:Groceries  20.00 USD
+  Assets:Bank
+`, date(1), date(2)),
+		body,
+	)
+
+	// Filter by date
+	statusCode, body = exportLedger(t, host, models.ExportLedgerReq{
+		SearchSpendsReq: models.SearchSpendsReq{
+			After: time.Date(now.Year(), now.Month(), 2, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	require.Equal(http.StatusOK, statusCode)
+	require.Equal(
+		fmt.Sprintf(`%[1]s * lidl
+    ; milk
+    Expenses:Food:Groceries    20.00
+    Assets:Cash
+`, date(2)),
+		body,
+	)
+
+	statusCode, _ = exportLedger(t, host, models.ExportLedgerReq{Format: "beancount"})
+	require.Equal(http.StatusBadRequest, statusCode)
+}
+
+func exportLedger(t *testing.T, host string, req models.ExportLedgerReq) (statusCode int, body string) {
+	require := require.New(t)
+
+	query := url.Values{}
+	require.NoError(schema.Encode(req, query))
+
+	u := &url.URL{Scheme: "http", Host: host, Path: string(ExportLedgerPath), RawQuery: query.Encode()}
+	httpReq, cancel := newRequest(t, GET, u.String(), nil)
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(err)
+
+	return resp.StatusCode, string(data)
+}
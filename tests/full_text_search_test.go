@@ -0,0 +1,72 @@
+//go:build sqlite_fts5
+// +build sqlite_fts5
+
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+// TestFullTextSearch checks features that are not supported by the LIKE fallback. SQLite supports them
+// only with FTS5, so the test requires build tag 'sqlite_fts5'
+func TestFullTextSearch(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestFn(testFullTextSearch))
+}
+
+func testFullTextSearch(t *testing.T, host string) {
+	require := require.New(t)
+
+	m := money.FromInt
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			Months: []db.BackupMonth{
+				{
+					Year: 2026, Month: time.January,
+					Days: []db.BackupDay{
+						{Day: 5, Spends: []db.BackupSpend{
+							{Title: "Books", Notes: "gift for a friend", Cost: m(15)}, // 1
+							{Title: "Book", Notes: "book about books", Cost: m(6)},    // 2
+							{Title: "Bookmark", Cost: m(1)},                           // 3
+							{Title: "Кофе", Notes: "Капучино", Cost: m(3)},            // 4
+							{Title: "Чай", Notes: "Зелёный чай с мятой", Cost: m(2)},  // 5
+						}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	for _, tt := range []struct {
+		text string
+		ids  []uint
+	}{
+		// Words are compared by their stems. The most relevant Spends go first
+		{text: "book", ids: []uint{2, 1}},
+		{text: "gifts", ids: []uint{1}},
+		// Case folding of non-ASCII letters
+		{text: "кофе", ids: []uint{4}},
+		{text: "капучино", ids: []uint{4}},
+		// Words in other languages are compared as a whole, English stop-words are not ignored
+		{text: "чай мятой", ids: []uint{5}},
+		{text: "for", ids: []uint{1}},
+	} {
+		var resp models.SearchSpendsResp
+		RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{Text: tt.text}}.Send(t, host, &resp)
+
+		ids := make([]uint, 0, len(resp.Spends))
+		for _, spend := range resp.Spends {
+			ids = append(ids, spend.ID)
+		}
+		require.Equal(tt.ids, ids, tt.text)
+	}
+}
@@ -0,0 +1,216 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestIdempotencyKeys(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "retry", Fn: testIdempotencyKeys_Retry},
+		{Name: "errors", Fn: testIdempotencyKeys_Errors},
+	})
+}
+
+func TestIdempotencyKeysWithAuth(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "users", Fn: testIdempotencyKeys_Users},
+		{Name: "secrets", Fn: testIdempotencyKeys_Secrets},
+	}, func(env *TestEnv) {
+		const hash = "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC" // qwerty
+
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user":  {PasswordHash: hash},
+			"admin": {PasswordHash: hash},
+		}
+	})
+}
+
+func testIdempotencyKeys_Retry(t *testing.T, host string) {
+	require := require.New(t)
+
+	header := http.Header{}
+	header.Set("Idempotency-Key", "add-bread")
+
+	req := models.AddSpendReq{DayID: 1, Title: "bread", Cost: 2}
+
+	code, respHeader, body := sendWithHeader(t, host, POST, SpendsPath, header, req)
+	require.Equal(http.StatusCreated, code)
+	require.Empty(respHeader.Get("Idempotent-Replayed"))
+
+	var firstResp models.AddSpendResp
+	require.NoError(json.Unmarshal(body, &firstResp))
+
+	// Retries return the stored response
+	for i := 0; i < 2; i++ {
+		code, respHeader, retryBody := sendWithHeader(t, host, POST, SpendsPath, header, req)
+		require.Equal(http.StatusCreated, code)
+		require.Equal("true", respHeader.Get("Idempotent-Replayed"))
+		require.Equal("application/json", respHeader.Get("Content-Type"))
+		require.Equal(string(body), string(retryBody))
+	}
+
+	// Requests without a key are processed as usual
+	for i := 0; i < 2; i++ {
+		RequestCreated{POST, SpendsPath, req}.Send(t, host, nil)
+	}
+
+	var spendsResp models.GetSpendsResp
+	RequestOK{GET, SpendsPath, models.GetSpendsReq{MonthID: 1}}.Send(t, host, &spendsResp)
+	require.Len(spendsResp.Spends, 3)
+	require.Equal(firstResp.ID, spendsResp.Spends[0].ID)
+
+	// Client errors are stored too
+	header.Set("Idempotency-Key", "add-to-unknown-day")
+	req = models.AddSpendReq{DayID: 1000, Title: "bread", Cost: 2}
+	for i := 0; i < 2; i++ {
+		code, _, body = sendWithHeader(t, host, POST, SpendsPath, header, req)
+		Request{StatusCode: http.StatusNotFound, Err: "such Day doesn't exist"}.checkResponse(t, code, body, nil)
+	}
+
+	// Keys are ignored for other methods
+	header.Set("Idempotency-Key", "edit-bread")
+	for _, cost := range []float64{3, 4} {
+		code, _, _ = sendWithHeader(t, host, PUT, SpendsPath, header, models.EditSpendReq{
+			ID: firstResp.ID, Cost: ptrFloat(cost),
+		})
+		require.Equal(http.StatusOK, code)
+	}
+
+	var spendResp models.GetSpendResp
+	RequestOK{GET, SpendsPath + Path("/"+strconv.Itoa(int(firstResp.ID))), nil}.Send(t, host, &spendResp)
+	require.Equal(money.FromInt(4), spendResp.Spend.Cost)
+}
+
+func testIdempotencyKeys_Errors(t *testing.T, host string) {
+	const errKeyReused = "Idempotency-Key was already used for another request"
+
+	header := http.Header{}
+	header.Set("Idempotency-Key", "add-income")
+
+	code, _, body := sendWithHeader(t, host, POST, IncomesPath, header, models.AddIncomeReq{
+		MonthID: 1, Title: "salary", Income: 1000,
+	})
+	Request{StatusCode: http.StatusCreated}.checkResponse(t, code, body, nil)
+
+	for _, tt := range []struct {
+		key  string
+		path Path
+		req  interface{}
+		code int
+		err  string
+	}{
+		{
+			key: "add-income", path: IncomesPath,
+			req:  models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 2000},
+			code: http.StatusUnprocessableEntity, err: errKeyReused,
+		},
+		{
+			key: "add-income", path: MonthlyPaymentsPath,
+			req:  models.AddMonthlyPaymentReq{MonthID: 1, Title: "salary", Cost: 1000},
+			code: http.StatusUnprocessableEntity, err: errKeyReused,
+		},
+		{
+			key: "add income", path: IncomesPath,
+			req:  models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 1000},
+			code: http.StatusBadRequest, err: "invalid Idempotency-Key header",
+		},
+		{
+			key: strings.Repeat("k", 256), path: IncomesPath,
+			req:  models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 1000},
+			code: http.StatusBadRequest, err: "invalid Idempotency-Key header",
+		},
+	} {
+		header.Set("Idempotency-Key", tt.key)
+		code, _, body := sendWithHeader(t, host, POST, tt.path, header, tt.req)
+		Request{StatusCode: tt.code, Err: tt.err}.checkResponse(t, code, body, nil)
+	}
+
+	// Large bodies are rejected before they are read into memory. Requests without a key are
+	// rejected by the handler
+	const errTooLarge = "request body must not be larger than 1048576 bytes"
+
+	largeReq := models.AddIncomeReq{MonthID: 1, Title: strings.Repeat("a", 2<<20), Income: 1000}
+	for _, key := range []string{"add-large-income", ""} {
+		header.Set("Idempotency-Key", key)
+		code, _, body := sendWithHeader(t, host, POST, IncomesPath, header, largeReq)
+		Request{StatusCode: http.StatusRequestEntityTooLarge, Err: errTooLarge}.checkResponse(t, code, body, nil)
+	}
+
+	var incomesResp models.GetIncomesResp
+	RequestOK{GET, IncomesPath, models.GetIncomesReq{MonthID: 1}}.Send(t, host, &incomesResp)
+	require.Len(t, incomesResp.Incomes, 1)
+}
+
+func testIdempotencyKeys_Users(t *testing.T, host string) {
+	require := require.New(t)
+
+	req := models.AddSpendTypeReq{Name: "food"}
+	send := func(username string) (code int, replayed string, resp models.AddSpendTypeResp) {
+		header := basicAuthHeader(username)
+		header.Set("Idempotency-Key", "add-food")
+
+		code, respHeader, body := sendWithHeader(t, host, POST, SpendTypesPath, header, req)
+		require.NoError(json.Unmarshal(body, &resp))
+		return code, respHeader.Get("Idempotent-Replayed"), resp
+	}
+
+	code, replayed, userResp := send("user")
+	require.Equal(http.StatusCreated, code)
+	require.Empty(replayed)
+
+	// The same key of another user is processed as a new request
+	code, replayed, adminResp := send("admin")
+	require.Equal(http.StatusCreated, code)
+	require.Empty(replayed)
+	require.NotEqual(userResp.ID, adminResp.ID)
+
+	code, replayed, resp := send("user")
+	require.Equal(http.StatusCreated, code)
+	require.Equal("true", replayed)
+	require.Equal(userResp.ID, resp.ID)
+}
+
+func testIdempotencyKeys_Secrets(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, tt := range []struct {
+		path Path
+		req  interface{}
+	}{
+		{path: APITokensPath, req: models.AddAPITokenReq{Name: "script"}},
+		{
+			path: WebhooksPath,
+			req:  models.AddWebhookReq{URL: "http://example.com", Events: []db.WebhookEvent{db.WebhookEventSpendCreated}},
+		},
+	} {
+		header := basicAuthHeader("user")
+		header.Set("Idempotency-Key", "add-"+string(tt.path))
+
+		// Responses with secrets are not stored, so retries are processed again
+		var bodies []string
+		for i := 0; i < 2; i++ {
+			code, respHeader, body := sendWithHeader(t, host, POST, tt.path, header, tt.req)
+			require.Equal(http.StatusCreated, code)
+			require.Empty(respHeader.Get("Idempotent-Replayed"))
+			require.Equal("no-store", respHeader.Get("Cache-Control"))
+			bodies = append(bodies, string(body))
+		}
+		require.NotEqual(bodies[0], bodies[1])
+	}
+}
@@ -0,0 +1,79 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/app"
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/db/base"
+	"github.com/ShoshinNikita/budget-manager/internal/logger"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestMigrateDB(t *testing.T) {
+	t.Parallel()
+
+	require := require.New(t)
+
+	cfg := getDefaultConfig(db.Sqlite3)
+	prepareApp(t, &cfg, StartSQLite, StartPostgreSQL)
+	host := fmt.Sprintf("localhost:%d", cfg.Server.Port)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "groceries"}}, // 1
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},      // 2
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "(?i)lidl", TypeID: 1}},
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 2500}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", Cost: 1000}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "lidl", Cost: 20}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 3, Title: "pizza", TypeID: 2, Cost: 15}},
+	} {
+		req.Send(t, host, nil)
+	}
+	// The parent is added after the child
+	RequestOK{PUT, SpendTypesPath, models.EditSpendTypeReq{ID: 1, ParentID: ptrUint(2)}}.Send(t, host, nil)
+
+	sqliteBackup := exportBackup(t, host)
+
+	stats, err := app.NewApp(cfg, logger.New(cfg.Logger), "", "").MigrateDB(context.Background(), db.Sqlite3, db.Postgres)
+	require.NoError(err)
+	require.Equal(
+		[]base.TableStats{
+			{Table: "spend_types", Rows: 2},
+			{Table: "spend_rules", Rows: 1},
+			{Table: "months", Rows: 1},
+			{Table: "days", Rows: daysInMonth(time.Now())},
+			{Table: "incomes", Rows: 1},
+			{Table: "monthly_payments", Rows: 1},
+			{Table: "spends", Rows: 2},
+		},
+		stats,
+	)
+
+	// Start the app with PostgreSQL
+	cfg.DB.Type = db.Postgres
+	prepareApp(t, &cfg)
+	host = fmt.Sprintf("localhost:%d", cfg.Server.Port)
+
+	pgBackup := exportBackup(t, host)
+	sqliteBackup.CreatedAt, pgBackup.CreatedAt = time.Time{}, time.Time{}
+	require.Equal(sqliteBackup, pgBackup)
+
+	// Ids of new entities must not conflict with the copied ones
+	var resp models.AddSpendTypeResp
+	RequestCreated{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "house"}}.Send(t, host, &resp)
+	require.Equal(uint(3), resp.ID)
+
+	// The destination db must be empty
+	_, err = app.NewApp(cfg, logger.New(cfg.Logger), "", "").MigrateDB(context.Background(), db.Sqlite3, db.Postgres)
+	require.EqualError(err, `couldn't copy data: destination db is not empty: table "spend_types" has 3 rows`)
+}
+
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
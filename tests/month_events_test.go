@@ -0,0 +1,155 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestMonthEvents(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "errors", Fn: testMonthEvents_Errors},
+		{Name: "stream", Fn: testMonthEvents_Stream},
+	})
+}
+
+func testMonthEvents_Errors(t *testing.T, host string) {
+	for _, req := range []Request{
+		{GET, MonthEventsPath, nil, 400, "month_id can't be empty or zero"},
+		{GET, MonthEventsPath, models.GetMonthEventsReq{MonthID: 100}, 404, "such Month doesn't exist"},
+	} {
+		req.Send(t, host, nil)
+	}
+}
+
+// subscribeToMonthEvents opens a stream of month changes. The returned channel is closed when the stream ends
+func subscribeToMonthEvents(t *testing.T, host string, monthID uint) (changes <-chan db.MonthChange, cancel func()) {
+	require := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	url := "http://" + host + string(MonthEventsPath) + "?month_id=" + strconv.FormatUint(uint64(monthID), 10)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	require.NoError(err)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(err)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Equal("text/event-stream", resp.Header.Get("Content-Type"))
+
+	ch := make(chan db.MonthChange, 10)
+	go func() {
+		defer close(ch)
+		defer resp.Body.Close()
+
+		var event string
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case strings.HasPrefix(line, "event: "):
+				event = strings.TrimPrefix(line, "event: ")
+			case strings.HasPrefix(line, "data: ") && event == "month_change":
+				var change db.MonthChange
+				if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &change); err == nil {
+					ch <- change
+				}
+			}
+		}
+	}()
+	return ch, cancel
+}
+
+func waitForMonthChange(t *testing.T, changes <-chan db.MonthChange) db.MonthChange {
+	select {
+	case change, ok := <-changes:
+		require.True(t, ok, "stream was closed")
+		return change
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no month change")
+		return db.MonthChange{}
+	}
+}
+
+func testMonthEvents_Stream(t *testing.T, host string) {
+	require := require.New(t)
+
+	changes, cancel := subscribeToMonthEvents(t, host, 1)
+	defer cancel()
+
+	// Another tab with the same month
+	otherChanges, cancelOther := subscribeToMonthEvents(t, host, 1)
+
+	RequestCreated{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 3000}}.Send(t, host, nil)
+	change := waitForMonthChange(t, changes)
+	require.Equal(db.WebhookEventIncomeCreated, change.Event)
+	require.NotEmpty(change.RequestID)
+	require.Equal(money.FromInt(3000), change.Month.TotalIncome)
+	require.Equal(change, waitForMonthChange(t, otherChanges))
+
+	// The stream is closed after the disconnect
+	cancelOther()
+	_, ok := <-otherChanges
+	require.False(ok)
+
+	RequestCreated{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{
+		MonthID: 1, Title: "rent", Cost: 1000,
+	}}.Send(t, host, nil)
+	change = waitForMonthChange(t, changes)
+	require.Equal(db.WebhookEventMonthlyPaymentCreated, change.Event)
+	require.Equal(money.FromInt(1000), change.MonthlyPaymentsCost)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "coffee", Cost: 10}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "bread", Cost: 5.5}},
+	} {
+		req.Send(t, host, nil)
+	}
+	waitForMonthChange(t, changes)
+	change = waitForMonthChange(t, changes)
+	require.Equal(db.WebhookEventSpendCreated, change.Event)
+	require.Equal(money.FromInt(2000).Sub(money.FromFloat(15.5)), change.Month.Result)
+
+	// Saldos must match the recomputed month
+	var monthResp models.GetMonthResp
+	RequestOK{GET, MonthsPath, models.GetMonthByDateReq{
+		Year: change.Month.Year, Month: change.Month.Month,
+	}}.Send(t, host, &monthResp)
+	require.Equal(monthResp.Month.MonthOverview, change.Month)
+	require.Len(change.Days, len(monthResp.Month.Days))
+	for i, day := range monthResp.Month.Days {
+		require.Equal(day.ID, change.Days[i].ID)
+		require.Equal(day.Day, change.Days[i].Day)
+		require.Equal(day.Saldo, change.Days[i].Saldo)
+		require.Equal(len(day.Spends), change.Days[i].SpendsCount)
+	}
+	require.Equal(2, change.Days[0].SpendsCount)
+	require.Equal(money.FromFloat(15.5), change.Days[0].SpendsCost)
+
+	RequestOK{DELETE, SpendsPath, models.RemoveSpendReq{ID: 1}}.Send(t, host, nil)
+	change = waitForMonthChange(t, changes)
+	require.Equal(db.WebhookEventSpendDeleted, change.Event)
+	require.Equal(1, change.Days[0].SpendsCount)
+	require.Equal(money.FromFloat(5.5), change.Days[0].SpendsCost)
+
+	// Failed requests don't produce changes
+	Request{DELETE, SpendsPath, models.RemoveSpendReq{ID: 1}, 404, "such Spend doesn't exist"}.Send(t, host, nil)
+
+	select {
+	case change := <-changes:
+		require.FailNow("unexpected change", "%+v", change)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestGetMonths(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "range", Fn: testGetMonths_Range},
+	})
+}
+
+func testGetMonths_Range(t *testing.T, host string) {
+	require := require.New(t)
+
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			Months: []db.BackupMonth{
+				{
+					Year: 2019, Month: time.November,
+					Incomes: []db.BackupIncome{{Title: "salary", Income: money.FromInt(1000)}},
+				},
+				{
+					Year: 2019, Month: time.December,
+					Incomes:         []db.BackupIncome{{Title: "salary", Income: money.FromInt(1000)}},
+					MonthlyPayments: []db.BackupMonthlyPayment{{Title: "rent", Cost: money.FromInt(500)}},
+				},
+				{
+					Year: 2020, Month: time.January,
+					Incomes: []db.BackupIncome{{Title: "salary", Income: money.FromInt(1500)}},
+					Days: []db.BackupDay{
+						{Day: 10, Spends: []db.BackupSpend{{Title: "book", Cost: money.FromInt(100)}}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	var resp models.GetMonthsResp
+	RequestOK{GET, AllMonthsPath, models.GetMonthsReq{
+		From: date(2019, time.December, 1),
+		To:   date(2020, time.January, 31),
+	}}.Send(t, host, &resp)
+
+	require.Len(resp.Months, 2)
+	require.Equal(2019, resp.Months[0].Year)
+	require.Equal(time.December, resp.Months[0].Month)
+	require.Equal(money.FromInt(1000), resp.Months[0].TotalIncome)
+	require.Equal(money.FromInt(-500), resp.Months[0].TotalSpend)
+	require.Equal(2020, resp.Months[1].Year)
+	require.Equal(time.January, resp.Months[1].Month)
+	require.Equal(
+		models.MonthsTotal{
+			TotalIncome: money.FromInt(2500),
+			TotalSpend:  money.FromInt(-600),
+			Result:      money.FromInt(1900),
+		},
+		resp.Total,
+	)
+
+	// Months are included only if their first day is within the range
+	RequestOK{GET, AllMonthsPath, models.GetMonthsReq{
+		From: date(2019, time.November, 2),
+		To:   date(2019, time.December, 31),
+	}}.Send(t, host, &resp)
+	require.Len(resp.Months, 1)
+	require.Equal(time.December, resp.Months[0].Month)
+
+	// No limits. The current month is created after the restore
+	RequestOK{GET, AllMonthsPath, models.GetMonthsReq{}}.Send(t, host, &resp)
+	require.Len(resp.Months, 4)
+	require.Equal(time.November, resp.Months[0].Month)
+	require.Equal(time.Now().Month(), resp.Months[3].Month)
+
+	RequestOK{GET, AllMonthsPath, models.GetMonthsReq{From: date(2030, time.January, 1)}}.Send(t, host, &resp)
+	require.Len(resp.Months, 0)
+	require.Equal(models.MonthsTotal{}, resp.Total)
+
+	Request{
+		GET, AllMonthsPath, models.GetMonthsReq{From: date(2020, time.January, 1), To: date(2019, time.January, 1)},
+		http.StatusBadRequest, "from can't be after to",
+	}.Send(t, host, nil)
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestProxyAuth(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "trusted proxy", Fn: testProxyAuth_TrustedProxy},
+	}, func(env *TestEnv) {
+		prepareProxyAuthEnv(t, env, "127.0.0.1,::1")
+	})
+}
+
+func TestProxyAuthFromUntrustedHost(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "untrusted host", Fn: testProxyAuth_UntrustedHost},
+	}, func(env *TestEnv) {
+		prepareProxyAuthEnv(t, env, "10.0.0.0/8")
+	})
+}
+
+func prepareProxyAuthEnv(t *testing.T, env *TestEnv, authProxies string) {
+	env.Cfg.Server.Auth.Disable = false
+	env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+		"user": {PasswordHash: "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC"}, // user:qwerty
+	}
+	env.Cfg.Server.Auth.Proxy.Header = "Remote-User"
+	require.NoError(t, env.Cfg.Server.Auth.Proxy.TrustedProxies.UnmarshalText([]byte(authProxies)))
+	// The app is behind another proxy that passes the client ip
+	require.NoError(t, env.Cfg.Server.TrustedProxies.UnmarshalText([]byte("127.0.0.1,::1,10.0.0.0/8")))
+}
+
+func testProxyAuth_TrustedProxy(t *testing.T, host string) {
+	require := require.New(t)
+
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, http.Header{"Remote-User": {"user"}}, nil)
+	require.Equal(http.StatusOK, code)
+
+	// The header is checked even if the client ip is passed
+	code, _, _ = sendWithHeader(t, host, GET, SearchSpendsPath, http.Header{
+		"Remote-User": {"user"}, "X-Forwarded-For": {"203.0.113.5"},
+	}, nil)
+	require.Equal(http.StatusOK, code)
+
+	// Unknown user
+	code, _, body := sendWithHeader(t, host, GET, SearchSpendsPath, http.Header{"Remote-User": {"admin"}}, nil)
+	require.Equal(http.StatusUnauthorized, code)
+	require.Equal("auth header must contain a single known username", decodeProxyAuthError(t, body))
+
+	// Other auth methods are not checked if the header is passed
+	header := basicAuthHeader("user")
+	header.Set("Remote-User", "")
+	code, _, _ = sendWithHeader(t, host, GET, SearchSpendsPath, header, nil)
+	require.Equal(http.StatusUnauthorized, code)
+
+	// Multiple headers
+	req, cancel := newRequest(t, GET, "http://"+host+string(SearchSpendsPath), nil)
+	defer cancel()
+	req.Header.Add("Remote-User", "admin")
+	req.Header.Add("Remote-User", "user")
+	httpResp, err := http.DefaultClient.Do(req)
+	require.NoError(err)
+	httpResp.Body.Close()
+	require.Equal(http.StatusUnauthorized, httpResp.StatusCode)
+
+	// Requests without the header can use other auth methods
+	code, _, _ = sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("user"), nil)
+	require.Equal(http.StatusOK, code)
+}
+
+func testProxyAuth_UntrustedHost(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, header := range []http.Header{
+		{"Remote-User": {"user"}},
+		// The client ip from a trusted subnet doesn't make the header trusted
+		{"Remote-User": {"user"}, "X-Forwarded-For": {"10.0.0.1"}},
+	} {
+		code, _, body := sendWithHeader(t, host, GET, SearchSpendsPath, header, nil)
+		require.Equal(http.StatusUnauthorized, code)
+		require.Equal("auth header can be passed only by trusted proxies", decodeProxyAuthError(t, body))
+	}
+
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("user"), nil)
+	require.Equal(http.StatusOK, code)
+}
+
+func decodeProxyAuthError(t *testing.T, body []byte) string {
+	var resp models.BaseResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	return resp.Error
+}
@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestGetRecords(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "get by id", Fn: testGetRecords_ByID},
+		{Name: "get by month", Fn: testGetRecords_ByMonth},
+		{Name: "errors", Fn: testGetRecords_Errors},
+	})
+}
+
+func testGetRecords_ByID(t *testing.T, host string) {
+	require := require.New(t)
+
+	now := time.Now()
+	year, month := now.Year(), now.Month()
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},
+		//
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Notes: "july", Income: 2500}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", Cost: 1000, DueDay: 5}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 3, Title: "lidl", TypeID: 1, Cost: 20.5}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var incomeResp models.GetIncomeResp
+	RequestOK{GET, IncomesPath + "/1", nil}.Send(t, host, &incomeResp)
+	require.Equal(
+		db.Income{
+			ID: 1, Year: year, Month: month, Title: "salary", Notes: "july", Income: money.FromInt(2500), Version: 1,
+		},
+		incomeResp.Income,
+	)
+
+	var mpResp models.GetMonthlyPaymentResp
+	RequestOK{GET, MonthlyPaymentsPath + "/1", nil}.Send(t, host, &mpResp)
+	require.Equal(
+		db.MonthlyPayment{
+			ID: 1, Year: year, Month: month, Title: "rent", Cost: money.FromInt(1000), DueDay: 5, Version: 1,
+		},
+		mpResp.MonthlyPayment,
+	)
+
+	var spendResp models.GetSpendResp
+	RequestOK{GET, SpendsPath + "/1", nil}.Send(t, host, &spendResp)
+	require.Equal(
+		db.Spend{
+			ID: 1, Year: year, Month: month, Day: 3, Title: "lidl",
+			Type: &db.SpendType{ID: 1, Name: "food"}, Cost: money.FromFloat(20.5), Version: 1,
+		},
+		spendResp.Spend,
+	)
+
+	// Body-based endpoints must work as before
+	RequestOK{PUT, SpendsPath, models.EditSpendReq{ID: 1, Title: ptrStr("aldi")}}.Send(t, host, nil)
+	RequestOK{GET, SpendsPath + "/1", nil}.Send(t, host, &spendResp)
+	require.Equal("aldi", spendResp.Spend.Title)
+	require.Equal(2, spendResp.Spend.Version)
+}
+
+func testGetRecords_ByMonth(t *testing.T, host string) {
+	require := require.New(t)
+
+	// Init the next month
+	now := time.Now()
+	next := now.AddDate(0, 1, 0)
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			Months: []db.BackupMonth{
+				{
+					Year:  next.Year(),
+					Month: next.Month(),
+					Incomes: []db.BackupIncome{
+						{Title: "salary", Income: money.FromInt(3000)},
+					},
+					MonthlyPayments: []db.BackupMonthlyPayment{
+						{Title: "rent", Cost: money.FromInt(1000)},
+					},
+					Days: []db.BackupDay{
+						{Day: 2, Spends: []db.BackupSpend{{Title: "book", Cost: money.FromInt(7)}}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	var monthResp models.GetMonthResp
+	req := models.GetMonthByDateReq{Year: next.Year(), Month: next.Month()}
+	RequestOK{GET, MonthsPath, req}.Send(t, host, &monthResp)
+	nextID := monthResp.Month.ID
+
+	req = models.GetMonthByDateReq{Year: now.Year(), Month: now.Month()}
+	RequestOK{GET, MonthsPath, req}.Send(t, host, &monthResp)
+	currentID := monthResp.Month.ID
+	require.NotEqual(nextID, currentID)
+
+	for _, req := range []RequestCreated{
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: currentID, Title: "salary", Income: 2500}},
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: currentID, Title: "bonus", Income: 100}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: monthResp.Month.Days[4].ID, Title: "lidl", Cost: 20}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: monthResp.Month.Days[0].ID, Title: "bread", Cost: 2}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var incomesResp models.GetIncomesResp
+	RequestOK{GET, IncomesPath, models.GetIncomesReq{MonthID: currentID}}.Send(t, host, &incomesResp)
+	require.Len(incomesResp.Incomes, 2)
+	require.Equal("salary", incomesResp.Incomes[0].Title)
+	require.Equal("bonus", incomesResp.Incomes[1].Title)
+
+	RequestOK{GET, IncomesPath, models.GetIncomesReq{MonthID: nextID}}.Send(t, host, &incomesResp)
+	require.Len(incomesResp.Incomes, 1)
+	require.Equal(money.FromInt(3000), incomesResp.Incomes[0].Income)
+
+	var mpsResp models.GetMonthlyPaymentsResp
+	RequestOK{GET, MonthlyPaymentsPath, models.GetMonthlyPaymentsReq{MonthID: currentID}}.Send(t, host, &mpsResp)
+	require.Len(mpsResp.MonthlyPayments, 0)
+
+	RequestOK{GET, MonthlyPaymentsPath, models.GetMonthlyPaymentsReq{MonthID: nextID}}.Send(t, host, &mpsResp)
+	require.Len(mpsResp.MonthlyPayments, 1)
+	require.Equal("rent", mpsResp.MonthlyPayments[0].Title)
+
+	// Spends are sorted by date
+	var spendsResp models.GetSpendsResp
+	RequestOK{GET, SpendsPath, models.GetSpendsReq{MonthID: currentID}}.Send(t, host, &spendsResp)
+	require.Len(spendsResp.Spends, 2)
+	require.Equal("bread", spendsResp.Spends[0].Title)
+	require.Equal(1, spendsResp.Spends[0].Day)
+	require.Equal("lidl", spendsResp.Spends[1].Title)
+	require.Equal(5, spendsResp.Spends[1].Day)
+
+	RequestOK{GET, SpendsPath, models.GetSpendsReq{MonthID: nextID}}.Send(t, host, &spendsResp)
+	require.Len(spendsResp.Spends, 1)
+	require.Equal("book", spendsResp.Spends[0].Title)
+	require.Equal(2, spendsResp.Spends[0].Day)
+}
+
+func testGetRecords_Errors(t *testing.T, host string) {
+	for _, req := range []Request{
+		{GET, IncomesPath + "/100", nil, http.StatusNotFound, "such Income doesn't exist"},
+		{GET, MonthlyPaymentsPath + "/100", nil, http.StatusNotFound, "such Monthly Payment doesn't exist"},
+		{GET, SpendsPath + "/100", nil, http.StatusNotFound, "such Spend doesn't exist"},
+		//
+		{GET, IncomesPath, models.GetIncomesReq{MonthID: 100}, http.StatusNotFound, "such Month doesn't exist"},
+		{
+			GET, MonthlyPaymentsPath, models.GetMonthlyPaymentsReq{MonthID: 100},
+			http.StatusNotFound, "such Month doesn't exist",
+		},
+		{GET, SpendsPath, models.GetSpendsReq{MonthID: 100}, http.StatusNotFound, "such Month doesn't exist"},
+		{GET, SpendsPath, nil, http.StatusBadRequest, "month_id can't be empty or zero"},
+		//
+		{GET, SpendsPath + "/abc", nil, http.StatusNotFound, "unknown path"},
+		{GET, SpendsPath + "/1/2", nil, http.StatusNotFound, "unknown path"},
+		{GET, SpendsPath + "/", nil, http.StatusNotFound, "unknown path"},
+		{GET, SpendsPath + "/0", nil, http.StatusBadRequest, "id can't be empty or zero"},
+		{PUT, SpendsPath + "/1", models.EditSpendReq{ID: 1}, http.StatusMethodNotAllowed, "method not allowed"},
+	} {
+		req.Send(t, host, nil)
+	}
+}
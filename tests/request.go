@@ -23,8 +23,32 @@ const (
 	MonthlyPaymentsPath Path = "/api/monthly-payments"
 	SpendsPath          Path = "/api/spends"
 	SpendTypesPath      Path = "/api/spend-types"
+	SpendRulesPath      Path = "/api/spend-rules"
+	ApplySpendRulesPath Path = "/api/spend-rules/apply"
+	SavedSearchesPath   Path = "/api/saved-searches"
 	SearchSpendsPath    Path = "/api/search/spends"
+	SearchIncomesPath   Path = "/api/search/incomes"
+	SearchMPsPath       Path = "/api/search/monthly-payments"
+	SearchAllPath       Path = "/api/search/all"
 	MonthsPath          Path = "/api/months/date"
+	AllMonthsPath       Path = "/api/months"
+	MonthEventsPath     Path = "/api/months/events"
+	BackupPath          Path = "/api/backup"
+	RestoreBackupPath   Path = "/api/backup/restore"
+	ExportLedgerPath    Path = "/api/export/ledger"
+	CalendarPath        Path = "/api/monthly-payments/calendar.ics"
+	//
+	SpentBySpendTypePath Path = "/api/statistics/spent-by-spend-type"
+	SpentByDayPath       Path = "/api/statistics/spent-by-day"
+	CostIntervalsPath    Path = "/api/statistics/cost-intervals"
+	//
+	SavedSearchReportsPath Path = "/api/saved-searches/reports"
+	//
+	WebhooksPath          Path = "/api/webhooks"
+	WebhookDeliveriesPath Path = "/api/webhooks/deliveries"
+	//
+	APITokensPath    Path = "/api/tokens"
+	AuthLockoutsPath Path = "/api/auth/lockouts"
 )
 
 type Method string
@@ -128,6 +152,7 @@ func (r Request) checkResponse(t *testing.T, statusCode int, body []byte, custom
 	require.NotEqual("", basicResp.RequestID)
 	require.Equal(r.Err, basicResp.Error)
 	require.Equal(r.Err == "", basicResp.Success)
+	require.Equal(r.Err == "", basicResp.ErrorCode == "", "error code must be specified only for errors")
 	require.Equal(r.StatusCode, statusCode)
 
 	if customResp != nil {
@@ -0,0 +1,288 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestSavedSearches(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "manage", Fn: testSavedSearches_Manage},
+		{Name: "reports", Fn: testSavedSearches_Reports},
+		{Name: "spend types", Fn: testSavedSearches_SpendTypes},
+	})
+}
+
+func testSavedSearches_Manage(t *testing.T, host string) {
+	require := require.New(t)
+
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version:    db.BackupVersion,
+			SpendTypes: []db.BackupSpendType{{ID: 1, Name: "Food"}, {ID: 2, Name: "Cafe", ParentID: 1}},
+		},
+	}}.Send(t, host, nil)
+
+	for i, req := range []RequestCreated{
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Cafe", Pinned: true,
+			Search: models.SearchSpendsReq{Query: "type:food/cafe", Sort: "cost", Order: "desc"},
+		}},
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name:   "Cheap",
+			Search: models.SearchSpendsReq{Title: "Coffee", MaxCost: 12, TypeIDs: []uint{0, 1}},
+		}},
+	} {
+		var resp models.AddSavedSearchResp
+		req.Send(t, host, &resp)
+		require.Equal(uint(i+1), resp.ID)
+	}
+
+	for _, req := range []Request{
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{Name: " "}, 400, "name can't be empty"},
+		{
+			POST, SavedSearchesPath, models.AddSavedSearchReq{Name: "a", Search: models.SearchSpendsReq{Query: "type:x"}},
+			400, "invalid query: unknown Spend Type 'x'",
+		},
+		{
+			POST, SavedSearchesPath, models.AddSavedSearchReq{Name: "a", Search: models.SearchSpendsReq{MinCost: 2, MaxCost: 1}},
+			400, "min_cost can't be greater than max_cost",
+		},
+		{GET, SavedSearchesPath + "/10", nil, 404, "such Saved Search doesn't exist"},
+		{
+			PUT, SavedSearchesPath, models.EditSavedSearchReq{ID: 10, Name: ptrStr("a")},
+			404, "such Saved Search doesn't exist",
+		},
+		{PUT, SavedSearchesPath, models.EditSavedSearchReq{ID: 1, Name: ptrStr("")}, 400, "name can't be empty"},
+		{DELETE, SavedSearchesPath, models.RemoveSavedSearchReq{ID: 10}, 404, "such Saved Search doesn't exist"},
+		//
+		{
+			PUT, SavedSearchesPath, models.EditSavedSearchReq{ID: 2, Name: ptrStr("Cheap coffee"), Pinned: ptrBool(true)},
+			200, "",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var resp models.GetSavedSearchesResp
+	RequestOK{GET, SavedSearchesPath, nil}.Send(t, host, &resp)
+	require.Equal(
+		[]db.SavedSearch{
+			{
+				ID: 1, Name: "Cafe", Pinned: true,
+				Args: db.SearchSpendsArgs{
+					Sort:  db.SortSpendsByCost,
+					Order: db.OrderByDesc,
+					Expr: &db.SearchSpendsExpr{
+						Cond: &db.SearchSpendsCond{Field: db.SearchSpendsByType, Op: db.OpEqual, TypeIDs: []uint{2}},
+					},
+				},
+			},
+			{
+				ID: 2, Name: "Cheap coffee", Pinned: true,
+				Args: db.SearchSpendsArgs{Title: "coffee", MaxCost: money.FromInt(12), TypeIDs: []uint{0, 1}},
+			},
+		},
+		resp.SavedSearches,
+	)
+
+	// Edit args
+	RequestOK{PUT, SavedSearchesPath, models.EditSavedSearchReq{
+		ID: 1, Search: &models.SearchSpendsReq{Notes: "Trip"},
+	}}.Send(t, host, nil)
+
+	var searchResp models.GetSavedSearchResp
+	RequestOK{GET, SavedSearchesPath + "/1", nil}.Send(t, host, &searchResp)
+	require.Equal(
+		db.SavedSearch{ID: 1, Name: "Cafe", Pinned: true, Args: db.SearchSpendsArgs{Notes: "trip"}},
+		searchResp.SavedSearch,
+	)
+
+	// Pages
+	code, body := getPage(t, host, "/search/spends?saved_search=2")
+	require.Equal(http.StatusOK, code)
+	require.Contains(body, "Cheap coffee")
+
+	// Error pages are rendered with status 200
+	_, body = getPage(t, host, "/search/spends?saved_search=10")
+	require.Contains(body, "such Saved Search doesn&#39;t exist")
+
+	_, body = getPage(t, host, "/search/spends?saved_search=abc")
+	require.Contains(body, "Invalid URL: invalid saved_search value")
+
+	// Remove
+	RequestOK{DELETE, SavedSearchesPath, models.RemoveSavedSearchReq{ID: 1}}.Send(t, host, nil)
+
+	RequestOK{GET, SavedSearchesPath, nil}.Send(t, host, &resp)
+	require.Len(resp.SavedSearches, 1)
+	require.Equal(uint(2), resp.SavedSearches[0].ID)
+}
+
+func testSavedSearches_Reports(t *testing.T, host string) {
+	require := require.New(t)
+
+	m := money.FromInt
+	now := time.Now()
+	prev := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, time.UTC)
+
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version:    db.BackupVersion,
+			SpendTypes: []db.BackupSpendType{{ID: 1, Name: "Food"}, {ID: 2, Name: "Cafe", ParentID: 1}},
+			Months: []db.BackupMonth{
+				{
+					Year: prev.Year(), Month: prev.Month(),
+					Days: []db.BackupDay{
+						{Day: 1, Spends: []db.BackupSpend{{Title: "Coffee", TypeID: 2, Cost: m(4)}}},
+					},
+				},
+				{
+					Year: now.Year(), Month: now.Month(),
+					Days: []db.BackupDay{
+						{Day: 1, Spends: []db.BackupSpend{
+							{Title: "Coffee", TypeID: 2, Cost: m(6)},
+							{Title: "Coffee beans", TypeID: 1, Cost: m(15)},
+							{Title: "Book", Cost: m(10)},
+						}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	for _, req := range []RequestCreated{
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Cafe", Pinned: true, Search: models.SearchSpendsReq{Query: "type:food/cafe"},
+		}},
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Food", Pinned: true, Search: models.SearchSpendsReq{TypeIDs: []uint{1, 2}, Limit: 1},
+		}},
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Not pinned", Search: models.SearchSpendsReq{Title: "book"},
+		}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	checkReports := func(req models.GetSavedSearchReportsReq, want ...[3]db.SearchSpendsTotal) {
+		var resp models.GetSavedSearchReportsResp
+		RequestOK{GET, SavedSearchReportsPath, req}.Send(t, host, &resp)
+
+		require.Len(resp.Reports, len(want))
+		for i, r := range resp.Reports {
+			require.Equal(want[i][0], r.Total, r.SavedSearch.Name)
+			require.Equal(want[i][1], r.PrevTotal, r.SavedSearch.Name)
+			require.Equal(want[i][2].Cost, r.Change, r.SavedSearch.Name)
+		}
+	}
+	total := func(count int, cost int64) db.SearchSpendsTotal {
+		return db.SearchSpendsTotal{Count: count, Cost: m(cost)}
+	}
+
+	// The current month by default. Limit is ignored
+	checkReports(
+		models.GetSavedSearchReportsReq{},
+		[3]db.SearchSpendsTotal{total(1, 6), total(1, 4), total(0, 2)},
+		[3]db.SearchSpendsTotal{total(2, 21), total(1, 4), total(0, 17)},
+	)
+	checkReports(
+		models.GetSavedSearchReportsReq{Year: prev.Year(), Month: prev.Month()},
+		[3]db.SearchSpendsTotal{total(1, 4), total(0, 0), total(0, 4)},
+		[3]db.SearchSpendsTotal{total(1, 4), total(0, 0), total(0, 4)},
+	)
+
+	Request{
+		GET, SavedSearchReportsPath, models.GetSavedSearchReportsReq{Year: 2020},
+		http.StatusBadRequest, "year and month must be passed together",
+	}.Send(t, host, nil)
+
+	// Saved Searches are restored with new Spend Type ids
+	backup := exportBackup(t, host)
+	require.Len(backup.SavedSearches, 3)
+	require.Equal(
+		db.BackupSavedSearch{Name: "Not pinned", Args: db.SearchSpendsArgs{Title: "book"}},
+		backup.SavedSearches[2],
+	)
+
+	var restoreResp models.RestoreBackupResp
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{Backup: backup}}.Send(t, host, &restoreResp)
+	require.Equal(3, restoreResp.Stats.SavedSearches)
+
+	checkReports(
+		models.GetSavedSearchReportsReq{},
+		[3]db.SearchSpendsTotal{total(1, 6), total(1, 4), total(0, 2)},
+		[3]db.SearchSpendsTotal{total(2, 21), total(1, 4), total(0, 17)},
+	)
+
+	code, body := getPage(t, host, "/months")
+	require.Equal(http.StatusOK, code)
+	require.Contains(body, "/search/spends?saved_search=")
+	require.NotContains(body, "Not pinned")
+}
+
+func testSavedSearches_SpendTypes(t *testing.T, host string) {
+	const errTypeIsUsed = "Spend Type is used by Monthly Payment, Spend, Spend Rule or Saved Search"
+
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			SpendTypes: []db.BackupSpendType{
+				{ID: 1, Name: "Food"}, {ID: 2, Name: "Cafe", ParentID: 1}, {ID: 3, Name: "Taxi"}, {ID: 4, Name: "Misc"},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	for _, req := range []RequestCreated{
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Taxi", Search: models.SearchSpendsReq{TypeIDs: []uint{3}},
+		}},
+		{POST, SavedSearchesPath, models.AddSavedSearchReq{
+			Name: "Cafe", Search: models.SearchSpendsReq{Query: "cost>5 type:food/cafe"},
+		}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	// Spend Types used by Saved Searches can't be removed
+	for _, id := range []uint{2, 3} {
+		Request{DELETE, SpendTypesPath, models.RemoveSpendTypeReq{ID: id}, http.StatusBadRequest, errTypeIsUsed}.
+			Send(t, host, nil)
+	}
+	RequestOK{DELETE, SpendTypesPath, models.RemoveSpendTypeReq{ID: 4}}.Send(t, host, nil)
+
+	// The backup is still valid
+	require.NoError(t, exportBackup(t, host).Validate())
+
+	RequestOK{DELETE, SavedSearchesPath, models.RemoveSavedSearchReq{ID: 1}}.Send(t, host, nil)
+	RequestOK{DELETE, SpendTypesPath, models.RemoveSpendTypeReq{ID: 3}}.Send(t, host, nil)
+}
+
+func getPage(t *testing.T, host string, path string) (statusCode int, body string) {
+	require := require.New(t)
+
+	u, err := url.Parse("http://" + host + path)
+	require.NoError(err)
+
+	httpReq, cancel := newRequest(t, GET, u.String(), nil)
+	defer cancel()
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(err)
+
+	return resp.StatusCode, string(data)
+}
@@ -0,0 +1,363 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestSearch(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "incomes, monthly payments and all", Fn: testSearch_AllRecords},
+		{Name: "query", Fn: testSearch_Query},
+		{Name: "full-text", Fn: testSearch_FullText},
+		{Name: "page", Fn: testSearch_Page},
+	})
+}
+
+func testSearch_AllRecords(t *testing.T, host string) {
+	require := require.New(t)
+
+	m := money.FromInt
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version:    db.BackupVersion,
+			SpendTypes: []db.BackupSpendType{{ID: 1, Name: "internet"}},
+			Months: []db.BackupMonth{
+				{
+					Year: 2024, Month: time.December,
+					Incomes: []db.BackupIncome{
+						{Title: "Salary", Income: m(1000)},
+						{Title: "Bonus", Notes: "year end", Income: m(300)},
+					},
+					MonthlyPayments: []db.BackupMonthlyPayment{
+						{Title: "Internet", TypeID: 1, Cost: m(30)},
+						{Title: "Rent", Cost: m(500)},
+					},
+				},
+				{
+					Year: 2025, Month: time.January,
+					Incomes: []db.BackupIncome{{Title: "Salary", Income: m(1000)}},
+					MonthlyPayments: []db.BackupMonthlyPayment{
+						{Title: "Internet", TypeID: 1, Cost: m(35)},
+					},
+					Days: []db.BackupDay{
+						{Day: 5, Spends: []db.BackupSpend{{Title: "Internet router", TypeID: 1, Cost: m(60)}}},
+					},
+				},
+				{
+					Year: 2025, Month: time.February,
+					MonthlyPayments: []db.BackupMonthlyPayment{
+						{Title: "Internet", TypeID: 1, Notes: "new plan", Cost: m(35)},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	date := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	}
+
+	// Incomes
+	var incomesResp models.SearchIncomesResp
+	RequestOK{GET, SearchIncomesPath, models.SearchIncomesReq{Title: "salary"}}.Send(t, host, &incomesResp)
+	require.Len(incomesResp.Incomes, 2)
+	require.Equal(time.December, incomesResp.Incomes[0].Month)
+	require.Equal(time.January, incomesResp.Incomes[1].Month)
+
+	RequestOK{GET, SearchIncomesPath, models.SearchIncomesReq{Order: "desc"}}.Send(t, host, &incomesResp)
+	require.Len(incomesResp.Incomes, 3)
+	require.Equal(uint(3), incomesResp.Incomes[0].ID)
+	require.Equal(uint(2), incomesResp.Incomes[1].ID)
+	require.Equal(uint(1), incomesResp.Incomes[2].ID)
+
+	RequestOK{GET, SearchIncomesPath, models.SearchIncomesReq{
+		After: date(2024, time.January, 1), Before: date(2024, time.December, 31), MaxIncome: 500,
+	}}.Send(t, host, &incomesResp)
+	require.Equal(
+		[]db.Income{
+			{ID: 2, Year: 2024, Month: time.December, Title: "Bonus", Notes: "year end", Income: m(300), Version: 1},
+		},
+		incomesResp.Incomes,
+	)
+
+	// Monthly Payments
+	var mpsResp models.SearchMonthlyPaymentsResp
+	RequestOK{GET, SearchMPsPath, models.SearchMonthlyPaymentsReq{Title: "internet"}}.Send(t, host, &mpsResp)
+	require.Len(mpsResp.MonthlyPayments, 3)
+	require.Equal(uint(1), mpsResp.MonthlyPayments[0].ID)
+	require.Equal(uint(3), mpsResp.MonthlyPayments[1].ID)
+	require.Equal(uint(4), mpsResp.MonthlyPayments[2].ID)
+	require.Equal(&db.SpendType{ID: 1, Name: "internet"}, mpsResp.MonthlyPayments[0].Type)
+
+	RequestOK{GET, SearchMPsPath, models.SearchMonthlyPaymentsReq{TypeIDs: []uint{0}}}.Send(t, host, &mpsResp)
+	require.Len(mpsResp.MonthlyPayments, 1)
+	require.Equal("Rent", mpsResp.MonthlyPayments[0].Title)
+
+	RequestOK{GET, SearchMPsPath, models.SearchMonthlyPaymentsReq{
+		After: date(2025, time.January, 1), MinCost: 35, Notes: "plan",
+	}}.Send(t, host, &mpsResp)
+	require.Len(mpsResp.MonthlyPayments, 1)
+	require.Equal(uint(4), mpsResp.MonthlyPayments[0].ID)
+
+	// All
+	internetType := &db.SpendType{ID: 1, Name: "internet"}
+	var allResp models.SearchAllResp
+	RequestOK{GET, SearchAllPath, models.SearchAllReq{
+		SearchMonthlyPaymentsReq: models.SearchMonthlyPaymentsReq{Title: "internet"},
+	}}.Send(t, host, &allResp)
+	require.Equal(
+		[]models.MoneyMovement{
+			{
+				Kind: "monthly_payment", ID: 1, Year: 2024, Month: time.December,
+				Title: "Internet", Type: internetType, Amount: m(-30),
+			},
+			{
+				Kind: "monthly_payment", ID: 3, Year: 2025, Month: time.January,
+				Title: "Internet", Type: internetType, Amount: m(-35),
+			},
+			{
+				Kind: "spend", ID: 1, Year: 2025, Month: time.January, Day: 5,
+				Title: "Internet router", Type: internetType, Amount: m(-60),
+			},
+			{
+				Kind: "monthly_payment", ID: 4, Year: 2025, Month: time.February,
+				Title: "Internet", Type: internetType, Notes: "new plan", Amount: m(-35),
+			},
+		},
+		allResp.MoneyMovements,
+	)
+	require.Equal(m(-160), allResp.Total)
+
+	// Records are filtered by whole months
+	RequestOK{GET, SearchAllPath, models.SearchAllReq{
+		SearchMonthlyPaymentsReq: models.SearchMonthlyPaymentsReq{
+			Title: "internet", After: date(2025, time.January, 10), Before: date(2025, time.January, 20),
+		},
+	}}.Send(t, host, &allResp)
+	require.Len(allResp.MoneyMovements, 2)
+	require.Equal(uint(3), allResp.MoneyMovements[0].ID)
+	require.Equal(uint(1), allResp.MoneyMovements[1].ID)
+	require.Equal(m(-95), allResp.Total)
+
+	RequestOK{GET, SearchAllPath, models.SearchAllReq{
+		SearchMonthlyPaymentsReq: models.SearchMonthlyPaymentsReq{
+			Before: date(2025, time.January, 31), MinCost: 300, Order: "desc",
+		},
+	}}.Send(t, host, &allResp)
+	require.Len(allResp.MoneyMovements, 4)
+	require.Equal("income", allResp.MoneyMovements[0].Kind)
+	require.Equal(time.January, allResp.MoneyMovements[0].Month)
+	require.Equal("monthly_payment", allResp.MoneyMovements[1].Kind)
+	require.Equal("Rent", allResp.MoneyMovements[1].Title)
+	require.Equal("Bonus", allResp.MoneyMovements[2].Title)
+	require.Equal("Salary", allResp.MoneyMovements[3].Title)
+	require.Equal(m(1800), allResp.Total)
+
+	// Incomes don't have types
+	RequestOK{GET, SearchAllPath, models.SearchAllReq{
+		SearchMonthlyPaymentsReq: models.SearchMonthlyPaymentsReq{TypeIDs: []uint{0}},
+	}}.Send(t, host, &allResp)
+	require.Len(allResp.MoneyMovements, 1)
+	require.Equal("Rent", allResp.MoneyMovements[0].Title)
+
+	for _, req := range []Request{
+		{
+			GET, SearchIncomesPath, models.SearchIncomesReq{MinIncome: 10, MaxIncome: 5},
+			http.StatusBadRequest, "min_income can't be greater than max_income",
+		},
+		{
+			GET, SearchMPsPath, models.SearchMonthlyPaymentsReq{MinCost: 10, MaxCost: 5},
+			http.StatusBadRequest, "min_cost can't be greater than max_cost",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+}
+
+func testSearch_Query(t *testing.T, host string) {
+	require := require.New(t)
+
+	m := money.FromInt
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			SpendTypes: []db.BackupSpendType{
+				{ID: 1, Name: "Food"},
+				{ID: 2, Name: "Cafe", ParentID: 1},
+			},
+			Months: []db.BackupMonth{
+				{
+					Year: 2026, Month: time.January,
+					Days: []db.BackupDay{
+						{Day: 5, Spends: []db.BackupSpend{
+							{Title: "Coffee", TypeID: 2, Notes: "work trip", Cost: m(6)},  // 1
+							{Title: "Decaf coffee", TypeID: 2, Cost: m(7)},                // 2
+							{Title: "Coffee beans", TypeID: 1, Cost: m(15)},               // 3
+							{Title: "Coffee machine", Cost: m(300)},                       // 4
+							{Title: "Train ticket", Notes: "work trip", Cost: m(40)},      // 5
+							{Title: "Cheap coffee", TypeID: 2, Notes: "work", Cost: m(2)}, // 6
+						}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	for _, tt := range []struct {
+		query string
+		ids   []uint
+	}{
+		{query: `title:coffee -title:decaf cost>5 type:"Food/Cafe" after:2026-01-01 notes:"work trip"`, ids: []uint{1}},
+		{query: `coffee -decaf`, ids: []uint{1, 3, 4, 6}},
+		{query: `coffee (type:food OR cost>=100) -type:food/cafe`, ids: []uint{3, 4}},
+		{query: `notes:"work trip" | cost<3`, ids: []uint{1, 5, 6}},
+		{query: `-notes:work`, ids: []uint{2, 3, 4}},
+		{query: `title="coffee beans" date:2026-01-05`, ids: []uint{3}},
+		{query: `before:2025-12-31`, ids: []uint{}},
+	} {
+		var resp models.SearchSpendsResp
+		RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{Query: tt.query}}.Send(t, host, &resp)
+
+		ids := make([]uint, 0, len(resp.Spends))
+		for _, spend := range resp.Spends {
+			ids = append(ids, spend.ID)
+		}
+		require.Equal(tt.ids, ids, tt.query)
+		require.Equal(len(tt.ids), resp.Total.Count, tt.query)
+	}
+
+	// The query is combined with other args
+	var resp models.SearchSpendsResp
+	RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{Query: "coffee", MaxCost: 10}}.Send(t, host, &resp)
+	require.Len(resp.Spends, 3)
+
+	// Statistics support the query too
+	var byDayResp models.GetSpentByDayResp
+	RequestOK{GET, SpentByDayPath, models.SearchSpendsReq{Query: "type:food/cafe"}}.Send(t, host, &byDayResp)
+	require.Len(byDayResp.Dataset, 1)
+	require.Equal(m(15), byDayResp.Dataset[0].Spent)
+
+	for _, req := range []Request{
+		{
+			GET, SearchSpendsPath, models.SearchSpendsReq{Query: "type:unknown"},
+			http.StatusBadRequest, "invalid query: unknown Spend Type 'unknown'",
+		},
+		{
+			GET, SpentByDayPath, models.SearchSpendsReq{Query: "(coffee"},
+			http.StatusBadRequest, "invalid query: expected ')', got end of query",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+}
+
+func testSearch_FullText(t *testing.T, host string) {
+	require := require.New(t)
+
+	m := money.FromInt
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			Months: []db.BackupMonth{
+				{
+					Year: 2026, Month: time.January,
+					Days: []db.BackupDay{
+						{Day: 5, Spends: []db.BackupSpend{
+							{Title: "Coffee beans", Notes: "Arabica", Cost: m(15)}, // 1
+							{Title: "Coffee", Cost: m(6)},                          // 2
+							{Title: "Tea", Notes: "with coffee", Cost: m(4)},       // 3
+							{Title: "Cake", Cost: m(5)},                            // 4
+						}},
+						{Day: 6, Spends: []db.BackupSpend{
+							{Title: "кофе", Notes: "капучино", Cost: m(3)}, // 5
+						}},
+					},
+				},
+			},
+		},
+	}}.Send(t, host, nil)
+
+	search := func(req models.SearchSpendsReq) []uint {
+		var resp models.SearchSpendsResp
+		RequestOK{GET, SearchSpendsPath, req}.Send(t, host, &resp)
+
+		ids := make([]uint, 0, len(resp.Spends))
+		for _, spend := range resp.Spends {
+			ids = append(ids, spend.ID)
+		}
+		require.Equal(len(ids), resp.Total.Count, req.Text)
+		return ids
+	}
+
+	// Spends are sorted by relevance by default, so we check only found Spends
+	for _, tt := range []struct {
+		text string
+		ids  []uint
+	}{
+		{text: "coffee", ids: []uint{1, 2, 3}},
+		{text: "COFFEE, arabica!", ids: []uint{1}},
+		{text: "Кофе", ids: []uint{5}},
+		{text: "КАПУЧИНО", ids: []uint{5}},
+		{text: "juice", ids: []uint{}},
+		{text: "?", ids: []uint{1, 2, 3, 4, 5}},
+	} {
+		require.ElementsMatch(tt.ids, search(models.SearchSpendsReq{Text: tt.text}), tt.text)
+	}
+
+	// The text is combined with other args
+	ids := search(models.SearchSpendsReq{Text: "coffee", MaxCost: 10, Sort: "cost", Order: "desc"})
+	require.Equal([]uint{2, 3}, ids)
+
+	ids = search(models.SearchSpendsReq{Text: "coffee", Query: "-tea", Sort: "date"})
+	require.Equal([]uint{1, 2}, ids)
+
+	// Changes of Spends are taken into account
+	RequestOK{PUT, SpendsPath, models.EditSpendReq{ID: 4, Notes: ptrStr("coffee cake")}}.Send(t, host, nil)
+	RequestOK{PUT, SpendsPath, models.EditSpendReq{ID: 2, Title: ptrStr("Espresso")}}.Send(t, host, nil)
+	RequestOK{DELETE, SpendsPath, models.RemoveSpendReq{ID: 1}}.Send(t, host, nil)
+
+	require.ElementsMatch([]uint{3, 4}, search(models.SearchSpendsReq{Text: "coffee"}))
+	require.ElementsMatch([]uint{2}, search(models.SearchSpendsReq{Text: "espresso"}))
+}
+
+func testSearch_Page(t *testing.T, host string) {
+	require := require.New(t)
+
+	// Restore more Spends than fit on a single page
+	days := make([]db.BackupDay, 0, 21)
+	for day := 1; day <= 21; day++ {
+		spends := make([]db.BackupSpend, 0, 5)
+		for i := 0; i < 5; i++ {
+			title := fmt.Sprintf("spend-%03d", (day-1)*5+i+1)
+			spends = append(spends, db.BackupSpend{Title: title, Cost: money.FromInt(1)})
+		}
+		days = append(days, db.BackupDay{Day: day, Spends: spends})
+	}
+	RequestOK{POST, RestoreBackupPath, models.RestoreBackupReq{
+		Backup: db.Backup{
+			Version: db.BackupVersion,
+			Months:  []db.BackupMonth{{Year: 2025, Month: time.March, Days: days}},
+		},
+	}}.Send(t, host, nil)
+
+	code, body := getPage(t, host, "/search/spends?sort=title&page=2")
+	require.Equal(http.StatusOK, code)
+
+	// Only Spends of the page are rendered, but the total is calculated for all found Spends
+	require.Contains(body, "spend-101")
+	require.Contains(body, "spend-105")
+	require.NotContains(body, "spend-100")
+	require.Contains(body, "<span>(105)</span>")
+	require.Contains(body, "<span>2 / 2</span>")
+}
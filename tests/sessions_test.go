@@ -0,0 +1,173 @@
+package tests
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+)
+
+func TestSessions(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "login and logout", Fn: testSessions_LoginAndLogout},
+		{Name: "remember me", Fn: testSessions_RememberMe},
+		{Name: "unauthorized", Fn: testSessions_Unauthorized},
+	}, func(env *TestEnv) {
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user": {PasswordHash: "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC"}, // user:qwerty
+		}
+	})
+}
+
+func testSessions_LoginAndLogout(t *testing.T, host string) {
+	require := require.New(t)
+
+	resp, body := sendPageRequest(t, host, GET, "/login?next=%2Fmonths", nil, nil)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, `<form id="login__form" method="POST" action="/login">`)
+	require.Contains(body, `name="next" value="/months"`)
+
+	// Wrong password
+	resp, body = sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"123"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Invalid username or password")
+	require.Empty(resp.Cookies())
+
+	resp, _ = sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"qwerty"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/months", resp.Header.Get("Location"))
+
+	cookie := getSessionCookie(t, resp)
+	require.True(cookie.HttpOnly)
+	require.True(cookie.Secure)
+	require.Equal(http.SameSiteLaxMode, cookie.SameSite)
+	require.Equal("/", cookie.Path)
+	// Session cookie without 'Remember me'
+	require.Zero(cookie.MaxAge)
+
+	// Pages and API are available with the cookie
+	resp, body = sendPageRequest(t, host, GET, "/months", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, `action="/logout"`)
+
+	resp, _ = sendPageRequest(t, host, GET, string(SearchSpendsPath), nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+
+	// Authorized users are redirected from the login page
+	resp, _ = sendPageRequest(t, host, GET, "/login?next=%2Fsearch%2Fspends", nil, cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/search/spends", resp.Header.Get("Location"))
+
+	// Logout
+	resp, _ = sendPageRequest(t, host, POST, "/logout", withCSRFToken(nil, cookie), cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login", resp.Header.Get("Location"))
+	removedCookie := getSessionCookie(t, resp)
+	require.Empty(removedCookie.Value)
+	require.Equal(-1, removedCookie.MaxAge)
+
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login?next=%2Fmonths", resp.Header.Get("Location"))
+}
+
+func testSessions_RememberMe(t *testing.T, host string) {
+	require := require.New(t)
+
+	resp, _ := sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"qwerty"}, "remember": {"on"}, "next": {"//example.com"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/", resp.Header.Get("Location"))
+
+	cookie := getSessionCookie(t, resp)
+	require.Equal(24*60*60, cookie.MaxAge)
+
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+}
+
+func testSessions_Unauthorized(t *testing.T, host string) {
+	require := require.New(t)
+
+	// Pages are redirected to the login page
+	for _, path := range []string{"/", "/months", "/search/spends?title=a&sort=cost"} {
+		resp, _ := sendPageRequest(t, host, GET, path, nil, nil)
+		require.Equal(http.StatusSeeOther, resp.StatusCode)
+		require.Equal("/login?next="+url.QueryEscape(path), resp.Header.Get("Location"))
+	}
+
+	// API requests get 401 and can use Basic Auth
+	resp, _ := sendPageRequest(t, host, GET, string(SearchSpendsPath), nil, nil)
+	require.Equal(http.StatusUnauthorized, resp.StatusCode)
+	require.Equal(`Basic realm="Budget Manager"`, resp.Header.Get("WWW-Authenticate"))
+
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("user"), nil)
+	require.Equal(http.StatusOK, code)
+
+	// Invalid session
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, &http.Cookie{Name: "session", Value: "123"})
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+
+	// Static files don't require auth
+	resp, _ = sendPageRequest(t, host, GET, "/static/css/common.css", nil, nil)
+	require.Equal(http.StatusOK, resp.StatusCode)
+
+	// Logout without a session
+	resp, _ = sendPageRequest(t, host, POST, "/logout", nil, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login", resp.Header.Get("Location"))
+}
+
+// sendPageRequest sends a request with an optional form and cookie. Redirects are not followed
+func sendPageRequest(t *testing.T, host string, method Method, path string, form url.Values,
+	cookie *http.Cookie) (resp *http.Response, body string) {
+
+	require := require.New(t)
+
+	req, cancel := newRequest(t, method, "http://"+host+path, strings.NewReader(form.Encode()))
+	defer cancel()
+
+	if form != nil {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+
+	client := &http.Client{
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	resp, err := client.Do(req)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(err)
+
+	return resp, string(data)
+}
+
+func getSessionCookie(t *testing.T, resp *http.Response) *http.Cookie {
+	for _, c := range resp.Cookies() {
+		if c.Name == "session" {
+			return c
+		}
+	}
+	require.FailNow(t, "no session cookie")
+	return nil
+}
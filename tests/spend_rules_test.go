@@ -0,0 +1,177 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestSpendRules(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "manage", Fn: testSpendRules_Manage},
+		{Name: "add spends", Fn: testSpendRules_AddSpends},
+		{Name: "apply", Fn: testSpendRules_Apply},
+		{Name: "apply concurrently", Fn: testSpendRules_ApplyConcurrently},
+	})
+}
+
+func testSpendRules_Manage(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},                   // 1
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "groceries", ParentID: 1}}, // 2
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "cafe", ParentID: 1}},      // 3
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "temp"}},                   // 4
+	} {
+		req.Send(t, host, nil)
+	}
+
+	for i, req := range []RequestCreated{
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "(?i)lidl|aldi", TypeID: 2, Notes: "auto"}}, // 1
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "temp", TypeID: 4}},                         // 2
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "tmp", TypeID: 1}},                          // 3
+	} {
+		var resp models.AddSpendRuleResp
+		req.Send(t, host, &resp)
+		require.Equal(uint(i+1), resp.ID)
+	}
+
+	for _, req := range []Request{
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "[a-", TypeID: 1}, 400, "title_pattern is invalid: error parsing regexp: missing closing ]: `[a-`"},
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "a", TypeID: 10}, 400, "such Spend Type doesn't exist"},
+		{PUT, SpendRulesPath, models.EditSpendRuleReq{ID: 10, Notes: ptrStr("")}, 404, "such Spend Rule doesn't exist"},
+		{DELETE, SpendRulesPath, models.RemoveSpendRuleReq{ID: 10}, 404, "such Spend Rule doesn't exist"},
+		//
+		{PUT, SpendRulesPath, models.EditSpendRuleReq{ID: 3, TitlePattern: ptrStr("(?i)^cafe"), TypeID: ptrUint(3)}, 200, ""},
+		// Spend Type can't be removed while Spend Rule #2 uses it
+		{DELETE, SpendTypesPath, models.RemoveSpendTypeReq{ID: 4}, 400, "Spend Type is used by Monthly Payment, Spend, Spend Rule or Saved Search"},
+		{DELETE, SpendRulesPath, models.RemoveSpendRuleReq{ID: 2}, 200, ""},
+		{DELETE, SpendTypesPath, models.RemoveSpendTypeReq{ID: 4}, 200, ""},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var resp models.GetSpendRulesResp
+	RequestOK{GET, SpendRulesPath, nil}.Send(t, host, &resp)
+	require.Equal(
+		[]db.SpendRule{
+			{ID: 1, TitlePattern: "(?i)lidl|aldi", TypeID: 2, Notes: "auto"},
+			{ID: 3, TitlePattern: "(?i)^cafe", TypeID: 3},
+		},
+		resp.SpendRules,
+	)
+}
+
+func testSpendRules_AddSpends(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "Lidl", Cost: 20}},                    // 1
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "aldi", Notes: "milk", Cost: 5}},      // 2
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "lidl", TypeID: 1, Cost: 10}},         // 3
+		{POST, SpendsPath, models.AddSpendReq{DayID: 2, Title: "coffee in the cafe", Cost: 3}},       // 4
+		{POST, SpendsPath, models.AddSpendReq{DayID: 2, Title: "Cafe", Cost: 7}},                     // 5
+		{POST, SpendsPath, models.AddSpendReq{DayID: 2, Title: "new book", Notes: "gift", Cost: 15}}, // 6
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var resp models.SearchSpendsResp
+	RequestOK{GET, SearchSpendsPath, nil}.Send(t, host, &resp)
+
+	type spend struct {
+		typeID uint
+		notes  string
+	}
+	got := make([]spend, 0, len(resp.Spends))
+	for _, s := range resp.Spends {
+		var typeID uint
+		if s.Type != nil {
+			typeID = s.Type.ID
+		}
+		got = append(got, spend{typeID, s.Notes})
+	}
+	require.Equal(
+		[]spend{
+			{2, "auto"},
+			{2, "milk; auto"},
+			{1, ""},
+			{0, ""},
+			{3, ""},
+			{0, "gift"},
+		},
+		got,
+	)
+}
+
+func testSpendRules_Apply(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "(?i)coffee", TypeID: 3, Notes: "coffee"}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var applyResp models.ApplySpendRulesResp
+	RequestOK{POST, ApplySpendRulesPath, nil}.Send(t, host, &applyResp)
+	require.Equal(1, applyResp.Count)
+
+	var resp models.SearchSpendsResp
+	RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{TypeIDs: []uint{0}}}.Send(t, host, &resp)
+	require.Len(resp.Spends, 1)
+	require.Equal("new book", resp.Spends[0].Title)
+
+	RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{TypeIDs: []uint{3}}}.Send(t, host, &resp)
+	require.Len(resp.Spends, 2)
+	require.Equal("coffee in the cafe", resp.Spends[0].Title)
+	require.Equal("coffee", resp.Spends[0].Notes)
+	require.Equal(money.FromInt(3), resp.Spends[0].Cost)
+}
+
+func testSpendRules_ApplyConcurrently(t *testing.T, host string) {
+	require := require.New(t)
+
+	RequestCreated{POST, SpendRulesPath, models.AddSpendRuleReq{TitlePattern: "book", TypeID: 1}}.Send(t, host, nil)
+
+	const requests = 20
+
+	bodies := make(chan []byte, requests)
+	var wg sync.WaitGroup
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			code, _, body := sendWithHeader(t, host, POST, ApplySpendRulesPath, nil, nil)
+			if code == http.StatusOK {
+				bodies <- body
+			}
+		}()
+	}
+	wg.Wait()
+	close(bodies)
+
+	// The only Spend without type must be counted once
+	var count int
+	for body := range bodies {
+		var resp models.ApplySpendRulesResp
+		require.NoError(json.Unmarshal(body, &resp))
+		count += resp.Count
+	}
+	require.Equal(1, count)
+
+	var resp models.SearchSpendsResp
+	RequestOK{GET, SearchSpendsPath, models.SearchSpendsReq{TypeIDs: []uint{0}}}.Send(t, host, &resp)
+	require.Empty(resp.Spends)
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/web/pages/statistics"
+)
+
+func TestStatistics(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "spends", Fn: testStatistics_Spends},
+	})
+}
+
+func testStatistics_Spends(t *testing.T, host string) {
+	require := require.New(t)
+
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},                   // 1
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "groceries", ParentID: 1}}, // 2
+		//
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "lidl", TypeID: 2, Cost: 20}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "cafe", TypeID: 1, Cost: 17}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 3, Title: "book", Cost: 30}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 3, Title: "aldi", TypeID: 2, Cost: 35}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	now := time.Now()
+	date := func(day int) time.Time {
+		return time.Date(now.Year(), now.Month(), day, 0, 0, 0, 0, time.UTC)
+	}
+	m := money.FromInt
+
+	// Spent by Spend Type
+	var byTypeResp models.GetSpentBySpendTypeResp
+	RequestOK{GET, SpentBySpendTypePath, models.SearchSpendsReq{}}.Send(t, host, &byTypeResp)
+	require.Equal(
+		[]statistics.SpentBySpendTypeDataset{
+			{
+				{SpendTypeName: "food", Spent: m(72)},
+				{SpendTypeName: "No Type", Spent: m(30)},
+			},
+			// Children datasets are aligned with parents: Spends of a parent itself and
+			// Spends of types without children are added without name
+			{
+				{SpendTypeName: "groceries", Spent: m(55)},
+				{SpendTypeName: "", Spent: m(17)},
+				{SpendTypeName: "", Spent: m(30)},
+			},
+		},
+		byTypeResp.Datasets,
+	)
+
+	RequestOK{GET, SpentBySpendTypePath, models.SearchSpendsReq{TypeIDs: []uint{0}}}.Send(t, host, &byTypeResp)
+	require.Equal(
+		[]statistics.SpentBySpendTypeDataset{
+			{{SpendTypeName: "No Type", Spent: m(30)}},
+		},
+		byTypeResp.Datasets,
+	)
+
+	// Spent by day
+	var byDayResp models.GetSpentByDayResp
+	RequestOK{GET, SpentByDayPath, models.SearchSpendsReq{After: date(1), Before: date(4)}}.Send(t, host, &byDayResp)
+	require.Equal(
+		statistics.SpentByDayDataset{
+			{Year: now.Year(), Month: now.Month(), Day: 1, Spent: m(37)},
+			{Year: now.Year(), Month: now.Month(), Day: 2, Spent: 0},
+			{Year: now.Year(), Month: now.Month(), Day: 3, Spent: m(65)},
+			{Year: now.Year(), Month: now.Month(), Day: 4, Spent: 0},
+		},
+		byDayResp.Dataset,
+	)
+
+	RequestOK{GET, SpentByDayPath, models.SearchSpendsReq{Title: "LIDL"}}.Send(t, host, &byDayResp)
+	require.Equal(
+		statistics.SpentByDayDataset{
+			{Year: now.Year(), Month: now.Month(), Day: 1, Spent: m(20)},
+		},
+		byDayResp.Dataset,
+	)
+
+	// Cost intervals
+	var intervalsResp models.GetCostIntervalsResp
+	RequestOK{GET, CostIntervalsPath, models.GetCostIntervalsReq{IntervalNumber: 5}}.Send(t, host, &intervalsResp)
+	require.Equal(
+		[]statistics.CostInterval{
+			{From: m(17), To: m(21) - 1, Count: 2, Total: m(37)},
+			{From: m(21), To: m(25) - 1, Count: 0, Total: 0},
+			{From: m(25), To: m(29) - 1, Count: 0, Total: 0},
+			{From: m(29), To: m(33) - 1, Count: 1, Total: m(30)},
+			{From: m(33), To: m(35), Count: 1, Total: m(35)},
+		},
+		intervalsResp.Intervals,
+	)
+
+	RequestOK{GET, CostIntervalsPath, models.GetCostIntervalsReq{IntervalNumber: 1}}.Send(t, host, &intervalsResp)
+	require.Equal(
+		[]statistics.CostInterval{
+			{From: m(17), To: m(35), Count: 4, Total: m(102)},
+		},
+		intervalsResp.Intervals,
+	)
+
+	// Default number of intervals
+	RequestOK{GET, CostIntervalsPath, models.GetCostIntervalsReq{}}.Send(t, host, &intervalsResp)
+	require.NotEmpty(intervalsResp.Intervals)
+
+	for _, req := range []Request{
+		{
+			GET, CostIntervalsPath, models.GetCostIntervalsReq{IntervalNumber: 101},
+			http.StatusBadRequest, "interval_number must be in range [1, 100]",
+		},
+		{
+			GET, SpentByDayPath, models.SearchSpendsReq{MinCost: 10, MaxCost: 5},
+			http.StatusBadRequest, "min_cost can't be greater than max_cost",
+		},
+	} {
+		req.Send(t, host, nil)
+	}
+}
@@ -3,6 +3,7 @@ package tests
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/ShoshinNikita/budget-manager/internal/app"
 	"github.com/ShoshinNikita/budget-manager/internal/db"
@@ -10,6 +11,7 @@ import (
 	"github.com/ShoshinNikita/budget-manager/internal/db/sqlite"
 	"github.com/ShoshinNikita/budget-manager/internal/logger"
 	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/webhooks"
 )
 
 type Test interface {
@@ -109,8 +111,20 @@ func getDefaultConfig(dbType db.Type) app.Config {
 			UseEmbed:        true,
 			EnableProfiling: false,
 			Auth: web.AuthConfig{
-				Disable: true,
+				Disable:       true,
+				SessionTTL:    time.Hour,
+				RememberMeTTL: 24 * time.Hour,
+				SecureCookie:  true,
 			},
+			CalendarReminder:  24 * time.Hour,
+			IdempotencyKeyTTL: time.Hour,
+		},
+		Webhooks: webhooks.Config{
+			PollInterval:  50 * time.Millisecond,
+			Timeout:       time.Second,
+			MaxAttempts:   3,
+			RetryDelay:    50 * time.Millisecond,
+			MaxRetryDelay: 100 * time.Millisecond,
 		},
 	}
 }
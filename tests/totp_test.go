@@ -0,0 +1,274 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/csrf"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/totp"
+	"github.com/ShoshinNikita/budget-manager/internal/web"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestTOTP(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "optional", Fn: testTOTP_Optional},
+		{Name: "required", Fn: testTOTP_Required},
+		{Name: "backup restore", Fn: testTOTP_BackupRestore},
+	}, func(env *TestEnv) {
+		const hash = "$2y$05$wK5Ad.qdY.ZLPsfEv3rc/.uO.8SkbD6r2ptiuZefMUOX0wgGK/1rC" // qwerty
+
+		env.Cfg.Server.Auth.Disable = false
+		env.Cfg.Server.Auth.BasicAuthCreds = web.Credentials{
+			"user":  {PasswordHash: hash},
+			"admin": {PasswordHash: hash, RequireTOTP: true},
+		}
+	})
+}
+
+//nolint:gochecknoglobals
+var (
+	totpSecretRegexp   = regexp.MustCompile(`id="totp__secret">([A-Z2-7]+)<`)
+	recoveryCodeRegexp = regexp.MustCompile(`<span>([0-9a-f]{5}-[0-9a-f]{5})</span>`)
+)
+
+//nolint:funlen
+func testTOTP_Optional(t *testing.T, host string) {
+	require := require.New(t)
+
+	login := func() *http.Cookie {
+		resp, _ := sendPageRequest(t, host, POST, "/login", url.Values{
+			"username": {"user"}, "password": {"qwerty"}, "next": {"/months"},
+		}, nil)
+		require.Equal(http.StatusSeeOther, resp.StatusCode)
+		return getSessionCookie(t, resp)
+	}
+
+	// Enroll
+	cookie := login()
+	resp, body := sendPageRequest(t, host, GET, "/settings/totp", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, `<div id="totp__qr-code"><svg`)
+	secret := getTOTPSecret(t, body)
+
+	resp, body = sendPageRequest(t, host, POST, "/settings/totp/enable", withCSRFToken(url.Values{
+		"code": {"000000"},
+	}, cookie), cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Invalid code")
+	// The secret is not changed
+	require.Equal(secret, getTOTPSecret(t, body))
+
+	step := totp.Step(time.Now())
+	resp, body = sendPageRequest(t, host, POST, "/settings/totp/enable", withCSRFToken(url.Values{
+		"code": {newTOTPCode(t, secret, step)},
+	}, cookie), cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	recoveryCodes := getRecoveryCodes(t, body)
+
+	resp, body = sendPageRequest(t, host, GET, "/settings/totp", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Unused recovery codes: 10")
+
+	// Basic Auth can't be used
+	code, _, respBody := sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("user"), nil)
+	require.Equal(http.StatusUnauthorized, code)
+	var errResp models.BaseResponse
+	require.NoError(json.Unmarshal(respBody, &errResp))
+	require.Equal("Basic Auth can't be used with two-factor authentication, use API Tokens instead", errResp.Error)
+
+	// Login requires a code
+	resp, _ = sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"qwerty"}, "remember": {"on"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login/totp?next=%2Fmonths&remember=true", resp.Header.Get("Location"))
+	pendingCookie := getSessionCookie(t, resp)
+	require.Zero(pendingCookie.MaxAge)
+
+	// Pending session can't be used
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, pendingCookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login/totp?next=%2Fmonths", resp.Header.Get("Location"))
+
+	resp, _ = sendPageRequest(t, host, GET, string(SearchSpendsPath), nil, pendingCookie)
+	require.Equal(http.StatusUnauthorized, resp.StatusCode)
+
+	resp, body = sendPageRequest(t, host, GET, "/login/totp?next=%2Fmonths&remember=true", nil, pendingCookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, `<form class="totp__form" method="POST" action="/login/totp">`)
+	require.NotContains(body, `id="totp__qr-code"`)
+
+	// The code used to enable TOTP can't be used again
+	verify := func(code string) (*http.Response, string) {
+		return sendPageRequest(t, host, POST, "/login/totp", withCSRFToken(url.Values{
+			"code": {code}, "next": {"/months"}, "remember": {"true"},
+		}, pendingCookie), pendingCookie)
+	}
+	resp, body = verify(newTOTPCode(t, secret, step))
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Invalid code")
+
+	resp, _ = verify(newTOTPCode(t, secret, step+1))
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/months", resp.Header.Get("Location"))
+	cookie = getSessionCookie(t, resp)
+	require.Equal(24*60*60, cookie.MaxAge)
+
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+
+	// The pending session is removed
+	resp, _ = sendPageRequest(t, host, GET, "/login/totp", nil, pendingCookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login?next=%2F", resp.Header.Get("Location"))
+
+	// Recovery codes can be used once
+	pendingCookie = login()
+	resp, _ = verify(recoveryCodes[0])
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+
+	pendingCookie = login()
+	resp, body = verify(recoveryCodes[0])
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Invalid code")
+
+	// Regenerate recovery codes
+	resp, body = sendPageRequest(t, host, POST, "/settings/totp/recovery-codes", withCSRFToken(url.Values{
+		"code": {recoveryCodes[1]},
+	}, cookie), cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	newRecoveryCodes := getRecoveryCodes(t, body)
+	require.NotContains(newRecoveryCodes, recoveryCodes[2])
+
+	// Disable
+	resp, body = sendPageRequest(t, host, POST, "/settings/totp/disable", withCSRFToken(url.Values{
+		"code": {"123456"},
+	}, cookie), cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Invalid code")
+
+	resp, _ = sendPageRequest(t, host, POST, "/settings/totp/disable", withCSRFToken(url.Values{
+		"code": {newRecoveryCodes[0]},
+	}, cookie), cookie)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/settings/totp", resp.Header.Get("Location"))
+
+	code, _, _ = sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("user"), nil)
+	require.Equal(http.StatusOK, code)
+
+	resp, _ = sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"user"}, "password": {"qwerty"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/months", resp.Header.Get("Location"))
+}
+
+func testTOTP_Required(t *testing.T, host string) {
+	require := require.New(t)
+
+	// Basic Auth can't be used even before enrollment
+	code, _, _ := sendWithHeader(t, host, GET, SearchSpendsPath, basicAuthHeader("admin"), nil)
+	require.Equal(http.StatusUnauthorized, code)
+
+	resp, _ := sendPageRequest(t, host, POST, "/login", url.Values{
+		"username": {"admin"}, "password": {"qwerty"}, "next": {"/months"},
+	}, nil)
+	require.Equal(http.StatusSeeOther, resp.StatusCode)
+	require.Equal("/login/totp?next=%2Fmonths", resp.Header.Get("Location"))
+	pendingCookie := getSessionCookie(t, resp)
+
+	// The user must set up TOTP
+	resp, body := sendPageRequest(t, host, GET, "/login/totp?next=%2Fmonths", nil, pendingCookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, "Two-factor authentication is required for your account")
+	require.Contains(body, `<div id="totp__qr-code"><svg`)
+	secret := getTOTPSecret(t, body)
+
+	resp, body = sendPageRequest(t, host, POST, "/login/totp", withCSRFToken(url.Values{
+		"code": {newTOTPCode(t, secret, totp.Step(time.Now()))}, "next": {"/months"},
+	}, pendingCookie), pendingCookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Len(getRecoveryCodes(t, body), 10)
+	require.Contains(body, `<a href="/months">Continue</a>`)
+	cookie := getSessionCookie(t, resp)
+
+	resp, body = sendPageRequest(t, host, GET, "/settings/totp", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+	require.Contains(body, `title="Two-factor authentication is required" disabled`)
+
+	_, body = sendPageRequest(t, host, POST, "/settings/totp/disable", withCSRFToken(url.Values{
+		"code": {"123456"},
+	}, cookie), cookie)
+	require.Contains(body, "Two-factor authentication is required for this user and can&#39;t be disabled")
+}
+
+func testTOTP_BackupRestore(t *testing.T, host string) {
+	require := require.New(t)
+
+	login := func() *http.Response {
+		resp, _ := sendPageRequest(t, host, POST, "/login", url.Values{
+			"username": {"user"}, "password": {"qwerty"}, "next": {"/months"},
+		}, nil)
+		require.Equal(http.StatusSeeOther, resp.StatusCode)
+		return resp
+	}
+
+	// Enroll
+	cookie := getSessionCookie(t, login())
+	_, body := sendPageRequest(t, host, GET, "/settings/totp", nil, cookie)
+	resp, _ := sendPageRequest(t, host, POST, "/settings/totp/enable", withCSRFToken(url.Values{
+		"code": {newTOTPCode(t, getTOTPSecret(t, body), totp.Step(time.Now()))},
+	}, cookie), cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+
+	// Restore
+	header := http.Header{
+		"Cookie":       {cookie.String()},
+		"X-Csrf-Token": {csrf.SessionToken(cookie.Value)},
+	}
+	code, _, respBody := sendWithHeader(t, host, GET, BackupPath, header, nil)
+	require.Equal(http.StatusOK, code)
+	var backup db.Backup
+	require.NoError(json.Unmarshal(respBody, &backup))
+
+	code, _, _ = sendWithHeader(t, host, POST, RestoreBackupPath, header, models.RestoreBackupReq{Backup: backup})
+	require.Equal(http.StatusOK, code)
+
+	// The session is kept and login still requires a code
+	resp, _ = sendPageRequest(t, host, GET, "/months", nil, cookie)
+	require.Equal(http.StatusOK, resp.StatusCode)
+
+	resp = login()
+	require.Equal("/login/totp?next=%2Fmonths", resp.Header.Get("Location"))
+}
+
+func getTOTPSecret(t *testing.T, body string) string {
+	match := totpSecretRegexp.FindStringSubmatch(body)
+	require.Len(t, match, 2, "no TOTP secret")
+	return match[1]
+}
+
+func getRecoveryCodes(t *testing.T, body string) []string {
+	var codes []string
+	for _, match := range recoveryCodeRegexp.FindAllStringSubmatch(body, -1) {
+		codes = append(codes, match[1])
+	}
+	require.Len(t, codes, 10)
+	return codes
+}
+
+func newTOTPCode(t *testing.T, secret string, step int64) string {
+	code, err := totp.Code(secret, step)
+	require.NoError(t, err)
+	return code
+}
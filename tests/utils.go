@@ -1,10 +1,14 @@
 package tests
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"io"
+	"io/ioutil"
 	"net"
 	"net/http"
+	"net/url"
 	"testing"
 	"time"
 
@@ -33,6 +37,36 @@ func newRequest(t *testing.T, method Method, url string, body io.Reader) (req *h
 	return req, cancel
 }
 
+// sendWithHeader sends a request with the passed header and returns the status code, header and body
+// of the response. The request is encoded as JSON body
+func sendWithHeader(t *testing.T, host string, method Method, path Path, header http.Header,
+	req interface{}) (statusCode int, respHeader http.Header, body []byte) {
+
+	require := require.New(t)
+
+	u := &url.URL{Scheme: "http", Host: host, Path: string(path)}
+
+	buf := &bytes.Buffer{}
+	if req != nil {
+		require.NoError(json.NewEncoder(buf).Encode(req))
+	}
+	httpReq, cancel := newRequest(t, method, u.String(), buf)
+	defer cancel()
+
+	for k := range header {
+		httpReq.Header.Set(k, header.Get(k))
+	}
+
+	resp, err := http.DefaultClient.Do(httpReq)
+	require.NoError(err)
+	defer resp.Body.Close()
+
+	body, err = ioutil.ReadAll(resp.Body)
+	require.NoError(err)
+
+	return resp.StatusCode, resp.Header, body
+}
+
 func ptrStr(v string) *string {
 	return &v
 }
@@ -44,3 +78,7 @@ func ptrUint(v uint) *uint {
 func ptrFloat(v float64) *float64 {
 	return &v
 }
+
+func ptrBool(v bool) *bool {
+	return &v
+}
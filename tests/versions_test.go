@@ -0,0 +1,214 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+)
+
+func TestVersions(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "version field", Fn: testVersions_Field},
+		{Name: "if-match header", Fn: testVersions_IfMatch},
+		{Name: "errors", Fn: testVersions_Errors},
+	})
+}
+
+func testVersions_Field(t *testing.T, host string) {
+	for _, req := range []RequestCreated{
+		{POST, SpendTypesPath, models.AddSpendTypeReq{Name: "food"}},
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 2500}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", Cost: 1000}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "bread", Cost: 2}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	const errConflict = "record was changed by another request, version doesn't match"
+
+	for _, tt := range []struct {
+		path  Path
+		edit  func(version int) interface{}
+		check func(require *require.Assertions, body []byte)
+	}{
+		{
+			path: SpendTypesPath,
+			edit: func(version int) interface{} {
+				return models.EditSpendTypeReq{ID: 1, Name: ptrStr("groceries"), Version: &version}
+			},
+			check: func(require *require.Assertions, body []byte) {
+				var resp models.GetSpendTypeResp
+				require.NoError(json.Unmarshal(body, &resp))
+				require.Equal("groceries", resp.SpendType.Name)
+				require.Equal(2, resp.SpendType.Version)
+			},
+		},
+		{
+			path: IncomesPath,
+			edit: func(version int) interface{} {
+				return models.EditIncomeReq{ID: 1, Income: ptrFloat(3000), Version: &version}
+			},
+			check: func(require *require.Assertions, body []byte) {
+				var resp models.GetIncomeResp
+				require.NoError(json.Unmarshal(body, &resp))
+				require.Equal(2, resp.Income.Version)
+			},
+		},
+		{
+			path: MonthlyPaymentsPath,
+			edit: func(version int) interface{} {
+				return models.EditMonthlyPaymentReq{ID: 1, Title: ptrStr("flat"), Version: &version}
+			},
+			check: func(require *require.Assertions, body []byte) {
+				var resp models.GetMonthlyPaymentResp
+				require.NoError(json.Unmarshal(body, &resp))
+				require.Equal("flat", resp.MonthlyPayment.Title)
+				require.Equal(2, resp.MonthlyPayment.Version)
+			},
+		},
+		{
+			path: SpendsPath,
+			edit: func(version int) interface{} {
+				return models.EditSpendReq{ID: 1, Cost: ptrFloat(3), Version: &version}
+			},
+			check: func(require *require.Assertions, body []byte) {
+				var resp models.GetSpendResp
+				require.NoError(json.Unmarshal(body, &resp))
+				require.Equal(2, resp.Spend.Version)
+			},
+		},
+	} {
+		tt := tt
+		t.Run(string(tt.path), func(t *testing.T) {
+			require := require.New(t)
+
+			code, etag, _ := sendVersionedRequest(t, host, GET, tt.path+"/1", "", nil)
+			require.Equal(http.StatusOK, code)
+			require.Equal(`"1"`, etag)
+
+			code, etag, body := sendVersionedRequest(t, host, PUT, tt.path, "", tt.edit(1))
+			require.Equal(http.StatusOK, code)
+			require.Equal(`"2"`, etag)
+			var editResp struct {
+				Version int `json:"version"`
+			}
+			require.NoError(json.Unmarshal(body, &editResp))
+			require.Equal(2, editResp.Version)
+
+			// The second edit with the same version is stale
+			code, etag, body = sendVersionedRequest(t, host, PUT, tt.path, "", tt.edit(1))
+			require.Equal(http.StatusConflict, code)
+			require.Equal(`"2"`, etag)
+			Request{StatusCode: http.StatusConflict, Err: errConflict}.checkResponse(t, code, body, nil)
+			tt.check(require, body)
+		})
+	}
+}
+
+func testVersions_IfMatch(t *testing.T, host string) {
+	require := require.New(t)
+
+	var addResp models.AddSpendResp
+	RequestCreated{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "milk", Cost: 2}}.Send(t, host, &addResp)
+	id := addResp.ID
+	spendPath := SpendsPath + Path("/"+strconv.Itoa(int(id)))
+
+	// Get the current version
+	code, etag, _ := sendVersionedRequest(t, host, GET, spendPath, "", nil)
+	require.Equal(http.StatusOK, code)
+
+	req := models.EditSpendReq{ID: id, Cost: ptrFloat(3)}
+	code, etag, _ = sendVersionedRequest(t, host, PUT, SpendsPath, etag, req)
+	require.Equal(http.StatusOK, code)
+	require.Equal(`"2"`, etag)
+
+	// Weak ETags are accepted too
+	req = models.EditSpendReq{ID: id, Cost: ptrFloat(4)}
+	code, etag, _ = sendVersionedRequest(t, host, PUT, SpendsPath, `W/"2"`, req)
+	require.Equal(http.StatusOK, code)
+	require.Equal(`"3"`, etag)
+
+	req = models.EditSpendReq{ID: id, Cost: ptrFloat(5)}
+	code, etag, body := sendVersionedRequest(t, host, PUT, SpendsPath, `"2"`, req)
+	require.Equal(http.StatusConflict, code)
+	require.Equal(`"3"`, etag)
+
+	var resp models.GetSpendResp
+	require.NoError(json.Unmarshal(body, &resp))
+	require.Equal(money.FromInt(4), resp.Spend.Cost)
+
+	// Edits without a version are not checked
+	for _, ifMatch := range []string{"", "*"} {
+		code, _, _ = sendVersionedRequest(t, host, PUT, SpendsPath, ifMatch, req)
+		require.Equal(http.StatusOK, code)
+	}
+
+	var spendResp models.GetSpendResp
+	RequestOK{GET, spendPath, nil}.Send(t, host, &spendResp)
+	require.Equal(money.FromInt(5), spendResp.Spend.Cost)
+	require.Equal(5, spendResp.Spend.Version)
+}
+
+func testVersions_Errors(t *testing.T, host string) {
+	require := require.New(t)
+
+	var addResp models.AddSpendResp
+	RequestCreated{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "milk", Cost: 2}}.Send(t, host, &addResp)
+	id := addResp.ID
+	spendPath := SpendsPath + Path("/"+strconv.Itoa(int(id)))
+
+	version := 0
+	Request{
+		PUT, SpendsPath, models.EditSpendReq{ID: id, Version: &version},
+		http.StatusBadRequest, "version must be greater than zero",
+	}.Send(t, host, nil)
+
+	version = 1
+	for _, tt := range []struct {
+		ifMatch string
+		req     interface{}
+		err     string
+	}{
+		{ifMatch: "1", req: models.EditSpendReq{ID: id}, err: "invalid If-Match header"},
+		{ifMatch: `"0"`, req: models.EditSpendReq{ID: id}, err: "invalid If-Match header"},
+		{ifMatch: `"1", "2"`, req: models.EditSpendReq{ID: id}, err: "invalid If-Match header"},
+		{
+			ifMatch: `"2"`, req: models.EditSpendReq{ID: id, Version: &version},
+			err: "version and If-Match header don't match",
+		},
+	} {
+		code, _, body := sendVersionedRequest(t, host, PUT, SpendsPath, tt.ifMatch, tt.req)
+		Request{StatusCode: http.StatusBadRequest, Err: tt.err}.checkResponse(t, code, body, nil)
+	}
+
+	// Existence is checked before the version
+	Request{
+		PUT, SpendsPath, models.EditSpendReq{ID: 1000, Version: &version},
+		http.StatusNotFound, "such Spend doesn't exist",
+	}.Send(t, host, nil)
+
+	code, etag, _ := sendVersionedRequest(t, host, GET, spendPath, "", nil)
+	require.Equal(http.StatusOK, code)
+	require.Equal(`"1"`, etag)
+}
+
+// sendVersionedRequest sends a request with 'If-Match' header and returns the status code, 'ETag' header
+// and body of the response
+func sendVersionedRequest(t *testing.T, host string, method Method, path Path, ifMatch string,
+	req interface{}) (statusCode int, etag string, body []byte) {
+
+	header := http.Header{}
+	if ifMatch != "" {
+		header.Set("If-Match", ifMatch)
+	}
+	statusCode, respHeader, body := sendWithHeader(t, host, method, path, header, req)
+	return statusCode, respHeader.Get("ETag"), body
+}
@@ -0,0 +1,253 @@
+package tests
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/ShoshinNikita/budget-manager/internal/db"
+	"github.com/ShoshinNikita/budget-manager/internal/pkg/money"
+	"github.com/ShoshinNikita/budget-manager/internal/web/api/models"
+	"github.com/ShoshinNikita/budget-manager/internal/webhooks"
+)
+
+func TestWebhooks(t *testing.T) {
+	t.Parallel()
+
+	RunTest(t, TestCases{
+		{Name: "manage", Fn: testWebhooks_Manage},
+		{Name: "deliveries", Fn: testWebhooks_Deliveries},
+	})
+}
+
+func testWebhooks_Manage(t *testing.T, host string) {
+	require := require.New(t)
+
+	var addResp models.AddWebhookResp
+	RequestCreated{POST, WebhooksPath, models.AddWebhookReq{
+		URL: "https://example.com/hook", Events: []db.WebhookEvent{db.WebhookEventSpendCreated}, Enabled: true,
+	}}.Send(t, host, &addResp)
+	require.Equal(uint(1), addResp.ID)
+	require.Len(addResp.Secret, 64)
+
+	RequestCreated{POST, WebhooksPath, models.AddWebhookReq{
+		URL: "http://localhost/hook", Secret: "qwerty", Events: []db.WebhookEvent{db.WebhookEventIncomeDeleted},
+	}}.Send(t, host, &addResp)
+	require.Equal(models.AddWebhookResp{ID: 2, Secret: "qwerty", BaseResponse: addResp.BaseResponse}, addResp)
+
+	for _, req := range []Request{
+		{POST, WebhooksPath, models.AddWebhookReq{Events: []db.WebhookEvent{"spend.created"}}, 400, "url can't be empty"},
+		{
+			POST, WebhooksPath, models.AddWebhookReq{URL: "/hook", Events: []db.WebhookEvent{"spend.created"}},
+			400, "url must be an absolute http or https url",
+		},
+		{POST, WebhooksPath, models.AddWebhookReq{URL: "http://localhost"}, 400, "events can't be empty"},
+		{
+			POST, WebhooksPath, models.AddWebhookReq{URL: "http://localhost", Events: []db.WebhookEvent{"spend"}},
+			400, `invalid event: "spend"`,
+		},
+		{GET, WebhooksPath + "/10", nil, 404, "such Webhook doesn't exist"},
+		{PUT, WebhooksPath, models.EditWebhookReq{ID: 10, Enabled: ptrBool(true)}, 404, "such Webhook doesn't exist"},
+		{PUT, WebhooksPath, models.EditWebhookReq{ID: 1, Secret: ptrStr("")}, 400, "secret can't be empty"},
+		{DELETE, WebhooksPath, models.RemoveWebhookReq{ID: 10}, 404, "such Webhook doesn't exist"},
+		{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{WebhookID: 10}, 404, "such Webhook doesn't exist"},
+		{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{Status: "ok"}, 400, `invalid status: "ok"`},
+		//
+		{
+			PUT, WebhooksPath, models.EditWebhookReq{
+				ID: 2, Events: &[]db.WebhookEvent{db.WebhookEventIncomeCreated, db.WebhookEventIncomeDeleted},
+				Enabled: ptrBool(true),
+			},
+			200, "",
+		},
+		{DELETE, WebhooksPath, models.RemoveWebhookReq{ID: 1}, 200, ""},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	var resp models.GetWebhooksResp
+	RequestOK{GET, WebhooksPath, nil}.Send(t, host, &resp)
+	require.Equal(
+		[]db.Webhook{
+			{
+				ID: 2, URL: "http://localhost/hook", Enabled: true,
+				Events: []db.WebhookEvent{db.WebhookEventIncomeCreated, db.WebhookEventIncomeDeleted},
+			},
+		},
+		resp.Webhooks,
+	)
+
+	var webhookResp models.GetWebhookResp
+	RequestOK{GET, WebhooksPath + "/2", nil}.Send(t, host, &webhookResp)
+	require.Equal(resp.Webhooks[0], webhookResp.Webhook)
+}
+
+type webhookRequest struct {
+	Header http.Header
+	Body   []byte
+}
+
+type webhookReceiver struct {
+	mu       sync.Mutex
+	requests []webhookRequest
+}
+
+func (rcv *webhookReceiver) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := ioutil.ReadAll(r.Body)
+
+	rcv.mu.Lock()
+	rcv.requests = append(rcv.requests, webhookRequest{Header: r.Header, Body: body})
+	rcv.mu.Unlock()
+}
+
+func (rcv *webhookReceiver) Requests() []webhookRequest {
+	rcv.mu.Lock()
+	defer rcv.mu.Unlock()
+
+	return append([]webhookRequest(nil), rcv.requests...)
+}
+
+func testWebhooks_Deliveries(t *testing.T, host string) {
+	require := require.New(t)
+
+	receiver := &webhookReceiver{}
+	server := httptest.NewServer(receiver)
+	defer server.Close()
+
+	failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer failingServer.Close()
+
+	// Remove Webhooks added by other tests
+	var webhooksResp models.GetWebhooksResp
+	RequestOK{GET, WebhooksPath, nil}.Send(t, host, &webhooksResp)
+	for _, w := range webhooksResp.Webhooks {
+		RequestOK{DELETE, WebhooksPath, models.RemoveWebhookReq{ID: w.ID}}.Send(t, host, nil)
+	}
+
+	var webhookIDs []uint
+	for _, req := range []RequestCreated{
+		{POST, WebhooksPath, models.AddWebhookReq{
+			URL: server.URL, Secret: "secret", Enabled: true,
+			Events: []db.WebhookEvent{
+				db.WebhookEventIncomeCreated,
+				db.WebhookEventSpendCreated, db.WebhookEventSpendUpdated, db.WebhookEventSpendDeleted,
+			},
+		}},
+		{POST, WebhooksPath, models.AddWebhookReq{
+			URL: failingServer.URL, Enabled: true, Events: []db.WebhookEvent{db.WebhookEventIncomeCreated},
+		}},
+		// Disabled Webhooks are ignored
+		{POST, WebhooksPath, models.AddWebhookReq{
+			URL: server.URL, Events: []db.WebhookEvent{db.WebhookEventIncomeCreated},
+		}},
+	} {
+		var resp models.AddWebhookResp
+		req.Send(t, host, &resp)
+		webhookIDs = append(webhookIDs, resp.ID)
+	}
+
+	for _, req := range []RequestCreated{
+		{POST, IncomesPath, models.AddIncomeReq{MonthID: 1, Title: "salary", Income: 3000}},
+		{POST, MonthlyPaymentsPath, models.AddMonthlyPaymentReq{MonthID: 1, Title: "rent", Cost: 1000}},
+		{POST, SpendsPath, models.AddSpendReq{DayID: 1, Title: "coffee", Cost: 10}},
+	} {
+		req.Send(t, host, nil)
+	}
+	for _, req := range []RequestOK{
+		{PUT, SpendsPath, models.EditSpendReq{ID: 1, Cost: ptrFloat(20)}},
+		{DELETE, SpendsPath, models.RemoveSpendReq{ID: 1}},
+	} {
+		req.Send(t, host, nil)
+	}
+
+	require.Eventually(func() bool {
+		return len(receiver.Requests()) >= 4
+	}, 5*time.Second, 50*time.Millisecond)
+
+	type payload struct {
+		Event db.WebhookEvent  `json:"event"`
+		Data  json.RawMessage  `json:"data"`
+		Month db.MonthOverview `json:"month"`
+	}
+	var (
+		requests = receiver.Requests()
+		payloads = make([]payload, 0, len(requests))
+	)
+	require.Len(requests, 4)
+	for _, req := range requests {
+		signature := webhooks.Sign("secret", req.Header.Get(webhooks.TimestampHeader), req.Body)
+		require.Equal(signature, req.Header.Get(webhooks.SignatureHeader))
+
+		var p payload
+		require.NoError(json.Unmarshal(req.Body, &p))
+		require.Equal(string(p.Event), req.Header.Get(webhooks.EventHeader))
+		payloads = append(payloads, p)
+	}
+
+	require.Equal(db.WebhookEventIncomeCreated, payloads[0].Event)
+	require.Equal(money.FromInt(3000), payloads[0].Month.TotalIncome)
+	require.Equal(money.FromInt(3000), payloads[0].Month.Result)
+
+	var income db.Income
+	require.NoError(json.Unmarshal(payloads[0].Data, &income))
+	require.Equal("salary", income.Title)
+
+	// Month totals are recomputed before the event is sent
+	for i, want := range []struct {
+		event  db.WebhookEvent
+		cost   money.Money
+		result money.Money
+	}{
+		{db.WebhookEventSpendCreated, money.FromInt(10), money.FromInt(1990)},
+		{db.WebhookEventSpendUpdated, money.FromInt(20), money.FromInt(1980)},
+		{db.WebhookEventSpendDeleted, money.FromInt(20), money.FromInt(2000)},
+	} {
+		p := payloads[i+1]
+		require.Equal(want.event, p.Event)
+		require.Equal(want.result, p.Month.Result)
+
+		var spend db.Spend
+		require.NoError(json.Unmarshal(p.Data, &spend))
+		require.Equal(uint(1), spend.ID)
+		require.Equal("coffee", spend.Title)
+		require.Equal(want.cost, spend.Cost)
+	}
+
+	// Delivery log
+	var resp models.GetWebhookDeliveriesResp
+	RequestOK{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{WebhookID: webhookIDs[0]}}.Send(t, host, &resp)
+	require.Len(resp.Deliveries, 4)
+	require.Equal(db.WebhookEventSpendDeleted, resp.Deliveries[0].Event)
+	for _, d := range resp.Deliveries {
+		require.Equal(db.WebhookDeliverySucceeded, d.Status)
+		require.Equal(1, d.Attempts)
+		require.Equal(http.StatusOK, d.ResponseCode)
+	}
+
+	// The failed delivery is retried
+	require.Eventually(func() bool {
+		var resp models.GetWebhookDeliveriesResp
+		RequestOK{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{
+			Status: db.WebhookDeliveryFailed,
+		}}.Send(t, host, &resp)
+		return len(resp.Deliveries) == 1
+	}, 5*time.Second, 50*time.Millisecond)
+
+	RequestOK{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{WebhookID: webhookIDs[1]}}.Send(t, host, &resp)
+	require.Len(resp.Deliveries, 1)
+	require.Equal(db.WebhookDeliveryFailed, resp.Deliveries[0].Status)
+	require.Equal(3, resp.Deliveries[0].Attempts)
+	require.Equal(http.StatusServiceUnavailable, resp.Deliveries[0].ResponseCode)
+	require.Equal("unexpected status code: 503", resp.Deliveries[0].Error)
+
+	RequestOK{GET, WebhookDeliveriesPath, models.GetWebhookDeliveriesReq{WebhookID: webhookIDs[2]}}.Send(t, host, &resp)
+	require.Empty(resp.Deliveries)
+}